@@ -0,0 +1,24 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package all registers every endpoint type perftester supports, for
+// programs which test any of them:
+//
+//	import _ "storj.io/perftester/backends/all"
+package all
+
+import (
+	// Register the endpoint types.
+	_ "storj.io/perftester/backends/driveclient"
+	_ "storj.io/perftester/backends/dropboxclient"
+	_ "storj.io/perftester/backends/execclient"
+	_ "storj.io/perftester/backends/ftpclient"
+	_ "storj.io/perftester/backends/gcsclient"
+	_ "storj.io/perftester/backends/httpclient"
+	_ "storj.io/perftester/backends/ipfsclient"
+	_ "storj.io/perftester/backends/minioclient"
+	_ "storj.io/perftester/backends/rcloneclient"
+	_ "storj.io/perftester/backends/s3client"
+	_ "storj.io/perftester/backends/storjclient"
+	_ "storj.io/perftester/backends/webdavclient"
+)
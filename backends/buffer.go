@@ -0,0 +1,54 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package backends
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultBufferSize is the size of the buffers transfers are copied
+// through. io.Copy's 32KiB buffers measurably limit the throughput of
+// high-bandwidth links.
+const DefaultBufferSize = 1 << 20
+
+var (
+	bufferSize int64 = DefaultBufferSize // accessed atomically
+	bufferPool sync.Pool
+)
+
+// SetBufferSize sets the size of the buffers Copy uses.
+func SetBufferSize(size int) {
+	atomic.StoreInt64(&bufferSize, int64(size))
+}
+
+// getBuffer returns a pooled buffer of the current buffer size.
+func getBuffer() *[]byte {
+	size := int(atomic.LoadInt64(&bufferSize))
+	if buf, ok := bufferPool.Get().(*[]byte); ok && len(*buf) == size {
+		return buf
+	}
+	buf := make([]byte, size)
+	return &buf
+}
+
+// Copy copies r to w through a pooled buffer. Unlike io.Copy, it always
+// uses the buffer, since the io.ReaderFrom of writers such as
+// ioutil.Discard copies in small chunks.
+func Copy(w io.Writer, r io.Reader) (written int64, err error) {
+	buf := getBuffer()
+	defer bufferPool.Put(buf)
+	return io.CopyBuffer(struct{ io.Writer }{w}, struct{ io.Reader }{r}, *buf)
+}
+
+// CopyN copies n bytes from r to w like io.CopyN, but through a pooled
+// buffer.
+func CopyN(w io.Writer, r io.Reader, n int64) (written int64, err error) {
+	written, err = Copy(w, io.LimitReader(r, n))
+	if written < n && err == nil {
+		err = io.EOF
+	}
+	return written, err
+}
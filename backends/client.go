@@ -0,0 +1,195 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package backends defines the interface of the clients of the storage
+// services perftester tests and the registry of endpoint types, which the
+// packages of the individual backends, such as s3client, register their
+// types with.
+package backends
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// ErrUnsupported is the error class of operations a client doesn't support.
+var ErrUnsupported = errs.Class("unsupported operation")
+
+// Client represents a storage client. Operations stop when their context is
+// done, including the streams of downloads, so that the timeouts of checks
+// abort stalled transfers.
+type Client interface {
+	List(ctx context.Context, prefix string, recursive bool) (obj []*ListObject, err error)
+	Upload(ctx context.Context, name string, strm io.Reader) (err error)
+	// UploadMultipart uploads strm in parts of partSize bytes, uploading up
+	// to concurrency parts at once where the backend supports it.
+	UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []Part, err error)
+	Download(ctx context.Context, name string) (strm io.ReadCloser, err error)
+	// DownloadRange downloads length bytes starting at offset. A negative
+	// length downloads until the end of the object.
+	DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error)
+	Delete(ctx context.Context, name string) (err error)
+	// Copy copies the object src to dst on the server, without
+	// transferring its data through the client.
+	Copy(ctx context.Context, src, dst string) (err error)
+	// CreateBucket creates a new bucket next to the bucket of the client.
+	CreateBucket(ctx context.Context, bucket string) (err error)
+	// ListBuckets returns the names of all buckets.
+	ListBuckets(ctx context.Context) (buckets []string, err error)
+	// DeleteBucket deletes an empty bucket.
+	DeleteBucket(ctx context.Context, bucket string) (err error)
+	IP(ctx context.Context) (addr string, err error)
+	Close() (err error)
+}
+
+// ReadOnly is implemented by clients which can only download existing
+// objects. Their other operations fail with ErrUnsupported.
+type ReadOnly interface {
+	ReadOnly() bool
+}
+
+// IsReadOnly returns whether client can only download existing objects.
+func IsReadOnly(client Client) bool {
+	readOnly, ok := client.(ReadOnly)
+	return ok && readOnly.ReadOnly()
+}
+
+// Addresser is implemented by clients which can report the network address
+// they connect to, so that their connection setup can be measured.
+type Addresser interface {
+	// NetworkAddress returns the host:port the client connects to and
+	// whether its connections use TLS.
+	NetworkAddress() (address string, useTLS bool, err error)
+}
+
+// Tuned is implemented by clients with tunable transfer settings, which
+// describe the settings they use so that reports record them.
+type Tuned interface {
+	Settings() string
+}
+
+// ServerDescriber is implemented by clients which can describe the servers
+// they connect to, such as the version and health of a self-hosted cluster,
+// so that reports record the state results were measured in.
+type ServerDescriber interface {
+	DescribeServer(ctx context.Context) (string, error)
+}
+
+// Versioner is implemented by clients of backends which keep versions of
+// objects.
+type Versioner interface {
+	// UploadVersion uploads a new version of the object and returns its
+	// version ID.
+	UploadVersion(ctx context.Context, name string, strm io.Reader) (versionID string, err error)
+	// ListVersions returns the IDs of the versions of the object.
+	ListVersions(ctx context.Context, name string) (versionIDs []string, err error)
+	// DeleteVersion deletes a single version of the object.
+	DeleteVersion(ctx context.Context, name, versionID string) (err error)
+}
+
+// Stater is implemented by clients which can look up a single object
+// without listing or downloading it.
+type Stater interface {
+	// Stat returns the object, or nil if it doesn't exist.
+	Stat(ctx context.Context, name string) (obj *ListObject, err error)
+}
+
+// Pager is implemented by clients which can choose how many entries the
+// pages of their lists have.
+type Pager interface {
+	// ListPages lists like List, requesting pages of pageSize entries, and
+	// returns how many pages the list took. It stops once it has maxKeys
+	// entries, unless maxKeys is zero. A zero pageSize requests the
+	// backend's default page size.
+	ListPages(ctx context.Context, prefix string, recursive bool, pageSize, maxKeys int) (obj []*ListObject, pages int, err error)
+}
+
+// URLAddress returns the host:port of u, with the default port of its
+// scheme, and whether it uses TLS.
+func URLAddress(u *url.URL) (address string, useTLS bool) {
+	useTLS = u.Scheme != "http"
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if !useTLS {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port), useTLS
+}
+
+// NodeStats are the storage node level stats of downloads from a
+// decentralized backend, which splits objects into erasure coded pieces.
+type NodeStats struct {
+	// Nodes is the number of storage nodes piece downloads were started on.
+	Nodes int64
+	// Failed is the number of piece downloads which failed.
+	Failed int64
+	// Cancelled is the number of piece downloads cancelled as the long
+	// tail, once enough pieces of a segment had been downloaded.
+	Cancelled int64
+	// Bytes is the number of bytes received from storage nodes, which
+	// exceeds the size of the objects by the erasure overhead.
+	Bytes int64
+}
+
+// Add adds the stats of other to stats.
+func (stats *NodeStats) Add(other NodeStats) {
+	stats.Nodes += other.Nodes
+	stats.Failed += other.Failed
+	stats.Cancelled += other.Cancelled
+	stats.Bytes += other.Bytes
+}
+
+// NodeStatsReader is implemented by download streams which know the
+// NodeStats of their download once they are closed.
+type NodeStatsReader interface {
+	NodeStats() NodeStats
+}
+
+// ListObject is an object type that can be used by any client.
+type ListObject struct {
+	// Key is the full name of the object, as Download and Delete take it,
+	// and IsPre whether it is a prefix rather than an object.
+	Key   string
+	IsPre bool
+	// Size is the size of the object in bytes, and LastModified when it was
+	// last written, where the client knows them. They are unset for
+	// prefixes.
+	Size         int64
+	LastModified time.Time
+}
+
+// Part is the timing of a single uploaded part.
+type Part struct {
+	Number   int
+	Size     int64
+	Duration time.Duration
+}
+
+// CopyParts copies r to w in chunks of partSize bytes, timing each chunk.
+// It is used by backends which upload parts sequentially from a stream.
+func CopyParts(w io.Writer, r io.Reader, partSize int64) (parts []Part, err error) {
+	for number := 1; ; number++ {
+		start := time.Now()
+		n, err := CopyN(w, r, partSize)
+		if n > 0 {
+			parts = append(parts, Part{
+				Number:   number,
+				Size:     n,
+				Duration: time.Since(start),
+			})
+		}
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return parts, err
+		}
+	}
+}
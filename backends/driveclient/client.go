@@ -0,0 +1,461 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package driveclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	drive "google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("drive-client")
+
+	// errNotFound is the error class of files and folders which don't exist.
+	errNotFound = errs.Class("not found")
+)
+
+func init() {
+	cli.Register("drive", func() cli.EndpointConfig { return &Config{} })
+}
+
+const folderMimeType = "application/vnd.google-apps.folder"
+
+// Config is the config of [endpoint.drive.<id>] tables, which benchmark
+// Google Drive. Files are stored in the folder named by the bucket in the
+// root of My Drive.
+//
+// Consumer accounts authenticate with the client ID, client secret and
+// refresh token of an OAuth client, such as those rclone config creates.
+type Config struct {
+	ClientID     string `toml:"client_id"`
+	ClientSecret string `toml:"client_secret" secret:"true"`
+	RefreshToken string `toml:"refresh_token" secret:"true"`
+	// CredentialsFile is a service account JSON key or the authorized user
+	// credentials of gcloud, used instead of the OAuth client.
+	CredentialsFile string `toml:"credentials_file"`
+	Bucket          string `toml:"bucket"` // Folder in the root of My Drive.
+	Path            string `toml:"path"`
+
+	config.EndpointDefaults
+}
+
+// Validate checks that the endpoint has credentials.
+func (cfg *Config) Validate() error {
+	if cfg.CredentialsFile == "" && (cfg.ClientID == "" || cfg.ClientSecret == "" || cfg.RefreshToken == "") {
+		return errs.New("either a credentials file or an OAuth client id, client secret and refresh token are required")
+	}
+	return nil
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *Config) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(ctx, *cfg)
+}
+
+// Client is a Google Drive client. Drive addresses files by ID rather than
+// path, so every object is a file in the folder of the bucket named by its
+// full key, and the client remembers the IDs of the files it finds.
+type Client struct {
+	cfg     Config
+	service *drive.Service
+
+	mu       sync.Mutex
+	folderID string
+	ids      map[string]string
+}
+
+// New creates a new Google Drive client.
+func New(ctx context.Context, cfg Config) (*Client, error) {
+	if cfg.Bucket == "" {
+		return nil, errs.New("bucket is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	opts := []option.ClientOption{option.WithScopes(drive.DriveScope)}
+	if cfg.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	} else {
+		oauthConfig := &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Endpoint:     google.Endpoint,
+			Scopes:       []string{drive.DriveScope},
+		}
+		// The token source refreshes tokens with a background context, so
+		// it outlives the context of the client.
+		tokens := oauthConfig.TokenSource(context.Background(), &oauth2.Token{RefreshToken: cfg.RefreshToken})
+		opts = append(opts, option.WithTokenSource(tokens))
+	}
+
+	service, err := drive.NewService(ctx, opts...)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return &Client{
+		cfg:     cfg,
+		service: service,
+		ids:     make(map[string]string),
+	}, nil
+}
+
+// List lists the files in the folder of the bucket with the prefix name.
+// Drive can't filter names by prefix, so every file of the folder is
+// listed.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	folderID, err := client.folder(ctx)
+	if err != nil {
+		return nil, Error.New("failed to list %q: %v", name, err)
+	}
+
+	prefix := client.fileName(name)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	seen := make(map[string]bool)
+	err = client.service.Files.List().
+		Q(fmt.Sprintf("'%s' in parents and trashed = false", folderID)).
+		Fields("nextPageToken", "files(id, name, size, modifiedTime)").
+		PageSize(1000).
+		Pages(ctx, func(page *drive.FileList) error {
+			for _, file := range page.Files {
+				if !strings.HasPrefix(file.Name, prefix) {
+					continue
+				}
+				client.remember(file.Name, file.Id)
+
+				key := client.key(file.Name)
+				rest := strings.TrimPrefix(file.Name, prefix)
+				if i := strings.Index(rest, "/"); !recursive && i >= 0 {
+					key = client.key(prefix + rest[:i+1])
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+					objs = append(objs, &cli.ListObject{Key: key, IsPre: true})
+					continue
+				}
+				modified, _ := time.Parse(time.RFC3339, file.ModifiedTime)
+				objs = append(objs, &cli.ListObject{Key: key, Size: file.Size, LastModified: modified})
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, Error.New("failed to list %q: %v", name, err)
+	}
+	return objs, nil
+}
+
+// Upload uploads the file in a single request.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.upload(ctx, name, strm, googleapi.ChunkSize(0))
+}
+
+// UploadMultipart uploads the file with a resumable upload in chunks of
+// partSize bytes, which Drive rounds up to a multiple of 256 KiB. The
+// chunks are uploaded in sequence, so concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	pipeReader, pipeWriter := io.Pipe()
+	uploadErr := make(chan error, 1)
+	go func() {
+		err := client.upload(ctx, name, pipeReader, googleapi.ChunkSize(int(partSize)))
+		// Unblock the writer when the upload ends early.
+		_ = pipeReader.CloseWithError(errs.New("upload ended"))
+		uploadErr <- err
+	}()
+
+	parts, err = cli.CopyParts(pipeWriter, strm, partSize)
+	_ = pipeWriter.CloseWithError(err)
+
+	if err := <-uploadErr; err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+func (client *Client) upload(ctx context.Context, name string, strm io.Reader, chunkSize googleapi.MediaOption) error {
+	folderID, err := client.folder(ctx)
+	if err != nil {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+
+	// Drive allows several files of the same name, so existing files are
+	// updated rather than another one created next to them. Files the
+	// client doesn't know of yet cost a lookup.
+	fileName := client.fileName(name)
+	id, err := client.lookup(ctx, fileName)
+	if err != nil && !errNotFound.Has(err) {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+
+	var file *drive.File
+	if id != "" {
+		file, err = client.service.Files.Update(id, &drive.File{}).
+			Media(strm, chunkSize).Fields("id").Context(ctx).Do()
+	} else {
+		file, err = client.service.Files.Create(&drive.File{Name: fileName, Parents: []string{folderID}}).
+			Media(strm, chunkSize).Fields("id").Context(ctx).Do()
+	}
+	if err != nil {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+	client.remember(fileName, file.Id)
+	return nil
+}
+
+// Download downloads the file.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.download(ctx, name, "")
+}
+
+// DownloadRange downloads a byte range of the file.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange += fmt.Sprint(offset + length - 1)
+	}
+	return client.download(ctx, name, byteRange)
+}
+
+func (client *Client) download(ctx context.Context, name, byteRange string) (io.ReadCloser, error) {
+	id, err := client.lookup(ctx, client.fileName(name))
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+
+	call := client.service.Files.Get(id).Context(ctx)
+	if byteRange != "" {
+		call.Header().Set("Range", byteRange)
+	}
+	resp, err := call.Download()
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete deletes the file permanently, skipping the trash.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	fileName := client.fileName(name)
+	id, err := client.lookup(ctx, fileName)
+	if err != nil {
+		return Error.New("failed to delete file %q: %v", name, err)
+	}
+	if err := client.service.Files.Delete(id).Context(ctx).Do(); err != nil {
+		return Error.New("failed to delete file %q: %v", name, err)
+	}
+	client.forget(fileName)
+	return nil
+}
+
+// Copy copies the file within the folder of the bucket.
+func (client *Client) Copy(ctx context.Context, src, dst string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	folderID, err := client.folder(ctx)
+	if err != nil {
+		return Error.New("failed to copy file %q: %v", src, err)
+	}
+	id, err := client.lookup(ctx, client.fileName(src))
+	if err != nil {
+		return Error.New("failed to copy file %q: %v", src, err)
+	}
+
+	dstName := client.fileName(dst)
+	file, err := client.service.Files.Copy(id, &drive.File{Name: dstName, Parents: []string{folderID}}).
+		Fields("id").Context(ctx).Do()
+	if err != nil {
+		return Error.New("failed to copy file %q: %v", src, err)
+	}
+	client.remember(dstName, file.Id)
+	return nil
+}
+
+// CreateBucket creates a folder in the root of My Drive.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	_, err = client.service.Files.Create(&drive.File{Name: bucket, MimeType: folderMimeType, Parents: []string{"root"}}).
+		Fields("id").Context(ctx).Do()
+	return Error.Wrap(err)
+}
+
+// ListBuckets lists the folders in the root of My Drive.
+func (client *Client) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = client.service.Files.List().
+		Q(fmt.Sprintf("'root' in parents and mimeType = '%s' and trashed = false", folderMimeType)).
+		Fields("nextPageToken", "files(name)").
+		PageSize(1000).
+		Pages(ctx, func(page *drive.FileList) error {
+			for _, file := range page.Files {
+				buckets = append(buckets, file.Name)
+			}
+			return nil
+		})
+	return buckets, Error.Wrap(err)
+}
+
+// DeleteBucket deletes a folder in the root of My Drive. Drive deletes the
+// files in folders along with them, so the folder isn't checked to be
+// empty.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	id, err := client.find(ctx, "root", bucket, true)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	return Error.Wrap(client.service.Files.Delete(id).Context(ctx).Do())
+}
+
+// IP returns the host of the Drive API.
+func (client *Client) IP(ctx context.Context) (addr string, err error) {
+	// Like GCS, the Drive API resolves to a range of IPs, which the network
+	// diagnostics record, so only its host is returned.
+	return "www.googleapis.com", nil
+}
+
+// NetworkAddress returns the address of the Drive API.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	return "www.googleapis.com:443", true, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() (err error) {
+	return nil
+}
+
+// folder returns the ID of the folder of the bucket.
+func (client *Client) folder(ctx context.Context) (string, error) {
+	client.mu.Lock()
+	folderID := client.folderID
+	client.mu.Unlock()
+	if folderID != "" {
+		return folderID, nil
+	}
+
+	folderID, err := client.find(ctx, "root", client.cfg.Bucket, true)
+	if err != nil {
+		return "", errs.New("folder %q: %v", client.cfg.Bucket, err)
+	}
+
+	client.mu.Lock()
+	client.folderID = folderID
+	client.mu.Unlock()
+	return folderID, nil
+}
+
+// lookup returns the ID of the file named fileName in the folder of the
+// bucket.
+func (client *Client) lookup(ctx context.Context, fileName string) (string, error) {
+	client.mu.Lock()
+	id, ok := client.ids[fileName]
+	client.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	folderID, err := client.folder(ctx)
+	if err != nil {
+		return "", err
+	}
+	id, err = client.find(ctx, folderID, fileName, false)
+	if err != nil {
+		return "", err
+	}
+	client.remember(fileName, id)
+	return id, nil
+}
+
+// find returns the ID of the file or folder named name in the folder
+// parentID.
+func (client *Client) find(ctx context.Context, parentID, name string, isFolder bool) (string, error) {
+	query := fmt.Sprintf("'%s' in parents and name = '%s' and trashed = false", parentID, escapeQuery(name))
+	if isFolder {
+		query += fmt.Sprintf(" and mimeType = '%s'", folderMimeType)
+	}
+
+	list, err := client.service.Files.List().Q(query).Fields("files(id)").PageSize(1).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	if len(list.Files) == 0 {
+		return "", errNotFound.New("%q", name)
+	}
+	return list.Files[0].Id, nil
+}
+
+func (client *Client) remember(fileName, id string) {
+	client.mu.Lock()
+	client.ids[fileName] = id
+	client.mu.Unlock()
+}
+
+func (client *Client) forget(fileName string) {
+	client.mu.Lock()
+	delete(client.ids, fileName)
+	client.mu.Unlock()
+}
+
+// fileName returns the name of the file of the key name.
+func (client *Client) fileName(name string) string {
+	if client.cfg.Path != "" {
+		return path.Join(client.cfg.Path, name)
+	}
+	return name
+}
+
+// key returns the key of the file named fileName, relative to the path of
+// the client.
+func (client *Client) key(fileName string) string {
+	if client.cfg.Path == "" {
+		return fileName
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(fileName, strings.TrimSuffix(client.cfg.Path, "/")), "/")
+}
+
+// escapeQuery escapes a string for the quoted values of Drive queries.
+func escapeQuery(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
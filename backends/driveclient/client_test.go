@@ -0,0 +1,128 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package driveclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	drive "google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+
+	"storj.io/common/testcontext"
+)
+
+var (
+	parentPattern = regexp.MustCompile(`'([^']*)' in parents`)
+	namePattern   = regexp.MustCompile(`name = '((?:[^'\\]|\\.)*)'`)
+)
+
+// driveServer is a fake Drive API with a folder, which records the files
+// created and updated.
+type driveServer struct {
+	mu      sync.Mutex
+	files   map[string]string // IDs of the files in the folder by name.
+	created []string
+	updated []string
+}
+
+func (server *driveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	var file drive.File
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/drive/v3/files":
+		query := r.URL.Query().Get("q")
+		parent := parentPattern.FindStringSubmatch(query)[1]
+		name := strings.ReplaceAll(namePattern.FindStringSubmatch(query)[1], `\'`, `'`)
+		var list drive.FileList
+		switch {
+		case parent == "root" && name == "bucket":
+			list.Files = append(list.Files, &drive.File{Id: "folder"})
+		case parent == "folder" && server.files[name] != "":
+			list.Files = append(list.Files, &drive.File{Id: server.files[name]})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+		return
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/drive/v3/files":
+		metadata, err := readMetadata(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file.Id = fmt.Sprintf("id%d", len(server.created))
+		server.files[metadata.Name] = file.Id
+		server.created = append(server.created, metadata.Name)
+	case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/upload/drive/v3/files/"):
+		if _, err := readMetadata(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file.Id = strings.TrimPrefix(r.URL.Path, "/upload/drive/v3/files/")
+		server.updated = append(server.updated, file.Id)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(file)
+}
+
+// readMetadata reads the file metadata and contents of a multipart upload.
+func readMetadata(r *http.Request) (*drive.File, error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	parts := multipart.NewReader(r.Body, params["boundary"])
+	part, err := parts.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	var metadata drive.File
+	if err := json.NewDecoder(part).Decode(&metadata); err != nil {
+		return nil, err
+	}
+	part, err = parts.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	_, err = ioutil.ReadAll(part)
+	return &metadata, err
+}
+
+func TestUploadUpdatesExisting(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	// A file of a previous run.
+	fake := &driveServer{files: map[string]string{"obj": "old"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	service, err := drive.NewService(ctx, option.WithEndpoint(server.URL+"/drive/v3/"), option.WithoutAuthentication())
+	require.NoError(t, err)
+	client := &Client{cfg: Config{Bucket: "bucket"}, service: service, ids: make(map[string]string)}
+
+	// Files of the same name are updated rather than duplicated, whether
+	// the client uploaded them or not.
+	require.NoError(t, client.Upload(ctx, "obj", strings.NewReader("data")))
+	require.NoError(t, client.Upload(ctx, "new", strings.NewReader("data")))
+	require.NoError(t, client.Upload(ctx, "new", strings.NewReader("data")))
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	require.Equal(t, []string{"new"}, fake.created)
+	require.Equal(t, []string{"old", "id0"}, fake.updated)
+}
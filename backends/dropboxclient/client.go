@@ -0,0 +1,454 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package dropboxclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("dropbox-client")
+)
+
+func init() {
+	cli.Register("dropbox", func() cli.EndpointConfig { return &Config{} })
+}
+
+const (
+	apiURL     = "https://api.dropboxapi.com/2/"
+	contentURL = "https://content.dropboxapi.com/2/"
+	tokenURL   = "https://api.dropboxapi.com/oauth2/token"
+)
+
+// Config is the config of [endpoint.dropbox.<id>] tables, which benchmark
+// Dropbox. Files are stored in the folder named by the bucket in the root
+// of the Dropbox.
+//
+// Endpoints authenticate with the app key, app secret and refresh token of
+// a Dropbox app, or with a short-lived access token.
+type Config struct {
+	AppKey       string `toml:"app_key"`
+	AppSecret    string `toml:"app_secret" secret:"true"`
+	RefreshToken string `toml:"refresh_token" secret:"true"`
+	AccessToken  string `toml:"access_token" secret:"true"`
+	Bucket       string `toml:"bucket"` // Folder in the root of the Dropbox.
+	Path         string `toml:"path"`
+
+	config.EndpointDefaults
+}
+
+// Validate checks that the endpoint has credentials.
+func (cfg *Config) Validate() error {
+	if cfg.AccessToken == "" && (cfg.AppKey == "" || cfg.AppSecret == "" || cfg.RefreshToken == "") {
+		return errs.New("either an access token or an app key, app secret and refresh token are required")
+	}
+	return nil
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *Config) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(*cfg)
+}
+
+// Client is a Dropbox client using the HTTP API.
+type Client struct {
+	cfg    Config
+	client *http.Client
+}
+
+// New creates a new Dropbox client.
+func New(cfg Config) (*Client, error) {
+	if cfg.Bucket == "" {
+		return nil, errs.New("bucket is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var tokens oauth2.TokenSource
+	if cfg.RefreshToken != "" {
+		oauthConfig := &oauth2.Config{
+			ClientID:     cfg.AppKey,
+			ClientSecret: cfg.AppSecret,
+			Endpoint:     oauth2.Endpoint{TokenURL: tokenURL},
+		}
+		// The token source refreshes tokens with a background context, so
+		// it outlives the context of the client.
+		tokens = oauthConfig.TokenSource(context.Background(), &oauth2.Token{RefreshToken: cfg.RefreshToken})
+	} else {
+		tokens = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: cfg.AccessToken})
+	}
+
+	return &Client{
+		cfg:    cfg,
+		client: oauth2.NewClient(context.Background(), tokens),
+	}, nil
+}
+
+// metadata is the metadata of a file or folder.
+type metadata struct {
+	Tag            string    `json:".tag"`
+	Name           string    `json:"name"`
+	PathDisplay    string    `json:"path_display"`
+	Size           int64     `json:"size"`
+	ServerModified time.Time `json:"server_modified"`
+}
+
+// List lists the files and folders at name.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	entries, err := client.listFolder(ctx, client.filePath(name), recursive)
+	if err != nil {
+		return nil, Error.New("failed to list %q: %v", name, err)
+	}
+
+	// Dropbox paths are case insensitive, so the entries may spell the
+	// folder of the client in another case than the config.
+	root := client.filePath("")
+	for _, entry := range entries {
+		if len(entry.PathDisplay) <= len(root) {
+			continue
+		}
+		key := strings.TrimPrefix(entry.PathDisplay[len(root):], "/")
+		switch {
+		case entry.Tag == "file":
+			objs = append(objs, &cli.ListObject{Key: key, Size: entry.Size, LastModified: entry.ServerModified})
+		case entry.Tag == "folder" && !recursive:
+			objs = append(objs, &cli.ListObject{Key: key + "/", IsPre: true})
+		}
+	}
+	return objs, nil
+}
+
+// Upload uploads the file in a single request, which Dropbox limits to
+// 150 MiB.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = client.content(ctx, "files/upload", commitInfo{Path: client.filePath(name), Mode: "overwrite", Mute: true}, strm, "", nil)
+	if err != nil {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+	return nil
+}
+
+// commitInfo describes the file an upload creates.
+type commitInfo struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Mute bool   `json:"mute"`
+}
+
+// cursor is the position of an upload session.
+type cursor struct {
+	SessionID string `json:"session_id"`
+	Offset    int64  `json:"offset"`
+}
+
+// UploadMultipart uploads the file with an upload session, appending parts
+// of partSize bytes in sequence, so concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var session struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := client.content(ctx, "files/upload_session/start", struct{}{}, nil, "", &session); err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+
+	position := cursor{SessionID: session.SessionID}
+	buf := make([]byte, partSize)
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(strm, buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return nil, Error.New("failed to upload file %q: %v", name, err)
+		}
+
+		start := time.Now()
+		arg := struct {
+			Cursor cursor `json:"cursor"`
+		}{position}
+		if err := client.content(ctx, "files/upload_session/append_v2", arg, bytes.NewReader(buf[:n]), "", nil); err != nil {
+			return nil, Error.New("failed to upload part %d of file %q: %v", number, name, err)
+		}
+		parts = append(parts, cli.Part{Number: number, Size: int64(n), Duration: time.Since(start)})
+		position.Offset += int64(n)
+	}
+
+	arg := struct {
+		Cursor cursor     `json:"cursor"`
+		Commit commitInfo `json:"commit"`
+	}{position, commitInfo{Path: client.filePath(name), Mode: "overwrite", Mute: true}}
+	if err := client.content(ctx, "files/upload_session/finish", arg, nil, "", nil); err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// Download downloads the file.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.download(ctx, name, "")
+}
+
+// DownloadRange downloads a byte range of the file.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange += fmt.Sprint(offset + length - 1)
+	}
+	return client.download(ctx, name, byteRange)
+}
+
+func (client *Client) download(ctx context.Context, name, byteRange string) (io.ReadCloser, error) {
+	arg := struct {
+		Path string `json:"path"`
+	}{client.filePath(name)}
+
+	resp, err := client.contentResponse(ctx, "files/download", arg, nil, byteRange)
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	return resp.Body, nil
+}
+
+// Delete deletes the file.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := client.deletePath(ctx, client.filePath(name)); err != nil {
+		return Error.New("failed to delete file %q: %v", name, err)
+	}
+	return nil
+}
+
+// Copy copies the file on the server.
+func (client *Client) Copy(ctx context.Context, src, dst string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	arg := struct {
+		FromPath string `json:"from_path"`
+		ToPath   string `json:"to_path"`
+	}{client.filePath(src), client.filePath(dst)}
+	if err := client.rpc(ctx, "files/copy_v2", arg, nil); err != nil {
+		return Error.New("failed to copy file %q: %v", src, err)
+	}
+	return nil
+}
+
+// CreateBucket creates a folder in the root of the Dropbox.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	arg := struct {
+		Path string `json:"path"`
+	}{"/" + bucket}
+	return Error.Wrap(client.rpc(ctx, "files/create_folder_v2", arg, nil))
+}
+
+// ListBuckets lists the folders in the root of the Dropbox.
+func (client *Client) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	entries, err := client.listFolder(ctx, "", false)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	for _, entry := range entries {
+		if entry.Tag == "folder" {
+			buckets = append(buckets, entry.Name)
+		}
+	}
+	return buckets, nil
+}
+
+// DeleteBucket deletes a folder in the root of the Dropbox. Dropbox deletes
+// the files in folders along with them, so the folder isn't checked to be
+// empty.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return Error.Wrap(client.deletePath(ctx, "/"+bucket))
+}
+
+// IP returns the host of the content API.
+func (client *Client) IP(ctx context.Context) (addr string, err error) {
+	return "content.dropboxapi.com", nil
+}
+
+// NetworkAddress returns the address of the content API, which transfers
+// the data of files.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	return "content.dropboxapi.com:443", true, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() (err error) {
+	return nil
+}
+
+// listFolder lists the folder at dir, which is "" for the root.
+func (client *Client) listFolder(ctx context.Context, dir string, recursive bool) (entries []metadata, err error) {
+	var page struct {
+		Entries []metadata `json:"entries"`
+		Cursor  string     `json:"cursor"`
+		HasMore bool       `json:"has_more"`
+	}
+
+	arg := struct {
+		Path      string `json:"path"`
+		Recursive bool   `json:"recursive"`
+	}{dir, recursive}
+	if err := client.rpc(ctx, "files/list_folder", arg, &page); err != nil {
+		return nil, err
+	}
+	for {
+		entries = append(entries, page.Entries...)
+		if !page.HasMore {
+			return entries, nil
+		}
+
+		arg := struct {
+			Cursor string `json:"cursor"`
+		}{page.Cursor}
+		page.Entries = nil
+		if err := client.rpc(ctx, "files/list_folder/continue", arg, &page); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (client *Client) deletePath(ctx context.Context, filePath string) error {
+	arg := struct {
+		Path string `json:"path"`
+	}{filePath}
+	return client.rpc(ctx, "files/delete_v2", arg, nil)
+}
+
+// rpc calls an RPC endpoint of the API, which takes and returns JSON.
+func (client *Client) rpc(ctx context.Context, endpoint string, arg, result interface{}) error {
+	body, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if result == nil {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// content calls a content endpoint of the API, which takes its argument in
+// the Dropbox-API-Arg header and the data of the file as the body.
+func (client *Client) content(ctx context.Context, endpoint string, arg interface{}, body io.Reader, byteRange string, result interface{}) error {
+	resp, err := client.contentResponse(ctx, endpoint, arg, body, byteRange)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if result == nil {
+		_, err = io.Copy(ioutil.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (client *Client) contentResponse(ctx context.Context, endpoint string, arg interface{}, body io.Reader, byteRange string) (*http.Response, error) {
+	header, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, contentURL+endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Dropbox-API-Arg", asciiJSON(header))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+	return client.do(req)
+}
+
+func (client *Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := client.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errs.New("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// filePath returns the path of the file name in the folder of the bucket.
+func (client *Client) filePath(name string) string {
+	return path.Join("/", client.cfg.Bucket, client.cfg.Path, name)
+}
+
+// asciiJSON escapes the non-ASCII characters of JSON, which HTTP headers
+// can't hold.
+func asciiJSON(data []byte) string {
+	var s strings.Builder
+	for _, r := range string(data) {
+		switch {
+		case r < utf8.RuneSelf:
+			s.WriteRune(r)
+		case r > 0xFFFF:
+			r1, r2 := utf16.EncodeRune(r)
+			fmt.Fprintf(&s, `\u%04x\u%04x`, r1, r2)
+		default:
+			fmt.Fprintf(&s, `\u%04x`, r)
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,436 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package execclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("exec-client")
+)
+
+func init() {
+	cli.Register("exec", func() cli.EndpointConfig { return &Config{} })
+}
+
+// Config is the config of [endpoint.exec.<id>] tables, which run an
+// external command for each operation, so that backends without a native
+// client can be benchmarked, for example through rclone:
+//
+//	[endpoint.exec.rclone-b2]
+//	upload = ["rclone", "rcat", "b2:bucket/{name}"]
+//	download = ["rclone", "cat", "b2:bucket/{name}"]
+//	download_range = ["rclone", "cat", "--offset", "{offset}", "--count", "{length}", "b2:bucket/{name}"]
+//	delete = ["rclone", "deletefile", "b2:bucket/{name}"]
+//	list = ["rclone", "lsf", "--format", "psth", "--separator", "\t", "b2:bucket/{prefix}"]
+//	list_recursive = ["rclone", "lsf", "-R", "--files-only", "--format", "psth", "--separator", "\t", "b2:bucket/{prefix}"]
+//
+// {name} is replaced by the name of the object, {prefix} by the listed
+// prefix, {src} and {dst} by the objects of a copy and {offset} and {length}
+// by the range of a range download, whose length is -1 up to the end of the
+// object. Operations without a command are unsupported.
+type Config struct {
+	// Upload reads the object from stdin.
+	Upload []string `toml:"upload"`
+	// Download and DownloadRange write the object to stdout.
+	Download      []string `toml:"download"`
+	DownloadRange []string `toml:"download_range"`
+	Delete        []string `toml:"delete"`
+	// List prints the keys under the prefix one per line, relative to the
+	// prefix like rclone lsf does. Keys ending with a slash are prefixes.
+	// Each key may be followed by tab separated optional columns: the size
+	// in bytes and the modification time in RFC 3339 or rclone's
+	// "2006-01-02 15:04:05" local time format. Later columns are ignored.
+	// Empty columns and sizes of -1 are unknown. Cleanups by age need the
+	// modification times and existing tests with a size check the sizes.
+	List []string `toml:"list"`
+	// ListRecursive prints the keys under the prefix and all its
+	// subprefixes like List. Without it, recursive lists run List for
+	// every prefix it prints.
+	ListRecursive []string `toml:"list_recursive"`
+	Copy          []string `toml:"copy"`
+
+	config.EndpointDefaults
+}
+
+// Validate checks that the endpoint can at least upload or download.
+func (cfg *Config) Validate() error {
+	if len(cfg.Upload) == 0 && len(cfg.Download) == 0 {
+		return errs.New("needs an upload or download command")
+	}
+	return nil
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *Config) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(*cfg)
+}
+
+// Client runs an external command for each operation.
+type Client struct {
+	cfg Config
+}
+
+// New creates a new exec client.
+func New(cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg}, nil
+}
+
+// List runs the list command. Non-recursive lists return the prefixes the
+// command prints. Recursive lists run the recursive list command, or
+// descend into the prefixes the list command prints.
+func (client *Client) List(ctx context.Context, prefix string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	args := client.cfg.List
+	if recursive && len(client.cfg.ListRecursive) > 0 {
+		args = client.cfg.ListRecursive
+	}
+
+	var stdout bytes.Buffer
+	if err := client.run(ctx, "list", args, map[string]string{"prefix": prefix}, nil, &stdout); err != nil {
+		return nil, err
+	}
+	listed, err := parseList(prefix, &stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, obj := range listed {
+		switch {
+		case !obj.IsPre || !recursive:
+			objs = append(objs, obj)
+		case len(client.cfg.ListRecursive) == 0:
+			children, err := client.List(ctx, strings.TrimSuffix(obj.Key, "/"), true)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, children...)
+		}
+	}
+	return objs, nil
+}
+
+// parseList parses the output of a list command, whose keys are relative
+// to prefix.
+func parseList(prefix string, r io.Reader) (objs []*cli.ListObject, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		columns := strings.Split(strings.TrimRight(scanner.Text(), "\r"), "\t")
+		key := strings.TrimSpace(columns[0])
+		if key == "" {
+			continue
+		}
+		isPrefix := strings.HasSuffix(key, "/")
+		key = path.Join(prefix, key)
+		if isPrefix {
+			key += "/"
+		}
+		obj := &cli.ListObject{Key: key, IsPre: isPrefix}
+		if err := parseListColumns(obj, columns[1:]); err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, Error.Wrap(scanner.Err())
+}
+
+// listTimeLayouts are the layouts of the modification times of listed
+// objects, tried in order.
+var listTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05"}
+
+// parseListColumns sets the size and modification time of a listed
+// object from the optional columns which follow its key.
+func parseListColumns(obj *cli.ListObject, columns []string) error {
+	for i, column := range columns {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		switch i {
+		case 0:
+			size, err := strconv.ParseInt(column, 10, 64)
+			if err != nil {
+				return Error.New("invalid size %q of %q", column, obj.Key)
+			}
+			if size > 0 {
+				obj.Size = size
+			}
+		case 1:
+			modified, err := parseListTime(column)
+			if err != nil {
+				return Error.New("invalid modification time %q of %q", column, obj.Key)
+			}
+			obj.LastModified = modified
+		}
+	}
+	return nil
+}
+
+// parseListTime parses a modification time in any of listTimeLayouts.
+// Times without a zone are local.
+func parseListTime(value string) (modified time.Time, err error) {
+	for _, layout := range listTimeLayouts {
+		modified, err = time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return modified, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// Upload runs the upload command with the object on stdin.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.run(ctx, "upload", client.cfg.Upload, map[string]string{"name": name}, strm, nil)
+}
+
+// UploadMultipart runs the upload command. Commands have no multipart
+// uploads, so the object is written to a single command while timing each
+// partSize bytes, and concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	pipeReader, pipeWriter := io.Pipe()
+	uploadErr := make(chan error, 1)
+	go func() {
+		err := client.Upload(ctx, name, pipeReader)
+		// Unblock the writer when the upload ends early.
+		_ = pipeReader.CloseWithError(errs.New("upload ended"))
+		uploadErr <- err
+	}()
+
+	parts, err = cli.CopyParts(pipeWriter, strm, partSize)
+	_ = pipeWriter.CloseWithError(err)
+
+	if err := <-uploadErr; err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// Download runs the download command and streams its stdout.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.start(ctx, "download", client.cfg.Download, map[string]string{"name": name})
+}
+
+// DownloadRange runs the range download command and streams its stdout.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.start(ctx, "range download", client.cfg.DownloadRange, map[string]string{
+		"name":   name,
+		"offset": strconv.FormatInt(offset, 10),
+		"length": strconv.FormatInt(length, 10),
+	})
+}
+
+// Delete runs the delete command.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.run(ctx, "delete", client.cfg.Delete, map[string]string{"name": name}, nil, nil)
+}
+
+// Copy runs the copy command.
+func (client *Client) Copy(ctx context.Context, src, dst string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.run(ctx, "copy", client.cfg.Copy, map[string]string{"src": src, "dst": dst}, nil, nil)
+}
+
+// CreateBucket is not supported by commands.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("create bucket")
+}
+
+// ListBuckets is not supported by commands.
+func (client *Client) ListBuckets(ctx context.Context) ([]string, error) {
+	return nil, cli.ErrUnsupported.New("list buckets")
+}
+
+// DeleteBucket is not supported by commands.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("delete bucket")
+}
+
+// IP returns nothing, since the commands don't tell which host they
+// connect to.
+func (client *Client) IP(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+// Close closes the client.
+func (client *Client) Close() error {
+	return nil
+}
+
+// command creates the command of an operation, or fails with
+// ErrUnsupported if it has none.
+func command(ctx context.Context, operation string, args []string, values map[string]string) (*exec.Cmd, error) {
+	if len(args) == 0 {
+		return nil, cli.ErrUnsupported.New("%s", operation)
+	}
+
+	expanded := expand(args, values)
+	return exec.CommandContext(ctx, expanded[0], expanded[1:]...), nil
+}
+
+// expand replaces the {key} placeholders of args by their values in a
+// single pass, so that values containing placeholders, such as object names
+// with braces, are kept as they are.
+func expand(args []string, values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	oldnew := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		oldnew = append(oldnew, "{"+key+"}", values[key])
+	}
+	replacer := strings.NewReplacer(oldnew...)
+
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = replacer.Replace(arg)
+	}
+	return expanded
+}
+
+// run runs the command of an operation to completion.
+func (client *Client) run(ctx context.Context, operation string, args []string, values map[string]string, stdin io.Reader, stdout io.Writer) error {
+	cmd, err := command(ctx, operation, args, values)
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return commandError(operation, values, err, &stderr)
+	}
+	return nil
+}
+
+// start starts the command of an operation and returns its stdout.
+func (client *Client) start(ctx context.Context, operation string, args []string, values map[string]string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	cmd, err := command(ctx, operation, args, values)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	download := &download{cmd: cmd, cancel: cancel, operation: operation, values: values}
+	cmd.Stderr = &download.stderr
+	download.stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, Error.Wrap(err)
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, commandError(operation, values, err, &download.stderr)
+	}
+	return download, nil
+}
+
+// download streams the stdout of a running command.
+type download struct {
+	cmd       *exec.Cmd
+	cancel    func()
+	operation string
+	values    map[string]string
+	stdout    io.ReadCloser
+	stderr    bytes.Buffer
+	done      bool
+	err       error
+}
+
+// Read reads the stdout of the command. Commands which exit with an error
+// fail the read instead of ending it.
+func (download *download) Read(p []byte) (int, error) {
+	n, err := download.stdout.Read(p)
+	if err == io.EOF {
+		if waitErr := download.wait(); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// Close stops the command if it is still running.
+func (download *download) Close() error {
+	if download.done {
+		return download.err
+	}
+	// Commands stopped before their output was read don't fail.
+	download.cancel()
+	_ = download.wait()
+	return nil
+}
+
+func (download *download) wait() error {
+	if !download.done {
+		download.done = true
+		if err := download.cmd.Wait(); err != nil {
+			download.err = commandError(download.operation, download.values, err, &download.stderr)
+		}
+		download.cancel()
+	}
+	return download.err
+}
+
+// commandError describes a failed command with the end of its stderr.
+func commandError(operation string, values map[string]string, err error, stderr *bytes.Buffer) error {
+	const maxOutput = 512
+
+	output := strings.TrimSpace(stderr.String())
+	if len(output) > maxOutput {
+		output = "..." + output[len(output)-maxOutput:]
+	}
+	var target string
+	for _, key := range []string{"name", "src", "prefix"} {
+		if target = values[key]; target != "" {
+			break
+		}
+	}
+	if output == "" {
+		return Error.New("%s %q failed: %v", operation, target, err)
+	}
+	return Error.New("%s %q failed: %v: %s", operation, target, err, output)
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package execclient
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cli "storj.io/perftester/backends"
+)
+
+func TestExpand(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		args     []string
+		values   map[string]string
+		expected []string
+	}{
+		{
+			name:     "name",
+			args:     []string{"rclone", "rcat", "remote:bucket/{name}"},
+			values:   map[string]string{"name": "a/b"},
+			expected: []string{"rclone", "rcat", "remote:bucket/a/b"},
+		},
+		{
+			name:     "several",
+			args:     []string{"cat", "--offset", "{offset}", "--count", "{length}", "{name}"},
+			values:   map[string]string{"name": "obj", "offset": "10", "length": "-1"},
+			expected: []string{"cat", "--offset", "10", "--count", "-1", "obj"},
+		},
+		{
+			name:     "placeholders in values",
+			args:     []string{"cp", "{src}", "{dst}"},
+			values:   map[string]string{"src": "{dst}", "dst": "{src}"},
+			expected: []string{"cp", "{dst}", "{src}"},
+		},
+		{
+			name:     "unknown placeholders",
+			args:     []string{"echo", "{other}", "{name}{name}"},
+			values:   map[string]string{"name": "x"},
+			expected: []string{"echo", "{other}", "xx"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				require.Equal(t, tt.expected, expand(tt.args, tt.values))
+			}
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	modified := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		prefix   string
+		output   string
+		expected []*cli.ListObject
+		err      bool
+	}{
+		{
+			name:     "keys",
+			prefix:   "dir",
+			output:   "a\nsub/\n\n",
+			expected: []*cli.ListObject{{Key: "dir/a"}, {Key: "dir/sub/", IsPre: true}},
+		},
+		{
+			name:   "columns",
+			output: "a\t10\t2020-06-01T12:00:00Z\tetag\r\nb\t-1\t\t\n",
+			expected: []*cli.ListObject{
+				{Key: "a", Size: 10, LastModified: modified},
+				{Key: "b"},
+			},
+		},
+		{name: "invalid size", output: "a\tten\n", err: true},
+		{name: "invalid time", output: "a\t10\tyesterday\n", err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := parseList(tt.prefix, strings.NewReader(tt.output))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, len(tt.expected), len(objs))
+			for i, expected := range tt.expected {
+				require.Equal(t, expected.Key, objs[i].Key)
+				require.Equal(t, expected.IsPre, objs[i].IsPre)
+				require.Equal(t, expected.Size, objs[i].Size)
+				require.True(t, expected.LastModified.Equal(objs[i].LastModified), objs[i].LastModified)
+			}
+		})
+	}
+}
+
+func TestListRecursive(t *testing.T) {
+	ctx := context.Background()
+
+	// The list command prints a prefix at the top and a file below it.
+	script := `case "$1" in "") printf 'a\tsub/\n' | tr '\t' '\n';; sub) echo b;; esac`
+	client, err := New(Config{
+		Upload: []string{"true"},
+		List:   []string{"sh", "-c", script, "sh", "{prefix}"},
+	})
+	require.NoError(t, err)
+
+	objs, err := client.List(ctx, "", false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "sub/"}, keys(objs))
+
+	objs, err = client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "sub/b"}, keys(objs))
+
+	// The recursive list command is used as it is.
+	client.cfg.ListRecursive = []string{"sh", "-c", `printf 'a\nsub/b\nsub/\n'`}
+	objs, err = client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "sub/b"}, keys(objs))
+}
+
+// keys returns the keys of the listed objects.
+func keys(objs []*cli.ListObject) []string {
+	var keys []string
+	for _, obj := range objs {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
@@ -0,0 +1,484 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ftpclient
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("ftp-client")
+)
+
+func init() {
+	cli.Register("ftp", func() cli.EndpointConfig { return &Config{} })
+}
+
+// TLSMode selects whether and how FTP connections are encrypted.
+type TLSMode string
+
+const (
+	// ExplicitTLS upgrades plain connections with AUTH TLS.
+	ExplicitTLS TLSMode = "explicit"
+	// ImplicitTLS connects over TLS, on port 990 by default.
+	ImplicitTLS TLSMode = "implicit"
+)
+
+// Config is the config of [endpoint.ftp.<id>] tables, which benchmark FTP
+// and FTPS servers in passive mode.
+type Config struct {
+	Host string `toml:"host"`
+	// Port defaults to 21, or 990 with implicit TLS.
+	Port     int    `toml:"port"`
+	User     string `toml:"user"`
+	Password string `toml:"password" secret:"true"`
+	Path     string `toml:"path"` // Directory to test in.
+	// TLS encrypts connections if set. Connections are plain by default.
+	TLS                TLSMode `toml:"tls"`
+	InsecureSkipVerify bool    `toml:"insecure_skip_verify"` // Don't verify the TLS certificate.
+
+	config.EndpointDefaults
+}
+
+// Validate checks the TLS mode of the endpoint.
+func (cfg *Config) Validate() error {
+	switch cfg.TLS {
+	case "", ExplicitTLS, ImplicitTLS:
+	default:
+		return errs.New("unknown tls mode %q", cfg.TLS)
+	}
+	if cfg.Port < 0 {
+		return errs.New("port must not be negative")
+	}
+	return nil
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *Config) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(*cfg)
+}
+
+// Client is an FTP client. It keeps idle control connections open to reuse
+// them, since each transfer needs a connection of its own.
+type Client struct {
+	cfg       Config
+	address   string
+	tlsConfig *tls.Config
+
+	mu   sync.Mutex
+	idle []*conn
+	dirs map[string]bool
+}
+
+// New creates a new FTP client.
+func New(cfg Config) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, errs.New("host is required")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.User == "" {
+		cfg.User = "anonymous"
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 21
+		if cfg.TLS == ImplicitTLS {
+			port = 990
+		}
+	}
+
+	return &Client{
+		cfg:     cfg,
+		address: net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		tlsConfig: &tls.Config{
+			ServerName:         cfg.Host,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+			// Many servers require data connections to resume the TLS
+			// session of the control connection.
+			ClientSessionCache: tls.NewLRUClientSessionCache(0),
+		},
+		dirs: make(map[string]bool),
+	}, nil
+}
+
+// Settings describes the TLS mode of the client.
+func (client *Client) Settings() string {
+	if client.cfg.TLS == "" {
+		return "plain"
+	}
+	return string(client.cfg.TLS) + " tls"
+}
+
+// List lists the files and directories in the directory name.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	entries, err := client.list(ctx, client.remotePath(name))
+	if err != nil {
+		return nil, Error.New("failed to list %q: %v", name, err)
+	}
+
+	for _, entry := range entries {
+		key := path.Join(name, entry.name)
+		switch {
+		case !entry.isDir:
+			objs = append(objs, &cli.ListObject{Key: key, Size: entry.size, LastModified: entry.modified})
+		case !recursive:
+			objs = append(objs, &cli.ListObject{Key: key + "/", IsPre: true})
+		default:
+			children, err := client.List(ctx, key, true)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, children...)
+		}
+	}
+	return objs, nil
+}
+
+// Upload stores the file, creating its directories first.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	remotePath := client.remotePath(name)
+	err = client.withConn(ctx, func(c *conn) error {
+		if err := client.makeDirs(c, path.Dir(remotePath)); err != nil {
+			return err
+		}
+
+		dataConn, err := c.dataConn(ctx, "STOR %s", remotePath)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dataConn, strm)
+		err = errs.Combine(err, dataConn.Close())
+		return errs.Combine(err, c.finish())
+	})
+	if err != nil {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+	return nil
+}
+
+// UploadMultipart stores the file. FTP has no multipart uploads, so the
+// file is sent over a single data connection while timing each partSize
+// bytes, and concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	pipeReader, pipeWriter := io.Pipe()
+	uploadErr := make(chan error, 1)
+	go func() {
+		err := client.Upload(ctx, name, pipeReader)
+		// Unblock the writer when the upload ends early.
+		_ = pipeReader.CloseWithError(errs.New("upload ended"))
+		uploadErr <- err
+	}()
+
+	parts, err = cli.CopyParts(pipeWriter, strm, partSize)
+	_ = pipeWriter.CloseWithError(err)
+
+	if err := <-uploadErr; err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// Download retrieves the file.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.retrieve(ctx, name, 0, -1)
+}
+
+// DownloadRange retrieves a byte range of the file, restarting the
+// transfer at offset.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.retrieve(ctx, name, offset, length)
+}
+
+// Delete deletes the file.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = client.withConn(ctx, func(c *conn) error {
+		_, err := c.cmd(250, "DELE %s", client.remotePath(name))
+		return err
+	})
+	if err != nil {
+		return Error.New("failed to delete file %q: %v", name, err)
+	}
+	return nil
+}
+
+// Copy is not supported, since FTP servers can't copy files.
+func (client *Client) Copy(ctx context.Context, src, dst string) error {
+	return cli.ErrUnsupported.New("copy")
+}
+
+// CreateBucket creates a directory next to the directory of the client.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return Error.Wrap(client.withConn(ctx, func(c *conn) error {
+		_, err := c.cmd(257, "MKD %s", client.bucketPath(bucket))
+		return err
+	}))
+}
+
+// ListBuckets lists the directories next to the directory of the client.
+func (client *Client) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	entries, err := client.list(ctx, client.bucketPath(""))
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	for _, entry := range entries {
+		if entry.isDir {
+			buckets = append(buckets, entry.name)
+		}
+	}
+	return buckets, nil
+}
+
+// DeleteBucket deletes an empty directory next to the directory of the
+// client.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return Error.Wrap(client.withConn(ctx, func(c *conn) error {
+		_, err := c.cmd(250, "RMD %s", client.bucketPath(bucket))
+		return err
+	}))
+}
+
+// IP returns the host of the server.
+func (client *Client) IP(ctx context.Context) (string, error) {
+	return client.cfg.Host, nil
+}
+
+// NetworkAddress returns the address of the server and whether it is
+// connected to over TLS right away.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	return client.address, client.cfg.TLS == ImplicitTLS, nil
+}
+
+// Close closes the idle connections.
+func (client *Client) Close() error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	var group errs.Group
+	for _, c := range client.idle {
+		_, _, _ = c.cmdAny("QUIT")
+		group.Add(c.close())
+	}
+	client.idle = nil
+	return group.Err()
+}
+
+// withConn runs f with an idle or new connection, which is kept for reuse
+// if f succeeds.
+func (client *Client) withConn(ctx context.Context, f func(c *conn) error) error {
+	c, err := client.conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	stop := c.closeOnCancel(ctx)
+	err = f(c)
+	stop()
+
+	client.release(ctx, c, err == nil)
+	return err
+}
+
+// conn returns an idle connection, or a new one if there is none.
+func (client *Client) conn(ctx context.Context) (*conn, error) {
+	client.mu.Lock()
+	if n := len(client.idle); n > 0 {
+		c := client.idle[n-1]
+		client.idle = client.idle[:n-1]
+		client.mu.Unlock()
+		return c, nil
+	}
+	client.mu.Unlock()
+
+	return client.dial(ctx)
+}
+
+// release keeps the connection for reuse, unless its state is unknown
+// because the command failed or was cancelled.
+func (client *Client) release(ctx context.Context, c *conn, ok bool) {
+	if !ok || ctx.Err() != nil {
+		_ = c.close()
+		return
+	}
+	client.mu.Lock()
+	client.idle = append(client.idle, c)
+	client.mu.Unlock()
+}
+
+// list lists the directory at the remote path dir.
+func (client *Client) list(ctx context.Context, dir string) (entries []entry, err error) {
+	err = client.withConn(ctx, func(c *conn) error {
+		dataConn, err := c.dataConn(ctx, "MLSD %s", dir)
+		if err != nil {
+			return err
+		}
+
+		scanner := bufio.NewScanner(dataConn)
+		for scanner.Scan() {
+			if entry, ok := parseMLSD(strings.TrimRight(scanner.Text(), "\r")); ok {
+				entries = append(entries, entry)
+			}
+		}
+		err = errs.Combine(scanner.Err(), dataConn.Close())
+		return errs.Combine(err, c.finish())
+	})
+	return entries, err
+}
+
+// makeDirs creates dir and its parents, unless the client created them
+// before. Directories which exist already fail to be created, so errors
+// are ignored and only the upload fails.
+func (client *Client) makeDirs(c *conn, dir string) error {
+	var missing []string
+	client.mu.Lock()
+	for d := dir; d != "." && d != "/" && d != "" && !client.dirs[d]; d = path.Dir(d) {
+		missing = append(missing, d)
+	}
+	client.mu.Unlock()
+
+	for i := len(missing) - 1; i >= 0; i-- {
+		if _, _, err := c.cmdAny("MKD %s", missing[i]); err != nil {
+			return err
+		}
+	}
+
+	client.mu.Lock()
+	for _, d := range missing {
+		client.dirs[d] = true
+	}
+	client.mu.Unlock()
+	return nil
+}
+
+// retrieve starts retrieving length bytes of the file from offset, or the
+// rest of it if length is negative.
+func (client *Client) retrieve(ctx context.Context, name string, offset, length int64) (_ io.ReadCloser, err error) {
+	c, err := client.conn(ctx)
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	stop := c.closeOnCancel(ctx)
+	defer func() {
+		if err != nil {
+			stop()
+			client.release(ctx, c, false)
+		}
+	}()
+
+	if offset > 0 {
+		if _, err := c.cmd(350, "REST %d", offset); err != nil {
+			return nil, Error.New("failed to download file %q: %v", name, err)
+		}
+	}
+	dataConn, err := c.dataConn(ctx, "RETR %s", client.remotePath(name))
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+
+	var reader io.Reader = dataConn
+	if length >= 0 {
+		reader = io.LimitReader(dataConn, length)
+	}
+	return &download{
+		client:   client,
+		ctx:      ctx,
+		conn:     c,
+		stop:     stop,
+		dataConn: dataConn,
+		reader:   reader,
+		partial:  length >= 0,
+	}, nil
+}
+
+// download streams a file over a data connection.
+type download struct {
+	client   *Client
+	ctx      context.Context
+	conn     *conn
+	stop     func()
+	dataConn net.Conn
+	reader   io.Reader
+	// partial downloads abort the transfer once they have read enough.
+	partial bool
+	eof     bool
+}
+
+func (download *download) Read(p []byte) (int, error) {
+	n, err := download.reader.Read(p)
+	if err == io.EOF {
+		download.eof = true
+	}
+	return n, err
+}
+
+// Close closes the data connection and reads the final reply of the
+// transfer. Transfers which were aborted leave the connection in an unknown
+// state, so it is closed.
+func (download *download) Close() error {
+	defer download.stop()
+
+	err := download.dataConn.Close()
+	if !download.eof || download.partial {
+		download.client.release(download.ctx, download.conn, false)
+		return Error.Wrap(err)
+	}
+
+	err = errs.Combine(err, download.conn.finish())
+	download.client.release(download.ctx, download.conn, err == nil)
+	return Error.Wrap(err)
+}
+
+// remotePath returns the path of the file name on the server.
+func (client *Client) remotePath(name string) string {
+	if client.cfg.Path != "" {
+		return path.Join(client.cfg.Path, name)
+	}
+	return name
+}
+
+// bucketPath returns the path of a directory next to the directory of the
+// client.
+func (client *Client) bucketPath(bucket string) string {
+	return path.Join(path.Dir(strings.TrimSuffix(client.cfg.Path, "/")), bucket)
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ftpclient
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// hangingServer is a fake FTP server whose transfers never finish, until
+// the client gives up on them.
+type hangingServer struct {
+	listener net.Listener
+	started  chan struct{}
+	once     sync.Once
+	wg       sync.WaitGroup
+}
+
+func newHangingServer(t *testing.T) *hangingServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &hangingServer{listener: listener, started: make(chan struct{})}
+	server.wg.Add(1)
+	go func() {
+		defer server.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.wg.Add(1)
+			go func() {
+				defer server.wg.Done()
+				server.serve(conn)
+			}()
+		}
+	}()
+	return server
+}
+
+func (server *hangingServer) port() int {
+	return server.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (server *hangingServer) close() {
+	_ = server.listener.Close()
+	server.wg.Wait()
+}
+
+func (server *hangingServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var data net.Listener
+	defer func() {
+		if data != nil {
+			_ = data.Close()
+		}
+	}()
+
+	reply := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+	reply("220 ready")
+	lines := bufio.NewScanner(conn)
+	for lines.Scan() {
+		command := strings.SplitN(lines.Text(), " ", 2)[0]
+		switch command {
+		case "USER":
+			reply("230 logged in")
+		case "TYPE":
+			reply("200 binary")
+		case "MKD":
+			reply("257 created")
+		case "EPSV":
+			var err error
+			if data, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+				reply("425 no data connection")
+				continue
+			}
+			reply("229 Entering Extended Passive Mode (|||%d|)", data.Addr().(*net.TCPAddr).Port)
+		case "STOR", "RETR":
+			reply("150 transferring")
+			dataConn, err := data.Accept()
+			if err != nil {
+				return
+			}
+			if command == "STOR" {
+				_, _ = io.CopyN(ioutil.Discard, dataConn, 1024)
+			} else {
+				_, _ = dataConn.Write(make([]byte, 1024))
+			}
+			server.once.Do(func() { close(server.started) })
+			// The transfer hangs until the client closes the connections.
+			_, _ = io.Copy(ioutil.Discard, dataConn)
+			_ = dataConn.Close()
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// endlessReader is an object which never ends.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func TestUploadCancel(t *testing.T) {
+	server := newHangingServer(t)
+	defer server.close()
+
+	client, err := New(Config{Host: "127.0.0.1", Port: server.port()})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, client.Close()) }()
+
+	for _, upload := range []func(ctx context.Context) error{
+		func(ctx context.Context) error { return client.Upload(ctx, "dir/obj", endlessReader{}) },
+		func(ctx context.Context) error {
+			_, err := client.UploadMultipart(ctx, "dir/obj", endlessReader{}, 1<<20, 1)
+			return err
+		},
+	} {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-server.started
+			cancel()
+		}()
+
+		// Cancelling stops the upload promptly, and its connection, whose
+		// state is unknown, isn't reused.
+		done := make(chan error, 1)
+		go func() { done <- upload(ctx) }()
+		select {
+		case err := <-done:
+			require.Error(t, err)
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "upload didn't stop when cancelled")
+		}
+		require.Empty(t, client.idle)
+	}
+}
+
+func TestDownloadCancel(t *testing.T) {
+	server := newHangingServer(t)
+	defer server.close()
+
+	client, err := New(Config{Host: "127.0.0.1", Port: server.port()})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, client.Close()) }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	strm, err := client.Download(ctx, "obj")
+	require.NoError(t, err)
+	_, err = io.ReadFull(strm, make([]byte, 1024))
+	require.NoError(t, err)
+
+	// Cancelling stops reading the rest of the file promptly.
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(ioutil.Discard, strm)
+		done <- err
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "download didn't stop when cancelled")
+	}
+	_ = strm.Close()
+	require.Empty(t, client.idle)
+}
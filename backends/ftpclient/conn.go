@@ -0,0 +1,292 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ftpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// conn is a logged in control connection.
+type conn struct {
+	client  *Client
+	netConn net.Conn
+	text    *textproto.Conn
+}
+
+// dial connects and logs in to the server.
+func (client *Client) dial(ctx context.Context) (_ *conn, err error) {
+	var dialer net.Dialer
+	netConn, err := dialer.DialContext(ctx, "tcp", client.address)
+	if err != nil {
+		return nil, err
+	}
+	if client.cfg.TLS == ImplicitTLS {
+		netConn = tls.Client(netConn, client.tlsConfig)
+	}
+
+	c := &conn{client: client}
+	c.setConn(netConn)
+	defer func() {
+		if err != nil {
+			_ = c.close()
+		}
+	}()
+
+	stop := c.closeOnCancel(ctx)
+	defer stop()
+
+	if _, _, err := c.text.ReadResponse(220); err != nil {
+		return nil, err
+	}
+
+	if client.cfg.TLS == ExplicitTLS {
+		if _, err := c.cmd(234, "AUTH TLS"); err != nil {
+			return nil, err
+		}
+		c.setConn(tls.Client(netConn, client.tlsConfig))
+	}
+	if client.cfg.TLS != "" {
+		// Protect the data connections too.
+		if _, err := c.cmd(200, "PBSZ 0"); err != nil {
+			return nil, err
+		}
+		if _, err := c.cmd(200, "PROT P"); err != nil {
+			return nil, err
+		}
+	}
+
+	code, _, err := c.cmdAny("USER %s", client.cfg.User)
+	if err != nil {
+		return nil, err
+	}
+	switch code {
+	case 230:
+	case 331:
+		if _, err := c.cmd(230, "PASS %s", client.cfg.Password); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errs.New("login failed with status %d", code)
+	}
+
+	if _, err := c.cmd(200, "TYPE I"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *conn) setConn(netConn net.Conn) {
+	c.netConn = netConn
+	c.text = textproto.NewConn(netConn)
+}
+
+// closeOnCancel closes the connection when ctx is done before stop is
+// called, which unblocks any command waiting for a reply.
+func (c *conn) closeOnCancel(ctx context.Context) (stop func()) {
+	return closeOnCancel(ctx, c.netConn)
+}
+
+// closeOnCancel closes netConn when ctx is done before stop is called.
+func closeOnCancel(ctx context.Context, netConn net.Conn) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = netConn.Close()
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// cancelableConn is a data connection which is closed when the context of
+// its transfer is done, since servers keep streaming data after the control
+// connection was closed.
+type cancelableConn struct {
+	net.Conn
+	stop func()
+}
+
+func (c *cancelableConn) Close() error {
+	c.stop()
+	return c.Conn.Close()
+}
+
+// cmd sends a command and reads its reply, which must have the expected
+// code.
+func (c *conn) cmd(expected int, format string, args ...interface{}) (string, error) {
+	id, err := c.text.Cmd(format, args...)
+	if err != nil {
+		return "", err
+	}
+	c.text.StartResponse(id)
+	defer c.text.EndResponse(id)
+
+	_, message, err := c.text.ReadResponse(expected)
+	return message, err
+}
+
+// cmdAny sends a command and returns its reply, whatever its code.
+func (c *conn) cmdAny(format string, args ...interface{}) (int, string, error) {
+	id, err := c.text.Cmd(format, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	c.text.StartResponse(id)
+	defer c.text.EndResponse(id)
+
+	code, message, err := c.text.ReadResponse(0)
+	if _, ok := err.(*textproto.Error); ok {
+		err = nil
+	}
+	return code, message, err
+}
+
+// epsvPattern matches the port of an extended passive mode reply.
+var epsvPattern = regexp.MustCompile(`\(\|\|\|(\d+)\|\)`)
+
+// pasvPattern matches the address of a passive mode reply.
+var pasvPattern = regexp.MustCompile(`(\d+),(\d+),(\d+),(\d+),(\d+),(\d+)`)
+
+// dataConn opens a passive data connection and sends the command which
+// transfers over it, which must be accepted with a preliminary reply.
+func (c *conn) dataConn(ctx context.Context, format string, args ...interface{}) (net.Conn, error) {
+	port, err := c.passivePort()
+	if err != nil {
+		return nil, err
+	}
+
+	// The data connection goes to the host of the control connection,
+	// since servers behind NAT often advertise private addresses.
+	host, _, err := net.SplitHostPort(c.client.address)
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	dataConn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.cmd(1, format, args...); err != nil {
+		_ = dataConn.Close()
+		return nil, err
+	}
+	stop := closeOnCancel(ctx, dataConn)
+	if c.client.cfg.TLS != "" {
+		dataConn = tls.Client(dataConn, c.client.tlsConfig)
+	}
+	return &cancelableConn{Conn: dataConn, stop: stop}, nil
+}
+
+// passivePort enters extended passive mode, or passive mode on servers
+// which don't support it, and returns the port of the data connection.
+func (c *conn) passivePort() (int, error) {
+	code, message, err := c.cmdAny("EPSV")
+	if err != nil {
+		return 0, err
+	}
+	if code == 229 {
+		return parseEPSV(message)
+	}
+
+	message, err = c.cmd(227, "PASV")
+	if err != nil {
+		return 0, err
+	}
+	return parsePASV(message)
+}
+
+// parseEPSV returns the port of an extended passive mode reply, such as
+// "Entering Extended Passive Mode (|||6446|)".
+func parseEPSV(message string) (int, error) {
+	match := epsvPattern.FindStringSubmatch(message)
+	if match == nil {
+		return 0, errs.New("invalid extended passive reply %q", message)
+	}
+	port, err := strconv.Atoi(match[1])
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, errs.New("invalid port in extended passive reply %q", message)
+	}
+	return port, nil
+}
+
+// parsePASV returns the port of a passive mode reply, such as
+// "Entering Passive Mode (192,168,1,2,25,46)", whose last two numbers are
+// the high and low bytes of the port.
+func parsePASV(message string) (int, error) {
+	match := pasvPattern.FindStringSubmatch(message)
+	if match == nil {
+		return 0, errs.New("invalid passive reply %q", message)
+	}
+	high, _ := strconv.Atoi(match[5])
+	low, _ := strconv.Atoi(match[6])
+	if high > 255 || low > 255 || high|low == 0 {
+		return 0, errs.New("invalid port in passive reply %q", message)
+	}
+	return high<<8 | low, nil
+}
+
+// finish reads the final reply of a transfer.
+func (c *conn) finish() error {
+	_, _, err := c.text.ReadResponse(2)
+	return err
+}
+
+// entry is an entry of a machine readable directory listing. The size and
+// modification time of files are zero if the server omits them.
+type entry struct {
+	name     string
+	isDir    bool
+	size     int64
+	modified time.Time
+}
+
+// parseMLSD parses a line of an MLSD listing, such as
+// "type=file;size=1024;modify=20200102030405; name".
+func parseMLSD(line string) (entry, bool) {
+	facts := strings.SplitN(line, " ", 2)
+	if len(facts) != 2 {
+		return entry{}, false
+	}
+	e := entry{name: facts[1]}
+	typed := false
+	for _, fact := range strings.Split(facts[0], ";") {
+		parts := strings.SplitN(fact, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch strings.ToLower(parts[0]) {
+		case "type":
+			switch strings.ToLower(parts[1]) {
+			case "file":
+				typed = true
+			case "dir":
+				typed, e.isDir = true, true
+			}
+		case "size":
+			e.size, _ = strconv.ParseInt(parts[1], 10, 64)
+		case "modify":
+			// The times are in UTC, with optional fractions of seconds.
+			e.modified, _ = time.Parse("20060102150405", strings.SplitN(parts[1], ".", 2)[0])
+		}
+	}
+	// The current and parent directories and links are skipped.
+	return e, typed
+}
+
+func (c *conn) close() error {
+	return c.text.Close()
+}
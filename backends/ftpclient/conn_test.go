@@ -0,0 +1,117 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ftpclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMLSD(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		line  string
+		entry entry
+		ok    bool
+	}{
+		{
+			name:  "file",
+			line:  "type=file;size=1024;modify=20200102030405; obj0",
+			entry: entry{name: "obj0", size: 1024, modified: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			ok:    true,
+		},
+		{
+			name:  "fractional time",
+			line:  "modify=20200102030405.123;type=file;size=5; obj1",
+			entry: entry{name: "obj1", size: 5, modified: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			ok:    true,
+		},
+		{
+			name:  "directory",
+			line:  "Type=dir;Modify=20200102030405;Perm=el; data",
+			entry: entry{name: "data", isDir: true, modified: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			ok:    true,
+		},
+		{
+			name:  "name with spaces",
+			line:  "type=file;size=3; my file.txt",
+			entry: entry{name: "my file.txt", size: 3},
+			ok:    true,
+		},
+		{
+			name:  "missing facts",
+			line:  "type=file; obj2",
+			entry: entry{name: "obj2"},
+			ok:    true,
+		},
+		{
+			name:  "invalid size and time",
+			line:  "type=file;size=large;modify=yesterday; obj3",
+			entry: entry{name: "obj3"},
+			ok:    true,
+		},
+		{name: "current directory", line: "type=cdir;modify=20200102030405; .", entry: entry{name: ".", modified: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		{name: "parent directory", line: "type=pdir; ..", entry: entry{name: ".."}},
+		{name: "link", line: "type=OS.unix=slink:/target; link", entry: entry{name: "link"}},
+		{name: "untyped", line: "size=1; obj4", entry: entry{name: "obj4", size: 1}},
+		{name: "no name", line: "type=file;size=1;"},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			entry, ok := parseMLSD(test.line)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.entry, entry)
+		})
+	}
+}
+
+func TestParseEPSV(t *testing.T) {
+	for _, test := range []struct {
+		message string
+		port    int
+		err     bool
+	}{
+		{message: "Entering Extended Passive Mode (|||6446|)", port: 6446},
+		{message: "EPSV ok (|||65535|)", port: 65535},
+		{message: "Entering Extended Passive Mode (|||0|)", err: true},
+		{message: "Entering Extended Passive Mode (|||70000|)", err: true},
+		{message: "Entering Extended Passive Mode (|||99999999999999999999|)", err: true},
+		{message: "Entering Extended Passive Mode (!!!6446!)", err: true},
+		{message: "Entering Extended Passive Mode", err: true},
+	} {
+		port, err := parseEPSV(test.message)
+		if test.err {
+			require.Error(t, err, test.message)
+			continue
+		}
+		require.NoError(t, err, test.message)
+		require.Equal(t, test.port, port, test.message)
+	}
+}
+
+func TestParsePASV(t *testing.T) {
+	for _, test := range []struct {
+		message string
+		port    int
+		err     bool
+	}{
+		{message: "Entering Passive Mode (192,168,1,2,25,46)", port: 25<<8 | 46},
+		{message: "Entering Passive Mode (10,0,0,1,0,21).", port: 21},
+		{message: "Entering Passive Mode 127,0,0,1,255,255", port: 65535},
+		{message: "Entering Passive Mode (127,0,0,1,256,1)", err: true},
+		{message: "Entering Passive Mode (127,0,0,1,0,0)", err: true},
+		{message: "Entering Passive Mode (127,0,0,1,25)", err: true},
+		{message: "Entering Passive Mode", err: true},
+	} {
+		port, err := parsePASV(test.message)
+		if test.err {
+			require.Error(t, err, test.message)
+			continue
+		}
+		require.NoError(t, err, test.message)
+		require.Equal(t, test.port, port, test.message)
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gcsclient
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("gcs-client")
+)
+
+// Client is a Google Cloud Storage client.
+type Client struct {
+	cfg    config.GCSEndpoint
+	client *storage.Client
+}
+
+// New creates a new GCS client.
+func New(ctx context.Context, cfg config.GCSEndpoint) (*Client, error) {
+	if cfg.Bucket == "" {
+		return nil, errs.New("bucket is required")
+	}
+
+	var opts []option.ClientOption
+	switch {
+	case cfg.CredentialsJSON != "":
+		opts = append(opts, option.WithCredentialsJSON([]byte(cfg.CredentialsJSON)))
+	case cfg.CredentialsFile != "":
+		opts = append(opts, option.WithCredentialsFile(cfg.CredentialsFile))
+	}
+
+	client, err := storage.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return &Client{
+		cfg:    cfg,
+		client: client,
+	}, nil
+}
+
+// List returns the objects found at name.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	prefix := client.bucketKey(name)
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	query := &storage.Query{Prefix: prefix}
+	if !recursive {
+		query.Delimiter = "/"
+	}
+
+	it := client.client.Bucket(client.cfg.Bucket).Objects(ctx, query)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		if attrs.Prefix != "" {
+			objs = append(objs, &cli.ListObject{
+				Key:   client.key(attrs.Prefix),
+				IsPre: true,
+			})
+			continue
+		}
+		objs = append(objs, &cli.ListObject{
+			Key:          client.key(attrs.Name),
+			IsPre:        false,
+			Size:         attrs.Size,
+			LastModified: attrs.Updated,
+		})
+	}
+
+	return objs, nil
+}
+
+// Upload uploads to GCS.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := client.client.Bucket(client.cfg.Bucket).Object(client.bucketKey(name)).NewWriter(ctx)
+	if _, err := cli.Copy(writer, strm); err != nil {
+		// Cancelling the context aborts the upload.
+		cancel()
+		return Error.New("failed to upload file %q: %v", name, errs.Combine(err, writer.Close()))
+	}
+
+	if err := writer.Close(); err != nil {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+	return nil
+}
+
+// UploadMultipart uploads to GCS as a resumable upload in chunks of
+// partSize bytes. Chunks are always uploaded sequentially, so concurrency is
+// ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := client.client.Bucket(client.cfg.Bucket).Object(client.bucketKey(name)).NewWriter(ctx)
+	writer.ChunkSize = int(partSize)
+
+	parts, err = cli.CopyParts(writer, strm, partSize)
+	if err != nil {
+		// Cancelling the context aborts the upload.
+		cancel()
+		return nil, Error.New("failed to upload file %q: %v", name, errs.Combine(err, writer.Close()))
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// Download downloads from GCS.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	reader, err := client.client.Bucket(client.cfg.Bucket).Object(client.bucketKey(name)).NewReader(ctx)
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	return reader, nil
+}
+
+// DownloadRange downloads a byte range from GCS.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	reader, err := client.client.Bucket(client.cfg.Bucket).Object(client.bucketKey(name)).NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, Error.New("failed to download range of file %q: %v", name, err)
+	}
+	return reader, nil
+}
+
+// Delete deletes from GCS.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	err = client.client.Bucket(client.cfg.Bucket).Object(client.bucketKey(name)).Delete(ctx)
+	if err != nil {
+		return Error.New("failed to delete file %q: %v", name, err)
+	}
+	return nil
+}
+
+// Copy copies an object within the GCS bucket.
+func (client *Client) Copy(ctx context.Context, src, dst string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	bucket := client.client.Bucket(client.cfg.Bucket)
+	_, err = bucket.Object(client.bucketKey(dst)).CopierFrom(bucket.Object(client.bucketKey(src))).Run(ctx)
+	if err != nil {
+		return Error.New("failed to copy file %q to %q: %v", src, dst, err)
+	}
+	return nil
+}
+
+// CreateBucket creates a bucket in the project of the client.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if client.cfg.ProjectID == "" {
+		return cli.ErrUnsupported.New("creating buckets without a project ID")
+	}
+	if err := client.client.Bucket(bucket).Create(ctx, client.cfg.ProjectID, nil); err != nil {
+		return Error.New("failed to create bucket %q: %v", bucket, err)
+	}
+	return nil
+}
+
+// ListBuckets returns the names of the buckets of the project of the
+// client.
+func (client *Client) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if client.cfg.ProjectID == "" {
+		return nil, cli.ErrUnsupported.New("listing buckets without a project ID")
+	}
+	it := client.client.Buckets(ctx, client.cfg.ProjectID)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return buckets, nil
+		}
+		if err != nil {
+			return nil, Error.New("failed to list buckets: %v", err)
+		}
+		buckets = append(buckets, attrs.Name)
+	}
+}
+
+// DeleteBucket deletes an empty bucket.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if err := client.client.Bucket(bucket).Delete(ctx); err != nil {
+		return Error.New("failed to delete bucket %q: %v", bucket, err)
+	}
+	return nil
+}
+
+// IP returns the IP address of the endpoint.
+func (client *Client) IP(ctx context.Context) (addr string, err error) {
+	// Like S3, the GCS endpoint resolves to a range of IPs, which the
+	// network diagnostics record, so only its host is returned.
+	return "storage.googleapis.com", nil
+}
+
+// NetworkAddress returns the address of the GCS JSON API.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	return "storage.googleapis.com:443", true, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() (err error) {
+	return Error.Wrap(client.client.Close())
+}
+
+func (client *Client) bucketKey(name string) string {
+	if client.cfg.Path != "" {
+		return path.Join(client.cfg.Path, name)
+	}
+	return name
+}
+
+// key returns the name of the object at the bucket key k.
+func (client *Client) key(k string) string {
+	if client.cfg.Path == "" {
+		return k
+	}
+	return strings.TrimPrefix(k, strings.Trim(client.cfg.Path, "/")+"/")
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gcsclient
+
+import (
+	"context"
+	"io"
+	"strconv"
+
+	"cloud.google.com/go/storage"
+	"github.com/zeebo/errs"
+	"google.golang.org/api/iterator"
+
+	cli "storj.io/perftester/backends"
+)
+
+// UploadVersion uploads a new generation of the object. The generations
+// are kept as versions in buckets with object versioning enabled.
+func (client *Client) UploadVersion(ctx context.Context, name string, strm io.Reader) (versionID string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := client.client.Bucket(client.cfg.Bucket).Object(client.bucketKey(name)).NewWriter(ctx)
+	if _, err := cli.Copy(writer, strm); err != nil {
+		// Cancelling the context aborts the upload.
+		cancel()
+		return "", Error.New("failed to upload version of file %q: %v", name, errs.Combine(err, writer.Close()))
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", Error.New("failed to upload version of file %q: %v", name, err)
+	}
+	return strconv.FormatInt(writer.Attrs().Generation, 10), nil
+}
+
+// ListVersions returns the generations of the object.
+func (client *Client) ListVersions(ctx context.Context, name string) (versionIDs []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	key := client.bucketKey(name)
+	it := client.client.Bucket(client.cfg.Bucket).Objects(ctx, &storage.Query{Prefix: key, Versions: true})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, Error.New("failed to list versions of file %q: %v", name, err)
+		}
+		if attrs.Name == key {
+			versionIDs = append(versionIDs, strconv.FormatInt(attrs.Generation, 10))
+		}
+	}
+	return versionIDs, nil
+}
+
+// DeleteVersion permanently deletes a single generation of the object.
+func (client *Client) DeleteVersion(ctx context.Context, name, versionID string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	generation, err := strconv.ParseInt(versionID, 10, 64)
+	if err != nil {
+		return Error.New("invalid generation %q of file %q", versionID, name)
+	}
+
+	err = client.client.Bucket(client.cfg.Bucket).Object(client.bucketKey(name)).Generation(generation).Delete(ctx)
+	if err != nil {
+		return Error.New("failed to delete version %q of file %q: %v", versionID, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("http-client")
+)
+
+// Client is a read-only client which downloads a single file over HTTP.
+// Every object name refers to that file.
+type Client struct {
+	cfg    config.HTTPEndpoint
+	url    *url.URL
+	client *http.Client
+}
+
+// New creates a new HTTP client.
+func New(cfg config.HTTPEndpoint) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errs.New("url is required")
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return &Client{
+		cfg:    cfg,
+		url:    u,
+		client: &http.Client{},
+	}, nil
+}
+
+// ReadOnly returns true, as the client can only download.
+func (client *Client) ReadOnly() bool { return true }
+
+// List is not supported.
+func (client *Client) List(ctx context.Context, name string, recursive bool) ([]*cli.ListObject, error) {
+	return nil, cli.ErrUnsupported.New("list")
+}
+
+// Upload is not supported.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) error {
+	return cli.ErrUnsupported.New("upload")
+}
+
+// UploadMultipart is not supported.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) ([]cli.Part, error) {
+	return nil, cli.ErrUnsupported.New("multipart upload")
+}
+
+// Download downloads the file.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	resp, err := client.get(ctx, nil)
+	if err != nil {
+		return nil, Error.New("failed to download %q: %v", client.cfg.URL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, Error.New("failed to download %q: %s", client.cfg.URL, errs.Combine(errs.New("%s", resp.Status), resp.Body.Close()))
+	}
+	return resp.Body, nil
+}
+
+// DownloadRange downloads a byte range of the file.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange += fmt.Sprint(offset + length - 1)
+	}
+
+	resp, err := client.get(ctx, http.Header{"Range": {byteRange}})
+	if err != nil {
+		return nil, Error.New("failed to download range of %q: %v", client.cfg.URL, err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, Error.New("failed to download range of %q: %s", client.cfg.URL, errs.Combine(errs.New("%s", resp.Status), resp.Body.Close()))
+	}
+	return resp.Body, nil
+}
+
+// Delete is not supported.
+func (client *Client) Delete(ctx context.Context, name string) error {
+	return cli.ErrUnsupported.New("delete")
+}
+
+// Copy is not supported.
+func (client *Client) Copy(ctx context.Context, src, dst string) error {
+	return cli.ErrUnsupported.New("copy")
+}
+
+// CreateBucket is not supported.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("create bucket")
+}
+
+// ListBuckets is not supported.
+func (client *Client) ListBuckets(ctx context.Context) ([]string, error) {
+	return nil, cli.ErrUnsupported.New("list buckets")
+}
+
+// DeleteBucket is not supported.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("delete bucket")
+}
+
+// IP returns the host of the endpoint.
+func (client *Client) IP(ctx context.Context) (string, error) {
+	return client.url.Hostname(), nil
+}
+
+// NetworkAddress returns the address of the server.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	address, useTLS := cli.URLAddress(client.url)
+	return address, useTLS, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() error {
+	client.client.CloseIdleConnections()
+	return nil
+}
+
+func (client *Client) get(ctx context.Context, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, client.url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	return client.client.Do(req)
+}
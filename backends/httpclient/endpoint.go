@@ -0,0 +1,27 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package httpclient
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+func init() {
+	cli.Register("http", func() cli.EndpointConfig { return &endpointConfig{} })
+}
+
+// endpointConfig is the config of [endpoint.http.<id>] tables.
+type endpointConfig struct {
+	config.HTTPEndpoint
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *endpointConfig) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(cfg.HTTPEndpoint)
+}
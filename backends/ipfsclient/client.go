@@ -0,0 +1,433 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ipfsclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("ipfs-client")
+)
+
+func init() {
+	cli.Register("ipfs", func() cli.EndpointConfig { return &Config{} })
+}
+
+// API selects the API objects are uploaded and pinned through.
+type API string
+
+const (
+	// KuboAPI uploads through the RPC API of a Kubo (go-ipfs) node. It is
+	// the default.
+	KuboAPI API = "kubo"
+	// PinataAPI uploads through the Pinata pinning service.
+	PinataAPI API = "pinata"
+)
+
+// Config is the config of [endpoint.ipfs.<id>] tables, which upload
+// objects to IPFS through a node or pinning service and download them
+// through an HTTP gateway.
+type Config struct {
+	API API `toml:"api"`
+	// APIURL is the address of the API. Defaults to http://localhost:5001
+	// for Kubo and https://api.pinata.cloud for Pinata.
+	APIURL string `toml:"api_url"`
+	// Token is the bearer token of the API, such as a Pinata JWT.
+	Token string `toml:"token" secret:"true"`
+	// GatewayURL is the HTTP gateway objects are downloaded from. Defaults
+	// to https://ipfs.io.
+	GatewayURL string `toml:"gateway_url"`
+
+	config.EndpointDefaults
+}
+
+// Validate checks the API of the endpoint.
+func (cfg *Config) Validate() error {
+	switch cfg.API {
+	case "", KuboAPI, PinataAPI:
+		return nil
+	default:
+		return errs.New("unknown api %q", cfg.API)
+	}
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *Config) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(*cfg)
+}
+
+// Client is an IPFS client. IPFS addresses objects by the CID of their
+// contents, so the client remembers the CIDs of the objects it uploaded
+// and only downloads and deletes those. Objects with the same contents
+// share their CID and its pin, so the client counts the objects of each
+// pinned CID and only unpins it with the last of them.
+type Client struct {
+	cfg     Config
+	api     *url.URL
+	gateway *url.URL
+	client  *http.Client
+
+	mu   sync.Mutex
+	cids map[string]string // CIDs of the uploaded objects by name.
+	pins map[string]int    // Number of objects of each pinned CID.
+
+	// unpinning keeps uploads from pinning contents while they are
+	// unpinned: uploads hold it for reading until they are counted, and
+	// unpins for writing.
+	unpinning sync.RWMutex
+}
+
+// New creates a new IPFS client.
+func New(cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if cfg.API == "" {
+		cfg.API = KuboAPI
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = "http://localhost:5001"
+		if cfg.API == PinataAPI {
+			cfg.APIURL = "https://api.pinata.cloud"
+		}
+	}
+	if cfg.GatewayURL == "" {
+		cfg.GatewayURL = "https://ipfs.io"
+	}
+
+	api, err := url.Parse(cfg.APIURL)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	gateway, err := url.Parse(cfg.GatewayURL)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return &Client{
+		cfg:     cfg,
+		api:     api,
+		gateway: gateway,
+		client:  &http.Client{},
+		cids:    make(map[string]string),
+		pins:    make(map[string]int),
+	}, nil
+}
+
+// Settings describes the APIs the client uses.
+func (client *Client) Settings() string {
+	return fmt.Sprintf("%s upload, gateway %s", client.cfg.API, client.gateway.Host)
+}
+
+// List is not supported, since IPFS has no names.
+func (client *Client) List(ctx context.Context, name string, recursive bool) ([]*cli.ListObject, error) {
+	return nil, cli.ErrUnsupported.New("list")
+}
+
+// Upload adds the object to IPFS and pins it. Replacing an object unpins
+// its previous contents, unless other objects have them.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	client.unpinning.RLock()
+	cid, err := client.add(ctx, name, strm)
+	if err != nil {
+		client.unpinning.RUnlock()
+		return err
+	}
+	client.mu.Lock()
+	previous, replaced := client.cids[name]
+	client.cids[name] = cid
+	client.pins[cid]++
+	client.mu.Unlock()
+	client.unpinning.RUnlock()
+
+	if replaced {
+		if err := client.release(ctx, previous); err != nil {
+			return Error.New("failed to unpin the previous contents of file %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// add adds the object to IPFS, pinning it, and returns its CID.
+func (client *Client) add(ctx context.Context, name string, strm io.Reader) (cid string, err error) {
+	var endpoint string
+	var out struct {
+		Hash     string // Kubo
+		IpfsHash string // Pinata
+	}
+	switch client.cfg.API {
+	case PinataAPI:
+		endpoint = "/pinning/pinFileToIPFS"
+	default:
+		endpoint = "/api/v0/add?pin=true&cid-version=1"
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	form := multipart.NewWriter(pipeWriter)
+	go func() {
+		part, err := form.CreateFormFile("file", path.Base(name))
+		if err == nil {
+			_, err = io.Copy(part, strm)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		_ = pipeWriter.CloseWithError(err)
+	}()
+
+	err = client.callAPI(ctx, http.MethodPost, endpoint, form.FormDataContentType(), pipeReader, &out)
+	_ = pipeReader.CloseWithError(errs.New("upload ended"))
+	if err != nil {
+		return "", Error.New("failed to upload file %q: %v", name, err)
+	}
+
+	cid = out.Hash
+	if cid == "" {
+		cid = out.IpfsHash
+	}
+	if cid == "" {
+		return "", Error.New("failed to upload file %q: no CID returned", name)
+	}
+	return cid, nil
+}
+
+// UploadMultipart uploads the object. IPFS chunks objects itself, so the
+// object is sent in a single request while timing each partSize bytes,
+// and concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	pipeReader, pipeWriter := io.Pipe()
+	uploadErr := make(chan error, 1)
+	go func() {
+		err := client.Upload(ctx, name, pipeReader)
+		// Unblock the writer when the upload ends early.
+		_ = pipeReader.CloseWithError(errs.New("upload ended"))
+		uploadErr <- err
+	}()
+
+	parts, err = cli.CopyParts(pipeWriter, strm, partSize)
+	_ = pipeWriter.CloseWithError(err)
+
+	if err := <-uploadErr; err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// Download downloads the object from the gateway.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.get(ctx, name, "")
+}
+
+// DownloadRange downloads a byte range of the object from the gateway.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange += fmt.Sprint(offset + length - 1)
+	}
+	return client.get(ctx, name, byteRange)
+}
+
+// Delete unpins the object, so that it is eventually garbage collected,
+// unless other objects have its contents.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	client.mu.Lock()
+	cid, ok := client.cids[name]
+	delete(client.cids, name)
+	client.mu.Unlock()
+	if !ok {
+		return Error.New("file %q was not uploaded by this client", name)
+	}
+
+	if err := client.release(ctx, cid); err != nil {
+		client.mu.Lock()
+		if _, ok := client.cids[name]; !ok {
+			client.cids[name] = cid
+		}
+		client.mu.Unlock()
+		return Error.New("failed to delete file %q: %v", name, err)
+	}
+	return nil
+}
+
+// release uncounts an object of cid and unpins cid if it was the last one.
+// If unpinning fails, the object is counted again.
+func (client *Client) release(ctx context.Context, cid string) (err error) {
+	client.mu.Lock()
+	client.pins[cid]--
+	last := client.pins[cid] <= 0
+	client.mu.Unlock()
+	if !last {
+		return nil
+	}
+
+	client.unpinning.Lock()
+	defer client.unpinning.Unlock()
+
+	// Uploads of the same contents may have been counted meanwhile.
+	client.mu.Lock()
+	if client.pins[cid] > 0 {
+		client.mu.Unlock()
+		return nil
+	}
+	delete(client.pins, cid)
+	client.mu.Unlock()
+
+	switch client.cfg.API {
+	case PinataAPI:
+		err = client.callAPI(ctx, http.MethodDelete, "/pinning/unpin/"+url.PathEscape(cid), "", nil, nil)
+	default:
+		err = client.callAPI(ctx, http.MethodPost, "/api/v0/pin/rm?arg="+url.QueryEscape(cid), "", nil, nil)
+	}
+	if err != nil {
+		client.mu.Lock()
+		client.pins[cid]++
+		client.mu.Unlock()
+	}
+	return err
+}
+
+// Copy is not supported, since IPFS has no names to copy objects to.
+func (client *Client) Copy(ctx context.Context, src, dst string) error {
+	return cli.ErrUnsupported.New("copy")
+}
+
+// CreateBucket is not supported by IPFS.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("create bucket")
+}
+
+// ListBuckets is not supported by IPFS.
+func (client *Client) ListBuckets(ctx context.Context) ([]string, error) {
+	return nil, cli.ErrUnsupported.New("list buckets")
+}
+
+// DeleteBucket is not supported by IPFS.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("delete bucket")
+}
+
+// IP returns the host of the gateway.
+func (client *Client) IP(ctx context.Context) (string, error) {
+	return client.gateway.Hostname(), nil
+}
+
+// NetworkAddress returns the address of the gateway.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	address, useTLS := cli.URLAddress(client.gateway)
+	return address, useTLS, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() error {
+	client.client.CloseIdleConnections()
+	return nil
+}
+
+// cid returns the CID of an uploaded object.
+func (client *Client) cid(name string) (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	cid, ok := client.cids[name]
+	if !ok {
+		return "", Error.New("file %q was not uploaded by this client", name)
+	}
+	return cid, nil
+}
+
+// callAPI sends a request to the upload API and decodes the JSON response
+// into out, if set.
+func (client *Client) callAPI(ctx context.Context, method, endpoint, contentType string, body io.Reader, out interface{}) (err error) {
+	ref, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	u := client.api.ResolveReference(ref)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if client.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.cfg.Token)
+	}
+
+	resp, err := client.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errs.Combine(err, resp.Body.Close()) }()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return errs.New("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// get gets the object from the gateway, with a byte range if set.
+func (client *Client) get(ctx context.Context, name, byteRange string) (io.ReadCloser, error) {
+	cid, err := client.cid(name)
+	if err != nil {
+		return nil, err
+	}
+
+	u := client.gateway.ResolveReference(&url.URL{Path: "/ipfs/" + cid})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+
+	resp, err := client.client.Do(req)
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, Error.New("failed to download file %q: %s", name, errs.Combine(errs.New("%s", resp.Status), resp.Body.Close()))
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ipfsclient_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/backends/ipfsclient"
+)
+
+// kuboServer is a fake Kubo API which addresses files by their contents
+// and records the CIDs unpinned.
+type kuboServer struct {
+	mu       sync.Mutex
+	unpinned []string
+}
+
+func (server *kuboServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/v0/add":
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, err := ioutil.ReadAll(file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"Hash": "cid-" + string(data)})
+	case "/api/v0/pin/rm":
+		server.mu.Lock()
+		server.unpinned = append(server.unpinned, r.URL.Query().Get("arg"))
+		server.mu.Unlock()
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (server *kuboServer) unpins() []string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return append([]string(nil), server.unpinned...)
+}
+
+func TestPins(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	kubo := &kuboServer{}
+	server := httptest.NewServer(kubo)
+	defer server.Close()
+
+	client, err := ipfsclient.New(ipfsclient.Config{APIURL: server.URL})
+	require.NoError(t, err)
+	defer ctx.Check(client.Close)
+
+	// Objects with the same contents share their pin, which is only
+	// unpinned with the last of them.
+	require.NoError(t, client.Upload(ctx, "a", strings.NewReader("same")))
+	require.NoError(t, client.Upload(ctx, "b", strings.NewReader("same")))
+	require.NoError(t, client.Delete(ctx, "a"))
+	require.Empty(t, kubo.unpins())
+	require.NoError(t, client.Delete(ctx, "b"))
+	require.Equal(t, []string{"cid-same"}, kubo.unpins())
+	require.Error(t, client.Delete(ctx, "b"))
+
+	// Replacing an object unpins its previous contents.
+	require.NoError(t, client.Upload(ctx, "c", strings.NewReader("old")))
+	require.NoError(t, client.Upload(ctx, "c", strings.NewReader("new")))
+	require.Equal(t, []string{"cid-same", "cid-old"}, kubo.unpins())
+
+	// Uploading the same contents again doesn't unpin them.
+	require.NoError(t, client.Upload(ctx, "c", strings.NewReader("new")))
+	require.Equal(t, []string{"cid-same", "cid-old"}, kubo.unpins())
+	require.NoError(t, client.Delete(ctx, "c"))
+	require.Equal(t, []string{"cid-same", "cid-old", "cid-new"}, kubo.unpins())
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package backends
+
+import (
+	"context"
+)
+
+type metadataKey struct{}
+
+// WithMetadata returns a context whose uploads attach the custom metadata to
+// the objects they create, on backends which implement Metadater.
+func WithMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	return context.WithValue(ctx, metadataKey{}, metadata)
+}
+
+// Metadata returns the custom metadata of the objects uploaded with ctx, or
+// nil if they have none.
+func Metadata(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return metadata
+}
+
+// Metadater is implemented by clients of backends which store custom
+// metadata with objects. Their uploads attach the metadata of their context,
+// see WithMetadata.
+type Metadater interface {
+	// GetMetadata returns the custom metadata of the object.
+	GetMetadata(ctx context.Context, name string) (metadata map[string]string, err error)
+	// SetMetadata attaches metadata to an existing object, such as the tags
+	// of S3 objects, which replace those set before. Backends which can only
+	// attach metadata on upload fail with ErrUnsupported.
+	SetMetadata(ctx context.Context, name string, metadata map[string]string) (err error)
+}
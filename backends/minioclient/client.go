@@ -0,0 +1,207 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package minioclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	s3 "storj.io/perftester/backends/s3client"
+	"storj.io/perftester/config"
+)
+
+// Error is the error for this package.
+var Error = errs.Class("minio-client")
+
+func init() {
+	cli.Register("minio", func() cli.EndpointConfig { return &Config{} })
+}
+
+// Config is the config of [endpoint.minio.<id>] tables. They take the
+// settings of S3 endpoints, but always address buckets in the path and
+// default to the us-east-1 region, as MinIO does.
+type Config struct {
+	config.S3Endpoint
+}
+
+// Validate checks that the endpoint has the address of the cluster.
+func (cfg *Config) Validate() error {
+	if cfg.Address == "" {
+		return errs.New("address is required")
+	}
+	return cfg.S3Endpoint.Validate()
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *Config) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(cfg.S3Endpoint)
+}
+
+// Client is an S3 client of a MinIO cluster which also describes the
+// cluster through the admin API.
+type Client struct {
+	*s3.Client
+
+	cfg    config.S3Endpoint
+	url    *url.URL
+	signer *v4.Signer
+	http   *http.Client
+}
+
+// New creates a new MinIO client.
+func New(cfg config.S3Endpoint) (*Client, error) {
+	if cfg.Address == "" {
+		return nil, errs.New("address is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.PathStyle = true
+
+	u, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	client, err := s3.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Client: client,
+		cfg:    cfg,
+		url:    u,
+		signer: v4.NewSigner(credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, "")),
+		http:   &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// serverInfo is the part of the admin API's server info the description
+// is made of.
+type serverInfo struct {
+	Mode    string `json:"mode"`
+	Servers []struct {
+		State   string `json:"state"`
+		Version string `json:"version"`
+		Drives  []struct {
+			State string `json:"state"`
+		} `json:"drives"`
+	} `json:"servers"`
+}
+
+// DescribeServer describes the version and health of the cluster, such as
+// "online, 4/4 servers online, 16/16 drives ok, version
+// 2020-10-03T02:19:42Z". Credentials without admin permissions only get
+// the cluster health.
+func (client *Client) DescribeServer(ctx context.Context) (string, error) {
+	var info serverInfo
+	infoErr := client.admin(ctx, "/minio/admin/v3/info", &info)
+	if infoErr == nil {
+		return describeInfo(info), nil
+	}
+
+	health, err := client.health(ctx)
+	if err != nil {
+		return "", errs.Combine(infoErr, err)
+	}
+	return fmt.Sprintf("%s, no admin info: %v", health, infoErr), nil
+}
+
+// describeInfo describes the server info of a cluster.
+func describeInfo(info serverInfo) string {
+	var online, drives, drivesOK int
+	versions := make(map[string]bool)
+	for _, server := range info.Servers {
+		if server.State == "online" {
+			online++
+		}
+		versions[server.Version] = true
+		for _, drive := range server.Drives {
+			drives++
+			if drive.State == "ok" {
+				drivesOK++
+			}
+		}
+	}
+
+	sortedVersions := make([]string, 0, len(versions))
+	for version := range versions {
+		sortedVersions = append(sortedVersions, version)
+	}
+	sort.Strings(sortedVersions)
+
+	return fmt.Sprintf("%s, %d/%d servers online, %d/%d drives ok, version %s",
+		info.Mode, online, len(info.Servers), drivesOK, drives, strings.Join(sortedVersions, "/"))
+}
+
+// admin gets the JSON result of an admin API call, signed like S3
+// requests.
+func (client *Client) admin(ctx context.Context, path string, out interface{}) (err error) {
+	u := client.url.ResolveReference(&url.URL{Path: path})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	if _, err := client.signer.Sign(req, nil, "s3", client.cfg.Region, time.Now()); err != nil {
+		return Error.Wrap(err)
+	}
+
+	resp, err := client.http.Do(req)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(resp.Body.Close())) }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return Error.New("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return Error.Wrap(json.NewDecoder(resp.Body).Decode(out))
+}
+
+// health returns whether the cluster has write quorum, which needs no
+// credentials.
+func (client *Client) health(ctx context.Context) (_ string, err error) {
+	u := client.url.ResolveReference(&url.URL{Path: "/minio/health/cluster"})
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", Error.Wrap(err)
+	}
+
+	resp, err := client.http.Do(req)
+	if err != nil {
+		return "", Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(resp.Body.Close())) }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return "healthy", nil
+	case http.StatusServiceUnavailable:
+		return "unhealthy", nil
+	default:
+		return "", Error.New("health check: %s", resp.Status)
+	}
+}
+
+// Close closes the client.
+func (client *Client) Close() error {
+	client.http.CloseIdleConnections()
+	return client.Client.Close()
+}
@@ -0,0 +1,344 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package rcloneclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/rclone/rclone/backend/all" // register rclone's remotes
+	"github.com/rclone/rclone/fs"
+	"github.com/rclone/rclone/fs/cache"
+	"github.com/rclone/rclone/fs/operations"
+	"github.com/rclone/rclone/librclone/librclone"
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("rclone-client")
+)
+
+func init() {
+	cli.Register("rclone", func() cli.EndpointConfig { return &Config{} })
+}
+
+// Config is the config of [endpoint.rclone.<id>] tables, which benchmark
+// any of rclone's remotes with rclone linked in through librclone. Remotes
+// are those of rclone's config file, those configured by RCLONE_CONFIG_*
+// environment variables, or connection strings such as
+// ":s3,provider=Minio,endpoint='http://localhost:9000':bucket".
+type Config struct {
+	// Remote is the rclone remote and the bucket or directory in it to
+	// test in, such as "b2:bucket".
+	Remote string `toml:"remote"`
+	Path   string `toml:"path"`
+
+	config.EndpointDefaults
+}
+
+// Validate checks that the endpoint has a remote.
+func (cfg *Config) Validate() error {
+	if cfg.Remote == "" {
+		return errs.New("remote is required")
+	}
+	return nil
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *Config) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(*cfg)
+}
+
+// initialize initializes librclone, which loads rclone's config, the first
+// time a client is created.
+var initialize sync.Once
+
+// Client is a client of an rclone remote. Transfers use the rclone
+// filesystem of the remote, since the remote control calls of librclone
+// can't stream objects, and the other operations call librclone.
+type Client struct {
+	cfg Config
+}
+
+// New creates a new rclone client.
+func New(cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	initialize.Do(librclone.Initialize)
+	return &Client{cfg: cfg}, nil
+}
+
+// Settings describes the remote the client benchmarks.
+func (client *Client) Settings() string {
+	return "remote=" + client.cfg.Remote
+}
+
+// listItem is an entry of the result of operations/list.
+type listItem struct {
+	Path    string
+	Name    string
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+// List lists the objects under name.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var out struct{ List []listItem }
+	err = client.call(ctx, "operations/list", map[string]interface{}{
+		"fs":     client.cfg.Remote,
+		"remote": client.remote(name),
+		"opt":    map[string]interface{}{"recurse": recursive},
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range out.List {
+		key := client.key(item.Path)
+		switch {
+		case !item.IsDir:
+			objs = append(objs, &cli.ListObject{Key: key, Size: item.Size, LastModified: item.ModTime})
+		case !recursive:
+			objs = append(objs, &cli.ListObject{Key: key + "/", IsPre: true})
+		}
+	}
+	return objs, nil
+}
+
+// Upload uploads the object, streaming it if its size is known.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	f, err := client.fs(ctx)
+	if err != nil {
+		return err
+	}
+	size := int64(-1)
+	if known, ok := cli.Size(ctx); ok {
+		size = known
+	}
+	if _, err := operations.RcatSize(ctx, f, client.remote(name), ioutil.NopCloser(strm), size, time.Now()); err != nil {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+	return nil
+}
+
+// UploadMultipart uploads the object. Rclone chooses how to split uploads
+// into parts itself, so the object is sent in a single upload while timing
+// each partSize bytes, and concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	pipeReader, pipeWriter := io.Pipe()
+	uploadErr := make(chan error, 1)
+	go func() {
+		err := client.Upload(ctx, name, pipeReader)
+		// Unblock the writer when the upload ends early.
+		_ = pipeReader.CloseWithError(errs.New("upload ended"))
+		uploadErr <- err
+	}()
+
+	parts, err = cli.CopyParts(pipeWriter, strm, partSize)
+	_ = pipeWriter.CloseWithError(err)
+
+	if err := <-uploadErr; err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// Download downloads the object.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.open(ctx, name)
+}
+
+// DownloadRange downloads a byte range of the object.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	end := int64(-1)
+	if length >= 0 {
+		end = offset + length - 1
+	}
+	return client.open(ctx, name, &fs.RangeOption{Start: offset, End: end})
+}
+
+// Delete deletes the object through operations/deletefile.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.call(ctx, "operations/deletefile", map[string]interface{}{
+		"fs":     client.cfg.Remote,
+		"remote": client.remote(name),
+	}, nil)
+}
+
+// Copy copies the object on the remote through operations/copyfile.
+func (client *Client) Copy(ctx context.Context, src, dst string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.call(ctx, "operations/copyfile", map[string]interface{}{
+		"srcFs":     client.cfg.Remote,
+		"srcRemote": client.remote(src),
+		"dstFs":     client.cfg.Remote,
+		"dstRemote": client.remote(dst),
+	}, nil)
+}
+
+// CreateBucket creates a bucket at the root of the remote.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.call(ctx, "operations/mkdir", map[string]interface{}{
+		"fs":     client.root(),
+		"remote": bucket,
+	}, nil)
+}
+
+// ListBuckets lists the buckets at the root of the remote.
+func (client *Client) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	var out struct{ List []listItem }
+	err = client.call(ctx, "operations/list", map[string]interface{}{
+		"fs":     client.root(),
+		"remote": "",
+		"opt":    map[string]interface{}{"dirsOnly": true},
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range out.List {
+		buckets = append(buckets, item.Name)
+	}
+	return buckets, nil
+}
+
+// DeleteBucket deletes an empty bucket at the root of the remote.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.call(ctx, "operations/rmdir", map[string]interface{}{
+		"fs":     client.root(),
+		"remote": bucket,
+	}, nil)
+}
+
+// IP returns nothing, since rclone doesn't tell which host the remote is
+// on.
+func (client *Client) IP(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+// Close closes the client. The filesystems of remotes are cached and
+// shared by the clients of the same remote.
+func (client *Client) Close() error {
+	return nil
+}
+
+// call calls a method of rclone's remote control API through librclone
+// with JSON parameters and decodes its result into out, if set. The calls
+// can't be cancelled, so ctx is only checked before.
+func (client *Client) call(ctx context.Context, method string, params map[string]interface{}, out interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	input, err := json.Marshal(params)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	output, status := librclone.RPC(method, string(input))
+	if status != http.StatusOK {
+		return Error.New("%s: %v", method, callError(output, status))
+	}
+	if out == nil {
+		return nil
+	}
+	return Error.Wrap(json.Unmarshal([]byte(output), out))
+}
+
+// callError returns the error of a failed call, which librclone describes
+// in a JSON output along with an HTTP status.
+func callError(output string, status int) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(output), &body); err != nil || body.Error == "" {
+		return errs.New("%d %s", status, http.StatusText(status))
+	}
+	return errs.New("%d %s: %s", status, http.StatusText(status), body.Error)
+}
+
+// fs returns the rclone filesystem of the remote.
+func (client *Client) fs(ctx context.Context) (fs.Fs, error) {
+	f, err := cache.Get(ctx, client.cfg.Remote)
+	if err != nil && !errors.Is(err, fs.ErrorIsFile) {
+		return nil, Error.Wrap(err)
+	}
+	return f, nil
+}
+
+// open opens the object with the options.
+func (client *Client) open(ctx context.Context, name string, options ...fs.OpenOption) (io.ReadCloser, error) {
+	f, err := client.fs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := f.NewObject(ctx, client.remote(name))
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	strm, err := obj.Open(ctx, options...)
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	return strm, nil
+}
+
+// remote returns the path of the object name in the remote.
+func (client *Client) remote(name string) string {
+	if client.cfg.Path != "" {
+		return path.Join(client.cfg.Path, name)
+	}
+	return name
+}
+
+// key returns the name of the object at the path p in the remote.
+func (client *Client) key(p string) string {
+	if client.cfg.Path == "" {
+		return p
+	}
+	return strings.TrimPrefix(p, strings.Trim(client.cfg.Path, "/")+"/")
+}
+
+// root returns the root of the remote, which holds its buckets.
+func (client *Client) root() string {
+	return client.cfg.Remote[:strings.Index(client.cfg.Remote, ":")+1]
+}
@@ -0,0 +1,64 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package rcloneclient_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/backends/rcloneclient"
+)
+
+func TestClient(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	// A local directory is a remote of its own.
+	client, err := rcloneclient.New(rcloneclient.Config{Remote: ctx.Dir("remote"), Path: "bench"})
+	require.NoError(t, err)
+	defer ctx.Check(client.Close)
+
+	require.NoError(t, client.Upload(cli.WithSize(ctx, 11), "data/obj0", strings.NewReader("hello world")))
+	require.NoError(t, client.Upload(ctx, "data/obj1", strings.NewReader("unsized")))
+
+	download, err := client.Download(ctx, "data/obj0")
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(download)
+	require.NoError(t, err)
+	require.NoError(t, download.Close())
+	require.Equal(t, "hello world", string(data))
+
+	download, err = client.DownloadRange(ctx, "data/obj0", 6, 3)
+	require.NoError(t, err)
+	data, err = ioutil.ReadAll(download)
+	require.NoError(t, err)
+	require.NoError(t, download.Close())
+	require.Equal(t, "wor", string(data))
+
+	// Keys are relative to the path of the endpoint.
+	objects, err := client.List(ctx, "", false)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	require.Equal(t, "data/", objects[0].Key)
+	require.True(t, objects[0].IsPre)
+
+	require.NoError(t, client.Copy(ctx, "data/obj0", "data/copy"))
+	objects, err = client.List(ctx, "data", true)
+	require.NoError(t, err)
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	require.ElementsMatch(t, []string{"data/obj0", "data/obj1", "data/copy"}, keys)
+
+	require.NoError(t, client.Delete(ctx, "data/obj0"))
+	_, err = client.Download(ctx, "data/obj0")
+	require.Error(t, err)
+	require.Error(t, client.Delete(ctx, "data/obj0"))
+}
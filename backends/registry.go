@@ -0,0 +1,78 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package backends
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// EndpointConfig is the config of an endpoint of a registered type, which
+// the endpoint's table in the config file is decoded into. Fields with
+// secrets are tagged secret:"true", which redacts them where the config is
+// printed.
+type EndpointConfig interface {
+	// NewClient creates the client of the endpoint.
+	NewClient(ctx context.Context, log *zap.Logger) (Client, error)
+}
+
+// Validator is implemented by endpoint configs which check their settings
+// when the config is validated.
+type Validator interface {
+	Validate() error
+}
+
+// Renamer is implemented by endpoint configs whose results are reported
+// under another ID than the configured one, such as variants of an
+// endpoint which are run side by side.
+type Renamer interface {
+	ReportID(id string) string
+}
+
+// Factory returns a pointer to a new, empty config of an endpoint type.
+type Factory func() EndpointConfig
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{}
+)
+
+// Register makes an endpoint type available under name, so that endpoints
+// configured in [endpoint.<name>.<id>] tables are created by its clients.
+// It is usually called from the init function of the client's package and
+// panics if the name is already registered.
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if _, ok := factories[name]; ok {
+		panic("client: endpoint type registered twice: " + name)
+	}
+	factories[name] = factory
+}
+
+// Lookup returns the factory of the named endpoint type.
+func Lookup(name string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	factory, ok := factories[name]
+	return factory, ok
+}
+
+// Types returns the sorted names of the registered endpoint types.
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
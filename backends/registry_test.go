@@ -0,0 +1,46 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package backends
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testEndpointConfig struct {
+	Name string `toml:"name"`
+}
+
+func (cfg *testEndpointConfig) NewClient(ctx context.Context, log *zap.Logger) (Client, error) {
+	return nil, nil
+}
+
+func TestRegistry(t *testing.T) {
+	_, ok := Lookup("registry-test")
+	require.False(t, ok)
+	require.NotContains(t, Types(), "registry-test")
+
+	Register("registry-test", func() EndpointConfig { return &testEndpointConfig{} })
+	Register("registry-test-b", func() EndpointConfig { return &testEndpointConfig{Name: "b"} })
+
+	factory, ok := Lookup("registry-test")
+	require.True(t, ok)
+	// Every call returns a new config, so that endpoints don't share one.
+	first, second := factory(), factory()
+	require.NotSame(t, first, second)
+	require.Equal(t, &testEndpointConfig{}, first)
+
+	types := Types()
+	require.Contains(t, types, "registry-test")
+	require.Contains(t, types, "registry-test-b")
+	require.True(t, sort.StringsAreSorted(types))
+
+	require.Panics(t, func() {
+		Register("registry-test", func() EndpointConfig { return &testEndpointConfig{} })
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/config"
+)
+
+// hangingServer is a fake S3 server whose part uploads and downloads hang
+// until the client gives up on them.
+type hangingServer struct {
+	started chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	aborted bool
+}
+
+func (server *hangingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	_, uploads := query["uploads"]
+	switch {
+	case r.Method == http.MethodPost && uploads:
+		_, _ = w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>obj</Key><UploadId>upload1</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		server.once.Do(func() { close(server.started) })
+		<-r.Context().Done()
+	case r.Method == http.MethodDelete && query.Get("uploadId") == "upload1":
+		server.mu.Lock()
+		server.aborted = true
+		server.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet:
+		w.Header().Set("Content-Length", "1048576")
+		_, _ = w.Write(make([]byte, 1024))
+		w.(http.Flusher).Flush()
+		server.once.Do(func() { close(server.started) })
+		<-r.Context().Done()
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func newHangingClient(t *testing.T) (*Client, *hangingServer, func()) {
+	hanging := &hangingServer{started: make(chan struct{})}
+	server := httptest.NewServer(hanging)
+	client, err := New(config.S3Endpoint{
+		Region: "us-east-1", AccessKey: "access", SecretKey: "secret",
+		Bucket: "bucket", Address: server.URL, PathStyle: true,
+	})
+	require.NoError(t, err)
+	return client, hanging, server.Close
+}
+
+func TestUploadMultipartCancel(t *testing.T) {
+	client, hanging, closeServer := newHangingClient(t)
+	defer closeServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-hanging.started
+		cancel()
+	}()
+
+	// Cancelling stops the upload promptly and still aborts it.
+	start := time.Now()
+	_, err := client.UploadMultipart(ctx, "obj", bytes.NewReader(make([]byte, 10<<20)), 5<<20, 1)
+	require.Error(t, err)
+	require.Less(t, int64(time.Since(start)), int64(10*time.Second))
+
+	hanging.mu.Lock()
+	defer hanging.mu.Unlock()
+	require.True(t, hanging.aborted, "the multipart upload wasn't aborted")
+}
+
+func TestDownloadCancel(t *testing.T) {
+	client, hanging, closeServer := newHangingClient(t)
+	defer closeServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	strm, err := client.Download(ctx, "obj")
+	require.NoError(t, err)
+	defer func() { _ = strm.Close() }()
+
+	_, err = io.ReadFull(strm, make([]byte, 1024))
+	require.NoError(t, err)
+	<-hanging.started
+
+	// Cancelling stops reading the rest of the object promptly.
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(ioutil.Discard, strm)
+		done <- err
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "download didn't stop when cancelled")
+	}
+}
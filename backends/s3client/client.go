@@ -0,0 +1,548 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/common/memory"
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("s3-client")
+)
+
+// abortTimeout limits aborting a failed or cancelled multipart upload.
+const abortTimeout = 30 * time.Second
+
+// Client is an S3 client.
+type Client struct {
+	cfg     config.S3Endpoint
+	session *session.Session
+	// http transfers objects through presigned URLs.
+	http *http.Client
+
+	// expirationRules are the TTLs in days of expiring objects which the
+	// bucket has lifecycle rules for.
+	mu              sync.Mutex
+	expirationRules map[int64]bool
+}
+
+// New creates a new S3 client.
+func New(cfg config.S3Endpoint) (*Client, error) {
+	switch {
+	case cfg.Region == "":
+		return nil, errs.New("region is required")
+	case cfg.Bucket == "":
+		return nil, errs.New("bucket is required")
+	case cfg.AccessKey == "":
+		return nil, errs.New("access key is required")
+	case cfg.SecretKey == "":
+		return nil, errs.New("secret key is required")
+	}
+
+	httpClient := newHTTPClient(cfg)
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String(cfg.Region),
+		Credentials:      credentials.NewStaticCredentials(cfg.AccessKey, cfg.SecretKey, ""),
+		Endpoint:         aws.String(cfg.Address),
+		S3ForcePathStyle: aws.Bool(cfg.PathStyle),
+		S3UseAccelerate:  aws.Bool(cfg.Accelerate),
+		UseDualStack:     aws.Bool(cfg.Dualstack),
+		HTTPClient:       httpClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		session:         sess,
+		cfg:             cfg,
+		http:            httpClient,
+		expirationRules: make(map[int64]bool),
+	}, nil
+}
+
+// List returns the objects found at name.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	objs, _, err = client.ListPages(ctx, name, recursive, 0, 0)
+	return objs, err
+}
+
+// ListPages returns the objects found at name, following the continuation
+// tokens of pages of up to pageSize keys until it has maxKeys of them. S3
+// defaults to 1000 keys per page when pageSize is zero, and lists all keys
+// when maxKeys is zero.
+func (client *Client) ListPages(ctx context.Context, name string, recursive bool, pageSize, maxKeys int) (objs []*cli.ListObject, pages int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	path := client.bucketKey(name)
+	if path != "" && !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	var delimeter *string
+	if !recursive {
+		delimeter = aws.String("/")
+	}
+	if maxKeys > 0 && (pageSize <= 0 || pageSize > maxKeys) {
+		pageSize = maxKeys
+	}
+	var pageKeys *int64
+	if pageSize > 0 {
+		pageKeys = aws.Int64(int64(pageSize))
+	}
+
+	err = svc.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(client.cfg.Bucket),
+		Prefix:    aws.String(path),
+		Delimiter: delimeter,
+		MaxKeys:   pageKeys,
+	}, func(out *s3.ListObjectsV2Output, lastPage bool) bool {
+		pages++
+		for _, pre := range out.CommonPrefixes {
+			objs = append(objs, &cli.ListObject{
+				Key:   client.key(aws.StringValue(pre.Prefix)),
+				IsPre: true,
+			})
+		}
+
+		for _, obj := range out.Contents {
+			objs = append(objs, &cli.ListObject{
+				Key:          client.key(aws.StringValue(obj.Key)),
+				IsPre:        false,
+				Size:         aws.Int64Value(obj.Size),
+				LastModified: aws.TimeValue(obj.LastModified),
+			})
+		}
+		return maxKeys <= 0 || len(objs) < maxKeys
+	})
+	if err != nil {
+		return nil, pages, err
+	}
+	if maxKeys > 0 && len(objs) > maxKeys {
+		objs = objs[:maxKeys]
+	}
+
+	return objs, pages, nil
+}
+
+// Stat returns the object at name, or nil if it doesn't exist.
+func (client *Client) Stat(ctx context.Context, name string) (obj *cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	key := client.bucketKey(name)
+	out, err := svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(client.cfg.Bucket),
+		Key:    aws.String(key),
+	})
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == "NotFound" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file %q: %v", name, err)
+	}
+	return &cli.ListObject{
+		Key:          name,
+		Size:         aws.Int64Value(out.ContentLength),
+		LastModified: aws.TimeValue(out.LastModified),
+	}, nil
+}
+
+// Upload uploads to S3.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	tagging := client.uploadTagging(ctx)
+	if client.cfg.Presign {
+		return client.presignedUpload(ctx, name, strm, tagging)
+	}
+
+	_, err = client.newUploader().UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:  aws.String(client.cfg.Bucket),
+		Key:     aws.String(client.bucketKey(name)),
+		Body:    strm,
+		Tagging: tagging,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload file %q: %v", name, err)
+	}
+	return nil
+}
+
+// newUploader returns an uploader with the configured settings. Every
+// upload uses a new uploader so we don't skew results with the caching done
+// by the uploader part pool.
+func (client *Client) newUploader() *s3manager.Uploader {
+	return s3manager.NewUploader(client.session, func(uploader *s3manager.Uploader) {
+		if client.cfg.UploadConcurrency > 0 {
+			uploader.Concurrency = client.cfg.UploadConcurrency
+		}
+		if client.cfg.UploadPartSize > 0 {
+			uploader.PartSize = int64(client.cfg.UploadPartSize)
+		}
+	})
+}
+
+// UploadMultipart uploads to S3 using the multipart upload API, uploading up
+// to concurrency parts of partSize bytes at once.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	tagging := client.uploadTagging(ctx)
+
+	svc := s3.New(client.session)
+	bucket, key := aws.String(client.cfg.Bucket), aws.String(client.bucketKey(name))
+
+	created, err := svc.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:  bucket,
+		Key:     key,
+		Tagging: tagging,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart upload for file %q: %v", name, err)
+	}
+	defer func() {
+		if err != nil {
+			// The upload is aborted even if ctx was cancelled, so that its
+			// parts don't stay behind.
+			abortCtx, cancel := context.WithTimeout(context.Background(), abortTimeout)
+			defer cancel()
+			_, abortErr := svc.AbortMultipartUploadWithContext(abortCtx, &s3.AbortMultipartUploadInput{
+				Bucket:   bucket,
+				Key:      key,
+				UploadId: created.UploadId,
+			})
+			err = errs.Combine(err, abortErr)
+		}
+	}()
+
+	var mu sync.Mutex
+	var completed []*s3.CompletedPart
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	limiter := make(chan struct{}, concurrency)
+
+	var readErr error
+	for number := 1; ; number++ {
+		buf := make([]byte, partSize)
+		n, err := io.ReadFull(strm, buf)
+		if errors.Is(err, io.EOF) && number > 1 {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			readErr = err
+			break
+		}
+
+		select {
+		case limiter <- struct{}{}:
+		case <-groupCtx.Done():
+		}
+		if groupCtx.Err() != nil {
+			break
+		}
+
+		number, body := number, buf[:n]
+		group.Go(func() error {
+			defer func() { <-limiter }()
+
+			start := time.Now()
+			etag, err := client.uploadPart(groupCtx, svc, &s3.UploadPartInput{
+				Bucket:     bucket,
+				Key:        key,
+				UploadId:   created.UploadId,
+				PartNumber: aws.Int64(int64(number)),
+			}, body)
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d of file %q: %v", number, name, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			completed = append(completed, &s3.CompletedPart{
+				ETag:       etag,
+				PartNumber: aws.Int64(int64(number)),
+			})
+			parts = append(parts, cli.Part{
+				Number:   number,
+				Size:     int64(len(body)),
+				Duration: time.Since(start),
+			})
+			return nil
+		})
+
+		if n < len(buf) {
+			break
+		}
+	}
+
+	if err := errs.Combine(group.Wait(), readErr); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(completed, func(i, j int) bool { return *completed[i].PartNumber < *completed[j].PartNumber })
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+
+	_, err = svc.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          bucket,
+		Key:             key,
+		UploadId:        created.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete multipart upload for file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// uploadPart uploads a part of a multipart upload, returning its ETag.
+func (client *Client) uploadPart(ctx context.Context, svc *s3.S3, input *s3.UploadPartInput, body []byte) (etag *string, err error) {
+	if client.cfg.Presign {
+		return client.presignedUploadPart(ctx, svc, input, body)
+	}
+
+	input.Body = bytes.NewReader(body)
+	out, err := svc.UploadPartWithContext(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return out.ETag, nil
+}
+
+// Download downloads from S3, through s3manager's downloader if the
+// endpoint has a download concurrency, or through a presigned URL.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	switch {
+	case client.cfg.Presign:
+		return client.presignedDownload(ctx, name, "")
+	case client.cfg.DownloadConcurrency > 0:
+		return client.managedDownload(ctx, name), nil
+	}
+
+	svc := s3.New(client.session)
+
+	out, err := svc.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(client.cfg.Bucket),
+		Key:    aws.String(client.bucketKey(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file %q: %v", name, err)
+	}
+	return out.Body, nil
+}
+
+// DownloadRange downloads a byte range from S3.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange += strconv.FormatInt(offset+length-1, 10)
+	}
+	if client.cfg.Presign {
+		return client.presignedDownload(ctx, name, byteRange)
+	}
+
+	out, err := svc.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(client.cfg.Bucket),
+		Key:    aws.String(client.bucketKey(name)),
+		Range:  aws.String(byteRange),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download range %q of file %q: %v", byteRange, name, err)
+	}
+	return out.Body, nil
+}
+
+// Delete deletes from S3.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	_, err = svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(client.cfg.Bucket),
+		Key:    aws.String(client.bucketKey(name)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file %q: %v", name, err)
+	}
+	return nil
+}
+
+// Copy copies an object within the S3 bucket.
+func (client *Client) Copy(ctx context.Context, src, dst string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	source := (&url.URL{Path: path.Join(client.cfg.Bucket, client.bucketKey(src))}).EscapedPath()
+	_, err = svc.CopyObjectWithContext(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(client.cfg.Bucket),
+		Key:        aws.String(client.bucketKey(dst)),
+		CopySource: aws.String(source),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy file %q to %q: %v", src, dst, err)
+	}
+	return nil
+}
+
+// CreateBucket creates a bucket in the region of the client.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	input := &s3.CreateBucketInput{Bucket: aws.String(bucket)}
+	// us-east-1 is the default location, which can't be specified.
+	if client.cfg.Region != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: aws.String(client.cfg.Region),
+		}
+	}
+	if _, err := svc.CreateBucketWithContext(ctx, input); err != nil {
+		return fmt.Errorf("failed to create bucket %q: %v", bucket, err)
+	}
+	return nil
+}
+
+// ListBuckets returns the names of the buckets of the account.
+func (client *Client) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	out, err := svc.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %v", err)
+	}
+	for _, bucket := range out.Buckets {
+		buckets = append(buckets, aws.StringValue(bucket.Name))
+	}
+	return buckets, nil
+}
+
+// DeleteBucket deletes an empty bucket.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	if _, err := svc.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{Bucket: aws.String(bucket)}); err != nil {
+		return fmt.Errorf("failed to delete bucket %q: %v", bucket, err)
+	}
+	return nil
+}
+
+// Settings describes the transfer settings of the client.
+func (client *Client) Settings() string {
+	if client.cfg.Presign {
+		return "download and upload through presigned URLs"
+	}
+
+	download := "streamed"
+	if client.cfg.DownloadConcurrency > 0 {
+		partSize := int64(client.cfg.DownloadPartSize)
+		if partSize <= 0 {
+			partSize = s3manager.DefaultDownloadPartSize
+		}
+		download = fmt.Sprintf("%d x %v parts", client.cfg.DownloadConcurrency, memory.Size(partSize))
+	}
+
+	concurrency := client.cfg.UploadConcurrency
+	if concurrency <= 0 {
+		concurrency = s3manager.DefaultUploadConcurrency
+	}
+	partSize := int64(client.cfg.UploadPartSize)
+	if partSize <= 0 {
+		partSize = s3manager.DefaultUploadPartSize
+	}
+	return fmt.Sprintf("download %s, upload %d x %v parts", download, concurrency, memory.Size(partSize))
+}
+
+// IP returns the IP address of the endpoint.
+func (client *Client) IP(ctx context.Context) (addr string, err error) {
+	// The endpoint resolves to a range of IPs, which the network
+	// diagnostics record, so only its host is returned.
+	address, _, err := client.NetworkAddress()
+	if err != nil {
+		return "", err
+	}
+	host, _, err := net.SplitHostPort(address)
+	return host, Error.Wrap(err)
+}
+
+// NetworkAddress returns the address of the S3 endpoint the client sends its
+// requests to.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	endpoint, err := url.Parse(s3.New(client.session).Endpoint)
+	if err != nil {
+		return "", false, Error.Wrap(err)
+	}
+	address, useTLS := cli.URLAddress(endpoint)
+	return address, useTLS, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() (err error) { return nil }
+
+func (client *Client) bucketKey(name string) string {
+	if client.cfg.Path != "" {
+		return path.Join(client.cfg.Path, name)
+	}
+	return name
+}
+
+// key returns the name of the object at the bucket key k.
+func (client *Client) key(k string) string {
+	if client.cfg.Path == "" {
+		return k
+	}
+	return strings.TrimPrefix(k, strings.Trim(client.cfg.Path, "/")+"/")
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/config"
+)
+
+func TestListKeysRelativeToPath(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("prefix") != "bench/data/" {
+			http.Error(w, "unexpected prefix", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`<ListBucketResult>` +
+			`<Contents><Key>bench/data/obj0</Key><Size>5</Size></Contents>` +
+			`<CommonPrefixes><Prefix>bench/data/dir/</Prefix></CommonPrefixes>` +
+			`</ListBucketResult>`))
+	}))
+	defer server.Close()
+
+	client, err := New(config.S3Endpoint{
+		Region: "us-east-1", AccessKey: "access", SecretKey: "secret",
+		Bucket: "bucket", Address: server.URL, PathStyle: true, Path: "bench",
+	})
+	require.NoError(t, err)
+
+	objects, err := client.List(ctx, "data", false)
+	require.NoError(t, err)
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	require.ElementsMatch(t, []string{"data/obj0", "data/dir/"}, keys)
+}
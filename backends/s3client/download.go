@@ -0,0 +1,140 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// managedDownload downloads an object with s3manager's downloader, which
+// gets ranges of it concurrently, and streams it in order without
+// buffering more than the parts being downloaded at once.
+func (client *Client) managedDownload(ctx context.Context, name string) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	r, w := io.Pipe()
+
+	downloader := s3manager.NewDownloader(client.session, func(downloader *s3manager.Downloader) {
+		downloader.Concurrency = client.cfg.DownloadConcurrency
+		if client.cfg.DownloadPartSize > 0 {
+			downloader.PartSize = int64(client.cfg.DownloadPartSize)
+		}
+	})
+	ordered := newOrderedWriter(w, int64(downloader.Concurrency)*downloader.PartSize)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := downloader.DownloadWithContext(ctx, ordered, &s3.GetObjectInput{
+			Bucket: aws.String(client.cfg.Bucket),
+			Key:    aws.String(client.bucketKey(name)),
+		})
+		_ = w.CloseWithError(err)
+	}()
+	go func() {
+		// Parts waiting for the ones before them give up with the download.
+		select {
+		case <-ctx.Done():
+			ordered.fail(ctx.Err())
+		case <-done:
+		}
+	}()
+
+	return &managedStream{PipeReader: r, cancel: cancel, done: done}
+}
+
+// managedStream is the stream of a managed download.
+type managedStream struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Close stops the download.
+func (strm *managedStream) Close() error {
+	strm.cancel()
+	err := strm.PipeReader.Close()
+	<-strm.done
+	return err
+}
+
+// orderedWriter writes the parts written at their offsets to w in order,
+// keeping the parts written ahead of the next offset until it is reached.
+// Writes ending more than limit bytes after it wait, so that at most limit
+// bytes are kept.
+type orderedWriter struct {
+	mu      sync.Mutex
+	cond    sync.Cond
+	w       io.Writer
+	limit   int64
+	offset  int64
+	pending map[int64][]byte
+	err     error
+}
+
+// newOrderedWriter returns an orderedWriter which keeps at most limit bytes
+// written ahead.
+func newOrderedWriter(w io.Writer, limit int64) *orderedWriter {
+	ordered := &orderedWriter{w: w, limit: limit, pending: make(map[int64][]byte)}
+	ordered.cond.L = &ordered.mu
+	return ordered
+}
+
+// fail makes pending and later writes fail with err.
+func (ordered *orderedWriter) fail(err error) {
+	ordered.mu.Lock()
+	defer ordered.mu.Unlock()
+	if ordered.err == nil {
+		ordered.err = err
+	}
+	ordered.cond.Broadcast()
+}
+
+// WriteAt implements io.WriterAt.
+func (ordered *orderedWriter) WriteAt(p []byte, off int64) (int, error) {
+	ordered.mu.Lock()
+	defer ordered.mu.Unlock()
+
+	for ordered.err == nil && off != ordered.offset && off+int64(len(p))-ordered.offset > ordered.limit {
+		ordered.cond.Wait()
+	}
+	if ordered.err != nil {
+		return 0, ordered.err
+	}
+
+	if off != ordered.offset {
+		ordered.pending[off] = append([]byte(nil), p...)
+		return len(p), nil
+	}
+
+	// Writes ahead may fit now.
+	defer ordered.cond.Broadcast()
+
+	n, err := ordered.w.Write(p)
+	ordered.offset += int64(n)
+	if err != nil {
+		ordered.err = err
+		return n, err
+	}
+
+	for {
+		next, ok := ordered.pending[ordered.offset]
+		if !ok {
+			return len(p), nil
+		}
+		delete(ordered.pending, ordered.offset)
+
+		n, err := ordered.w.Write(next)
+		ordered.offset += int64(n)
+		if err != nil {
+			ordered.err = err
+			return len(p), err
+		}
+	}
+}
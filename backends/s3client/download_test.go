@@ -0,0 +1,55 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedWriter(t *testing.T) {
+	var buf bytes.Buffer
+	ordered := newOrderedWriter(&buf, 6)
+
+	// Parts ahead are kept until the ones before them are written.
+	_, err := ordered.WriteAt([]byte("cd"), 2)
+	require.NoError(t, err)
+	_, err = ordered.WriteAt([]byte("ef"), 4)
+	require.NoError(t, err)
+	require.Empty(t, buf.String())
+
+	// More than the limit ahead waits until the parts before are written.
+	written := make(chan error, 1)
+	go func() {
+		_, err := ordered.WriteAt([]byte("gh"), 6)
+		written <- err
+	}()
+	select {
+	case <-written:
+		t.Fatal("write beyond the limit didn't wait")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	_, err = ordered.WriteAt([]byte("ab"), 0)
+	require.NoError(t, err)
+	require.NoError(t, <-written)
+	require.Equal(t, "abcdefgh", buf.String())
+	require.Empty(t, ordered.pending)
+
+	// Failing releases the writes which wait.
+	go func() {
+		_, err := ordered.WriteAt([]byte("zz"), 20)
+		written <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	failure := errors.New("canceled")
+	ordered.fail(failure)
+	require.Equal(t, failure, <-written)
+	_, err = ordered.WriteAt([]byte("ij"), 8)
+	require.Equal(t, failure, err)
+}
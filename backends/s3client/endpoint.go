@@ -0,0 +1,43 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+func init() {
+	cli.Register("s3", func() cli.EndpointConfig { return &endpointConfig{} })
+}
+
+// endpointConfig is the config of [endpoint.s3.<id>] tables.
+type endpointConfig struct {
+	config.S3Endpoint
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *endpointConfig) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(cfg.S3Endpoint)
+}
+
+// ReportID marks whether the endpoint uses transfer acceleration,
+// dualstack or presigned URLs, so the variants are told apart in the
+// report.
+func (cfg *endpointConfig) ReportID(id string) string {
+	if cfg.Accelerate {
+		id += "+accelerate"
+	}
+	if cfg.Dualstack {
+		id += "+dualstack"
+	}
+	if cfg.Presign {
+		id += "+presigned"
+	}
+	return id
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	cli "storj.io/perftester/backends"
+)
+
+// ttlTagKey is the key of the tag of objects uploaded with a TTL. Its value
+// is the number of days they expire after, which the lifecycle rule
+// expiring them matches.
+const ttlTagKey = "perftester-ttl-days"
+
+// ttlDays returns the number of whole days objects with a TTL of ttl
+// expire after, since lifecycle rules expire objects after whole days.
+func ttlDays(ttl time.Duration) int64 {
+	return int64((ttl + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// expirationRuleID returns the ID of the lifecycle rule expiring the objects
+// tagged to expire after days.
+func expirationRuleID(days int64) string {
+	return fmt.Sprintf("perftester-expire-%dd", days)
+}
+
+// PrepareExpiration checks that the bucket has a lifecycle rule expiring
+// the objects tagged with a TTL of ttl, adding it if the endpoint manages
+// the lifecycle of the bucket. Objects are only tagged once the bucket has
+// the rule. Servers without lifecycle rules don't expire objects.
+func (client *Client) PrepareExpiration(ctx context.Context, ttl time.Duration) (bool, error) {
+	days := ttlDays(ttl)
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if client.expirationRules[days] {
+		return true, nil
+	}
+
+	svc := s3.New(client.session)
+	bucket := aws.String(client.cfg.Bucket)
+	id := expirationRuleID(days)
+
+	var rules []*s3.LifecycleRule
+	out, err := svc.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: bucket})
+	switch {
+	case err == nil:
+		rules = out.Rules
+	case errorCode(err) == "NoSuchLifecycleConfiguration":
+	case lifecycleUnsupported(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to get lifecycle rules of bucket %q: %v", client.cfg.Bucket, err)
+	}
+
+	for _, rule := range rules {
+		if aws.StringValue(rule.ID) == id {
+			client.expirationRules[days] = true
+			return true, nil
+		}
+	}
+	if !client.cfg.ManageLifecycle {
+		return false, nil
+	}
+
+	// Rules with the deprecated top-level prefix can't be mixed with the
+	// filtered rule, and putting them back would break them.
+	for _, rule := range rules {
+		if rule.Prefix != nil {
+			return false, fmt.Errorf("bucket %q has lifecycle rules with a top-level prefix, which can't be mixed with the rule %q expiring objects tagged %s=%d; add the rule yourself", client.cfg.Bucket, id, ttlTagKey, days)
+		}
+	}
+
+	rules = append(rules, &s3.LifecycleRule{
+		ID:     aws.String(id),
+		Status: aws.String(s3.ExpirationStatusEnabled),
+		Filter: &s3.LifecycleRuleFilter{
+			Tag: &s3.Tag{Key: aws.String(ttlTagKey), Value: aws.String(strconv.FormatInt(days, 10))},
+		},
+		Expiration: &s3.LifecycleExpiration{Days: aws.Int64(days)},
+	})
+	_, err = svc.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 bucket,
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+	})
+	switch {
+	case err == nil:
+	case lifecycleUnsupported(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to add lifecycle rule expiring objects to bucket %q: %v", client.cfg.Bucket, err)
+	}
+
+	client.expirationRules[days] = true
+	return true, nil
+}
+
+// ttlTagging returns the tagging of objects uploaded with ctx, which is nil
+// unless they have a TTL which the bucket has a lifecycle rule for.
+func (client *Client) ttlTagging(ctx context.Context) *string {
+	ttl := cli.TTL(ctx)
+	if ttl <= 0 {
+		return nil
+	}
+
+	days := ttlDays(ttl)
+	client.mu.Lock()
+	expires := client.expirationRules[days]
+	client.mu.Unlock()
+	if !expires {
+		return nil
+	}
+	return aws.String(url.Values{ttlTagKey: {strconv.FormatInt(days, 10)}}.Encode())
+}
+
+// errorCode returns the code of an S3 error, or "" for other errors.
+func errorCode(err error) string {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code()
+	}
+	return ""
+}
+
+// lifecycleUnsupported returns whether err is the response of a server
+// without lifecycle rules, as some S3 compatible servers are.
+func lifecycleUnsupported(err error) bool {
+	switch errorCode(err) {
+	case "NotImplemented", "MethodNotAllowed":
+		return true
+	}
+	var failure awserr.RequestFailure
+	if errors.As(err, &failure) {
+		switch failure.StatusCode() {
+		case http.StatusNotImplemented, http.StatusMethodNotAllowed:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// lifecycleServer is a fake S3 server which only serves the lifecycle
+// configuration of buckets.
+type lifecycleServer struct {
+	status int
+	rules  string
+
+	mu   sync.Mutex
+	puts []string
+}
+
+func (server *lifecycleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["lifecycle"]; !ok {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	if server.status != 0 {
+		w.WriteHeader(server.status)
+		_, _ = w.Write([]byte(`<Error><Code>NotImplemented</Code></Error>`))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if server.rules == "" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>NoSuchLifecycleConfiguration</Code></Error>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<LifecycleConfiguration>` + server.rules + `</LifecycleConfiguration>`))
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		server.mu.Lock()
+		server.puts = append(server.puts, string(body))
+		server.mu.Unlock()
+	}
+}
+
+func TestPrepareExpiration(t *testing.T) {
+	ctx := context.Background()
+	ruleWithPrefix := `<Rule><ID>old</ID><Prefix>logs/</Prefix><Status>Enabled</Status><Expiration><Days>30</Days></Expiration></Rule>`
+	existingRule := `<Rule><ID>perftester-expire-2d</ID><Filter><Tag><Key>perftester-ttl-days</Key><Value>2</Value></Tag></Filter><Status>Enabled</Status><Expiration><Days>2</Days></Expiration></Rule>`
+
+	for _, tt := range []struct {
+		name    string
+		server  *lifecycleServer
+		manage  bool
+		expires bool
+		err     bool
+		puts    int
+	}{
+		{name: "not managed", server: &lifecycleServer{}},
+		{name: "existing rule", server: &lifecycleServer{rules: existingRule}, expires: true},
+		{name: "managed", server: &lifecycleServer{}, manage: true, expires: true, puts: 1},
+		{name: "unsupported", server: &lifecycleServer{status: http.StatusNotImplemented}, manage: true},
+		{name: "method not allowed", server: &lifecycleServer{status: http.StatusMethodNotAllowed}, manage: true},
+		{name: "prefix rules", server: &lifecycleServer{rules: ruleWithPrefix}, manage: true, err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.server)
+			defer server.Close()
+
+			client, err := New(config.S3Endpoint{
+				Region: "us-east-1", AccessKey: "access", SecretKey: "secret",
+				Bucket: "bucket", Address: server.URL, PathStyle: true,
+				ManageLifecycle: tt.manage,
+			})
+			require.NoError(t, err)
+
+			expires, err := client.PrepareExpiration(ctx, 36*time.Hour)
+			if tt.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expires, expires)
+			require.Len(t, tt.server.puts, tt.puts)
+			if tt.puts > 0 {
+				require.True(t, strings.Contains(tt.server.puts[0], "perftester-expire-2d"))
+			}
+
+			// Objects are only tagged once the bucket has the rule.
+			tagging := client.ttlTagging(cli.WithTTL(ctx, 36*time.Hour))
+			if tt.expires {
+				require.Equal(t, "perftester-ttl-days=2", *tagging)
+			} else {
+				require.Nil(t, tagging)
+			}
+		})
+	}
+}
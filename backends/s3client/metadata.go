@@ -0,0 +1,99 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	cli "storj.io/perftester/backends"
+)
+
+// uploadTagging returns the tagging of objects uploaded with ctx, which has
+// their custom metadata and the tag expiring them, or nil if they have
+// neither. The custom metadata of objects is stored in their tags, which
+// unlike their user metadata can be changed without rewriting the objects,
+// so that uploads, SetMetadata and GetMetadata use the same tags.
+func (client *Client) uploadTagging(ctx context.Context) *string {
+	return client.tagging(ctx, cli.Metadata(ctx))
+}
+
+// tagging returns the tagging of objects with metadata uploaded with ctx,
+// or nil if it is empty.
+func (client *Client) tagging(ctx context.Context, metadata map[string]string) *string {
+	values := make(url.Values)
+	if ttlTagging := client.ttlTagging(ctx); ttlTagging != nil {
+		// ttlTagging is encoded by us, so it parses.
+		values, _ = url.ParseQuery(*ttlTagging)
+	}
+	for key, value := range metadata {
+		values.Set(key, value)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return aws.String(values.Encode())
+}
+
+// GetMetadata returns the custom metadata of the object, which is in its
+// tags. The tag expiring objects isn't part of it.
+func (client *Client) GetMetadata(ctx context.Context, name string) (metadata map[string]string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	out, err := svc.GetObjectTaggingWithContext(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(client.cfg.Bucket),
+		Key:    aws.String(client.bucketKey(name)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata of file %q: %v", name, err)
+	}
+
+	metadata = make(map[string]string, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		if key := aws.StringValue(tag.Key); key != ttlTagKey {
+			metadata[key] = aws.StringValue(tag.Value)
+		}
+	}
+	return metadata, nil
+}
+
+// SetMetadata replaces the tags of the object with metadata. The tag
+// expiring objects uploaded with a TTL is kept.
+func (client *Client) SetMetadata(ctx context.Context, name string, metadata map[string]string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	values := make(url.Values)
+	if tagging := client.tagging(ctx, metadata); tagging != nil {
+		values, _ = url.ParseQuery(*tagging)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tags := make([]*s3.Tag, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, &s3.Tag{Key: aws.String(key), Value: aws.String(values.Get(key))})
+	}
+
+	svc := s3.New(client.session)
+
+	_, err = svc.PutObjectTaggingWithContext(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(client.cfg.Bucket),
+		Key:     aws.String(client.bucketKey(name)),
+		Tagging: &s3.Tagging{TagSet: tags},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tag file %q: %v", name, err)
+	}
+	return nil
+}
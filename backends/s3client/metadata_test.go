@@ -0,0 +1,100 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// taggingServer is a fake S3 server which keeps the tags of the objects
+// uploaded to it.
+type taggingServer struct {
+	mu   sync.Mutex
+	tags map[string]url.Values
+}
+
+type tagSet struct {
+	Tags []struct {
+		Key   string
+		Value string
+	} `xml:"TagSet>Tag"`
+}
+
+func (server *taggingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	_, tagging := r.URL.Query()["tagging"]
+	switch {
+	case r.Method == http.MethodPut && !tagging:
+		values, _ := url.ParseQuery(r.Header.Get("X-Amz-Tagging"))
+		server.tags[r.URL.Path] = values
+	case r.Method == http.MethodPut:
+		var set tagSet
+		if err := xml.NewDecoder(r.Body).Decode(&set); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		values := make(url.Values)
+		for _, tag := range set.Tags {
+			values.Set(tag.Key, tag.Value)
+		}
+		server.tags[r.URL.Path] = values
+	case r.Method == http.MethodGet && tagging:
+		var body strings.Builder
+		body.WriteString(`<Tagging><TagSet>`)
+		for key := range server.tags[r.URL.Path] {
+			body.WriteString(`<Tag><Key>` + key + `</Key><Value>` + server.tags[r.URL.Path].Get(key) + `</Value></Tag>`)
+		}
+		body.WriteString(`</TagSet></Tagging>`)
+		_, _ = w.Write([]byte(body.String()))
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func TestMetadataTags(t *testing.T) {
+	ctx := context.Background()
+	tags := &taggingServer{tags: make(map[string]url.Values)}
+	server := httptest.NewServer(tags)
+	defer server.Close()
+
+	client, err := New(config.S3Endpoint{
+		Region: "us-east-1", AccessKey: "access", SecretKey: "secret",
+		Bucket: "bucket", Address: server.URL, PathStyle: true, Presign: true,
+	})
+	require.NoError(t, err)
+	// Pretend the bucket has the rule expiring objects after a day.
+	client.expirationRules = map[int64]bool{1: true}
+	ctx = cli.WithTTL(ctx, 24*time.Hour)
+
+	// Uploads tag the objects with their metadata, which reads back.
+	uploadCtx := cli.WithSize(cli.WithMetadata(ctx, map[string]string{"team": "storage"}), 5)
+	require.NoError(t, client.Upload(uploadCtx, "obj", strings.NewReader("hello")))
+	metadata, err := client.GetMetadata(ctx, "obj")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"team": "storage"}, metadata)
+
+	// Set metadata replaces it, and reads back too.
+	require.NoError(t, client.SetMetadata(ctx, "obj", map[string]string{"owner": "perf"}))
+	metadata, err = client.GetMetadata(ctx, "obj")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"owner": "perf"}, metadata)
+
+	// The tag expiring the object is kept without being metadata.
+	require.Equal(t, "1", tags.tags["/bucket/obj"].Get(ttlTagKey))
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/zeebo/errs"
+
+	cli "storj.io/perftester/backends"
+)
+
+// presignExpiry is how long presigned URLs are valid. They are used right
+// away, so it only needs to cover slow transfers.
+const presignExpiry = time.Hour
+
+// presignedUpload uploads an object through a presigned URL. S3 doesn't
+// accept chunked uploads to presigned URLs, so the object is streamed with
+// the length of its context, see backends.WithSize, and only read into
+// memory first if its length is unknown.
+func (client *Client) presignedUpload(ctx context.Context, name string, strm io.Reader, tagging *string) error {
+	length, ok := cli.Size(ctx)
+	if !ok {
+		data, err := ioutil.ReadAll(strm)
+		if err != nil {
+			return fmt.Errorf("failed to upload file %q: %v", name, err)
+		}
+		strm, length = bytes.NewReader(data), int64(len(data))
+	}
+
+	req, _ := s3.New(client.session).PutObjectRequest(&s3.PutObjectInput{
+		Bucket:  aws.String(client.cfg.Bucket),
+		Key:     aws.String(client.bucketKey(name)),
+		Tagging: tagging,
+	})
+	// The tagging header is signed, so it has to be sent too.
+	header := make(http.Header)
+	if tagging != nil {
+		header.Set("X-Amz-Tagging", *tagging)
+	}
+	resp, err := client.presignedDo(ctx, req, http.MethodPut, strm, length, header)
+	if err != nil {
+		return fmt.Errorf("failed to upload file %q: %v", name, err)
+	}
+	return discardResponse(resp)
+}
+
+// presignedUploadPart uploads a part of a multipart upload through a
+// presigned URL, returning its ETag.
+func (client *Client) presignedUploadPart(ctx context.Context, svc *s3.S3, input *s3.UploadPartInput, body []byte) (etag *string, err error) {
+	req, _ := svc.UploadPartRequest(input)
+	resp, err := client.presignedDo(ctx, req, http.MethodPut, bytes.NewReader(body), int64(len(body)), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := discardResponse(resp); err != nil {
+		return nil, err
+	}
+	return aws.String(resp.Header.Get("ETag")), nil
+}
+
+// presignedDownload downloads an object, or its byteRange if set, through
+// a presigned URL.
+func (client *Client) presignedDownload(ctx context.Context, name, byteRange string) (io.ReadCloser, error) {
+	req, _ := s3.New(client.session).GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(client.cfg.Bucket),
+		Key:    aws.String(client.bucketKey(name)),
+	})
+
+	header := make(http.Header)
+	if byteRange != "" {
+		header.Set("Range", byteRange)
+	}
+	resp, err := client.presignedDo(ctx, req, http.MethodGet, nil, 0, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file %q: %v", name, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("failed to download file %q: %v", name, discardResponse(resp))
+	}
+	return resp.Body, nil
+}
+
+// presignedDo presigns req and sends it with a plain HTTP request, whose
+// body has length bytes.
+func (client *Client) presignedDo(ctx context.Context, req *request.Request, method string, body io.Reader, length int64, header http.Header) (*http.Response, error) {
+	u, err := req.Presign(presignExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		// Without the length, unknown readers would be sent chunked.
+		httpReq.Body = ioutil.NopCloser(body)
+		httpReq.ContentLength = length
+		if length == 0 {
+			httpReq.Body = http.NoBody
+		}
+	}
+	for key, values := range header {
+		httpReq.Header[key] = values
+	}
+	return client.http.Do(httpReq)
+}
+
+// discardResponse reads and closes the body of a response, returning an
+// error with the status of unsuccessful ones.
+func discardResponse(resp *http.Response) error {
+	_, err := io.Copy(ioutil.Discard, resp.Body)
+	err = errs.Combine(err, resp.Body.Close())
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errs.New("%s", resp.Status)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// onlyReader hides the type of a reader, so that its length can't be
+// guessed from it.
+type onlyReader struct{ io.Reader }
+
+func TestPresignedUploadStreams(t *testing.T) {
+	ctx := context.Background()
+
+	var lengths []int64
+	var encodings [][]string
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lengths = append(lengths, r.ContentLength)
+		encodings = append(encodings, r.TransferEncoding)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	client, err := New(config.S3Endpoint{
+		Region: "us-east-1", AccessKey: "access", SecretKey: "secret",
+		Bucket: "bucket", Address: server.URL, PathStyle: true, Presign: true,
+	})
+	require.NoError(t, err)
+
+	// Streamed with the length of the context.
+	err = client.Upload(cli.WithSize(ctx, 5), "streamed", onlyReader{strings.NewReader("hello")})
+	require.NoError(t, err)
+	// Read into memory without it.
+	err = client.Upload(ctx, "buffered", onlyReader{strings.NewReader("world!")})
+	require.NoError(t, err)
+
+	require.Equal(t, []int64{5, 6}, lengths)
+	require.Equal(t, [][]string{nil, nil}, encodings)
+	require.Equal(t, []string{"hello", "world!"}, bodies)
+
+	// Streams shorter than their length fail instead of sending less.
+	err = client.Upload(cli.WithSize(ctx, 10), "short", onlyReader{strings.NewReader("hello")})
+	require.Error(t, err)
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"storj.io/perftester/config"
+)
+
+// newHTTPClient returns an HTTP client with the transport settings of the
+// endpoint.
+func newHTTPClient(cfg config.S3Endpoint) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(cfg.ConnectTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+	readTimeout := time.Duration(cfg.ReadTimeout)
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, address)
+			if err != nil || readTimeout <= 0 {
+				return conn, err
+			}
+			return &readTimeoutConn{Conn: conn, timeout: readTimeout}, nil
+		},
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // opt-in for test deployments
+		},
+	}
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConns
+	}
+	if cfg.DisableHTTP2 {
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return &http.Client{Transport: transport}
+}
+
+// readTimeoutConn fails reads which wait longer than timeout for data.
+type readTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (conn *readTimeoutConn) Read(p []byte) (int, error) {
+	if err := conn.Conn.SetReadDeadline(time.Now().Add(conn.timeout)); err != nil {
+		return 0, err
+	}
+	return conn.Conn.Read(p)
+}
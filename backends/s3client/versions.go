@@ -0,0 +1,77 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// UploadVersion uploads a new version of the object to a versioned bucket.
+func (client *Client) UploadVersion(ctx context.Context, name string, strm io.Reader) (versionID string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	tagging := client.uploadTagging(ctx)
+
+	out, err := client.newUploader().UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket:  aws.String(client.cfg.Bucket),
+		Key:     aws.String(client.bucketKey(name)),
+		Body:    strm,
+		Tagging: tagging,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload version of file %q: %v", name, err)
+	}
+	if out.VersionID == nil {
+		return "", fmt.Errorf("no version of file %q: bucket %q isn't versioned", name, client.cfg.Bucket)
+	}
+	return *out.VersionID, nil
+}
+
+// ListVersions returns the IDs of the versions of the object, leaving out
+// delete markers.
+func (client *Client) ListVersions(ctx context.Context, name string) (versionIDs []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	key := client.bucketKey(name)
+	err = svc.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(client.cfg.Bucket),
+		Prefix: aws.String(key),
+	}, func(out *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		for _, version := range out.Versions {
+			if aws.StringValue(version.Key) == key {
+				versionIDs = append(versionIDs, aws.StringValue(version.VersionId))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions of file %q: %v", name, err)
+	}
+	return versionIDs, nil
+}
+
+// DeleteVersion permanently deletes a single version of the object.
+func (client *Client) DeleteVersion(ctx context.Context, name, versionID string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	svc := s3.New(client.session)
+
+	_, err = svc.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket:    aws.String(client.cfg.Bucket),
+		Key:       aws.String(client.bucketKey(name)),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete version %q of file %q: %v", versionID, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package backends
+
+import (
+	"context"
+)
+
+type sizeKey struct{}
+
+// WithSize returns a context whose uploads stream objects of exactly size
+// bytes, so that clients which have to send the length up front don't need
+// to read the objects into memory first.
+func WithSize(ctx context.Context, size int64) context.Context {
+	return context.WithValue(ctx, sizeKey{}, size)
+}
+
+// Size returns the size of the objects uploaded with ctx, and whether it is
+// known.
+func Size(ctx context.Context) (int64, bool) {
+	size, ok := ctx.Value(sizeKey{}).(int64)
+	return size, ok
+}
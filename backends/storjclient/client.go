@@ -0,0 +1,359 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/memory"
+	"storj.io/common/storj"
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+	"storj.io/uplink"
+	"storj.io/uplink/private/testuplink"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("storj-client")
+)
+
+// Client is a storj client.
+type Client struct {
+	cfg     config.StorjEndpoint
+	address string
+
+	access  *uplink.Access
+	project *uplink.Project
+	config  uplink.Config
+}
+
+// New creates a new storj client.
+func New(ctx context.Context, log *zap.Logger, cfg config.StorjEndpoint) (*Client, error) {
+	access, err := uplink.ParseAccess(cfg.Access)
+	if err != nil {
+		return nil, err
+	}
+
+	satelliteAddress, err := parseSatelliteAddressFromScope(cfg.Access)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	nodeURL, err := storj.ParseNodeURL(satelliteAddress)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	uplinkConfig := uplink.Config{
+		UserAgent:   cfg.UserAgent,
+		DialTimeout: time.Duration(cfg.DialTimeout),
+		DialContext: nodeDialer{satellite: nodeURL.Address}.DialContext,
+	}
+
+	projectCtx := ctx
+	if cfg.SegmentSize > 0 {
+		projectCtx = testuplink.WithMaxSegmentSize(ctx, memory.Size(cfg.SegmentSize))
+	}
+	project, err := uplinkConfig.OpenProject(projectCtx, access)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	_, err = project.EnsureBucket(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return &Client{
+		cfg:     cfg,
+		address: satelliteAddress,
+		access:  access,
+		project: project,
+		config:  uplinkConfig,
+	}, nil
+}
+
+// Address returns the client address.
+func (client *Client) Address() string {
+	return client.address
+}
+
+// List returns the objects found at prefix.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if name != "" && !strings.HasSuffix(name, "/") {
+		name += "/"
+	}
+	path := client.joinWithClientPath(name)
+	objects := client.project.ListObjects(ctx, client.cfg.Bucket, &uplink.ListObjectsOptions{
+		Prefix:    path,
+		Recursive: recursive,
+		System:    true,
+	})
+
+	for objects.Next() {
+		item := objects.Item()
+		objs = append(objs, &cli.ListObject{
+			Key:          client.key(item.Key),
+			IsPre:        item.IsPrefix,
+			Size:         item.System.ContentLength,
+			LastModified: item.System.Created,
+		})
+	}
+	return objs, objects.Err()
+}
+
+// Stat returns the object at name, or nil if it doesn't exist.
+func (client *Client) Stat(ctx context.Context, name string) (obj *cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	object, err := client.project.StatObject(ctx, client.cfg.Bucket, client.joinWithClientPath(name))
+	if errors.Is(err, uplink.ErrObjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, Error.New("could not stat object at %q/%q: %v", client.cfg.Bucket, name, err)
+	}
+	return &cli.ListObject{
+		Key:          client.key(object.Key),
+		Size:         object.System.ContentLength,
+		LastModified: object.System.Created,
+	}, nil
+}
+
+// Upload uploads to storj.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	upload, err := client.project.UploadObject(ctx, client.cfg.Bucket, client.joinWithClientPath(name), uploadOptions(ctx))
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	err = setUploadMetadata(ctx, upload)
+	if err == nil {
+		_, err = cli.Copy(upload, strm)
+	}
+	if err != nil {
+		aborterr := upload.Abort()
+		return Error.Wrap(errs.Combine(err, aborterr))
+	}
+
+	err = upload.Commit()
+	return Error.Wrap(err)
+}
+
+// PrepareExpiration returns true, since objects uploaded with a TTL are
+// uploaded with their expiration time.
+func (client *Client) PrepareExpiration(ctx context.Context, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+// uploadOptions returns the options of uploads with ctx, which set the
+// expiration of objects uploaded with a TTL.
+func uploadOptions(ctx context.Context) *uplink.UploadOptions {
+	ttl := cli.TTL(ctx)
+	if ttl <= 0 {
+		return nil
+	}
+	return &uplink.UploadOptions{Expires: time.Now().Add(ttl)}
+}
+
+// UploadMultipart uploads to storj using segments of partSize bytes. The
+// segment size is fixed when a project is opened, so a dedicated project is
+// opened for the upload. Segments are always uploaded sequentially, so
+// concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	project, err := client.config.OpenProject(testuplink.WithMaxSegmentSize(ctx, memory.Size(partSize)), client.access)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(project.Close())) }()
+
+	upload, err := project.UploadObject(ctx, client.cfg.Bucket, client.joinWithClientPath(name), uploadOptions(ctx))
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	err = setUploadMetadata(ctx, upload)
+	if err == nil {
+		parts, err = cli.CopyParts(upload, strm, partSize)
+	}
+	if err != nil {
+		aborterr := upload.Abort()
+		return nil, Error.Wrap(errs.Combine(err, aborterr))
+	}
+
+	return parts, Error.Wrap(upload.Commit())
+}
+
+// Download downloads from storj, in parallel chunks if the endpoint has a
+// parallelism. The stream knows the storage node level stats of the
+// download once closed.
+func (client *Client) Download(ctx context.Context, name string) (stream io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if client.cfg.Parallelism > 1 {
+		return client.parallelDownload(ctx, name)
+	}
+	return client.download(ctx, name, nil)
+}
+
+// DownloadRange downloads a byte range from storj.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (stream io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	return client.download(ctx, name, &uplink.DownloadOptions{
+		Offset: offset,
+		Length: length,
+	})
+}
+
+// download opens a download of the object, collecting its storage node
+// level stats.
+func (client *Client) download(ctx context.Context, name string, options *uplink.DownloadOptions) (io.ReadCloser, error) {
+	stats := newNodeStats()
+	release := observeTraces()
+	download, err := client.project.DownloadObject(withNodeStats(ctx, stats), client.cfg.Bucket, client.joinWithClientPath(name), options)
+	if err != nil {
+		release()
+		return nil, Error.New("could not open object at %q/%q: %v", client.cfg.Bucket, name, err)
+	}
+
+	return &nodeStatsDownload{Download: download, stats: stats, release: release}, nil
+}
+
+// Delete deletes from storj.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	// Initiate a download of the same object again
+	if _, err := client.project.DeleteObject(ctx, client.cfg.Bucket, client.joinWithClientPath(name)); err != nil {
+		return Error.New("could not delete object at %q/%q: %v", client.cfg.Bucket, name, err)
+	}
+	return nil
+}
+
+// Copy is not supported, since uplink has no server-side copy yet.
+func (client *Client) Copy(ctx context.Context, src, dst string) error {
+	return cli.ErrUnsupported.New("copy")
+}
+
+// CreateBucket creates a bucket in the project.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if _, err := client.project.CreateBucket(ctx, bucket); err != nil {
+		return Error.New("could not create bucket %q: %v", bucket, err)
+	}
+	return nil
+}
+
+// ListBuckets returns the names of the buckets of the project.
+func (client *Client) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	iterator := client.project.ListBuckets(ctx, nil)
+	for iterator.Next() {
+		buckets = append(buckets, iterator.Item().Name)
+	}
+	if err := iterator.Err(); err != nil {
+		return nil, Error.New("could not list buckets: %v", err)
+	}
+	return buckets, nil
+}
+
+// DeleteBucket deletes an empty bucket of the project.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	if _, err := client.project.DeleteBucket(ctx, bucket); err != nil {
+		return Error.New("could not delete bucket %q: %v", bucket, err)
+	}
+	return nil
+}
+
+// Settings describes the transfer settings of the client.
+func (client *Client) Settings() string {
+	download := "sequential"
+	if client.cfg.Parallelism > 1 {
+		chunkSize := int64(client.cfg.ChunkSize)
+		if chunkSize <= 0 {
+			chunkSize = defaultChunkSize
+		}
+		download = fmt.Sprintf("%d x %v chunks", client.cfg.Parallelism, memory.Size(chunkSize))
+	}
+
+	return strings.Join(append([]string{"download " + download}, client.uplinkSettings()...), ", ")
+}
+
+// uplinkSettings describes the uplink settings which aren't the defaults.
+func (client *Client) uplinkSettings() (settings []string) {
+	if client.cfg.SegmentSize > 0 {
+		settings = append(settings, fmt.Sprintf("segment size %v", memory.Size(client.cfg.SegmentSize)))
+	}
+	if client.cfg.DialTimeout > 0 {
+		settings = append(settings, fmt.Sprintf("dial timeout %v", time.Duration(client.cfg.DialTimeout)))
+	}
+	return settings
+}
+
+// IP returns the IP address of the endpoint.
+func (client *Client) IP(ctx context.Context) (string, error) {
+	nodeURL, err := storj.ParseNodeURL(client.address)
+	if err != nil {
+		return "", err
+	}
+
+	addr, _, err := net.SplitHostPort(nodeURL.Address)
+	if err != nil {
+		return "", err
+	}
+
+	return addr, nil
+}
+
+// NetworkAddress returns the address of the satellite. Storage nodes are
+// only known once objects are transferred.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	nodeURL, err := storj.ParseNodeURL(client.address)
+	if err != nil {
+		return "", false, Error.Wrap(err)
+	}
+	return nodeURL.Address, true, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() (err error) {
+	return client.project.Close()
+}
+
+func (client *Client) joinWithClientPath(path string) string {
+	if client.cfg.Path == "" {
+		return path
+	}
+	return storj.JoinPaths(client.cfg.Path, path)
+}
+
+// key returns the name of the object at the bucket key k.
+func (client *Client) key(k string) string {
+	if client.cfg.Path == "" {
+		return k
+	}
+	return strings.TrimPrefix(k, strings.Trim(client.cfg.Path, "/")+"/")
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"context"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/memory"
+	cli "storj.io/perftester/backends"
+	s3 "storj.io/perftester/backends/s3client"
+	"storj.io/perftester/config"
+)
+
+func init() {
+	cli.Register("storj", func() cli.EndpointConfig { return &endpointConfig{} })
+}
+
+// endpointConfig is the config of [endpoint.storj.<id>] tables.
+type endpointConfig struct {
+	config.StorjEndpoint
+}
+
+// Validate checks the settings of the endpoint, and that parallel
+// downloads don't buffer more than maxParallelBuffer bytes.
+func (cfg *endpointConfig) Validate() error {
+	var group errs.Group
+	group.Add(cfg.StorjEndpoint.Validate())
+
+	chunkSize := int64(cfg.ChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	if buffer := int64(cfg.Parallelism) * chunkSize; cfg.Parallelism > 1 && buffer > maxParallelBuffer {
+		group.Add(errs.New("parallel downloads would buffer %v, more than %v: lower the parallelism or the chunk size", memory.Size(buffer), memory.Size(maxParallelBuffer)))
+	}
+	return group.Err()
+}
+
+// NewClient creates a client for the endpoint in its mode.
+func (cfg *endpointConfig) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	endpoint := cfg.StorjEndpoint
+	switch endpoint.Mode {
+	case "", config.StorjNative:
+		return New(ctx, log, endpoint)
+	case config.StorjGateway:
+		return s3.New(config.S3Endpoint{
+			// The gateway ignores the region, but the S3 client requires one.
+			Region:    "us-east-1",
+			AccessKey: endpoint.GatewayAccessKey,
+			SecretKey: endpoint.GatewaySecretKey,
+			Bucket:    endpoint.Bucket,
+			Path:      endpoint.Path,
+			Address:   endpoint.GatewayAddress,
+			Presign:   endpoint.Presign,
+		})
+	case config.StorjLinkshare:
+		return NewLinkshare(ctx, log, endpoint)
+	default:
+		return nil, errs.New("unknown storj mode %q", endpoint.Mode)
+	}
+}
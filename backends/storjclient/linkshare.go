@@ -0,0 +1,121 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// LinkshareClient uploads, lists and deletes with uplink like Client, but
+// downloads through linksharing.
+type LinkshareClient struct {
+	*Client
+
+	url    *url.URL
+	client *http.Client
+}
+
+// NewLinkshare creates a new storj client which downloads through the
+// linkshare URL of the endpoint.
+func NewLinkshare(ctx context.Context, log *zap.Logger, cfg config.StorjEndpoint) (*LinkshareClient, error) {
+	if cfg.LinkshareURL == "" {
+		return nil, errs.New("linkshare url is required")
+	}
+
+	u, err := url.Parse(cfg.LinkshareURL)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	client, err := New(ctx, log, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LinkshareClient{
+		Client: client,
+		url:    u,
+		client: &http.Client{},
+	}, nil
+}
+
+// Download downloads through linksharing.
+func (client *LinkshareClient) Download(ctx context.Context, name string) (stream io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	resp, err := client.get(ctx, name, nil)
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, Error.New("failed to download file %q: %s", name, errs.Combine(errs.New("%s", resp.Status), resp.Body.Close()))
+	}
+	return resp.Body, nil
+}
+
+// DownloadRange downloads a byte range through linksharing.
+func (client *LinkshareClient) DownloadRange(ctx context.Context, name string, offset, length int64) (stream io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange += fmt.Sprint(offset + length - 1)
+	}
+
+	resp, err := client.get(ctx, name, http.Header{"Range": {byteRange}})
+	if err != nil {
+		return nil, Error.New("failed to download range of file %q: %v", name, err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, Error.New("failed to download range of file %q: %s", name, errs.Combine(errs.New("%s", resp.Status), resp.Body.Close()))
+	}
+	return resp.Body, nil
+}
+
+// Settings describes the transfer settings of the client.
+func (client *LinkshareClient) Settings() string {
+	return strings.Join(append([]string{"download through linksharing"}, client.uplinkSettings()...), ", ")
+}
+
+// IP returns the host of the linksharing service.
+func (client *LinkshareClient) IP(ctx context.Context) (string, error) {
+	return client.url.Hostname(), nil
+}
+
+// NetworkAddress returns the address of the server.
+func (client *LinkshareClient) NetworkAddress() (string, bool, error) {
+	address, useTLS := cli.URLAddress(client.url)
+	return address, useTLS, nil
+}
+
+// Close closes the client.
+func (client *LinkshareClient) Close() error {
+	client.client.CloseIdleConnections()
+	return client.Client.Close()
+}
+
+func (client *LinkshareClient) get(ctx context.Context, name string, header http.Header) (*http.Response, error) {
+	u := *client.url
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + client.joinWithClientPath(name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	return client.client.Do(req)
+}
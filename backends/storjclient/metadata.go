@@ -0,0 +1,38 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"context"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/uplink"
+)
+
+// setUploadMetadata sets the custom metadata of the objects uploaded with ctx
+// on upload, if they have any.
+func setUploadMetadata(ctx context.Context, upload *uplink.Upload) error {
+	metadata := cli.Metadata(ctx)
+	if len(metadata) == 0 {
+		return nil
+	}
+	return upload.SetCustomMetadata(ctx, uplink.CustomMetadata(metadata))
+}
+
+// GetMetadata returns the custom metadata of the object.
+func (client *Client) GetMetadata(ctx context.Context, name string) (metadata map[string]string, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	object, err := client.project.StatObject(ctx, client.cfg.Bucket, client.joinWithClientPath(name))
+	if err != nil {
+		return nil, Error.New("could not stat object at %q/%q: %v", client.cfg.Bucket, name, err)
+	}
+	return object.Custom, nil
+}
+
+// SetMetadata is not supported, since uplink can only set the custom
+// metadata of objects while uploading them.
+func (client *Client) SetMetadata(ctx context.Context, name string, metadata map[string]string) error {
+	return cli.ErrUnsupported.New("set metadata")
+}
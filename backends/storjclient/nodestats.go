@@ -0,0 +1,218 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+
+	"storj.io/common/errs2"
+	"storj.io/common/socket"
+	cli "storj.io/perftester/backends"
+	"storj.io/uplink"
+)
+
+// uplink doesn't expose the piece downloads of an object, so they are
+// followed through the spans of its ecclient and piecestore packages.
+const (
+	ecclientScope   = "storj.io/uplink/private/ecclient"
+	piecestoreScope = "storj.io/uplink/private/piecestore"
+)
+
+// traceObserver makes nodeObserver observe the spans of all traces of
+// monkit's default registry while downloads collect their node stats, since
+// uplink starts a new trace for every download. It is unregistered when the
+// last download is closed, so that it doesn't outlive the check.
+var traceObserver struct {
+	mu        sync.Mutex
+	downloads int
+	cancel    func()
+}
+
+// observeTraces registers nodeObserver for a download, if it isn't yet,
+// until release is called.
+func observeTraces() (release func()) {
+	traceObserver.mu.Lock()
+	defer traceObserver.mu.Unlock()
+
+	if traceObserver.downloads == 0 {
+		traceObserver.cancel = monkit.Default.ObserveTraces(func(trace *monkit.Trace) {
+			trace.ObserveSpans(nodeObserver{})
+		})
+	}
+	traceObserver.downloads++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			traceObserver.mu.Lock()
+			defer traceObserver.mu.Unlock()
+
+			traceObserver.downloads--
+			if traceObserver.downloads == 0 {
+				traceObserver.cancel()
+				traceObserver.cancel = nil
+			}
+		})
+	}
+}
+
+// nodeStatsKey is the context key of the nodeStats of a download.
+type nodeStatsKey struct{}
+
+// nodeStats collects the NodeStats of a single download.
+type nodeStats struct {
+	bytes int64 // accessed atomically
+
+	mu            sync.Mutex
+	nodes         int64
+	dialFailed    int64
+	dialCancelled int64
+	failed        map[string]bool // by node of failed piece reads
+	cancelled     map[string]bool // by node of cancelled piece reads
+}
+
+func newNodeStats() *nodeStats {
+	return &nodeStats{
+		failed:    make(map[string]bool),
+		cancelled: make(map[string]bool),
+	}
+}
+
+// withNodeStats returns ctx with stats collecting the stats of the
+// download it is used for, while its traces are observed.
+func withNodeStats(ctx context.Context, stats *nodeStats) context.Context {
+	return context.WithValue(ctx, nodeStatsKey{}, stats)
+}
+
+// stats returns the collected stats. A node whose reads were both
+// cancelled and failed counts as failed.
+func (stats *nodeStats) stats() cli.NodeStats {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	cancelled := stats.dialCancelled
+	for node := range stats.cancelled {
+		if !stats.failed[node] {
+			cancelled++
+		}
+	}
+	return cli.NodeStats{
+		Nodes:     stats.nodes,
+		Failed:    stats.dialFailed + int64(len(stats.failed)),
+		Cancelled: cancelled,
+		Bytes:     atomic.LoadInt64(&stats.bytes),
+	}
+}
+
+// nodeObserver counts the piece downloads of the spans of downloads
+// carrying nodeStats.
+type nodeObserver struct{}
+
+func (nodeObserver) Start(s *monkit.Span) {}
+
+func (nodeObserver) Finish(s *monkit.Span, err error, panicked bool, finish time.Time) {
+	f := s.Func()
+	scope := f.Scope().Name()
+	if scope != ecclientScope && scope != piecestoreScope {
+		return
+	}
+	stats, ok := s.Value(nodeStatsKey{}).(*nodeStats)
+	if !ok {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	switch {
+	case scope == ecclientScope && f.ShortName() == "(*lazyPieceRanger).dial":
+		switch {
+		case err == nil:
+			stats.nodes++
+		case errs2.IsCanceled(err):
+			stats.dialCancelled++
+		default:
+			stats.dialFailed++
+		}
+	case scope == piecestoreScope && f.ShortName() == "(*Download).Read":
+		if err == nil || err == io.EOF {
+			return
+		}
+		node := spanNode(s)
+		if errs2.IsCanceled(err) {
+			stats.cancelled[node] = true
+		} else {
+			stats.failed[node] = true
+		}
+	}
+}
+
+// spanNode returns the storage node a piecestore span is annotated with.
+func spanNode(s *monkit.Span) string {
+	for _, arg := range s.Args() {
+		if node := strings.TrimPrefix(arg, `"node: `); node != arg {
+			return strings.TrimSuffix(node, `"`)
+		}
+	}
+	return ""
+}
+
+// nodeDialer dials the connections of a project, counting the bytes
+// received from storage nodes for downloads carrying nodeStats.
+type nodeDialer struct {
+	satellite string
+}
+
+// DialContext dials address, counting the bytes received if it is a storage
+// node of a download.
+func (dialer nodeDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := socket.BackgroundDialer().DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, ok := ctx.Value(nodeStatsKey{}).(*nodeStats)
+	if !ok || address == dialer.satellite {
+		return conn, nil
+	}
+	return &countingConn{Conn: conn, bytes: &stats.bytes}, nil
+}
+
+// countingConn counts the bytes read from a connection.
+type countingConn struct {
+	net.Conn
+	bytes *int64
+}
+
+func (conn *countingConn) Read(p []byte) (int, error) {
+	n, err := conn.Conn.Read(p)
+	atomic.AddInt64(conn.bytes, int64(n))
+	return n, err
+}
+
+// nodeStatsDownload is a download which knows its NodeStats once closed.
+type nodeStatsDownload struct {
+	*uplink.Download
+	stats   *nodeStats
+	release func()
+}
+
+// Close closes the download and stops observing its traces.
+func (download *nodeStatsDownload) Close() error {
+	defer download.release()
+	return download.Download.Close()
+}
+
+// NodeStats returns the storage node level stats of the download.
+func (download *nodeStatsDownload) NodeStats() cli.NodeStats {
+	return download.stats.stats()
+}
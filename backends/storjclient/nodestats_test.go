@@ -0,0 +1,104 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveTraces(t *testing.T) {
+	first := observeTraces()
+	second := observeTraces()
+	require.Equal(t, 2, traceObserver.downloads)
+
+	// Releasing twice doesn't release another download.
+	first()
+	first()
+	require.Equal(t, 1, traceObserver.downloads)
+	require.NotNil(t, traceObserver.cancel)
+
+	// The last release unregisters the observer.
+	second()
+	require.Equal(t, 0, traceObserver.downloads)
+	require.Nil(t, traceObserver.cancel)
+}
+
+// TestUplinkSpans checks that the uplink version perftester builds with
+// still has the spans nodeObserver follows, since renaming them would
+// silently stop collecting node stats.
+func TestUplinkSpans(t *testing.T) {
+	// The dial of a piece download is a span of its own.
+	dial := monitoredMethod(t, ecclientScope, "lazyPieceRanger", "dial")
+	require.Len(t, dial.Args, 1)
+
+	// Piece reads are spans annotated with their node.
+	read := monitoredMethod(t, piecestoreScope, "Download", "Read")
+	require.Len(t, read.Args, 2)
+	node, ok := read.Args[1].(*ast.BinaryExpr)
+	require.True(t, ok, "piece reads aren't annotated with their node")
+	prefix, ok := node.X.(*ast.BasicLit)
+	require.True(t, ok, "piece reads aren't annotated with their node")
+	value, err := strconv.Unquote(prefix.Value)
+	require.NoError(t, err)
+	require.Equal(t, "node: ", value)
+}
+
+// monitoredMethod returns the mon.Task() call of the method name of the
+// pointer receiver recv in the package pkgPath, failing the test if it
+// isn't a monitored method of the package.
+func monitoredMethod(t *testing.T, pkgPath, recv, name string) *ast.CallExpr {
+	pkg, err := build.Import(pkgPath, ".", 0)
+	require.NoError(t, err)
+
+	fset := token.NewFileSet()
+	for _, file := range pkg.GoFiles {
+		parsed, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, file), nil, 0)
+		require.NoError(t, err)
+		for _, decl := range parsed.Decls {
+			f, ok := decl.(*ast.FuncDecl)
+			if !ok || f.Recv == nil || f.Name.Name != name {
+				continue
+			}
+			star, ok := f.Recv.List[0].Type.(*ast.StarExpr)
+			if !ok || star.X.(*ast.Ident).Name != recv {
+				continue
+			}
+			for _, stmt := range f.Body.List {
+				// defer mon.Task()(&ctx, args...)(&err)
+				deferred, ok := stmt.(*ast.DeferStmt)
+				if !ok {
+					continue
+				}
+				if task, ok := deferred.Call.Fun.(*ast.CallExpr); ok && isMonTask(task.Fun) {
+					return task
+				}
+			}
+			require.FailNow(t, "method not monitored", "(*%s).%s in %s", recv, name, pkgPath)
+		}
+	}
+	require.FailNow(t, "method not found", "(*%s).%s in %s", recv, name, pkgPath)
+	return nil
+}
+
+// isMonTask returns whether expr is mon.Task().
+func isMonTask(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	mon, ok := selector.X.(*ast.Ident)
+	return ok && mon.Name == "mon" && selector.Sel.Name == "Task"
+}
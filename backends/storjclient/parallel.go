@@ -0,0 +1,176 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/zeebo/errs"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/uplink"
+)
+
+// defaultChunkSize is the size of the chunks of parallel downloads, which
+// matches uplink's default segment size.
+const defaultChunkSize = 64 << 20
+
+// maxParallelBuffer limits the memory a parallel download buffers, which is
+// up to its parallelism times its chunk size.
+const maxParallelBuffer = 1 << 30
+
+// parallelDownload downloads an object in chunks of consecutive ranges,
+// parallelism of them at a time, and reads them in order. Chunks are read
+// into memory, since later chunks arrive before earlier ones are read, but
+// only those being downloaded or waiting to be read are: a download buffers
+// at most parallelism times the chunk size.
+type parallelDownload struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// slots limits the chunks downloaded or buffered at once.
+	slots  chan struct{}
+	chunks []chan chunk
+
+	next int
+	buf  []byte
+	err  error
+
+	mu    sync.Mutex
+	stats cli.NodeStats
+}
+
+// chunk is a downloaded chunk, or the error downloading it.
+type chunk struct {
+	data []byte
+	err  error
+}
+
+// openRange opens a download of length bytes of an object from offset.
+type openRange func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+
+// parallelDownload opens a parallel download of the object.
+func (client *Client) parallelDownload(ctx context.Context, name string) (io.ReadCloser, error) {
+	object, err := client.project.StatObject(ctx, client.cfg.Bucket, client.joinWithClientPath(name))
+	if err != nil {
+		return nil, Error.New("could not open object at %q/%q: %v", client.cfg.Bucket, name, err)
+	}
+
+	chunkSize := int64(client.cfg.ChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	return newParallelDownload(ctx, object.System.ContentLength, chunkSize, client.cfg.Parallelism, func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		return client.download(ctx, name, &uplink.DownloadOptions{
+			Offset: offset,
+			Length: length,
+		})
+	}), nil
+}
+
+// newParallelDownload starts downloading the size bytes of an object in
+// chunks of chunkSize bytes opened with open, parallelism of them at a time.
+func newParallelDownload(ctx context.Context, size, chunkSize int64, parallelism int, open openRange) *parallelDownload {
+	numChunks := int((size + chunkSize - 1) / chunkSize)
+
+	ctx, cancel := context.WithCancel(ctx)
+	download := &parallelDownload{
+		cancel: cancel,
+		slots:  make(chan struct{}, parallelism),
+		chunks: make([]chan chunk, numChunks),
+	}
+	for i := range download.chunks {
+		download.chunks[i] = make(chan chunk, 1)
+	}
+
+	download.wg.Add(1)
+	go func() {
+		defer download.wg.Done()
+		for i := range download.chunks {
+			select {
+			case download.slots <- struct{}{}:
+			case <-ctx.Done():
+				download.chunks[i] <- chunk{err: ctx.Err()}
+				continue
+			}
+
+			offset := int64(i) * chunkSize
+			length := chunkSize
+			if offset+length > size {
+				length = size - offset
+			}
+
+			download.wg.Add(1)
+			go func(i int) {
+				defer download.wg.Done()
+				data, err := download.fetch(ctx, open, offset, length)
+				download.chunks[i] <- chunk{data: data, err: err}
+			}(i)
+		}
+	}()
+
+	return download
+}
+
+// fetch downloads a single chunk, adding its node stats to the download.
+func (download *parallelDownload) fetch(ctx context.Context, open openRange, offset, length int64) (_ []byte, err error) {
+	strm, err := open(ctx, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, strm.Close())
+		if reader, ok := strm.(cli.NodeStatsReader); ok {
+			download.mu.Lock()
+			download.stats.Add(reader.NodeStats())
+			download.mu.Unlock()
+		}
+	}()
+
+	data := make([]byte, length)
+	_, err = io.ReadFull(strm, data)
+	return data, err
+}
+
+// Read reads the chunks in order, freeing the slot of each once it has been
+// read.
+func (download *parallelDownload) Read(p []byte) (int, error) {
+	for len(download.buf) == 0 {
+		if download.err != nil {
+			return 0, download.err
+		}
+		if download.next >= len(download.chunks) {
+			return 0, io.EOF
+		}
+
+		next := <-download.chunks[download.next]
+		download.next++
+		if next.err != nil {
+			download.err = Error.Wrap(next.err)
+			return 0, download.err
+		}
+		download.buf = next.data
+		<-download.slots
+	}
+
+	n := copy(p, download.buf)
+	download.buf = download.buf[n:]
+	return n, nil
+}
+
+// Close cancels the chunks still being downloaded.
+func (download *parallelDownload) Close() error {
+	download.cancel()
+	download.wg.Wait()
+	return nil
+}
+
+// NodeStats returns the storage node level stats of all chunks.
+func (download *parallelDownload) NodeStats() cli.NodeStats {
+	download.mu.Lock()
+	defer download.mu.Unlock()
+	return download.stats
+}
@@ -0,0 +1,138 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package storjclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// rangeServer opens ranges of data, finishing them in random order and
+// recording how many are open at once.
+type rangeServer struct {
+	data []byte
+
+	mu      sync.Mutex
+	open    int
+	maxOpen int
+	fail    int64 // offset of the range which fails, or -1
+}
+
+func (server *rangeServer) openRange(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	server.mu.Lock()
+	server.open++
+	if server.open > server.maxOpen {
+		server.maxOpen = server.open
+	}
+	server.mu.Unlock()
+
+	// Later chunks often finish before earlier ones.
+	select {
+	case <-time.After(time.Duration(rand.Intn(5)) * time.Millisecond):
+	case <-ctx.Done():
+	}
+	if offset == server.fail {
+		server.done()
+		return nil, errs.New("range %d failed", offset)
+	}
+	return &rangeStream{Reader: bytes.NewReader(server.data[offset : offset+length]), server: server}, nil
+}
+
+func (server *rangeServer) done() {
+	server.mu.Lock()
+	server.open--
+	server.mu.Unlock()
+}
+
+type rangeStream struct {
+	io.Reader
+	server *rangeServer
+}
+
+func (strm *rangeStream) Close() error {
+	strm.server.done()
+	return nil
+}
+
+func (strm *rangeStream) NodeStats() cli.NodeStats {
+	return cli.NodeStats{Nodes: 1}
+}
+
+func TestParallelDownload(t *testing.T) {
+	data := make([]byte, 1000)
+	_, _ = rand.Read(data)
+
+	for _, size := range []int64{0, 1, 99, 100, 101, 1000} {
+		server := &rangeServer{data: data[:size], fail: -1}
+		download := newParallelDownload(context.Background(), size, 100, 3, server.openRange)
+
+		// The chunks are read in order, whatever order they finish in.
+		downloaded, err := ioutil.ReadAll(download)
+		require.NoError(t, err, size)
+		require.NoError(t, download.Close())
+		require.Equal(t, data[:size], downloaded, size)
+
+		// At most parallelism chunks are downloaded or buffered at once.
+		require.LessOrEqual(t, server.maxOpen, 3, size)
+		require.Equal(t, int64((size+99)/100), download.NodeStats().Nodes, size)
+	}
+}
+
+func TestParallelDownloadError(t *testing.T) {
+	data := make([]byte, 1000)
+	server := &rangeServer{data: data, fail: 500}
+	download := newParallelDownload(context.Background(), 1000, 100, 3, server.openRange)
+
+	downloaded, err := ioutil.ReadAll(download)
+	require.Error(t, err)
+	require.Len(t, downloaded, 500)
+	require.NoError(t, download.Close())
+	require.Zero(t, server.open)
+}
+
+func TestParallelDownloadClose(t *testing.T) {
+	data := make([]byte, 1000)
+	server := &rangeServer{data: data, fail: -1}
+	download := newParallelDownload(context.Background(), 1000, 100, 3, server.openRange)
+
+	// Closing early cancels the chunks being downloaded.
+	_, err := io.ReadFull(download, make([]byte, 150))
+	require.NoError(t, err)
+	require.NoError(t, download.Close())
+	require.Zero(t, server.open)
+}
+
+func TestValidateParallelBuffer(t *testing.T) {
+	for _, test := range []struct {
+		parallelism int
+		chunkSize   config.ByteSize
+		err         bool
+	}{
+		{parallelism: 0},
+		{parallelism: 16},
+		{parallelism: 16, chunkSize: 64 << 20},
+		{parallelism: 17, err: true},
+		{parallelism: 64, chunkSize: 16 << 20},
+		{parallelism: 64, chunkSize: 32 << 20, err: true},
+	} {
+		cfg := &endpointConfig{StorjEndpoint: config.StorjEndpoint{Parallelism: test.parallelism, ChunkSize: test.chunkSize}}
+		if test.err {
+			require.Error(t, cfg.Validate(), test)
+		} else {
+			require.NoError(t, cfg.Validate(), test)
+		}
+	}
+}
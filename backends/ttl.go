@@ -0,0 +1,43 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package backends
+
+import (
+	"context"
+	"time"
+)
+
+type ttlKey struct{}
+
+// WithTTL returns a context whose uploads create objects which expire ttl
+// after their upload, on backends which support expiration.
+func WithTTL(ctx context.Context, ttl time.Duration) context.Context {
+	return context.WithValue(ctx, ttlKey{}, ttl)
+}
+
+// TTL returns the time objects uploaded with ctx expire after, or zero if
+// they don't expire.
+func TTL(ctx context.Context) time.Duration {
+	ttl, _ := ctx.Value(ttlKey{}).(time.Duration)
+	return ttl
+}
+
+// Expirer is implemented by clients which can expire the objects they
+// upload with a context from WithTTL.
+type Expirer interface {
+	// PrepareExpiration prepares expiring the objects uploaded with a TTL
+	// of ttl, before any of them is uploaded or timed, and returns whether
+	// they will expire.
+	PrepareExpiration(ctx context.Context, ttl time.Duration) (bool, error)
+}
+
+// PrepareExpiration prepares client to expire the objects it uploads with a
+// TTL of ttl and returns whether they will expire.
+func PrepareExpiration(ctx context.Context, client Client, ttl time.Duration) (bool, error) {
+	expirer, ok := client.(Expirer)
+	if !ok {
+		return false, nil
+	}
+	return expirer.PrepareExpiration(ctx, ttl)
+}
@@ -0,0 +1,330 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package webdavclient
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var (
+	mon = monkit.Package()
+
+	// Error is the error for this package.
+	Error = errs.Class("webdav-client")
+)
+
+// Client is a WebDAV client.
+type Client struct {
+	cfg    config.WebDAVEndpoint
+	url    *url.URL
+	client *http.Client
+}
+
+// New creates a new WebDAV client.
+func New(cfg config.WebDAVEndpoint) (*Client, error) {
+	if cfg.URL == "" {
+		return nil, errs.New("url is required")
+	}
+
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return &Client{
+		cfg:    cfg,
+		url:    u,
+		client: &http.Client{},
+	}, nil
+}
+
+// List returns the objects found at name.
+func (client *Client) List(ctx context.Context, name string, recursive bool) (objs []*cli.ListObject, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	entries, err := client.propfind(ctx, client.davPath(name))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.collection {
+			objs = append(objs, &cli.ListObject{Key: entry.key, Size: entry.size, LastModified: entry.modified})
+			continue
+		}
+		if !recursive {
+			objs = append(objs, &cli.ListObject{Key: entry.key + "/", IsPre: true})
+			continue
+		}
+
+		children, err := client.List(ctx, entry.key, true)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, children...)
+	}
+
+	return objs, nil
+}
+
+// Upload uploads to the WebDAV server.
+func (client *Client) Upload(ctx context.Context, name string, strm io.Reader) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	resp, err := client.do(ctx, http.MethodPut, name, ioutil.NopCloser(strm), nil)
+	if err != nil {
+		return Error.New("failed to upload file %q: %v", name, err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(resp.Body.Close())) }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return Error.New("failed to upload file %q: %s", name, resp.Status)
+	}
+	return nil
+}
+
+// UploadMultipart uploads to the WebDAV server. WebDAV has no multipart
+// uploads, so the file is sent in a single request while timing each
+// partSize bytes, and concurrency is ignored.
+func (client *Client) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) (parts []cli.Part, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	pipeReader, pipeWriter := io.Pipe()
+	uploadErr := make(chan error, 1)
+	go func() {
+		err := client.Upload(ctx, name, pipeReader)
+		// Unblock the writer when the upload ends early.
+		_ = pipeReader.CloseWithError(errs.New("upload ended"))
+		uploadErr <- err
+	}()
+
+	parts, err = cli.CopyParts(pipeWriter, strm, partSize)
+	_ = pipeWriter.CloseWithError(err)
+
+	if err := <-uploadErr; err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, Error.New("failed to upload file %q: %v", name, err)
+	}
+	return parts, nil
+}
+
+// Download downloads from the WebDAV server.
+func (client *Client) Download(ctx context.Context, name string) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	resp, err := client.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, Error.New("failed to download file %q: %v", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, Error.New("failed to download file %q: %s", name, errs.Combine(errs.New("%s", resp.Status), resp.Body.Close()))
+	}
+	return resp.Body, nil
+}
+
+// DownloadRange downloads a byte range from the WebDAV server.
+func (client *Client) DownloadRange(ctx context.Context, name string, offset, length int64) (strm io.ReadCloser, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	byteRange := fmt.Sprintf("bytes=%d-", offset)
+	if length >= 0 {
+		byteRange += fmt.Sprint(offset + length - 1)
+	}
+
+	resp, err := client.do(ctx, http.MethodGet, name, nil, http.Header{"Range": {byteRange}})
+	if err != nil {
+		return nil, Error.New("failed to download range of file %q: %v", name, err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		return nil, Error.New("failed to download range of file %q: %s", name, errs.Combine(errs.New("%s", resp.Status), resp.Body.Close()))
+	}
+	return resp.Body, nil
+}
+
+// Delete deletes from the WebDAV server.
+func (client *Client) Delete(ctx context.Context, name string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	resp, err := client.do(ctx, http.MethodDelete, name, nil, nil)
+	if err != nil {
+		return Error.New("failed to delete file %q: %v", name, err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(resp.Body.Close())) }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return Error.New("failed to delete file %q: %s", name, resp.Status)
+	}
+	return nil
+}
+
+// Copy copies a file on the WebDAV server.
+func (client *Client) Copy(ctx context.Context, src, dst string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	destination := *client.url
+	destination.Path = client.davPath(dst)
+	resp, err := client.do(ctx, "COPY", src, nil, http.Header{
+		"Destination": {destination.String()},
+		"Overwrite":   {"T"},
+	})
+	if err != nil {
+		return Error.New("failed to copy file %q to %q: %v", src, dst, err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(resp.Body.Close())) }()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return Error.New("failed to copy file %q to %q: %s", src, dst, resp.Status)
+	}
+	return nil
+}
+
+// CreateBucket is not supported, since WebDAV shares have no buckets.
+func (client *Client) CreateBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("create bucket")
+}
+
+// ListBuckets is not supported, since WebDAV shares have no buckets.
+func (client *Client) ListBuckets(ctx context.Context) ([]string, error) {
+	return nil, cli.ErrUnsupported.New("list buckets")
+}
+
+// DeleteBucket is not supported, since WebDAV shares have no buckets.
+func (client *Client) DeleteBucket(ctx context.Context, bucket string) error {
+	return cli.ErrUnsupported.New("delete bucket")
+}
+
+// IP returns the host of the endpoint.
+func (client *Client) IP(ctx context.Context) (string, error) {
+	return client.url.Hostname(), nil
+}
+
+// NetworkAddress returns the address of the server.
+func (client *Client) NetworkAddress() (string, bool, error) {
+	address, useTLS := cli.URLAddress(client.url)
+	return address, useTLS, nil
+}
+
+// Close closes the client.
+func (client *Client) Close() error {
+	client.client.CloseIdleConnections()
+	return nil
+}
+
+// do sends a request for the file name.
+func (client *Client) do(ctx context.Context, method, name string, body io.ReadCloser, header http.Header) (*http.Response, error) {
+	return client.doPath(ctx, method, client.davPath(name), body, header)
+}
+
+// doPath sends a request for the absolute path p on the server.
+func (client *Client) doPath(ctx context.Context, method, p string, body io.ReadCloser, header http.Header) (*http.Response, error) {
+	u := *client.url
+	u.Path = p
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if client.cfg.Username != "" || client.cfg.Password != "" {
+		req.SetBasicAuth(client.cfg.Username, client.cfg.Password)
+	}
+
+	return client.client.Do(req)
+}
+
+// propfindEntry is a member of a listed collection. The size and
+// modification time are zero if the server omits them.
+type propfindEntry struct {
+	key        string
+	collection bool
+	size       int64
+	modified   time.Time
+}
+
+type multistatus struct {
+	Responses []struct {
+		Href          string    `xml:"href"`
+		Collection    *struct{} `xml:"propstat>prop>resourcetype>collection"`
+		ContentLength int64     `xml:"propstat>prop>getcontentlength"`
+		LastModified  string    `xml:"propstat>prop>getlastmodified"`
+	} `xml:"response"`
+}
+
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/><getcontentlength/><getlastmodified/></prop></propfind>`
+
+// propfind lists the members of the collection at the absolute path p.
+func (client *Client) propfind(ctx context.Context, p string) (entries []propfindEntry, err error) {
+	collectionPath := strings.TrimSuffix(p, "/") + "/"
+
+	resp, err := client.doPath(ctx, "PROPFIND", collectionPath, ioutil.NopCloser(strings.NewReader(propfindBody)), http.Header{
+		"Depth":        {"1"},
+		"Content-Type": {"application/xml"},
+	})
+	if err != nil {
+		return nil, Error.New("failed to list %q: %v", p, err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(resp.Body.Close())) }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, Error.New("failed to list %q: %s", p, resp.Status)
+	}
+
+	var status multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, Error.New("failed to list %q: %v", p, err)
+	}
+
+	for _, response := range status.Responses {
+		href, err := url.Parse(response.Href)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		memberPath := strings.TrimSuffix(href.Path, "/") + "/"
+		if memberPath == collectionPath {
+			continue
+		}
+
+		modified, _ := http.ParseTime(response.LastModified)
+		entries = append(entries, propfindEntry{
+			key:        client.key(strings.TrimSuffix(memberPath, "/")),
+			collection: response.Collection != nil,
+			size:       response.ContentLength,
+			modified:   modified,
+		})
+	}
+	return entries, nil
+}
+
+// davPath returns the absolute server path of the file name.
+func (client *Client) davPath(name string) string {
+	return path.Join("/", client.url.Path, client.cfg.Path, name)
+}
+
+// key returns the name of the file at the absolute server path p, relative
+// to the collection of the endpoint, like the names davPath takes.
+func (client *Client) key(p string) string {
+	return strings.TrimPrefix(p, strings.TrimSuffix(client.davPath(""), "/")+"/")
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package webdavclient_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/backends/webdavclient"
+	"storj.io/perftester/config"
+)
+
+// davServer is a fake WebDAV server which lists a fixed tree of
+// collections, whose paths end with a slash, and files.
+type davServer struct {
+	tree map[string][]string
+}
+
+func (server *davServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PROPFIND" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	members, ok := server.tree[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0"?><multistatus xmlns="DAV:">`)
+	for _, member := range append([]string{r.URL.Path}, members...) {
+		resourceType := ""
+		if strings.HasSuffix(member, "/") {
+			resourceType = "<collection/>"
+		}
+		fmt.Fprintf(&body, `<response><href>%s</href><propstat><prop><resourcetype>%s</resourcetype><getcontentlength>3</getcontentlength></prop></propstat></response>`, member, resourceType)
+	}
+	body.WriteString(`</multistatus>`)
+
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write([]byte(body.String()))
+}
+
+func TestListRecursive(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(&davServer{tree: map[string][]string{
+		"/dav/bench/":            {"/dav/bench/a", "/dav/bench/run/"},
+		"/dav/bench/run/":        {"/dav/bench/run/b", "/dav/bench/run/nested/"},
+		"/dav/bench/run/nested/": {"/dav/bench/run/nested/c"},
+	}})
+	defer server.Close()
+
+	client, err := webdavclient.New(config.WebDAVEndpoint{URL: server.URL + "/dav", Path: "bench"})
+	require.NoError(t, err)
+
+	objs, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	var keys []string
+	for _, obj := range objs {
+		keys = append(keys, obj.Key)
+	}
+	sort.Strings(keys)
+	require.Equal(t, []string{"a", "run/b", "run/nested/c"}, keys)
+
+	objs, err = client.List(ctx, "run", false)
+	require.NoError(t, err)
+	keys = nil
+	for _, obj := range objs {
+		keys = append(keys, obj.Key)
+	}
+	sort.Strings(keys)
+	require.Equal(t, []string{"run/b", "run/nested/"}, keys)
+}
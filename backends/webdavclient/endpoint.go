@@ -0,0 +1,27 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package webdavclient
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+func init() {
+	cli.Register("webdav", func() cli.EndpointConfig { return &endpointConfig{} })
+}
+
+// endpointConfig is the config of [endpoint.webdav.<id>] tables.
+type endpointConfig struct {
+	config.WebDAVEndpoint
+}
+
+// NewClient creates the client of the endpoint.
+func (cfg *endpointConfig) NewClient(ctx context.Context, log *zap.Logger) (cli.Client, error) {
+	return New(cfg.WebDAVEndpoint)
+}
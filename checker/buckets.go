@@ -0,0 +1,164 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// bucketOperations are the operations of bucket tests in the order they
+// are run.
+var bucketOperations = []config.Operation{config.CreateBucket, config.ListBuckets, config.DeleteBucket}
+
+// runBucketCheck creates buckets, lists them and deletes them again in
+// every iteration, reporting the selected operations. Buckets left behind
+// are deleted afterwards.
+func (c *Checker) runBucketCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	if backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupportedOperations(ctx, bucketOperations, fileTestID, fileTest, endpoint)
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		c.checkLog(fileTestID, endpoint).Info("BucketLifecycle", zap.Int64("iteration", iteration))
+		supported, err := c.bucketLifecycle(ctx, fileTestID, fileTest, endpoint)
+		if err != nil || !supported {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketLifecycle creates, lists and deletes the buckets of one iteration.
+// It returns false if the endpoint doesn't support bucket operations.
+func (c *Checker) bucketLifecycle(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) (supported bool, err error) {
+	names := bucketNames(fileTest)
+	created := make([]bool, len(names))
+	defer c.cleanupBuckets(fileTestID, fileTest, endpoint, names, created)
+
+	result, createErr := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return createBuckets(ctx, fileTest, endpoint.Client, names, created, result)
+	})
+	if backends.ErrUnsupported.Has(createErr) {
+		return false, c.reportUnsupportedOperations(ctx, bucketOperations, fileTestID, fileTest, endpoint)
+	}
+	// Without the buckets, the other operations would only fail as well.
+	if err := c.reportSelected(ctx, config.CreateBucket, fileTestID, fileTest, endpoint, result, createErr); err != nil || createErr != nil {
+		return true, err
+	}
+
+	if fileTest.Runs(config.ListBuckets) {
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return listBuckets(ctx, fileTest, endpoint.Client, names)
+		})
+		if err := c.reportSelected(ctx, config.ListBuckets, fileTestID, fileTest, endpoint, result, err); err != nil {
+			return true, err
+		}
+	}
+
+	if fileTest.Runs(config.DeleteBucket) {
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return deleteBuckets(ctx, fileTest, endpoint.Client, names, created, result)
+		})
+		if err := c.reportSelected(ctx, config.DeleteBucket, fileTestID, fileTest, endpoint, result, err); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// createBuckets creates the buckets which weren't created by an earlier
+// attempt, timing each creation.
+func createBuckets(ctx context.Context, fileTest config.FileTest, client backends.Client, names []string, created []bool, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, len(names), int(fileTest.NumParallel), timeCalls(len(names), 0, result, func(ctx context.Context, i int) error {
+		if created[i] {
+			return nil
+		}
+		if err := client.CreateBucket(ctx, names[i]); err != nil {
+			return err
+		}
+		created[i] = true
+		return nil
+	}))
+}
+
+// listBuckets lists the buckets, checking that the created ones are listed.
+func listBuckets(ctx context.Context, fileTest config.FileTest, client backends.Client, names []string) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	buckets, err := client.ListBuckets(ctx)
+	if err != nil {
+		return err
+	}
+	listed := make(map[string]bool, len(buckets))
+	for _, bucket := range buckets {
+		listed[bucket] = true
+	}
+	for _, name := range names {
+		if !listed[name] {
+			return errs.New("created bucket %q isn't listed", name)
+		}
+	}
+	return nil
+}
+
+// deleteBuckets deletes the created buckets, timing each deletion.
+func deleteBuckets(ctx context.Context, fileTest config.FileTest, client backends.Client, names []string, created []bool, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, len(names), int(fileTest.NumParallel), timeCalls(len(names), 0, result, func(ctx context.Context, i int) error {
+		if !created[i] {
+			return nil
+		}
+		if err := client.DeleteBucket(ctx, names[i]); err != nil {
+			return err
+		}
+		created[i] = false
+		return nil
+	}))
+}
+
+// cleanupBuckets deletes the buckets left behind by a bucket check, such as
+// those of failed or cancelled checks.
+func (c *Checker) cleanupBuckets(fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, names []string, created []bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	err := runPool(ctx, len(names), int(fileTest.NumParallel), func(ctx context.Context, i int) error {
+		if !created[i] {
+			return nil
+		}
+		return endpoint.Client.DeleteBucket(ctx, names[i])
+	})
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Warn("Deleting buckets failed", zap.Error(err))
+	}
+}
+
+// bucketNames returns new names for the buckets of an iteration, which are
+// valid on all backends.
+func bucketNames(fileTest config.FileTest) []string {
+	prefix := fmt.Sprintf("perftester-%x", time.Now().UnixNano())
+	names := make([]string, fileTest.NumObjects)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s-%d", prefix, i)
+	}
+	return names
+}
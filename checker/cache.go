@@ -0,0 +1,64 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/common/sync2"
+	"storj.io/perftester/config"
+)
+
+// runCacheCheck uploads the objects and downloads them twice, CacheDelay
+// apart, so that the repeated downloads can be compared to the first ones
+// to tell how much edge and CDN caches speed them up.
+func (c *Checker) runCacheCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		uploaded := true
+		if fileTest.Runs(config.Upload) {
+			log.Info("Upload", zap.Int64("iteration", iteration))
+			var err error
+			uploaded, err = c.Upload(ctx, fileTestID, fileTest, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if uploaded {
+			if fileTest.Runs(config.Download) {
+				log.Info("Download", zap.Int64("iteration", iteration))
+				if err := c.Download(ctx, fileTestID, fileTest, endpoint); err != nil {
+					return err
+				}
+			}
+
+			if !sync2.Sleep(ctx, time.Duration(fileTest.CacheDelay)) {
+				return ctx.Err()
+			}
+
+			if fileTest.Runs(config.RepeatDownload) {
+				log.Info("RepeatDownload", zap.Int64("iteration", iteration))
+				if err := c.downloadAs(ctx, config.RepeatDownload, fileTestID, fileTest, endpoint); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !uploaded {
+			c.cleanupObjects(fileTestID, fileTest, endpoint)
+			continue
+		}
+		if fileTest.Runs(config.Delete) {
+			log.Info("Delete", zap.Int64("iteration", iteration))
+			if err := c.Delete(ctx, fileTestID, fileTest, endpoint); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,1052 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package checker runs the performance checks of a config on endpoints and
+// reports the result of every operation, which is the engine of the
+// perftester command. Programs embedding it register the endpoint types
+// they test, usually by importing storj.io/perftester/backends/all, and
+// run the checks of a config:
+//
+//	conf, err := config.LoadConfig("config.toml")
+//	...
+//	endpoints, err := checker.NewEndpoints(ctx, log, conf)
+//	...
+//	reporter := report.NewJSONReporter(conf.FileTestSizes())
+//	err = checker.NewChecker(log, reporter, endpoints, conf).RunChecks(ctx)
+package checker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/common/sync2"
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+var mon = monkit.Package()
+
+// Reporter handles the reports of each operation as they finish, such as
+// the reporters of the report package.
+type Reporter interface {
+	Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) error
+}
+
+// Checker can run various performance tests.
+type Checker struct {
+	log                *zap.Logger
+	endpoints          []*config.Endpoint
+	fileTests          map[config.ID]config.FileTest
+	timeout            config.Duration
+	concurrency        int
+	serializeEndpoints bool
+	simultaneous       bool
+	shuffle            bool
+	shuffleSeed        int64
+	progressInterval   config.Duration
+	reporter           Reporter
+	runPrefix          bool
+
+	// runID prefixes the names of the objects if runPrefix is set.
+	runID    string
+	hashes   *hashCache
+	cooldown *cooldown
+}
+
+// NewChecker creates a new checker.
+func NewChecker(log *zap.Logger, reporter Reporter, endpoints []*config.Endpoint, conf config.Config) *Checker {
+	concurrency := conf.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	shuffleSeed := conf.ShuffleSeed
+	if conf.Shuffle && shuffleSeed == 0 {
+		shuffleSeed = time.Now().UnixNano()
+	}
+
+	return &Checker{
+		endpoints:          endpoints,
+		fileTests:          conf.FileTests,
+		timeout:            conf.Timeout,
+		concurrency:        concurrency,
+		serializeEndpoints: conf.SerializeEndpoints,
+		simultaneous:       conf.Simultaneous,
+		shuffle:            conf.Shuffle,
+		shuffleSeed:        shuffleSeed,
+		progressInterval:   conf.ProgressInterval,
+		reporter:           reporter,
+		runPrefix:          conf.RunPrefix,
+		log:                log,
+		hashes:             new(hashCache),
+		cooldown:           &cooldown{pause: time.Duration(conf.Cooldown)},
+	}
+}
+
+// SetRunID sets the ID of the run, which the objects are put under if the
+// config enables run prefixes.
+func (c *Checker) SetRunID(runID string) {
+	c.runID = runID
+}
+
+// Mode describes how the checks are scheduled, so that reports record
+// which endpoints ran at the same time.
+func (c *Checker) Mode() string {
+	var mode string
+	switch {
+	case c.simultaneous:
+		mode = "simultaneous"
+	case c.concurrency == 1:
+		mode = "sequential"
+	case c.serializeEndpoints:
+		mode = fmt.Sprintf("concurrent, %d checks at once, one per endpoint", c.concurrency)
+	default:
+		mode = fmt.Sprintf("concurrent, %d checks at once", c.concurrency)
+	}
+	if c.shuffle {
+		mode += ", shuffled"
+	}
+	return mode
+}
+
+// ShuffleSeed returns the seed of the order of shuffled runs, which repeats
+// the order when it is set in the config, or zero if the run isn't
+// shuffled.
+func (c *Checker) ShuffleSeed() int64 {
+	if !c.shuffle {
+		return 0
+	}
+	return c.shuffleSeed
+}
+
+// RunChecks runs all operations on all files. Checks which fail don't stop
+// the other checks; their errors are returned once all checks finished.
+func (c *Checker) RunChecks(ctx context.Context) error {
+	if c.shuffle {
+		c.log.Info("Shuffling checks", zap.Int64("seed", c.shuffleSeed))
+	}
+	if c.simultaneous {
+		return c.runSimultaneousChecks(ctx)
+	}
+	if c.shuffle {
+		return c.runShuffledChecks(ctx)
+	}
+
+	var failures checkFailures
+
+	group, ctx := errgroup.WithContext(ctx)
+	limiter := make(chan struct{}, c.concurrency)
+
+	endpointLocks := make(map[config.ID]*sync.Mutex, len(c.endpoints))
+	for _, endpoint := range c.endpoints {
+		endpointLocks[endpoint.ID] = new(sync.Mutex)
+	}
+
+	// Run all checks on all endpoints
+	for fileTestID, fileTest := range c.fileTests {
+		for _, endpoint := range c.endpoints {
+			fileTestID, fileTest, endpoint := fileTestID, fileTest, endpoint
+			group.Go(func() error {
+				if c.serializeEndpoints {
+					lock := endpointLocks[endpoint.ID]
+					lock.Lock()
+					defer lock.Unlock()
+				}
+
+				limiter <- struct{}{}
+				defer func() { <-limiter }()
+
+				if err := c.cooldown.wait(ctx); err != nil {
+					return err
+				}
+				// Don't start new checks once the run was cancelled.
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				err := c.RunCheck(ctx, fileTestID, fileTest, endpoint)
+				c.cooldown.finish()
+				return c.recordFailure(ctx, &failures, fileTestID, endpoint, err)
+			})
+		}
+	}
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+	return failures.Err()
+}
+
+// recordFailure logs and collects the error of a failed check. A failed
+// check only affects its own file test and endpoint, so the other checks
+// keep going, and only the error of a cancelled run is returned.
+func (c *Checker) recordFailure(ctx context.Context, failures *checkFailures, fileTestID config.ID, endpoint *config.Endpoint, err error) error {
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+	c.checkLog(fileTestID, endpoint).Error("Check failed", zap.Error(err))
+	failures.add(errs.New("check %q on %q failed: %v", fileTestID, endpoint.ID, err))
+	return nil
+}
+
+// checkLog returns the logger of the check of the file test on the endpoint,
+// which adds their IDs to everything logged.
+func (c *Checker) checkLog(fileTestID config.ID, endpoint *config.Endpoint) *zap.Logger {
+	return c.log.With(zap.String("fileTestID", string(fileTestID)), zap.String("endpointID", string(endpoint.ID)))
+}
+
+// RunCheck runs all operations on a single file and endpoint.
+func (c *Checker) RunCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) (err error) {
+	return c.runCheck(ctx, fileTestID, fileTest, endpoint, nil)
+}
+
+// runCheck runs a check, starting its measured operations once the other
+// checks of start are ready to start theirs too.
+func (c *Checker) runCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, start *barrierMember) (err error) {
+	defer mon.Task()(&ctx)(&err)
+	monkit.SpanFromCtx(ctx).Annotate("fileTest", string(fileTestID))
+	monkit.SpanFromCtx(ctx).Annotate("endpoint", string(endpoint.ID))
+	log := c.checkLog(fileTestID, endpoint)
+
+	log.Info("Starting check")
+
+	if fileTest.TTL > 0 {
+		// Expiration is set up before anything is timed.
+		expires, err := backends.PrepareExpiration(ctx, endpoint.Client, time.Duration(fileTest.TTL))
+		if err != nil {
+			return err
+		}
+		if !expires {
+			log.Warn("Endpoint doesn't expire objects; they are kept despite the TTL")
+		}
+		ctx = backends.WithTTL(ctx, time.Duration(fileTest.TTL))
+	}
+	if metadata := objectMetadata(fileTest); metadata != nil {
+		ctx = backends.WithMetadata(ctx, metadata)
+	}
+	// Every object uploaded in the check has the size of the file test.
+	ctx = backends.WithSize(ctx, int64(fileTest.Size))
+
+	if fileTest.Type == config.RampTest {
+		if err := start.wait(ctx); err != nil {
+			return err
+		}
+		return c.runRampCheck(ctx, fileTestID, fileTest.WithDefaults(c.timeout), endpoint)
+	}
+
+	if fileTest.Seed <= 0 {
+		// The contents of objects uploaded by an earlier run can only be
+		// verified with the seed they were uploaded with.
+		if !fileTest.Runs(config.Upload) && fileTest.Verify == nil {
+			verify := false
+			fileTest.Verify = &verify
+		}
+		fileTest.Seed = time.Now().UnixNano()
+	}
+
+	fileTest = endpoint.Defaults.Apply(fileTest.WithDefaults(c.timeout))
+
+	// Existing objects are never written, so there is neither a warmup nor
+	// a cleanup.
+	if fileTest.Type == config.ExistingTest {
+		if err := start.wait(ctx); err != nil {
+			return err
+		}
+		return c.runExistingCheck(ctx, fileTestID, fileTest, endpoint)
+	}
+	// Bucket tests transfer no objects either.
+	if fileTest.Type == config.BucketTest {
+		if err := start.wait(ctx); err != nil {
+			return err
+		}
+		return c.runBucketCheck(ctx, fileTestID, fileTest, endpoint)
+	}
+
+	fileTest.RunID = c.runID
+	if c.runPrefix && c.runID != "" {
+		fileTest.Prefix = path.Join(c.runID, fileTest.Prefix)
+	}
+
+	defer func() {
+		if ctx.Err() != nil {
+			c.cleanupObjects(fileTestID, fileTest, endpoint)
+		}
+	}()
+
+	c.warmup(ctx, fileTestID, fileTest, endpoint)
+	if err := start.wait(ctx); err != nil {
+		return err
+	}
+
+	switch fileTest.Type {
+	case config.ThroughputTest:
+		return c.runThroughputCheck(ctx, fileTestID, fileTest, endpoint)
+	case config.LatencyTest:
+		return c.runLatencyCheck(ctx, fileTestID, fileTest, endpoint)
+	case config.SoakTest:
+		return c.runSoakCheck(ctx, fileTestID, fileTest, endpoint)
+	case config.MixedTest:
+		return c.runMixedCheck(ctx, fileTestID, fileTest, endpoint)
+	case config.CacheTest:
+		return c.runCacheCheck(ctx, fileTestID, fileTest, endpoint)
+	case config.ConsistencyTest:
+		return c.runConsistencyCheck(ctx, fileTestID, fileTest, endpoint)
+	case config.ConflictTest:
+		return c.runConflictCheck(ctx, fileTestID, fileTest, endpoint)
+	case config.ListingTest:
+		return c.runListingCheck(ctx, fileTestID, fileTest, endpoint)
+	default:
+		return errs.New("unknown test type %q for %q", fileTest.Type, fileTestID)
+	}
+}
+
+// runThroughputCheck transfers whole files and measures the throughput of
+// each operation.
+func (c *Checker) runThroughputCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		// Without uploads, the objects are expected to exist already.
+		uploaded := true
+		var err error
+		switch {
+		case !fileTest.Runs(config.Upload):
+		case fileTest.PartSize > 0:
+			log.Info("MultipartUpload", zap.Int64("iteration", iteration))
+			uploaded, err = c.MultipartUpload(ctx, fileTestID, fileTest, endpoint)
+		default:
+			log.Info("Upload", zap.Int64("iteration", iteration))
+			uploaded, err = c.Upload(ctx, fileTestID, fileTest, endpoint)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Operations on the uploaded objects would only fail as well, so
+		// they are skipped, but partial uploads are still deleted.
+		if !uploaded {
+			log.Warn("Skipping operations after failed upload", zap.Int64("iteration", iteration))
+			c.cleanupObjects(fileTestID, fileTest, endpoint)
+			continue
+		}
+
+		if fileTest.Runs(config.Download) {
+			log.Info("Download", zap.Int64("iteration", iteration))
+			err = c.Download(ctx, fileTestID, fileTest, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if fileTest.Runs(config.Copy) {
+			log.Info("Copy", zap.Int64("iteration", iteration))
+			err = c.Copy(ctx, fileTestID, fileTest, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if fileTest.Runs(config.GetMetadata) || fileTest.Runs(config.SetMetadata) {
+			log.Info("Metadata", zap.Int64("iteration", iteration))
+			err = c.Metadata(ctx, fileTestID, fileTest, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if fileTest.Runs(config.PutVersion) || fileTest.Runs(config.ListVersions) || fileTest.Runs(config.DeleteVersion) {
+			log.Info("Versioning", zap.Int64("iteration", iteration))
+			err = c.Versioning(ctx, fileTestID, fileTest, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(fileTest.Ranges) > 0 && fileTest.Runs(config.RangeDownload) {
+			log.Info("RangeDownload", zap.Int64("iteration", iteration))
+			err = c.RangeDownload(ctx, fileTestID, fileTest, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+
+		if fileTest.Runs(config.Delete) {
+			log.Info("Delete", zap.Int64("iteration", iteration))
+			err = c.Delete(ctx, fileTestID, fileTest, endpoint)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// warmup runs unrecorded upload, download and delete cycles, so that first
+// request costs like TLS handshakes don't skew the measured run. It stops
+// at the first failure, leaving the measured run to report it.
+func (c *Checker) warmup(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) {
+	log := c.checkLog(fileTestID, endpoint)
+	// Warmup downloads would fill the caches cache tests measure.
+	if fileTest.Warmup <= 0 && fileTest.WarmupDuration <= 0 || fileTest.Type == config.CacheTest {
+		return
+	}
+
+	// Objects which the check doesn't upload itself must not be replaced or
+	// deleted.
+	readOnly := backends.IsReadOnly(endpoint.Client) || !fileTest.Runs(config.Upload)
+	expectedHashes, err := c.expectedHashes(fileTestID, fileTest, endpoint)
+	if err != nil {
+		log.Warn("Warmup failed", zap.Error(err))
+		return
+	}
+
+	deadline := time.Now().Add(time.Duration(fileTest.WarmupDuration))
+	for cycle := int64(0); cycle < fileTest.Warmup || time.Now().Before(deadline); cycle++ {
+		log.Info("Warmup", zap.Int64("cycle", cycle))
+
+		var err error
+		if !readOnly {
+			err = upload(ctx, fileTestID, fileTest, endpoint, nil, newResultNow())
+		}
+		if err == nil {
+			err = download(ctx, fileTestID, fileTest, endpoint, expectedHashes, nil, newResultNow())
+		}
+		if !readOnly {
+			err = errs.Combine(err, del(ctx, fileTestID, fileTest, endpoint, newResultNow()))
+		}
+		if err != nil {
+			log.Warn("Warmup failed", zap.Error(err))
+			return
+		}
+	}
+}
+
+// Upload makes an upload check. It returns whether the objects are
+// available to the checks which download them.
+func (c *Checker) Upload(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) (ok bool, err error) {
+	if backends.IsReadOnly(endpoint.Client) {
+		return true, c.reportUnsupported(ctx, config.Upload, fileTestID, endpoint)
+	}
+
+	progress := c.startProgress(ctx, config.Upload, fileTestID, endpoint.ID)
+	result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return upload(ctx, fileTestID, fileTest, endpoint, progress, result)
+	})
+	progress.stop()
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Error("Upload failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+	}
+	return result.Success, c.reporter.Report(ctx, config.Upload, fileTestID, endpoint.ID, result)
+}
+
+// upload uploads every object.
+func upload(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, progress *progress, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	timeline := newTimeline(result.StartTime)
+	finalize := make([]time.Duration, fileTest.NumObjects)
+	err = runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeTransfers(fileTest, result, func(ctx context.Context, i int) error {
+		r := &timedReader{Reader: timeline.wrap(progress.wrap(throttle(ctx, fileTest, fileReader(fileTest, i))))}
+		err := endpoint.Client.Upload(ctx, pathName(fileTestID, fileTest, i), r)
+		if err == nil && !r.eof.IsZero() {
+			finalize[i] = time.Since(r.eof)
+		}
+		return err
+	}))
+	result.Finalize = maxDuration(finalize)
+	result.Timeline = timeline.intervals()
+	return err
+}
+
+// MultipartUpload makes a multipart upload check. It returns whether the
+// objects are available to the checks which download them.
+func (c *Checker) MultipartUpload(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) (ok bool, err error) {
+	if backends.IsReadOnly(endpoint.Client) {
+		return true, c.reportUnsupported(ctx, config.MultipartUpload, fileTestID, endpoint)
+	}
+
+	progress := c.startProgress(ctx, config.MultipartUpload, fileTestID, endpoint.ID)
+	result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return multipartUpload(ctx, fileTestID, fileTest, endpoint, progress, result)
+	})
+	progress.stop()
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Error("MultipartUpload failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+	}
+	return result.Success, c.reporter.Report(ctx, config.MultipartUpload, fileTestID, endpoint.ID, result)
+}
+
+// multipartUpload uploads every object in parts.
+func multipartUpload(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, progress *progress, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	timeline := newTimeline(result.StartTime)
+	parts := make([][]backends.Part, fileTest.NumObjects)
+	err = runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeTransfers(fileTest, result, func(ctx context.Context, i int) (err error) {
+		parts[i], err = endpoint.Client.UploadMultipart(ctx, pathName(fileTestID, fileTest, i), timeline.wrap(progress.wrap(throttle(ctx, fileTest, fileReader(fileTest, i)))), fileTest.PartSize, fileTest.PartConcurrency)
+		return err
+	}))
+	for _, streamParts := range parts {
+		result.Parts = append(result.Parts, streamParts...)
+	}
+	result.Timeline = timeline.intervals()
+	return err
+}
+
+// Copy makes a server-side copy check, copying every object next to the
+// original. The copies are deleted afterwards.
+func (c *Checker) Copy(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	if backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupported(ctx, config.Copy, fileTestID, endpoint)
+	}
+
+	result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return copyObjects(ctx, fileTestID, fileTest, endpoint, result)
+	})
+	if backends.ErrUnsupported.Has(err) {
+		return c.reportUnsupported(ctx, config.Copy, fileTestID, endpoint)
+	}
+	if err != nil {
+		log.Error("Copy failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+	}
+
+	for i := 0; i < int(fileTest.NumObjects); i++ {
+		if err := endpoint.Client.Delete(ctx, copyName(fileTestID, fileTest, i)); err != nil {
+			log.Warn("Deleting copy failed", zap.Error(err))
+		}
+	}
+
+	return c.reporter.Report(ctx, config.Copy, fileTestID, endpoint.ID, result)
+}
+
+func copyObjects(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeObjects(fileTest, result, func(ctx context.Context, i int) error {
+		return endpoint.Client.Copy(ctx, pathName(fileTestID, fileTest, i), copyName(fileTestID, fileTest, i))
+	}))
+}
+
+// copyName returns the name of the copy of the i-th object. Copies get
+// indexes after the originals, so that cleanup still finds them.
+func copyName(fileTestID config.ID, fileTest config.FileTest, i int) string {
+	return pathName(fileTestID, fileTest, int(fileTest.NumObjects)+i)
+}
+
+// Delete makes a delete check.
+func (c *Checker) Delete(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	if backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupported(ctx, config.Delete, fileTestID, endpoint)
+	}
+
+	result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return del(ctx, fileTestID, fileTest, endpoint, result)
+	})
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Error("Delete failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+	}
+	return c.reporter.Report(ctx, config.Delete, fileTestID, endpoint.ID, result)
+}
+
+func del(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeObjects(fileTest, result, func(ctx context.Context, i int) error {
+		return endpoint.Client.Delete(ctx, pathName(fileTestID, fileTest, i))
+	}))
+}
+
+// Download runs the download check for a single fileTest and endpoint.
+func (c *Checker) Download(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	return c.downloadAs(ctx, config.Download, fileTestID, fileTest, endpoint)
+}
+
+// downloadAs makes a download check reported as operation.
+func (c *Checker) downloadAs(ctx context.Context, operation config.Operation, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	expectedHashes, err := c.expectedHashes(fileTestID, fileTest, endpoint)
+	if err != nil {
+		return err
+	}
+
+	// Downloads verified separately only check the size of the objects
+	// while they are timed.
+	timedHashes := expectedHashes
+	if fileTest.SeparateVerify {
+		timedHashes = make([][]byte, len(expectedHashes))
+	}
+
+	var verify func() error
+	if fileTest.SeparateVerify {
+		verify = func() error {
+			return verifyDownloads(ctx, fileTestID, fileTest, endpoint, expectedHashes)
+		}
+	}
+
+	progress := c.startProgress(ctx, operation, fileTestID, endpoint.ID)
+	result, err := runCheckedAttempts(ctx, fileTest, func(result *config.Result) error {
+		return download(ctx, fileTestID, fileTest, endpoint, timedHashes, progress, result)
+	}, verify)
+	progress.stop()
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Error(operation.String()+" failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+	}
+	return c.reporter.Report(ctx, operation, fileTestID, endpoint.ID, result)
+}
+
+// expectedHashes returns the expected digest of every object of the
+// file test, computed from their deterministic contents before anything is
+// timed and kept for the other checks of the file test. The digests are nil
+// when the file test doesn't verify downloads, or for read-only endpoints,
+// whose objects weren't uploaded by the checker.
+func (c *Checker) expectedHashes(fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) ([][]byte, error) {
+	if !fileTest.Verifies() || backends.IsReadOnly(endpoint.Client) {
+		return make([][]byte, fileTest.NumObjects), nil
+	}
+	return c.hashes.get(newHashKey(fileTestID, fileTest), fileTest, int(fileTest.NumObjects))
+}
+
+// computeExpectedHashes returns the digest of the contents of the
+// first count files, hashing them on all CPUs.
+func computeExpectedHashes(fileTest config.FileTest, count int) ([][]byte, error) {
+	expectedHashes := make([][]byte, count)
+	err := runPool(context.Background(), count, runtime.NumCPU(), func(ctx context.Context, i int) error {
+		expectedHash := newHash(fileTest.Checksum)
+		if _, err := backends.Copy(expectedHash, fileReader(fileTest, i)); err != nil {
+			return err
+		}
+		expectedHashes[i] = expectedHash.Sum(nil)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expectedHashes, nil
+}
+
+func download(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, expectedHashes [][]byte, progress *progress, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	timeline := newTimeline(result.StartTime)
+	nodeStats := new(nodeStats)
+	firstByte := make([]time.Duration, fileTest.NumObjects)
+	err = runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeTransfers(fileTest, result, func(ctx context.Context, i int) (err error) {
+		firstByte[i], err = downloadObject(ctx, fileTestID, fileTest, endpoint, i, expectedHashes[i], progress, timeline, nodeStats)
+		return err
+	}))
+	result.FirstByte = maxDuration(firstByte)
+	result.Timeline = timeline.intervals()
+	result.NodeStats = nodeStats.sum()
+	return err
+}
+
+// downloadObject downloads the i-th file and verifies its contents against
+// the expected hash, returning the time to its first byte. Without an
+// expected hash the contents aren't hashed and only the size of the file is
+// verified.
+func downloadObject(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, i int, expectedHash []byte, progress *progress, timeline *timeline, nodeStats *nodeStats) (firstByte time.Duration, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	hash := newHash(fileTest.Checksum)
+	var w io.Writer = hash
+	if expectedHash == nil {
+		w = ioutil.Discard
+	}
+
+	start := time.Now()
+	strm, err := endpoint.Client.Download(ctx, pathName(fileTestID, fileTest, i))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		err = errs.Combine(err, strm.Close())
+		nodeStats.add(strm)
+	}()
+
+	r := &timedReader{Reader: timeline.wrap(progress.wrap(throttle(ctx, fileTest, strm)))}
+	n, err := backends.Copy(w, r)
+	if err != nil {
+		return 0, err
+	}
+	if !r.firstByte.IsZero() {
+		firstByte = r.firstByte.Sub(start)
+	}
+
+	if n != int64(fileTest.Size) {
+		return firstByte, errSize.New("unexpected %q/%d file size: expected %d bytes; got %d", fileTestID, i, fileTest.Size, n)
+	}
+	if expectedHash == nil {
+		return firstByte, nil
+	}
+
+	digest := hash.Sum(nil)
+	if !bytes.Equal(digest, expectedHash) {
+		return firstByte, errChecksum.New("unexpected %q/%d file contents: expected %s digest %x; got %x", fileTestID, i, fileTest.Checksum, expectedHash, digest)
+	}
+
+	return firstByte, nil
+}
+
+// verifyDownloads downloads the objects again, without timing them, and
+// verifies their contents against the expected hashes. Objects without an
+// expected hash aren't downloaded again.
+func verifyDownloads(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, expectedHashes [][]byte) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), func(ctx context.Context, i int) error {
+		if expectedHashes[i] == nil {
+			return nil
+		}
+		_, err := downloadObject(ctx, fileTestID, fileTest, endpoint, i, expectedHashes[i], nil, nil, nil)
+		return err
+	})
+}
+
+// RangeDownload runs the range download check for a single fileTest and
+// endpoint, fetching every configured range of every object.
+func (c *Checker) RangeDownload(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	expectedHashes := make([][][]byte, fileTest.NumObjects)
+	if fileTest.Verifies() && !backends.IsReadOnly(endpoint.Client) {
+		var err error
+		expectedHashes, err = computeExpectedRangeHashes(fileTest)
+		if err != nil {
+			return err
+		}
+	}
+
+	progress := c.startProgress(ctx, config.RangeDownload, fileTestID, endpoint.ID)
+	result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return rangeDownload(ctx, fileTestID, fileTest, endpoint, expectedHashes, progress, result)
+	})
+	progress.stop()
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Error("RangeDownload failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+	}
+	return c.reporter.Report(ctx, config.RangeDownload, fileTestID, endpoint.ID, result)
+}
+
+// computeExpectedRangeHashes returns the digest of every range of
+// every object's file contents, indexed by object and then range. Objects
+// are hashed on all CPUs.
+func computeExpectedRangeHashes(fileTest config.FileTest) ([][][]byte, error) {
+	expectedHashes := make([][][]byte, fileTest.NumObjects)
+	err := runPool(context.Background(), int(fileTest.NumObjects), runtime.NumCPU(), func(ctx context.Context, i int) error {
+		rangeHashes := make([][]byte, 0, len(fileTest.Ranges))
+		for _, byteRange := range fileTest.Ranges {
+			r := fileReader(fileTest, i)
+			if _, err := backends.CopyN(ioutil.Discard, r, byteRange.Offset); err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+			if byteRange.Length >= 0 {
+				r = io.LimitReader(r, byteRange.Length)
+			}
+
+			expectedHash := newHash(fileTest.Checksum)
+			if _, err := backends.Copy(expectedHash, r); err != nil {
+				return err
+			}
+			rangeHashes = append(rangeHashes, expectedHash.Sum(nil))
+		}
+		expectedHashes[i] = rangeHashes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expectedHashes, nil
+}
+
+func rangeDownload(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, expectedHashes [][][]byte, progress *progress, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	firstByte := make([]time.Duration, fileTest.NumObjects)
+	var downloaded int64
+	err = runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeObjects(fileTest, result, func(ctx context.Context, i int) error {
+		for j, byteRange := range fileTest.Ranges {
+			start := time.Now()
+			strm, err := endpoint.Client.DownloadRange(ctx, pathName(fileTestID, fileTest, i), byteRange.Offset, byteRange.Length)
+			if err != nil {
+				return err
+			}
+
+			// Ranges without an expected hash aren't hashed at all.
+			hash := newHash(fileTest.Checksum)
+			var w io.Writer = hash
+			if expectedHashes[i] == nil {
+				w = ioutil.Discard
+			}
+
+			r := &timedReader{Reader: progress.wrap(throttle(ctx, fileTest, strm))}
+			_, err = backends.Copy(w, r)
+			err = errs.Combine(err, strm.Close())
+			if err != nil {
+				return err
+			}
+			if j == 0 && !r.firstByte.IsZero() {
+				firstByte[i] = r.firstByte.Sub(start)
+			}
+
+			if expectedHashes[i] != nil {
+				if digest := hash.Sum(nil); !bytes.Equal(digest, expectedHashes[i][j]) {
+					return errChecksum.New("unexpected %q/%d contents at range %d+%d: expected %s digest %x; got %x", fileTestID, i, byteRange.Offset, byteRange.Length, fileTest.Checksum, expectedHashes[i][j], digest)
+				}
+			}
+			atomic.AddInt64(&downloaded, rangeLength(fileTest, byteRange))
+		}
+		return nil
+	}))
+	result.FirstByte = maxDuration(firstByte)
+	result.Bytes = downloaded
+	return err
+}
+
+// rangeLength returns the number of bytes of the file test's objects in the
+// range.
+func rangeLength(fileTest config.FileTest, byteRange config.Range) int64 {
+	if byteRange.Length < 0 || byteRange.Offset+byteRange.Length > int64(fileTest.Size) {
+		return int64(fileTest.Size) - byteRange.Offset
+	}
+	return byteRange.Length
+}
+
+// reportUnsupported reports that the endpoint's client doesn't support the
+// operation.
+func (c *Checker) reportUnsupported(ctx context.Context, operation config.Operation, fileTestID config.ID, endpoint *config.Endpoint) error {
+	result := newResultNow()
+	result.Unsupported = true
+	result.Error = backends.ErrUnsupported.New("%s", operation).Error()
+	return c.reporter.Report(ctx, operation, fileTestID, endpoint.ID, result)
+}
+
+// reportSelected reports the result of an operation if it is selected.
+// Operations which only prepare the selected ones, such as the uploads of
+// versions to list, run regardless, so their errors are always logged.
+func (c *Checker) reportSelected(ctx context.Context, operation config.Operation, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, result *config.Result, err error) error {
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Error(operation.String()+" failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+	}
+	if !fileTest.Runs(operation) {
+		return nil
+	}
+	return c.reporter.Report(ctx, operation, fileTestID, endpoint.ID, result)
+}
+
+// reportUnsupportedOperations reports the selected operations as
+// unsupported.
+func (c *Checker) reportUnsupportedOperations(ctx context.Context, operations []config.Operation, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	for _, operation := range operations {
+		if fileTest.Runs(operation) {
+			if err := c.reportUnsupported(ctx, operation, fileTestID, endpoint); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runAttempts runs op until it succeeds or the file test's retries are
+// exhausted, doubling the backoff between attempts. The returned result
+// describes the last attempt and records every attempt made.
+func runAttempts(ctx context.Context, fileTest config.FileTest, op func(result *config.Result) error) (*config.Result, error) {
+	return runCheckedAttempts(ctx, fileTest, op, nil)
+}
+
+// runCheckedAttempts is runAttempts with check run after every successful
+// op, outside the timed window of the attempt. Attempts whose check fails
+// fail and are retried like those whose op fails.
+func runCheckedAttempts(ctx context.Context, fileTest config.FileTest, op func(result *config.Result) error, check func() error) (*config.Result, error) {
+	var attempts []config.Attempt
+	backoff := time.Duration(fileTest.RetryBackoff)
+
+	// Pausing before the operation keeps the previous one from interfering
+	// with its measurement.
+	if fileTest.Cooldown > 0 && !sync2.Sleep(ctx, time.Duration(fileTest.Cooldown)) {
+		result := newResultNow()
+		result.Error = ctx.Err().Error()
+		result.ErrorCategory = classifyError(ctx.Err())
+		return result, ctx.Err()
+	}
+
+	for {
+		// The sampler takes its baseline before the timing starts.
+		sampler := startResourceSampler()
+		result := newResultNow()
+		err := op(result)
+		result.Duration = time.Since(result.StartTime)
+		result.Resources = sampler.stop()
+		if err == nil && check != nil {
+			err = check()
+		}
+		result.Success = err == nil
+		if err != nil {
+			result.Error = err.Error()
+			result.ErrorCategory = classifyError(err)
+		}
+
+		attempts = append(attempts, config.Attempt{
+			StartTime: result.StartTime,
+			Duration:  result.Duration,
+			Error:     result.Error,
+		})
+		result.Attempts = attempts
+
+		if err == nil || int64(len(attempts)) > fileTest.Retries || !sync2.Sleep(ctx, backoff) {
+			return result, err
+		}
+		backoff *= 2
+	}
+}
+
+// timeObjects wraps f to record the duration of every object in the result.
+func timeObjects(fileTest config.FileTest, result *config.Result, f func(ctx context.Context, i int) error) func(ctx context.Context, i int) error {
+	return timeCalls(int(fileTest.NumObjects), 0, result, f)
+}
+
+// timeTransfers wraps f to record the duration of every object in the
+// result, as well as the bytes of those which were transferred.
+func timeTransfers(fileTest config.FileTest, result *config.Result, f func(ctx context.Context, i int) error) func(ctx context.Context, i int) error {
+	return timeCalls(int(fileTest.NumObjects), int64(fileTest.Size), result, f)
+}
+
+// timeCalls wraps f to record the duration of each of count calls in the
+// result, and which of them succeeded or failed. Every successful call
+// counts size bytes. Calls cancelled after another one failed count as
+// neither.
+func timeCalls(count int, size int64, result *config.Result, f func(ctx context.Context, i int) error) func(ctx context.Context, i int) error {
+	result.ObjectDurations = make([]time.Duration, count)
+	result.Succeeded = 0
+	result.FailedObjects = nil
+	result.Bytes = 0
+
+	var mu sync.Mutex
+	return func(ctx context.Context, i int) error {
+		start := time.Now()
+		err := f(ctx, i)
+		result.ObjectDurations[i] = time.Since(start)
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err == nil:
+			result.Succeeded++
+			result.Bytes += size
+		case !errors.Is(ctx.Err(), context.Canceled):
+			result.FailedObjects = append(result.FailedObjects, i)
+			sort.Ints(result.FailedObjects)
+		}
+		return err
+	}
+}
+
+// runPool runs f for every index below count using numWorkers goroutines. It
+// stops handing out indexes after the first failure. Calls failing once the
+// deadline of ctx passed fail with a timeout.
+func runPool(ctx context.Context, count, numWorkers int, f func(ctx context.Context, i int) error) error {
+	group, ctx := errgroup.WithContext(ctx)
+
+	indexes := make(chan int)
+	group.Go(func() error {
+		defer close(indexes)
+		for i := 0; i < count; i++ {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	for worker := 0; worker < numWorkers; worker++ {
+		group.Go(func() error {
+			for i := range indexes {
+				if err := f(ctx, i); err != nil {
+					return timedOut(ctx, err)
+				}
+			}
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// pathName returns the name of the i-th object of a file test, under the
+// file test's prefix.
+func pathName(id config.ID, fileTest config.FileTest, i int) string {
+	return path.Join(strings.Trim(fileTest.Prefix, "/"), fileTest.ObjectName(id, i))
+}
+
+// timedReader records when the first byte was read and when the underlying
+// reader was exhausted.
+type timedReader struct {
+	io.Reader
+	firstByte time.Time
+	eof       time.Time
+}
+
+func (r *timedReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if n > 0 && r.firstByte.IsZero() {
+		r.firstByte = time.Now()
+	}
+	if err == io.EOF && r.eof.IsZero() {
+		r.eof = time.Now()
+	}
+	return n, err
+}
+
+// maxDuration returns the longest of the per-stream durations, since a
+// parallel batch is only as fast as its slowest stream.
+func maxDuration(durations []time.Duration) (max time.Duration) {
+	for _, duration := range durations {
+		if duration > max {
+			max = duration
+		}
+	}
+	return max
+}
+
+// newResultNow returns a Result with the Time value set to now.
+func newResultNow() *config.Result {
+	return &config.Result{
+		StartTime: time.Now(),
+	}
+}
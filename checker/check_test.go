@@ -0,0 +1,1902 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/checker"
+	"storj.io/perftester/config"
+)
+
+// memClient is an in-memory client.
+type memClient struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	buckets map[string]bool
+}
+
+func newMemClient() *memClient {
+	return &memClient{objects: make(map[string][]byte), buckets: make(map[string]bool)}
+}
+
+func (client *memClient) List(ctx context.Context, prefix string, recursive bool) (objs []*cli.ListObject, err error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	prefixes := make(map[string]bool)
+	for key := range client.objects {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if i := strings.Index(key[len(prefix):], "/"); !recursive && i >= 0 {
+			pre := key[:len(prefix)+i+1]
+			if !prefixes[pre] {
+				prefixes[pre] = true
+				objs = append(objs, &cli.ListObject{Key: pre, IsPre: true})
+			}
+			continue
+		}
+		objs = append(objs, &cli.ListObject{Key: key, Size: int64(len(client.objects[key]))})
+	}
+	sort.Slice(objs, func(i, j int) bool { return objs[i].Key < objs[j].Key })
+	return objs, nil
+}
+
+func (client *memClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	data, err := ioutil.ReadAll(strm)
+	if err != nil {
+		return err
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.objects[name] = data
+	return nil
+}
+
+func (client *memClient) UploadMultipart(ctx context.Context, name string, strm io.Reader, partSize int64, concurrency int) ([]cli.Part, error) {
+	var buf bytes.Buffer
+	parts, err := cli.CopyParts(&buf, strm, partSize)
+	if err != nil {
+		return nil, err
+	}
+	return parts, client.Upload(ctx, name, &buf)
+}
+
+func (client *memClient) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	return client.DownloadRange(ctx, name, 0, -1)
+}
+
+func (client *memClient) DownloadRange(ctx context.Context, name string, offset, length int64) (io.ReadCloser, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	data, ok := client.objects[name]
+	if !ok {
+		return nil, errs.New("object %q not found", name)
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	data = data[offset:]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (client *memClient) Delete(ctx context.Context, name string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	delete(client.objects, name)
+	return nil
+}
+
+func (client *memClient) Copy(ctx context.Context, src, dst string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	data, ok := client.objects[src]
+	if !ok {
+		return errs.New("object %q not found", src)
+	}
+	client.objects[dst] = append([]byte(nil), data...)
+	return nil
+}
+
+func (client *memClient) CreateBucket(ctx context.Context, bucket string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.buckets[bucket] {
+		return errs.New("bucket %q already exists", bucket)
+	}
+	client.buckets[bucket] = true
+	return nil
+}
+
+func (client *memClient) ListBuckets(ctx context.Context) (buckets []string, err error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	for bucket := range client.buckets {
+		buckets = append(buckets, bucket)
+	}
+	sort.Strings(buckets)
+	return buckets, nil
+}
+
+func (client *memClient) DeleteBucket(ctx context.Context, bucket string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if !client.buckets[bucket] {
+		return errs.New("bucket %q not found", bucket)
+	}
+	delete(client.buckets, bucket)
+	return nil
+}
+
+func (client *memClient) IP(ctx context.Context) (string, error) { return "", nil }
+
+func (client *memClient) Close() error { return nil }
+
+type reportKey struct {
+	operation  config.Operation
+	fileTestID config.ID
+	endpointID config.ID
+}
+
+// memReporter collects every report.
+type memReporter struct {
+	mu      sync.Mutex
+	results map[reportKey][]*config.Result
+	order   []reportKey
+}
+
+func newMemReporter() *memReporter {
+	return &memReporter{results: make(map[reportKey][]*config.Result)}
+}
+
+func (reporter *memReporter) Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) error {
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+
+	key := reportKey{operation, fileTestID, endpointID}
+	reporter.results[key] = append(reporter.results[key], result)
+	reporter.order = append(reporter.order, key)
+	return nil
+}
+
+func TestRunChecks(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {
+				NumParallel: 2,
+				Iterations:  2,
+				Size:        10000,
+				Ranges:      []config.Range{{Offset: 100, Length: 200}, {Offset: 9000, Length: -1}},
+			},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Download, config.RangeDownload, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 2, operation.String())
+		for _, result := range results {
+			require.True(t, result.Success, "%s: %s", operation, result.Error)
+		}
+	}
+
+	// The timelines of full transfers add up to all of the objects.
+	for _, operation := range []config.Operation{config.Upload, config.Download} {
+		for _, result := range reporter.results[reportKey{operation, "ft", "mem"}] {
+			var total int64
+			for _, bytes := range result.Timeline {
+				total += bytes
+			}
+			require.Equal(t, int64(2*10000), total, operation.String())
+		}
+	}
+
+	// Range downloads move the bytes of their ranges only.
+	for _, result := range reporter.results[reportKey{config.RangeDownload, "ft", "mem"}] {
+		require.EqualValues(t, 2*(200+1000), result.Bytes)
+	}
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+func TestRunChecksMultipart(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{{ID: "mem", Client: newMemClient()}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 10000, PartSize: 3000},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	require.Empty(t, reporter.results[reportKey{config.Upload, "ft", "mem"}])
+	results := reporter.results[reportKey{config.MultipartUpload, "ft", "mem"}]
+	require.Len(t, results, 1)
+	require.True(t, results[0].Success, results[0].Error)
+
+	var sizes []int64
+	for _, part := range results[0].Parts {
+		sizes = append(sizes, part.Size)
+	}
+	require.Equal(t, []int64{3000, 3000, 3000, 1000}, sizes)
+
+	download := reporter.results[reportKey{config.Download, "ft", "mem"}]
+	require.Len(t, download, 1)
+	require.True(t, download[0].Success, download[0].Error)
+}
+
+func TestRunChecksLatency(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {
+				Type:        config.LatencyTest,
+				NumParallel: 4,
+				NumObjects:  20,
+				Size:        100,
+			},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Download, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Success, "%s: %s", operation, results[0].Error)
+		require.Len(t, results[0].Latencies, 20, operation.String())
+	}
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+func TestRunChecksNumObjects(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {
+				NumParallel: 3,
+				NumObjects:  10,
+				Size:        1000,
+				Ranges:      []config.Range{{Offset: 10, Length: 20}},
+				Copy:        true,
+			},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Download, config.Copy, config.RangeDownload, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Success, "%s: %s", operation, results[0].Error)
+	}
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+func TestRunChecksRamp(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{{ID: "mem", Client: newMemClient()}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Type: config.RampTest, Size: 1000, Ramp: []int64{1, 2, 4}},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	results := reporter.results[reportKey{config.Upload, "ft", "mem"}]
+	require.Len(t, results, 3)
+	for i, level := range []int64{1, 2, 4} {
+		require.True(t, results[i].Success, results[i].Error)
+		require.Equal(t, level, results[i].Parallelism)
+		require.Len(t, results[i].ObjectDurations, int(level))
+	}
+}
+
+func TestRunChecksSoak(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {
+				Type:         config.SoakTest,
+				NumParallel:  2,
+				Size:         1000,
+				Duration:     config.Duration(100 * time.Millisecond),
+				SoakInterval: config.Duration(20 * time.Millisecond),
+			},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Download} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Success, "%s: %s", operation, results[0].Error)
+		require.NotEmpty(t, results[0].Buckets, operation.String())
+
+		var objects int
+		for _, bucket := range results[0].Buckets {
+			objects += bucket.Objects
+		}
+		require.Equal(t, len(results[0].ObjectDurations), objects, operation.String())
+	}
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+// hangingClient is a memClient whose uploads of the object ending with
+// name hang until their context is done.
+type hangingClient struct {
+	*memClient
+	name string
+
+	downloads sync.Map
+}
+
+func (client *hangingClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	if strings.HasSuffix(name, client.name) {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return client.memClient.Upload(ctx, name, strm)
+}
+
+func (client *hangingClient) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	client.downloads.Store(name, true)
+	return client.memClient.Download(ctx, name)
+}
+
+func TestRunChecksSoakHangingUpload(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := &hangingClient{memClient: newMemClient(), name: "ft1"}
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(20 * time.Millisecond),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {
+				Type:         config.SoakTest,
+				NumObjects:   2,
+				NumParallel:  2,
+				Size:         1000,
+				Duration:     config.Duration(100 * time.Millisecond),
+				SoakInterval: config.Duration(20 * time.Millisecond),
+			},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	uploads := reporter.results[reportKey{config.Upload, "ft", "mem"}]
+	require.Len(t, uploads, 1)
+	require.True(t, uploads[0].Success, uploads[0].Error)
+	var errors int
+	for _, bucket := range uploads[0].Buckets {
+		errors += bucket.Errors
+	}
+	require.NotZero(t, errors)
+
+	downloads := reporter.results[reportKey{config.Download, "ft", "mem"}]
+	require.Len(t, downloads, 1)
+	require.True(t, downloads[0].Success, downloads[0].Error)
+	for _, bucket := range downloads[0].Buckets {
+		require.Zero(t, bucket.Errors)
+	}
+	_, downloaded := client.downloads.Load("ft1")
+	require.False(t, downloaded)
+	_, downloaded = client.downloads.Load("ft0")
+	require.True(t, downloaded)
+}
+
+func TestRunChecksMixed(t *testing.T) {
+	for _, readPercent := range []int64{50, 0} {
+		readPercent := readPercent
+		t.Run(fmt.Sprint(readPercent), func(t *testing.T) {
+			ctx := testcontext.New(t)
+			defer ctx.Cleanup()
+
+			client := newMemClient()
+			endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+			conf := config.Config{
+				Timeout: config.Duration(time.Minute),
+				FileTests: map[config.ID]config.FileTest{
+					"ft": {
+						Type:        config.MixedTest,
+						NumParallel: 4,
+						NumObjects:  20,
+						Size:        1000,
+						Seed:        1,
+						ReadPercent: &readPercent,
+					},
+				},
+			}
+
+			reporter := newMemReporter()
+			c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+			require.NoError(t, c.RunChecks(ctx))
+
+			operations := []config.Operation{config.Upload, config.Download, config.MixedUpload, config.MixedDownload}
+			if readPercent == 0 {
+				// A read percent of 0 means uploads only, not the default.
+				require.Empty(t, reporter.results[reportKey{config.MixedDownload, "ft", "mem"}])
+				operations = operations[:3]
+			}
+			var mixedObjects int
+			for _, operation := range operations {
+				results := reporter.results[reportKey{operation, "ft", "mem"}]
+				require.Len(t, results, 1, operation.String())
+				require.True(t, results[0].Success, "%s: %s", operation, results[0].Error)
+				if operation == config.MixedUpload || operation == config.MixedDownload {
+					mixedObjects += len(results[0].ObjectDurations)
+				}
+			}
+			require.Equal(t, 20, mixedObjects)
+
+			objects, err := client.List(ctx, "", true)
+			require.NoError(t, err)
+			require.Empty(t, objects)
+		})
+	}
+}
+
+func TestRunChecksCache(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {
+				Type:       config.CacheTest,
+				NumObjects: 2,
+				Size:       1000,
+				Warmup:     1,
+				CacheDelay: config.Duration(10 * time.Millisecond),
+			},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Download, config.RepeatDownload, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Success, "%s: %s", operation, results[0].Error)
+	}
+
+	download := reporter.results[reportKey{config.Download, "ft", "mem"}][0]
+	repeated := reporter.results[reportKey{config.RepeatDownload, "ft", "mem"}][0]
+	require.True(t, repeated.StartTime.Sub(download.StartTime) >= 10*time.Millisecond)
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+func TestRunChecksContent(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	require.NoError(t, ioutil.WriteFile(ctx.File("corpus", "a.txt"), []byte("first file"), 0644))
+	require.NoError(t, ioutil.WriteFile(ctx.File("corpus", "b.txt"), []byte("second file"), 0644))
+
+	for _, content := range []config.ContentType{config.RandomContent, config.ZeroContent, config.TextContent, config.CryptoContent, config.CorpusContent} {
+		endpoints := []*config.Endpoint{{ID: "mem", Client: newMemClient()}}
+		conf := config.Config{
+			Timeout: config.Duration(time.Minute),
+			FileTests: map[config.ID]config.FileTest{
+				"ft": {
+					NumParallel: 2,
+					Size:        10000,
+					Content:     content,
+					ContentDir:  ctx.Dir("corpus"),
+					Ranges:      []config.Range{{Offset: 100, Length: 200}},
+				},
+			},
+		}
+
+		reporter := newMemReporter()
+		c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+		require.NoError(t, c.RunChecks(ctx), content)
+
+		for _, operation := range []config.Operation{config.Upload, config.Download, config.RangeDownload} {
+			results := reporter.results[reportKey{operation, "ft", "mem"}]
+			require.Len(t, results, 1, "%s: %s", content, operation)
+			require.True(t, results[0].Success, "%s: %s: %s", content, operation, results[0].Error)
+		}
+	}
+}
+
+// corruptingClient is a memClient which flips the bits of every download.
+type corruptingClient struct {
+	*memClient
+}
+
+func (client corruptingClient) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	strm, err := client.memClient.Download(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(strm)
+	if err != nil {
+		return nil, err
+	}
+	for i := range data {
+		data[i] ^= 0xff
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestRunChecksVerify(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	verify := false
+	for _, fileTest := range []config.FileTest{
+		{Size: 1000},
+		{Size: 1000, Verify: &verify},
+		{Size: 1000, SeparateVerify: true},
+		{Size: 1000, Verify: &verify, SeparateVerify: true},
+	} {
+		endpoints := []*config.Endpoint{{ID: "mem", Client: corruptingClient{newMemClient()}}}
+		conf := config.Config{
+			Timeout:   config.Duration(time.Minute),
+			FileTests: map[config.ID]config.FileTest{"ft": fileTest},
+		}
+
+		reporter := newMemReporter()
+		c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+		require.NoError(t, c.RunChecks(ctx))
+
+		download := reporter.results[reportKey{config.Download, "ft", "mem"}]
+		require.Len(t, download, 1)
+		require.Equal(t, !fileTest.Verifies(), download[0].Success, download[0].Error)
+		if fileTest.Verifies() {
+			require.Equal(t, config.ChecksumError, download[0].ErrorCategory)
+			// Failed verifications are failed attempts, however they run.
+			require.Len(t, download[0].Attempts, 1)
+			require.Equal(t, download[0].Error, download[0].Attempts[0].Error)
+		}
+	}
+}
+
+func TestRunChecksChecksum(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	for _, checksum := range []config.Checksum{config.SHA256Checksum, config.XXHashChecksum, config.CRC32CChecksum, config.BLAKE3Checksum} {
+		client := newMemClient()
+		endpoints := []*config.Endpoint{
+			{ID: "mem", Client: client},
+			{ID: "corrupt", Client: corruptingClient{newMemClient()}},
+		}
+		conf := config.Config{
+			Timeout: config.Duration(time.Minute),
+			FileTests: map[config.ID]config.FileTest{
+				"ft": {
+					Size:     10000,
+					Checksum: checksum,
+					Ranges:   []config.Range{{Offset: 100, Length: 200}},
+				},
+			},
+		}
+
+		reporter := newMemReporter()
+		c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+		require.NoError(t, c.RunChecks(ctx), checksum)
+
+		for _, operation := range []config.Operation{config.Upload, config.Download, config.RangeDownload} {
+			results := reporter.results[reportKey{operation, "ft", "mem"}]
+			require.Len(t, results, 1, "%s: %s", checksum, operation)
+			require.True(t, results[0].Success, "%s: %s: %s", checksum, operation, results[0].Error)
+		}
+
+		download := reporter.results[reportKey{config.Download, "ft", "corrupt"}]
+		require.Len(t, download, 1, checksum)
+		require.False(t, download[0].Success, checksum)
+		require.Contains(t, download[0].Error, string(checksum)+" digest")
+	}
+}
+
+// nodeStatsClient is a memClient whose downloads report fixed node stats.
+type nodeStatsClient struct {
+	*memClient
+}
+
+func (client nodeStatsClient) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	strm, err := client.memClient.Download(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return nodeStatsStream{ReadCloser: strm}, nil
+}
+
+type nodeStatsStream struct {
+	io.ReadCloser
+}
+
+func (nodeStatsStream) NodeStats() cli.NodeStats {
+	return cli.NodeStats{Nodes: 39, Failed: 1, Cancelled: 10, Bytes: 2900}
+}
+
+func TestRunChecksNodeStats(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{{ID: "mem", Client: nodeStatsClient{newMemClient()}}}
+	conf := config.Config{
+		Timeout:   config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{"ft": {Size: 1000, NumObjects: 2}},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	download := reporter.results[reportKey{config.Download, "ft", "mem"}]
+	require.Len(t, download, 1)
+	require.Equal(t, &cli.NodeStats{Nodes: 78, Failed: 2, Cancelled: 20, Bytes: 5800}, download[0].NodeStats)
+
+	upload := reporter.results[reportKey{config.Upload, "ft", "mem"}]
+	require.Len(t, upload, 1)
+	require.Nil(t, upload[0].NodeStats)
+}
+
+// versionedClient is a memClient which keeps every uploaded version of
+// its objects.
+type versionedClient struct {
+	*memClient
+	versions map[string][]string
+	next     int
+}
+
+func newVersionedClient() *versionedClient {
+	return &versionedClient{memClient: newMemClient(), versions: make(map[string][]string)}
+}
+
+func (client *versionedClient) UploadVersion(ctx context.Context, name string, strm io.Reader) (string, error) {
+	if err := client.Upload(ctx, name, strm); err != nil {
+		return "", err
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.next++
+	versionID := strconv.Itoa(client.next)
+	client.versions[name] = append(client.versions[name], versionID)
+	return versionID, nil
+}
+
+func (client *versionedClient) ListVersions(ctx context.Context, name string) ([]string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return append([]string(nil), client.versions[name]...), nil
+}
+
+func (client *versionedClient) DeleteVersion(ctx context.Context, name, versionID string) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	for i, id := range client.versions[name] {
+		if id == versionID {
+			client.versions[name] = append(client.versions[name][:i], client.versions[name][i+1:]...)
+			if len(client.versions[name]) == 0 {
+				delete(client.versions, name)
+				delete(client.objects, name)
+			}
+			return nil
+		}
+	}
+	return errs.New("no version %q of %q", versionID, name)
+}
+
+func TestRunChecksVersioning(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newVersionedClient()
+	endpoints := []*config.Endpoint{
+		{ID: "versioned", Client: client},
+		{ID: "mem", Client: newMemClient()},
+	}
+	conf := config.Config{
+		Timeout:   config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{"ft": {Size: 1000, NumObjects: 2, Versions: 3}},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for operation, count := range map[config.Operation]int{config.PutVersion: 6, config.ListVersions: 2, config.DeleteVersion: 6} {
+		results := reporter.results[reportKey{operation, "ft", "versioned"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Success, results[0].Error)
+		require.Len(t, results[0].ObjectDurations, count, operation.String())
+
+		results = reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Unsupported, operation.String())
+	}
+
+	require.Empty(t, client.versions)
+	require.Empty(t, client.objects)
+}
+
+// cancelingVersionedClient is a versionedClient which cancels the checks
+// when they list versions, and whose calls fail once their context is
+// canceled.
+type cancelingVersionedClient struct {
+	*versionedClient
+	cancel context.CancelFunc
+}
+
+func (client cancelingVersionedClient) ListVersions(ctx context.Context, name string) ([]string, error) {
+	client.cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return client.versionedClient.ListVersions(ctx, name)
+}
+
+func (client cancelingVersionedClient) DeleteVersion(ctx context.Context, name, versionID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return client.versionedClient.DeleteVersion(ctx, name, versionID)
+}
+
+func TestRunChecksVersioningCanceled(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	checkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	client := newVersionedClient()
+	endpoints := []*config.Endpoint{{ID: "versioned", Client: cancelingVersionedClient{client, cancel}}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 2, Versions: 2, Operations: []string{"put_version", "list_versions"}},
+		},
+	}
+
+	c := checker.NewChecker(zaptest.NewLogger(t), newMemReporter(), endpoints, conf)
+	_ = c.RunChecks(checkCtx)
+
+	// The versions are deleted although the check was canceled.
+	require.Empty(t, client.versions)
+}
+
+// metadataClient is a memClient which stores the custom metadata of its
+// objects, but can't set it after their upload, like storj.
+type metadataClient struct {
+	*memClient
+	metadata map[string]map[string]string
+}
+
+func (client *metadataClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	if err := client.memClient.Upload(ctx, name, strm); err != nil {
+		return err
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.metadata[name] = cli.Metadata(ctx)
+	return nil
+}
+
+func (client *metadataClient) GetMetadata(ctx context.Context, name string) (map[string]string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.metadata[name], nil
+}
+
+func (client *metadataClient) SetMetadata(ctx context.Context, name string, metadata map[string]string) error {
+	return cli.ErrUnsupported.New("set metadata")
+}
+
+func TestRunChecksMetadata(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := &metadataClient{memClient: newMemClient(), metadata: make(map[string]map[string]string)}
+	endpoints := []*config.Endpoint{
+		{ID: "metadata", Client: client},
+		{ID: "mem", Client: newMemClient()},
+	}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 2, Metadata: map[string]string{"team": "perf"}},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	results := reporter.results[reportKey{config.GetMetadata, "ft", "metadata"}]
+	require.Len(t, results, 1)
+	require.True(t, results[0].Success, results[0].Error)
+	require.Len(t, results[0].ObjectDurations, 2)
+	require.True(t, reporter.results[reportKey{config.SetMetadata, "ft", "metadata"}][0].Unsupported)
+
+	for _, operation := range []config.Operation{config.GetMetadata, config.SetMetadata} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Unsupported, operation.String())
+	}
+}
+
+// laggingClient is a memClient whose stats only find objects lag after
+// their upload, like an eventually consistent backend.
+type laggingClient struct {
+	*memClient
+	lag      time.Duration
+	uploaded map[string]time.Time
+}
+
+func (client *laggingClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	if err := client.memClient.Upload(ctx, name, strm); err != nil {
+		return err
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	client.uploaded[name] = time.Now()
+	return nil
+}
+
+func (client *laggingClient) Stat(ctx context.Context, name string) (*cli.ListObject, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	if _, ok := client.objects[name]; !ok || time.Since(client.uploaded[name]) < client.lag {
+		return nil, nil
+	}
+	return &cli.ListObject{Key: name}, nil
+}
+
+func TestRunChecksConsistency(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := &laggingClient{memClient: newMemClient(), lag: 20 * time.Millisecond, uploaded: make(map[string]time.Time)}
+	endpoints := []*config.Endpoint{
+		{ID: "lagging", Client: client},
+		{ID: "mem", Client: newMemClient()},
+	}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Type: config.ConsistencyTest, Size: 1000, NumObjects: 2, PollInterval: config.Duration(time.Millisecond)},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.ReadAfterWrite, config.ReadAfterDelete, config.ListAfterWrite, config.ListAfterDelete} {
+		results := reporter.results[reportKey{operation, "ft", "lagging"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Success, results[0].Error)
+		require.Len(t, results[0].Latencies, 2, operation.String())
+	}
+	for _, delay := range reporter.results[reportKey{config.ReadAfterWrite, "ft", "lagging"}][0].Latencies {
+		require.GreaterOrEqual(t, int64(delay), int64(client.lag))
+	}
+
+	require.True(t, reporter.results[reportKey{config.ReadAfterWrite, "ft", "mem"}][0].Unsupported)
+	require.True(t, reporter.results[reportKey{config.ReadAfterDelete, "ft", "mem"}][0].Unsupported)
+	require.True(t, reporter.results[reportKey{config.ListAfterWrite, "ft", "mem"}][0].Success)
+	require.True(t, reporter.results[reportKey{config.ListAfterDelete, "ft", "mem"}][0].Success)
+	require.Empty(t, client.objects)
+}
+
+func TestRunChecksConflict(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{
+		{ID: "mem", Client: client},
+		{ID: "corrupting", Client: corruptingClient{newMemClient()}},
+	}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Type: config.ConflictTest, Size: 1000, NumObjects: 2, NumParallel: 3},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	write := reporter.results[reportKey{config.ConflictWrite, "ft", "mem"}]
+	require.Len(t, write, 1)
+	require.True(t, write[0].Success, write[0].Error)
+	require.Len(t, write[0].ObjectDurations, 6)
+
+	verify := reporter.results[reportKey{config.ConflictVerify, "ft", "mem"}]
+	require.Len(t, verify, 1)
+	require.True(t, verify[0].Success, verify[0].Error)
+	require.Len(t, verify[0].Conflicts, 2)
+	for i, conflict := range verify[0].Conflicts {
+		require.Equal(t, i, conflict.Object)
+		require.True(t, conflict.Winner >= 0 && conflict.Winner < 3, conflict.Winner)
+	}
+	require.True(t, reporter.results[reportKey{config.Delete, "ft", "mem"}][0].Success)
+	require.Empty(t, client.objects)
+
+	verify = reporter.results[reportKey{config.ConflictVerify, "ft", "corrupting"}]
+	require.Len(t, verify, 1)
+	require.False(t, verify[0].Success)
+	require.Equal(t, config.ChecksumError, verify[0].ErrorCategory)
+	// Every object is verified, even after the first corrupted one.
+	require.Len(t, verify[0].Conflicts, 2)
+	for _, conflict := range verify[0].Conflicts {
+		require.Equal(t, -1, conflict.Winner)
+	}
+	require.Equal(t, []int{0, 1}, verify[0].FailedObjects)
+	require.Contains(t, verify[0].Error, "2 of 2")
+}
+
+// pagingClient is a memClient which lists in pages of the requested size.
+type pagingClient struct {
+	*memClient
+}
+
+func (client pagingClient) ListPages(ctx context.Context, prefix string, recursive bool, pageSize, maxKeys int) ([]*cli.ListObject, int, error) {
+	objs, err := client.List(ctx, prefix, recursive)
+	if maxKeys > 0 && len(objs) > maxKeys {
+		objs = objs[:maxKeys]
+	}
+	if pageSize <= 0 {
+		pageSize = 1000
+	}
+	return objs, (len(objs) + pageSize - 1) / pageSize, err
+}
+
+func TestRunChecksListing(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := pagingClient{newMemClient()}
+	endpoints := []*config.Endpoint{
+		{ID: "paging", Client: client},
+		{ID: "mem", Client: newMemClient()},
+	}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Type: config.ListingTest, Size: 10, NumObjects: 20, NumParallel: 4, NumPrefixes: 4, KeyDistribution: config.SequentialKeys, PageSizes: []int64{3, 100}},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.ListRecursive, config.ListWalk, config.Delete} {
+		for _, endpoint := range endpoints {
+			results := reporter.results[reportKey{operation, "ft", endpoint.ID}]
+			require.Len(t, results, 1, operation.String())
+			require.True(t, results[0].Success, results[0].Error)
+		}
+	}
+
+	recursive := reporter.results[reportKey{config.ListRecursive, "ft", "paging"}][0].Listings
+	require.Len(t, recursive, 2)
+	require.Equal(t, []int64{3, 100}, []int64{recursive[0].PageSize, recursive[1].PageSize})
+	require.Equal(t, []int64{7, 1}, []int64{recursive[0].Pages, recursive[1].Pages})
+	require.Equal(t, []int64{20, 20}, []int64{recursive[0].Objects, recursive[1].Objects})
+
+	// The walk lists the 4 prefixes and then the 5 objects in each of them.
+	walk := reporter.results[reportKey{config.ListWalk, "ft", "paging"}][0].Listings
+	require.Len(t, walk, 2)
+	require.Equal(t, []int64{10, 5}, []int64{walk[0].Pages, walk[1].Pages})
+	require.Equal(t, []int64{20, 20}, []int64{walk[0].Objects, walk[1].Objects})
+
+	// Clients which can't choose the page size list once with the default.
+	unpaged := reporter.results[reportKey{config.ListWalk, "ft", "mem"}][0].Listings
+	require.Equal(t, []config.Listing{{Objects: 20, Duration: unpaged[0].Duration}}, unpaged)
+	require.Empty(t, client.objects)
+}
+
+func TestRunChecksBucket(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Type: config.BucketTest, NumObjects: 3, NumParallel: 2, Iterations: 2},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	// Listing is a single call, the other operations are timed per bucket.
+	for operation, count := range map[config.Operation]int{config.CreateBucket: 3, config.ListBuckets: 0, config.DeleteBucket: 3} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 2, operation.String())
+		for _, result := range results {
+			require.True(t, result.Success, "%s: %s", operation, result.Error)
+			require.Len(t, result.ObjectDurations, count, operation.String())
+		}
+	}
+
+	require.Empty(t, client.buckets)
+}
+
+// failingClient is a memClient whose uploads fail after leaving a partial
+// object behind.
+type failingClient struct {
+	*memClient
+}
+
+func (client failingClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	if err := client.memClient.Upload(ctx, name, io.LimitReader(strm, 10)); err != nil {
+		return err
+	}
+	return errs.New("upload failed")
+}
+
+func TestRunChecksFailedUpload(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	failing := failingClient{newMemClient()}
+	endpoints := []*config.Endpoint{
+		{ID: "failing", Client: failing},
+		{ID: "mem", Client: newMemClient()},
+	}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	upload := reporter.results[reportKey{config.Upload, "ft", "failing"}]
+	require.Len(t, upload, 1)
+	require.False(t, upload[0].Success)
+	require.Empty(t, reporter.results[reportKey{config.Download, "ft", "failing"}])
+	// The partial upload is deleted without reporting a delete.
+	require.Empty(t, reporter.results[reportKey{config.Delete, "ft", "failing"}])
+	objects, err := failing.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Empty(t, objects)
+
+	for _, operation := range []config.Operation{config.Upload, config.Download, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Success, "%s: %s", operation, results[0].Error)
+	}
+}
+
+// flakyClient is a memClient whose second upload fails.
+type flakyClient struct {
+	*memClient
+	uploads *int32
+}
+
+func (client flakyClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	if atomic.AddInt32(client.uploads, 1) == 2 {
+		return errs.New("upload failed")
+	}
+	return client.memClient.Upload(ctx, name, strm)
+}
+
+func TestRunChecksPartialFailure(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{{ID: "flaky", Client: flakyClient{newMemClient(), new(int32)}}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 4, Operations: []string{"upload"}},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	// The pool stops handing out objects after the failed one.
+	upload := reporter.results[reportKey{config.Upload, "ft", "flaky"}]
+	require.Len(t, upload, 1)
+	require.False(t, upload[0].Success)
+	require.Equal(t, 1, upload[0].Succeeded)
+	require.Equal(t, []int{1}, upload[0].FailedObjects)
+	require.EqualValues(t, 1000, upload[0].Bytes)
+}
+
+// stallingClient is a memClient whose downloads stall until they are
+// cancelled, when they fail like a closed connection.
+type stallingClient struct {
+	*memClient
+}
+
+func (client stallingClient) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(stallingReader{ctx}), nil
+}
+
+type stallingReader struct {
+	ctx context.Context
+}
+
+func (r stallingReader) Read(p []byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, errs.New("read tcp: use of closed network connection")
+}
+
+func TestRunChecksTimeout(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{{ID: "stalling", Client: stallingClient{newMemClient()}}}
+	conf := config.Config{
+		Timeout: config.Duration(200 * time.Millisecond),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 2, NumParallel: 2},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	// The download is aborted at its deadline and fails with a timeout.
+	download := reporter.results[reportKey{config.Download, "ft", "stalling"}]
+	require.Len(t, download, 1)
+	require.False(t, download[0].Success)
+	require.Equal(t, config.TimeoutError, download[0].ErrorCategory)
+	require.Contains(t, download[0].Error, "use of closed network connection")
+	require.Less(t, int64(download[0].Duration), int64(5*time.Second))
+
+	require.True(t, reporter.results[reportKey{config.Upload, "ft", "stalling"}][0].Success)
+	require.True(t, reporter.results[reportKey{config.Delete, "ft", "stalling"}][0].Success)
+}
+
+// expiringClient is a memClient which records the TTLs of its uploads.
+type expiringClient struct {
+	*memClient
+	mu   sync.Mutex
+	ttls []time.Duration
+}
+
+func (client *expiringClient) PrepareExpiration(ctx context.Context, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (client *expiringClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	client.mu.Lock()
+	client.ttls = append(client.ttls, cli.TTL(ctx))
+	client.mu.Unlock()
+	return client.memClient.Upload(ctx, name, strm)
+}
+
+func TestRunChecksTTL(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := &expiringClient{memClient: newMemClient()}
+	endpoints := []*config.Endpoint{{ID: "expiring", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 2, TTL: config.Duration(time.Hour)},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	require.Equal(t, []time.Duration{time.Hour, time.Hour}, client.ttls)
+	expires, err := cli.PrepareExpiration(ctx, client, time.Hour)
+	require.NoError(t, err)
+	require.True(t, expires)
+	expires, err = cli.PrepareExpiration(ctx, newMemClient(), time.Hour)
+	require.NoError(t, err)
+	require.False(t, expires)
+}
+
+// slowWarmupClient is a memClient whose first upload, that of the
+// warmup, is slow.
+type slowWarmupClient struct {
+	*memClient
+	uploads *int32
+}
+
+func (client slowWarmupClient) Upload(ctx context.Context, name string, strm io.Reader) error {
+	if atomic.AddInt32(client.uploads, 1) == 1 {
+		time.Sleep(300 * time.Millisecond)
+	}
+	return client.memClient.Upload(ctx, name, strm)
+}
+
+func TestRunChecksSimultaneous(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{
+		{ID: "slow", Client: slowWarmupClient{newMemClient(), new(int32)}},
+		{ID: "mem", Client: newMemClient()},
+		{ID: "failing", Client: failingClient{newMemClient()}},
+	}
+	conf := config.Config{
+		Timeout:      config.Duration(time.Minute),
+		Simultaneous: true,
+		FileTests: map[config.ID]config.FileTest{
+			"ft1": {Size: 1000, Warmup: 1},
+			"ft2": {Size: 1000},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.Equal(t, "simultaneous", c.Mode())
+	require.NoError(t, c.RunChecks(ctx))
+
+	// The measured uploads start together after the slow warmup, and the
+	// failing endpoint doesn't hold them back.
+	slow := reporter.results[reportKey{config.Upload, "ft1", "slow"}]
+	mem := reporter.results[reportKey{config.Upload, "ft1", "mem"}]
+	require.Len(t, slow, 1)
+	require.Len(t, mem, 1)
+	require.True(t, slow[0].Success, slow[0].Error)
+	require.True(t, mem[0].Success, mem[0].Error)
+	difference := slow[0].StartTime.Sub(mem[0].StartTime)
+	require.True(t, difference < 150*time.Millisecond && difference > -150*time.Millisecond, difference.String())
+
+	// File tests run one after another.
+	next := reporter.results[reportKey{config.Upload, "ft2", "mem"}]
+	require.Len(t, next, 1)
+	require.True(t, next[0].StartTime.After(slow[0].StartTime))
+
+	failed := reporter.results[reportKey{config.Upload, "ft1", "failing"}]
+	require.Len(t, failed, 1)
+	require.False(t, failed[0].Success)
+}
+
+func TestRunChecksShuffle(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{
+		{ID: "mem1", Client: newMemClient()},
+		{ID: "mem2", Client: newMemClient()},
+	}
+	run := func(seed int64) (*checker.Checker, *memReporter) {
+		conf := config.Config{
+			Timeout:     config.Duration(time.Minute),
+			Shuffle:     true,
+			ShuffleSeed: seed,
+			FileTests: map[config.ID]config.FileTest{
+				"ft1": {Size: 1000, Iterations: 3, Operations: []string{"upload"}},
+				"ft2": {Size: 1000, Iterations: 3, Operations: []string{"upload"}},
+			},
+		}
+		reporter := newMemReporter()
+		c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+		require.NoError(t, c.RunChecks(ctx))
+		return c, reporter
+	}
+
+	// Every iteration runs as a check of its own.
+	c, first := run(0)
+	require.NotZero(t, c.ShuffleSeed())
+	require.Equal(t, "sequential, shuffled", c.Mode())
+	require.Len(t, first.order, 12)
+	for _, fileTestID := range []config.ID{"ft1", "ft2"} {
+		for _, endpoint := range endpoints {
+			require.Len(t, first.results[reportKey{config.Upload, fileTestID, endpoint.ID}], 3)
+		}
+	}
+
+	// The seed of a run repeats its order.
+	_, again := run(c.ShuffleSeed())
+	require.Equal(t, first.order, again.order)
+}
+
+func TestRunChecksCooldown(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{
+		{ID: "mem1", Client: newMemClient()},
+		{ID: "mem2", Client: newMemClient()},
+	}
+	conf := config.Config{
+		Timeout:  config.Duration(time.Minute),
+		Cooldown: config.Duration(100 * time.Millisecond),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, Cooldown: config.Duration(50 * time.Millisecond), Operations: []string{"upload", "delete"}},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	end := func(result *config.Result) time.Time { return result.StartTime.Add(result.Duration) }
+	var checks [][2]*config.Result
+	for _, endpoint := range endpoints {
+		upload := reporter.results[reportKey{config.Upload, "ft", endpoint.ID}]
+		del := reporter.results[reportKey{config.Delete, "ft", endpoint.ID}]
+		require.Len(t, upload, 1)
+		require.Len(t, del, 1)
+
+		// Operations pause for the cooldown of the file test.
+		require.True(t, del[0].StartTime.Sub(end(upload[0])) >= 50*time.Millisecond)
+		checks = append(checks, [2]*config.Result{upload[0], del[0]})
+	}
+
+	// Checks pause for the cooldown of the config, and then the first
+	// operation for that of the file test.
+	first, second := checks[0], checks[1]
+	if second[0].StartTime.Before(first[0].StartTime) {
+		first, second = second, first
+	}
+	require.True(t, second[0].StartTime.Sub(end(first[1])) >= 150*time.Millisecond)
+}
+
+func TestRunChecksResources(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	endpoints := []*config.Endpoint{{ID: "mem", Client: newMemClient()}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000000, Operations: []string{"upload", "download", "delete"}},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Download, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1)
+
+		resources := results[0].Resources
+		require.NotNil(t, resources, operation)
+		require.True(t, resources.PeakHeap > 0, operation)
+		require.True(t, resources.CPUPercent >= 0, operation)
+		require.True(t, resources.GCCount >= 0, operation)
+		require.True(t, resources.NetworkPercent >= 0, operation)
+	}
+}
+
+func TestRunChecksResourcesExcludedFromDuration(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	// Sampling the resource usage is slower than the operations, so it
+	// mustn't be timed with them.
+	const delay = 200 * time.Millisecond
+	defer checker.SetResourceSamplingDelay(delay)()
+
+	endpoints := []*config.Endpoint{{ID: "mem", Client: newMemClient()}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, Operations: []string{"upload", "download", "delete"}},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Download, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "mem"}]
+		require.Len(t, results, 1)
+		require.NotNil(t, results[0].Resources, operation)
+		require.True(t, results[0].Duration < delay, "%s took %s", operation, results[0].Duration)
+	}
+}
+
+func TestRunChecksOperations(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+
+	run := func(operations ...string) *memReporter {
+		conf := config.Config{
+			Timeout: config.Duration(time.Minute),
+			FileTests: map[config.ID]config.FileTest{
+				"ft": {Size: 1000, Seed: 1, Operations: operations},
+			},
+		}
+		reporter := newMemReporter()
+		c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+		require.NoError(t, c.RunChecks(ctx))
+		return reporter
+	}
+
+	reporter := run("upload")
+	require.Len(t, reporter.results, 1)
+	require.Len(t, reporter.results[reportKey{config.Upload, "ft", "mem"}], 1)
+	require.Contains(t, client.objects, "ft0")
+
+	// The downloads are verified against the objects left by the upload.
+	reporter = run("download")
+	require.Len(t, reporter.results, 1)
+	results := reporter.results[reportKey{config.Download, "ft", "mem"}]
+	require.Len(t, results, 1)
+	require.True(t, results[0].Success, results[0].Error)
+	require.Contains(t, client.objects, "ft0")
+}
+
+func TestRunChecksExisting(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	client.objects["data/a"] = []byte("first object")
+	client.objects["data/sub/b"] = []byte("second object")
+	client.objects["other"] = []byte("not part of the data set")
+
+	digestA := sha256.Sum256(client.objects["data/a"])
+	digestB := sha256.Sum256([]byte("changed object"))
+	manifest := fmt.Sprintf("%x  a\n%x *sub/b\n", digestA, digestB)
+	require.NoError(t, ioutil.WriteFile(ctx.File("manifest"), []byte(manifest), 0644))
+
+	// Paged lists of the first objects stop after them. The objects have
+	// different sizes, so the file tests don't check them.
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}, {ID: "paging", Client: pagingClient{client}}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"all":      {Type: config.ExistingTest, NumParallel: 2, Prefix: "data/"},
+			"first":    {Type: config.ExistingTest, NumObjects: 1, Prefix: "data", Manifest: ctx.File("manifest")},
+			"verified": {Type: config.ExistingTest, Prefix: "data", Manifest: ctx.File("manifest")},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	require.Len(t, reporter.results, 6)
+	for _, endpoint := range endpoints {
+		for _, fileTestID := range []config.ID{"all", "first"} {
+			results := reporter.results[reportKey{config.Download, fileTestID, endpoint.ID}]
+			require.Len(t, results, 1, fileTestID)
+			require.True(t, results[0].Success, "%s: %s", fileTestID, results[0].Error)
+		}
+		require.Len(t, reporter.results[reportKey{config.Download, "all", endpoint.ID}][0].ObjectDurations, 2)
+		require.Len(t, reporter.results[reportKey{config.Download, "first", endpoint.ID}][0].ObjectDurations, 1)
+
+		results := reporter.results[reportKey{config.Download, "verified", endpoint.ID}]
+		require.Len(t, results, 1)
+		require.False(t, results[0].Success)
+		require.Contains(t, results[0].Error, "data/sub/b")
+	}
+
+	require.Len(t, client.objects, 3)
+
+	err := c.RunCheck(ctx, "sized", config.FileTest{Type: config.ExistingTest, Size: 13, Iterations: 1, Prefix: "data"}, endpoints[0])
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "12 bytes")
+}
+
+// addressedClient is a memClient which reports a network address.
+type addressedClient struct {
+	*memClient
+	address string
+}
+
+func (client addressedClient) NetworkAddress() (string, bool, error) {
+	return client.address, true, nil
+}
+
+func TestMeasureNetwork(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	endpoints := []*config.Endpoint{
+		{ID: "tls", Client: addressedClient{newMemClient(), server.Listener.Addr().String()}},
+		{ID: "mem", Client: newMemClient()},
+	}
+	c := checker.NewChecker(zaptest.NewLogger(t), newMemReporter(), endpoints, config.Config{})
+
+	network := c.MeasureNetwork(ctx, loopbackLocator{})
+	require.Len(t, network, 1)
+	timings := network["tls"]
+	require.Empty(t, timings.Error)
+	require.Equal(t, server.Listener.Addr().String(), timings.Address)
+	require.Equal(t, []config.IPLocation{{IP: "127.0.0.1", Location: "loopback"}}, timings.IPs)
+	require.NotZero(t, timings.Connect)
+	require.NotZero(t, timings.TLSHandshake)
+}
+
+// loopbackLocator locates loopback IPs.
+type loopbackLocator struct{}
+
+func (loopbackLocator) Locate(ip net.IP) (string, error) {
+	if ip.IsLoopback() {
+		return "loopback", nil
+	}
+	return "", nil
+}
+
+// readOnlyClient is a memClient which only supports downloads.
+type readOnlyClient struct {
+	*memClient
+}
+
+func (client readOnlyClient) ReadOnly() bool { return true }
+
+func TestRunChecksReadOnly(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := readOnlyClient{newMemClient()}
+	client.objects["ft0"] = make([]byte, 1000)
+
+	endpoints := []*config.Endpoint{{ID: "ro", Client: client}}
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000},
+		},
+	}
+
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	for _, operation := range []config.Operation{config.Upload, config.Delete} {
+		results := reporter.results[reportKey{operation, "ft", "ro"}]
+		require.Len(t, results, 1, operation.String())
+		require.True(t, results[0].Unsupported, operation.String())
+	}
+
+	download := reporter.results[reportKey{config.Download, "ft", "ro"}]
+	require.Len(t, download, 1)
+	require.True(t, download[0].Success, download[0].Error)
+}
+
+func TestCleanup(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := newMemClient()
+	for _, name := range []string{"ft0", "ft12", "ftx", "other0"} {
+		client.objects[name] = []byte("data")
+	}
+	endpoint := &config.Endpoint{ID: "mem", Client: client}
+
+	// Objects without a run ID are only found if asked for.
+	names, err := checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, "", false, 0, true)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	names, err = checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, "", true, 0, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ft0", "ft12"}, names)
+	require.Len(t, client.objects, 4)
+
+	// The mem client doesn't list modification times, so it keeps them.
+	names, err = checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, "", true, time.Hour, true)
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	names, err = checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, "", true, 0, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ft0", "ft12"}, names)
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+}
+
+// agedClient is a memClient which lists the modification times of objects.
+type agedClient struct {
+	*memClient
+	modified map[string]time.Time
+}
+
+func (client agedClient) List(ctx context.Context, prefix string, recursive bool) ([]*cli.ListObject, error) {
+	objs, err := client.memClient.List(ctx, prefix, recursive)
+	for _, obj := range objs {
+		obj.LastModified = client.modified[obj.Key]
+	}
+	return objs, err
+}
+
+func TestCleanupOlderThan(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	client := agedClient{memClient: newMemClient(), modified: map[string]time.Time{
+		"ft0": time.Now().Add(-2 * time.Hour),
+		"ft1": time.Now(),
+	}}
+	for _, name := range []string{"ft0", "ft1", "ft2"} {
+		client.objects[name] = []byte("data")
+	}
+	endpoint := &config.Endpoint{ID: "aged", Client: client}
+
+	names, err := checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, "", true, time.Hour, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ft0"}, names)
+	require.Len(t, client.objects, 2)
+}
+
+func TestCleanupRunPrefix(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	const run1, run2 = "0c6f4f3e-4a5b-4c1d-9e2f-3a4b5c6d7e8f", "5d8a1b2c-3d4e-4f5a-8b6c-7d8e9f0a1b2c"
+
+	client := newMemClient()
+	for _, name := range []string{"ft0", run1 + "/ft0", run1 + "/ft1", run2 + "/ft0", "data/ft0"} {
+		client.objects[name] = []byte("data")
+	}
+	endpoint := &config.Endpoint{ID: "mem", Client: client}
+
+	names, err := checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, "", false, 0, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{run1 + "/ft0", run1 + "/ft1", run2 + "/ft0"}, names)
+
+	names, err = checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, "", true, 0, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{run1 + "/ft0", run1 + "/ft1", run2 + "/ft0", "ft0"}, names)
+
+	names, err = checker.Cleanup(ctx, endpoint, map[config.ID]config.FileTest{"ft": {}}, run1, false, 0, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{run1 + "/ft0", run1 + "/ft1"}, names)
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+}
+
+func TestCleanupNames(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	const run = "0c6f4f3e-4a5b-4c1d-9e2f-3a4b5c6d7e8f"
+	fileTests := map[config.ID]config.FileTest{
+		"hashed":    {Size: 1000, NumObjects: 3, KeyDistribution: config.HashedKeys, Prefix: "bench"},
+		"templated": {Size: 1000, NumObjects: 3, NameTemplate: "logs/{{.RunID}}/{{.FileTestID}}-{{.Index}}.bin"},
+	}
+
+	client := newMemClient()
+	var named, unnamed []string
+	for id, fileTest := range fileTests {
+		for i := 0; i < 3; i++ {
+			unnamed = append(unnamed, path.Join(fileTest.Prefix, fileTest.ObjectName(id, i)))
+		}
+		fileTest.RunID = run
+		for i := 0; i < 3; i++ {
+			named = append(named, path.Join(run, fileTest.Prefix, fileTest.ObjectName(id, i)))
+		}
+		if fileTest.NameTemplate != "" {
+			// The run ID is in the names, without run prefixes too.
+			for i := 0; i < 3; i++ {
+				named = append(named, path.Join(fileTest.Prefix, fileTest.ObjectName(id, i)))
+			}
+		}
+	}
+	for _, name := range append(named, unnamed...) {
+		client.objects[name] = []byte("data")
+	}
+	for _, name := range []string{"bench/hashed0", "hashed0", "bench/00zz/hashed0", "logs/x/templated-1.txt", "logs/not-a-run-id/templated-1.bin", "other/" + run + "/templated0", "not-a-run-id/bench/0000/hashed0"} {
+		client.objects[name] = []byte("data")
+	}
+	endpoint := &config.Endpoint{ID: "mem", Client: client}
+
+	names, err := checker.Cleanup(ctx, endpoint, fileTests, "", false, 0, true)
+	require.NoError(t, err)
+	sort.Strings(named)
+	require.Equal(t, named, names)
+
+	names, err = checker.Cleanup(ctx, endpoint, fileTests, "", true, 0, true)
+	require.NoError(t, err)
+	all := append(named, unnamed...)
+	sort.Strings(all)
+	require.Equal(t, all, names)
+}
+
+func TestRunPrefix(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	conf := config.Config{
+		Timeout:   config.Duration(time.Minute),
+		RunPrefix: true,
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 2, Operations: []string{"upload", "download"}},
+		},
+	}
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	c := checker.NewChecker(zaptest.NewLogger(t), newMemReporter(), endpoints, conf)
+	c.SetRunID("run1")
+	require.NoError(t, c.RunChecks(ctx))
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	require.Equal(t, "run1/ft0", objects[0].Key)
+	require.Equal(t, "run1/ft1", objects[1].Key)
+}
+
+func TestNameTemplate(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 2, NameTemplate: "{{.RunID}}/{{.FileTestID}}/{{.Index}}.bin", Operations: []string{"upload", "download"}},
+		},
+	}
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	c.SetRunID("run1")
+	require.NoError(t, c.RunChecks(ctx))
+
+	download := reporter.results[reportKey{config.Download, "ft", "mem"}]
+	require.Len(t, download, 1)
+	require.True(t, download[0].Success, download[0].Error)
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	require.Equal(t, "run1/ft/0.bin", objects[0].Key)
+	require.Equal(t, "run1/ft/1.bin", objects[1].Key)
+}
+
+func TestKeyDistribution(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	conf := config.Config{
+		Timeout: config.Duration(time.Minute),
+		FileTests: map[config.ID]config.FileTest{
+			"ft": {Size: 1000, NumObjects: 4, NumPrefixes: 2, KeyDistribution: config.SequentialKeys, Operations: []string{"upload", "download"}},
+		},
+	}
+
+	client := newMemClient()
+	endpoints := []*config.Endpoint{{ID: "mem", Client: client}}
+	reporter := newMemReporter()
+	c := checker.NewChecker(zaptest.NewLogger(t), reporter, endpoints, conf)
+	require.NoError(t, c.RunChecks(ctx))
+
+	download := reporter.results[reportKey{config.Download, "ft", "mem"}]
+	require.Len(t, download, 1)
+	require.True(t, download[0].Success, download[0].Error)
+
+	objects, err := client.List(ctx, "", true)
+	require.NoError(t, err)
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	require.Equal(t, []string{"0000/ft0", "0000/ft1", "0001/ft2", "0001/ft3"}, keys)
+}
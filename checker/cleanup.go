@@ -0,0 +1,122 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// cleanupTimeout limits the best-effort cleanup after a cancelled check.
+const cleanupTimeout = 30 * time.Second
+
+// Cleanup finds the objects of the file tests left behind under the
+// endpoint's path by failed or interrupted runs, and deletes them unless
+// dryRun is set. The endpoint is listed recursively and objects are found
+// by the names the file tests give them, including those of name templates
+// and key distributions. Only objects named after a run are found, those
+// put under a run ID with run prefixes or whose name template includes it,
+// and only those of runID if it is set, so that objects which merely look
+// like those of the file tests are spared. Objects named like those of the
+// file tests without a run ID, such as those of runs without run prefixes,
+// are found as well if unprefixed is set. Unless olderThan is zero, only
+// objects last modified longer than olderThan ago are found, which spares
+// the objects of runs in progress, and objects whose modification time the
+// endpoint doesn't list are kept. It returns the names of the objects found.
+func Cleanup(ctx context.Context, endpoint *config.Endpoint, fileTests map[config.ID]config.FileTest, runID string, unprefixed bool, olderThan time.Duration, dryRun bool) (names []string, err error) {
+	if len(fileTests) == 0 || backends.IsReadOnly(endpoint.Client) {
+		return nil, nil
+	}
+
+	pattern := cleanupPattern(fileTests, runID, unprefixed)
+	// Only the objects of runID are all under it, unless name templates
+	// include it or objects without a run ID are cleaned up too.
+	listPrefix := runID
+	for id, fileTest := range fileTests {
+		if unprefixed || fileTest.NamesIncludeRunID(id) {
+			listPrefix = ""
+		}
+	}
+	objects, err := endpoint.Client.List(ctx, listPrefix, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, object := range objects {
+		old := olderThan <= 0 || (!object.LastModified.IsZero() && time.Since(object.LastModified) > olderThan)
+		if !object.IsPre && old && pattern.MatchString(object.Key) {
+			names = append(names, object.Key)
+		}
+	}
+	sort.Strings(names)
+
+	if dryRun {
+		return names, nil
+	}
+	for _, name := range names {
+		if err := endpoint.Client.Delete(ctx, name); err != nil {
+			return names, err
+		}
+	}
+	return names, nil
+}
+
+// cleanupPattern returns the pattern of the names of the objects of the
+// file tests named after runID if it is set, or after any run ID, and of
+// those named without a run ID if unprefixed is set.
+func cleanupPattern(fileTests map[config.ID]config.FileTest, runID string, unprefixed bool) *regexp.Regexp {
+	runIDPattern := config.RunIDPattern
+	if runID != "" {
+		runIDPattern = regexp.QuoteMeta(runID)
+	}
+
+	var patterns []string
+	for id, fileTest := range fileTests {
+		var prefix string
+		if trimmed := strings.Trim(fileTest.Prefix, "/"); trimmed != "" {
+			prefix = regexp.QuoteMeta(trimmed) + "/"
+		}
+		named := prefix + fileTest.ObjectNamePattern(id, runIDPattern)
+		patterns = append(patterns, runIDPattern+"/"+named)
+		if fileTest.NamesIncludeRunID(id) {
+			patterns = append(patterns, named)
+		}
+		if unprefixed {
+			patterns = append(patterns, prefix+fileTest.ObjectNamePattern(id, ""))
+		}
+	}
+	sort.Strings(patterns)
+	return regexp.MustCompile(`^(` + strings.Join(patterns, "|") + `)$`)
+}
+
+// cleanupObjects makes a best-effort attempt to delete the objects of a
+// check which was cancelled or whose upload failed, using a fresh context.
+// The deletes aren't reported, since they don't measure anything.
+func (c *Checker) cleanupObjects(fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) {
+	log := c.checkLog(fileTestID, endpoint)
+	if backends.IsReadOnly(endpoint.Client) || !fileTest.Runs(config.Upload) || !fileTest.Runs(config.Delete) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	log.Info("Cleaning up objects")
+	err := runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), func(ctx context.Context, i int) error {
+		// Objects which weren't uploaded yet fail to delete on some
+		// backends, so carry on regardless.
+		_ = endpoint.Client.Delete(ctx, pathName(fileTestID, fileTest, i))
+		return nil
+	})
+	if err != nil {
+		log.Warn("Cleanup failed", zap.Error(err))
+	}
+}
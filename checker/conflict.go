@@ -0,0 +1,180 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// conflictOperations are the operations of conflict tests in the order
+// they are run.
+var conflictOperations = []config.Operation{config.ConflictWrite, config.ConflictVerify, config.Delete}
+
+// runConflictCheck writes every object from NumParallel writers at once in
+// every iteration, each writer with different contents, downloads the
+// objects to find out which writer won and deletes them again, reporting
+// the selected operations. The contents of the j-th writer of the i-th
+// object are those of the file with index i*NumParallel+j.
+func (c *Checker) runConflictCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	if backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupportedOperations(ctx, conflictOperations, fileTestID, fileTest, endpoint)
+	}
+
+	writers := int(fileTest.NumParallel)
+	expectedHashes, err := computeExpectedHashes(fileTest, int(fileTest.NumObjects)*writers)
+	if err != nil {
+		return err
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		c.checkLog(fileTestID, endpoint).Info("ConflictWrite", zap.Int64("iteration", iteration))
+
+		var lastWriters []int
+		result, writeErr := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			// Every attempt writes all objects again.
+			lastWriters = make([]int, fileTest.NumObjects)
+			return writeConflicts(ctx, fileTestID, fileTest, endpoint, lastWriters, result)
+		})
+		if err := c.reportSelected(ctx, config.ConflictWrite, fileTestID, fileTest, endpoint, result, writeErr); err != nil {
+			return err
+		}
+
+		if writeErr == nil && fileTest.Runs(config.ConflictVerify) {
+			result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+				return verifyConflicts(ctx, fileTestID, fileTest, endpoint, expectedHashes, lastWriters, result)
+			})
+			if err := c.reportSelected(ctx, config.ConflictVerify, fileTestID, fileTest, endpoint, result, err); err != nil {
+				return err
+			}
+		}
+
+		// The objects are deleted even if deletes aren't selected.
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return del(ctx, fileTestID, fileTest, endpoint, result)
+		})
+		if err := c.reportSelected(ctx, config.Delete, fileTestID, fileTest, endpoint, result, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConflicts writes every object from NumParallel writers at once,
+// timing each write, and records the writer which finished last. The
+// objects are written one after another.
+func writeConflicts(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, lastWriters []int, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	writers := int(fileTest.NumParallel)
+	write := timeCalls(len(lastWriters)*writers, int64(fileTest.Size), result, func(ctx context.Context, call int) error {
+		return endpoint.Client.Upload(ctx, pathName(fileTestID, fileTest, call/writers), throttle(ctx, fileTest, fileReader(fileTest, call)))
+	})
+
+	for i := range lastWriters {
+		var mu sync.Mutex
+		err := runPool(ctx, writers, writers, func(ctx context.Context, writer int) error {
+			if err := write(ctx, i*writers+writer); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			lastWriters[i] = writer
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyConflicts downloads every object and records which of its writers
+// won. Objects with the contents of none of their writers fail the
+// verification, once all objects are verified.
+func verifyConflicts(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, expectedHashes [][]byte, lastWriters []int, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	writers := int(fileTest.NumParallel)
+	var mu sync.Mutex
+	var conflicts []config.Conflict
+	verify := timeTransfers(fileTest, result, func(ctx context.Context, i int) error {
+		winner, err := downloadWinner(ctx, fileTest, endpoint, pathName(fileTestID, fileTest, i), expectedHashes[i*writers:(i+1)*writers])
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		conflicts = append(conflicts, config.Conflict{Object: i, Winner: winner, LastWriter: lastWriters[i]})
+		mu.Unlock()
+
+		if winner < 0 {
+			return errChecksum.New("unexpected %q/%d file contents: written by none of its %d writers", fileTestID, i, writers)
+		}
+		return nil
+	})
+	// Corrupted objects fail, but don't stop verifying the others.
+	err = runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), func(ctx context.Context, i int) error {
+		if err := verify(ctx, i); err != nil && !errChecksum.Has(err) {
+			return err
+		}
+		return nil
+	})
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Object < conflicts[j].Object })
+	result.Conflicts = conflicts
+	if err != nil {
+		return err
+	}
+
+	corrupted := 0
+	for _, conflict := range conflicts {
+		if conflict.Winner < 0 {
+			corrupted++
+		}
+	}
+	if corrupted > 0 {
+		return errChecksum.New("%d of %d %q objects written by none of their %d writers", corrupted, len(conflicts), fileTestID, writers)
+	}
+	return nil
+}
+
+// downloadWinner downloads the object and returns the index of the
+// candidate digest its contents match, or -1 if they match none.
+func downloadWinner(ctx context.Context, fileTest config.FileTest, endpoint *config.Endpoint, name string, candidates [][]byte) (winner int, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	strm, err := endpoint.Client.Download(ctx, name)
+	if err != nil {
+		return -1, err
+	}
+	defer func() { err = errs.Combine(err, strm.Close()) }()
+
+	hash := newHash(fileTest.Checksum)
+	if _, err := backends.Copy(hash, throttle(ctx, fileTest, strm)); err != nil {
+		return -1, err
+	}
+
+	digest := hash.Sum(nil)
+	for writer, candidate := range candidates {
+		if bytes.Equal(digest, candidate) {
+			return writer, nil
+		}
+	}
+	return -1, nil
+}
@@ -0,0 +1,164 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/common/sync2"
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// defaultPollInterval is the pause between the checks of consistency tests
+// without a poll interval.
+const defaultPollInterval = 10 * time.Millisecond
+
+// consistencyOperations are the operations of consistency tests in the
+// order they are run.
+var consistencyOperations = []config.Operation{config.ReadAfterWrite, config.ReadAfterDelete, config.ListAfterWrite, config.ListAfterDelete}
+
+// consistencyRound observes whether objects exist in one way, with the
+// operations reporting the delays until uploads and deletes are observed.
+type consistencyRound struct {
+	write, delete config.Operation
+	exists        func(ctx context.Context, name string) (bool, error)
+}
+
+// runConsistencyCheck uploads objects and deletes them again in every
+// iteration, first observing them through stats and then through lists,
+// and reports the delays until the changes are visible.
+func (c *Checker) runConsistencyCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	if backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupportedOperations(ctx, consistencyOperations, fileTestID, fileTest, endpoint)
+	}
+	if fileTest.PollInterval <= 0 {
+		fileTest.PollInterval = config.Duration(defaultPollInterval)
+	}
+
+	stater, canStat := endpoint.Client.(backends.Stater)
+	rounds := []consistencyRound{
+		{config.ReadAfterWrite, config.ReadAfterDelete, func(ctx context.Context, name string) (bool, error) {
+			obj, err := stater.Stat(ctx, name)
+			return obj != nil, err
+		}},
+		{config.ListAfterWrite, config.ListAfterDelete, func(ctx context.Context, name string) (bool, error) {
+			return listed(ctx, endpoint.Client, name)
+		}},
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		for _, round := range rounds {
+			if !fileTest.Runs(round.write) && !fileTest.Runs(round.delete) {
+				continue
+			}
+			if round.write == config.ReadAfterWrite && !canStat {
+				if err := c.reportUnsupportedOperations(ctx, []config.Operation{round.write, round.delete}, fileTestID, fileTest, endpoint); err != nil {
+					return err
+				}
+				continue
+			}
+
+			c.checkLog(fileTestID, endpoint).Info(round.write.String(), zap.Int64("iteration", iteration))
+			if err := c.measureConsistency(ctx, fileTestID, fileTest, endpoint, round); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// measureConsistency uploads the objects and deletes them again, measuring
+// the delays until round observes the uploads and deletes. The objects are
+// deleted even if their delete operation isn't selected.
+func (c *Checker) measureConsistency(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, round consistencyRound) error {
+	result, writeErr := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return measurePropagation(ctx, fileTest, result, func(ctx context.Context, i int) error {
+			return endpoint.Client.Upload(ctx, pathName(fileTestID, fileTest, i), throttle(ctx, fileTest, fileReader(fileTest, i)))
+		}, func(ctx context.Context, i int) (bool, error) {
+			return round.exists(ctx, pathName(fileTestID, fileTest, i))
+		})
+	})
+	if err := c.reportSelected(ctx, round.write, fileTestID, fileTest, endpoint, result, writeErr); err != nil {
+		return err
+	}
+
+	result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return measurePropagation(ctx, fileTest, result, func(ctx context.Context, i int) error {
+			return endpoint.Client.Delete(ctx, pathName(fileTestID, fileTest, i))
+		}, func(ctx context.Context, i int) (bool, error) {
+			exists, err := round.exists(ctx, pathName(fileTestID, fileTest, i))
+			return !exists, err
+		})
+	})
+	// Deletes of objects whose uploads failed measure nothing.
+	if writeErr != nil {
+		return nil
+	}
+	return c.reportSelected(ctx, round.delete, fileTestID, fileTest, endpoint, result, err)
+}
+
+// measurePropagation runs change for each of the file test's objects,
+// NumParallel at a time, and then polls observed until it reports the
+// change, recording the delays from the end of the change until then as
+// the latencies of the result.
+func measurePropagation(ctx context.Context, fileTest config.FileTest, result *config.Result, change func(ctx context.Context, i int) error, observed func(ctx context.Context, i int) (bool, error)) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	var mu sync.Mutex
+	delays := make([]time.Duration, 0, fileTest.NumObjects)
+	err = runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeObjects(fileTest, result, func(ctx context.Context, i int) error {
+		if err := change(ctx, i); err != nil {
+			return err
+		}
+		changed := time.Now()
+		for {
+			ok, err := observed(ctx, i)
+			if err != nil {
+				return err
+			}
+			if ok {
+				break
+			}
+			if !sync2.Sleep(ctx, time.Duration(fileTest.PollInterval)) {
+				return ctx.Err()
+			}
+		}
+		delay := time.Since(changed)
+
+		mu.Lock()
+		defer mu.Unlock()
+		delays = append(delays, delay)
+		return nil
+	}))
+	result.Latencies = delays
+	return err
+}
+
+// listed returns whether the list of the directory of the object includes
+// it.
+func listed(ctx context.Context, client backends.Client, name string) (bool, error) {
+	dir := path.Dir(name)
+	if dir == "." {
+		dir = ""
+	}
+	objects, err := client.List(ctx, dir, false)
+	if err != nil {
+		return false, err
+	}
+	for _, object := range objects {
+		if !object.IsPre && path.Base(object.Key) == path.Base(name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
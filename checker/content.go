@@ -0,0 +1,230 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math/bits"
+	"math/rand"
+	"path/filepath"
+	"sort"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// fileReader returns the contents of the i-th file of the file test. The
+// contents only depend on the file test and i, so that they can be generated
+// again to verify downloads.
+func fileReader(fileTest config.FileTest, i int) io.Reader {
+	seed := config.ObjectSeed(fileTest.Seed, i)
+
+	var r io.Reader
+	switch fileTest.Content {
+	case config.ZeroContent:
+		r = generatorReader{zeroGenerator{}}
+	case config.TextContent:
+		r = &textReader{rng: rand.New(rand.NewSource(seed))}
+	case config.CryptoContent:
+		r = generatorReader{newCryptoGenerator(seed)}
+	case config.CorpusContent:
+		r = &corpusReader{dir: fileTest.ContentDir, next: i}
+	default:
+		r = generatorReader{newXoshiroGenerator(seed)}
+	}
+	return io.LimitReader(r, int64(fileTest.Size))
+}
+
+// generator fills buffers with an endless, deterministic stream of bytes,
+// which continues where the previous buffer ended.
+type generator interface {
+	fill(p []byte)
+}
+
+// generatorReader reads the stream of a generator.
+type generatorReader struct {
+	generator
+}
+
+func (r generatorReader) Read(p []byte) (int, error) {
+	r.fill(p)
+	return len(p), nil
+}
+
+// zeroGenerator generates zero bytes.
+type zeroGenerator struct{}
+
+func (zeroGenerator) fill(p []byte) {
+	for i := range p {
+		p[i] = 0
+	}
+}
+
+// xoshiroGenerator generates pseudo-random bytes with xoshiro256**, which
+// fills buffers many times faster than math/rand, so that generating the
+// contents doesn't limit the throughput of fast networks.
+type xoshiroGenerator struct {
+	state [4]uint64
+
+	// buf holds the last generated word, of which the final buffered bytes
+	// weren't filled in yet.
+	buf      [8]byte
+	buffered int
+}
+
+// newXoshiroGenerator creates a xoshiroGenerator with its state expanded
+// from seed by splitmix64.
+func newXoshiroGenerator(seed int64) *xoshiroGenerator {
+	g := &xoshiroGenerator{}
+	x := uint64(seed)
+	for i := range g.state {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		g.state[i] = z ^ (z >> 31)
+	}
+	return g
+}
+
+// next returns the next word.
+func (g *xoshiroGenerator) next() uint64 {
+	s := &g.state
+	result := bits.RotateLeft64(s[1]*5, 7) * 9
+	t := s[1] << 17
+	s[2] ^= s[0]
+	s[3] ^= s[1]
+	s[1] ^= s[2]
+	s[0] ^= s[3]
+	s[2] ^= t
+	s[3] = bits.RotateLeft64(s[3], 45)
+	return result
+}
+
+func (g *xoshiroGenerator) fill(p []byte) {
+	n := copy(p, g.buf[len(g.buf)-g.buffered:])
+	g.buffered -= n
+	p = p[n:]
+
+	for len(p) >= 8 {
+		binary.LittleEndian.PutUint64(p, g.next())
+		p = p[8:]
+	}
+	if len(p) > 0 {
+		binary.LittleEndian.PutUint64(g.buf[:], g.next())
+		g.buffered = len(g.buf) - copy(p, g.buf[:])
+	}
+}
+
+// textWords is the small vocabulary of text contents, which keeps them
+// highly compressible.
+var textWords = []string{
+	"the", "quick", "brown", "fox", "jumps", "over", "lazy", "dog",
+	"storage", "object", "bucket", "upload", "download", "network",
+}
+
+// textReader reads an endless stream of lines of words.
+type textReader struct {
+	rng *rand.Rand
+	buf []byte
+}
+
+func (r *textReader) Read(p []byte) (int, error) {
+	for len(r.buf) < len(p) {
+		for word := 0; word < 12; word++ {
+			if word > 0 {
+				r.buf = append(r.buf, ' ')
+			}
+			r.buf = append(r.buf, textWords[r.rng.Intn(len(textWords))]...)
+		}
+		r.buf = append(r.buf, '\n')
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// cryptoGenerator generates an AES-CTR key stream keyed by the seed, which
+// is indistinguishable from random bytes but can be generated again.
+type cryptoGenerator struct {
+	stream cipher.Stream
+}
+
+// newCryptoGenerator creates a cryptoGenerator keyed by the seed.
+func newCryptoGenerator(seed int64) cryptoGenerator {
+	var seedBytes [8]byte
+	binary.BigEndian.PutUint64(seedBytes[:], uint64(seed))
+	key := sha256.Sum256(seedBytes[:])
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		// Only happens for invalid key sizes.
+		panic(err)
+	}
+	return cryptoGenerator{stream: cipher.NewCTR(block, make([]byte, aes.BlockSize))}
+}
+
+func (g cryptoGenerator) fill(p []byte) {
+	zeroGenerator{}.fill(p)
+	g.stream.XORKeyStream(p, p)
+}
+
+// corpusReader reads the regular files of a directory in name order, one
+// after another and starting over after the last one. The next field is the
+// index of the next file to read, modulo the number of files.
+type corpusReader struct {
+	dir   string
+	names []string
+	next  int
+	buf   []byte
+}
+
+func (r *corpusReader) Read(p []byte) (int, error) {
+	if r.names == nil {
+		names, err := corpusFiles(r.dir)
+		if err != nil {
+			return 0, err
+		}
+		r.names = names
+	}
+
+	for len(r.buf) == 0 {
+		data, err := ioutil.ReadFile(filepath.Join(r.dir, r.names[r.next%len(r.names)]))
+		if err != nil {
+			return 0, errs.Wrap(err)
+		}
+		r.buf = data
+		r.next++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// corpusFiles returns the names of the non-empty regular files of dir.
+func corpusFiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	var names []string
+	for _, info := range infos {
+		if info.Mode().IsRegular() && info.Size() > 0 {
+			names = append(names, info.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, errs.New("content dir %q has no files", dir)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/config"
+)
+
+// TestFileReaderGolden pins the generated contents, which downloads of
+// objects uploaded by earlier versions are verified against.
+func TestFileReaderGolden(t *testing.T) {
+	for _, test := range []struct {
+		content config.ContentType
+		i       int
+		digest  string
+	}{
+		{config.RandomContent, 0, "9775d9a2c848c3a77a64b74bb6f1a52a09ef1700bf231b4687e5d2b7167bb5d4"},
+		{config.RandomContent, 1, "705bea95d482e503cdf964715f94e36e823f21573ce9fade6d1a872a78047842"},
+		{config.TextContent, 0, "95a52179888ee794030c286f084395cfd5d7f17f7a4c57e69ca2472fe7ee7ff0"},
+		{config.TextContent, 1, "18dd0a5ed6b7842b2553103db8b474512662d890187c8e636aaa9643afc93d98"},
+		{config.CryptoContent, 0, "97aa61536a7e65e9c7e77f87a7cc694a6dd6ed2010aa55a69e3962d9b5816707"},
+		{config.CryptoContent, 1, "5f441802700eeded2c98c2b5a56991f4cc425b9aaf12d591f3d28662e1ef0c7e"},
+	} {
+		data, err := ioutil.ReadAll(fileReader(config.FileTest{Size: 1024, Seed: 1, Content: test.content}, test.i))
+		require.NoError(t, err)
+		require.Len(t, data, 1024)
+		digest := sha256.Sum256(data)
+		require.Equal(t, test.digest, hex.EncodeToString(digest[:]), "%s %d", test.content, test.i)
+	}
+}
+
+func TestFileReaderNearbySeeds(t *testing.T) {
+	// The second object of seed 1 isn't the first of seed 2.
+	first, err := ioutil.ReadAll(fileReader(config.FileTest{Size: 1024, Seed: 1}, 1))
+	require.NoError(t, err)
+	second, err := ioutil.ReadAll(fileReader(config.FileTest{Size: 1024, Seed: 2}, 0))
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+}
@@ -0,0 +1,43 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"storj.io/common/sync2"
+)
+
+// cooldown holds back checks until a pause passed since the last check
+// finished.
+type cooldown struct {
+	pause time.Duration
+
+	mu       sync.Mutex
+	finished time.Time
+}
+
+// wait waits until the pause passed since the last check finished.
+func (cooldown *cooldown) wait(ctx context.Context) error {
+	cooldown.mu.Lock()
+	finished := cooldown.finished
+	cooldown.mu.Unlock()
+
+	if cooldown.pause <= 0 || finished.IsZero() {
+		return nil
+	}
+	if !sync2.Sleep(ctx, time.Until(finished.Add(cooldown.pause))) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// finish records that a check finished.
+func (cooldown *cooldown) finish() {
+	cooldown.mu.Lock()
+	defer cooldown.mu.Unlock()
+	cooldown.finished = time.Now()
+}
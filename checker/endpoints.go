@@ -0,0 +1,47 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/config"
+)
+
+// NewEndpoints creates the client of every endpoint of the config, whose
+// types must be registered. The caller closes the clients once the checks
+// are done.
+func NewEndpoints(ctx context.Context, log *zap.Logger, conf config.Config) (_ []*config.Endpoint, err error) {
+	decoded, err := conf.DecodeEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*config.Endpoint, 0, len(decoded))
+	defer func() {
+		if err != nil {
+			for _, endpoint := range endpoints {
+				err = errs.Combine(err, endpoint.Client.Close())
+			}
+		}
+	}()
+
+	for _, endpoint := range decoded {
+		client, err := endpoint.Config.NewClient(ctx, log.Named(endpoint.Type+"client"))
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, &config.Endpoint{
+			ID:       endpoint.ID,
+			Bucket:   endpoint.Bucket,
+			Path:     endpoint.Path,
+			Client:   client,
+			Defaults: endpoint.Defaults,
+		})
+	}
+	return endpoints, nil
+}
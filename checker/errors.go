@@ -0,0 +1,113 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// errChecksum is the error class of downloads whose contents don't match
+// the expected ones.
+var errChecksum = errs.Class("checksum mismatch")
+
+// errSize is the error class of downloads which don't return as many bytes
+// as the object has.
+var errSize = errs.Class("size mismatch")
+
+// errTimeout is the error class of operations aborted by their deadline,
+// whose clients fail with whatever error aborting them caused, such as that
+// of a closed connection.
+var errTimeout = errs.Class("timeout")
+
+// timedOut returns err as a timeout if the deadline of ctx passed.
+func timedOut(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) || classifyError(err) == config.TimeoutError {
+		return err
+	}
+	return errTimeout.Wrap(err)
+}
+
+// statusPattern matches the HTTP status in the error messages of the
+// clients, such as "404 Not Found" of HTTP servers, "status code: 503" of
+// S3 and "Error 403:" of GCS. Each form is anchored to what surrounds the
+// status, so that other numbers, such as sizes, don't match.
+var statusPattern = regexp.MustCompile(`(?:^|: )([45]\d\d) [A-Z][a-z]|status code:? ([45]\d\d)\b|\bError ([45]\d\d):`)
+
+// status returns the HTTP status in the message, if any.
+func status(message string) string {
+	match := statusPattern.FindStringSubmatch(message)
+	if match == nil {
+		return ""
+	}
+	for _, group := range match[1:] {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// classifyError returns the category of the error of a failed operation.
+// Most clients only keep the messages of the errors of their backends, so
+// the message is classified where the error itself doesn't tell.
+func classifyError(err error) config.ErrorCategory {
+	if err == nil {
+		return ""
+	}
+	if errChecksum.Has(err) {
+		return config.ChecksumError
+	}
+	if errSize.Has(err) {
+		return config.SizeError
+	}
+	var netErr net.Error
+	if errTimeout.Has(err) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return config.TimeoutError
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return config.ConnectionError
+	}
+
+	message := err.Error()
+	lower := strings.ToLower(message)
+	switch {
+	case containsAny(lower, "deadline exceeded", "timeout", "timed out"):
+		return config.TimeoutError
+	case containsAny(lower, "access denied", "accessdenied", "forbidden", "unauthorized", "unauthenticated", "permission denied", "invalidaccesskeyid", "signaturedoesnotmatch"):
+		return config.AuthError
+	case containsAny(lower, "connection reset", "connection refused", "broken pipe", "no such host", "unexpected eof"):
+		return config.ConnectionError
+	}
+
+	switch code := status(message); {
+	case code == "":
+	case code == "401" || code == "403":
+		return config.AuthError
+	case code[0] == '4':
+		return config.ClientError
+	default:
+		return config.ServerError
+	}
+	return config.OtherError
+}
+
+// containsAny returns whether s contains any of the substrings.
+func containsAny(s string, substrings ...string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+func TestClassifyError(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		err      error
+		category config.ErrorCategory
+	}{
+		{name: "nil", err: nil, category: ""},
+		{name: "checksum", err: errChecksum.New("unexpected digest"), category: config.ChecksumError},
+		{name: "size", err: errSize.New("expected 1024 bytes; got 512"), category: config.SizeError},
+		{name: "timeout class", err: errTimeout.Wrap(errs.New("use of closed network connection")), category: config.TimeoutError},
+		{name: "deadline", err: errs.Wrap(context.DeadlineExceeded), category: config.TimeoutError},
+		{name: "timed out message", err: errs.New("i/o timeout"), category: config.TimeoutError},
+		{name: "reset", err: errs.Wrap(syscall.ECONNRESET), category: config.ConnectionError},
+		{name: "unexpected eof", err: errs.Wrap(io.ErrUnexpectedEOF), category: config.ConnectionError},
+		{name: "refused message", err: errs.New("dial tcp: connection refused"), category: config.ConnectionError},
+		{name: "access denied", err: errs.New("AccessDenied: Access Denied"), category: config.AuthError},
+		{name: "http forbidden", err: errs.New("403 Forbidden"), category: config.AuthError},
+		{name: "http not found", err: errs.New("get obj: 404 Not Found"), category: config.ClientError},
+		{name: "http server", err: errs.New("503 Service Unavailable"), category: config.ServerError},
+		{name: "s3 status code", err: errs.New("SlowDown: please reduce your request rate\n\tstatus code: 503, request id: 1"), category: config.ServerError},
+		{name: "s3 client status code", err: errs.New("NoSuchKey: key\n\tstatus code: 404, request id: 1"), category: config.ClientError},
+		{name: "gcs error", err: errs.New("googleapi: Error 401: Invalid Credentials"), category: config.AuthError},
+		{name: "gcs client error", err: errs.New("googleapi: Error 429: rate limited"), category: config.ClientError},
+		{name: "count", err: errs.New("failed: 500 objects"), category: config.OtherError},
+		{name: "size in message", err: errs.New("upload of 404 bytes failed"), category: config.OtherError},
+		{name: "number prefix", err: errs.New("uploaded 500 of 1000"), category: config.OtherError},
+		{name: "other", err: errs.New("something went wrong"), category: config.OtherError},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.category, classifyError(test.err))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	_ "storj.io/perftester/backends/all"
+	"storj.io/perftester/checker"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+// Example runs the checks of a config and prints their report, as the
+// perftester command does.
+func Example() {
+	ctx := context.Background()
+	log := zap.NewNop()
+
+	run := func() (err error) {
+		conf, err := config.LoadConfig("config.toml")
+		if err != nil {
+			return err
+		}
+
+		endpoints, err := checker.NewEndpoints(ctx, log, conf)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			for _, endpoint := range endpoints {
+				err = errs.Combine(err, endpoint.Client.Close())
+			}
+		}()
+
+		reporter := report.NewTextReporter(conf.FileTestSizes())
+		if err := checker.NewChecker(log, reporter, endpoints, conf).RunChecks(ctx); err != nil {
+			return err
+		}
+
+		text, err := reporter.FormatResults(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Print(text)
+		return nil
+	}
+
+	if err := run(); err != nil {
+		fmt.Println(err)
+	}
+}
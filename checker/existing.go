@@ -0,0 +1,213 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// runExistingCheck downloads the objects which already exist under the file
+// test's prefix, NumParallel at a time, instead of uploading its own. With a
+// manifest, their contents are verified against its digests.
+func (c *Checker) runExistingCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	var manifest map[string][]byte
+	if fileTest.Manifest != "" {
+		var err error
+		manifest, err = readManifest(fileTest.Manifest, fileTest.Checksum)
+		if err != nil {
+			return err
+		}
+	}
+
+	names, err := existingObjects(ctx, fileTest, endpoint)
+	if err != nil {
+		return err
+	}
+	fileTest.NumObjects = int64(len(names))
+
+	expectedHashes := make([][]byte, len(names))
+	if manifest != nil {
+		prefix := strings.Trim(fileTest.Prefix, "/") + "/"
+		for i, name := range names {
+			expectedHash, ok := manifest[strings.TrimPrefix(name, prefix)]
+			if !ok {
+				return errs.New("object %q is missing from manifest %q", name, fileTest.Manifest)
+			}
+			expectedHashes[i] = expectedHash
+		}
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		log.Info("Download", zap.Int64("iteration", iteration), zap.Int("objects", len(names)))
+
+		progress := c.startProgress(ctx, config.Download, fileTestID, endpoint.ID)
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return downloadExisting(ctx, fileTest, endpoint, names, expectedHashes, progress, result)
+		})
+		progress.stop()
+		if err != nil {
+			log.Error("Download failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+		}
+
+		if err := c.reporter.Report(ctx, config.Download, fileTestID, endpoint.ID, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// existingObjects returns the names of the objects under the file test's
+// prefix in name order, limited to the first NumObjects if set. Clients
+// which page their lists stop listing after the first NumObjects, since
+// their lists are in name order as well. If the file test has a size,
+// objects whose listed size differs fail, since their throughput would be
+// misreported.
+func existingObjects(ctx context.Context, fileTest config.FileTest, endpoint *config.Endpoint) ([]string, error) {
+	prefix := strings.Trim(fileTest.Prefix, "/")
+	var objects []*backends.ListObject
+	var err error
+	if pager, ok := endpoint.Client.(backends.Pager); ok {
+		objects, _, err = pager.ListPages(ctx, prefix, true, 0, int(fileTest.NumObjects))
+	} else {
+		objects, err = endpoint.Client.List(ctx, prefix, true)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	sizes := make(map[string]int64)
+	for _, object := range objects {
+		if !object.IsPre {
+			names = append(names, object.Key)
+			sizes[object.Key] = object.Size
+		}
+	}
+	if len(names) == 0 {
+		return nil, errs.New("no objects under prefix %q", fileTest.Prefix)
+	}
+
+	sort.Strings(names)
+	if fileTest.NumObjects > 0 && int64(len(names)) > fileTest.NumObjects {
+		names = names[:fileTest.NumObjects]
+	}
+	for _, name := range names {
+		// Clients which don't list sizes leave them zero.
+		if size := sizes[name]; fileTest.Size > 0 && size != 0 && size != int64(fileTest.Size) {
+			return nil, errs.New("object %q has %d bytes instead of the file test's size of %d", name, size, fileTest.Size)
+		}
+	}
+	return names, nil
+}
+
+// downloadExisting downloads the named objects, verifying the contents of
+// those with an expected hash.
+func downloadExisting(ctx context.Context, fileTest config.FileTest, endpoint *config.Endpoint, names []string, expectedHashes [][]byte, progress *progress, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	timeline := newTimeline(result.StartTime)
+	nodeStats := new(nodeStats)
+	firstByte := make([]time.Duration, len(names))
+	err = runPool(ctx, len(names), int(fileTest.NumParallel), timeTransfers(fileTest, result, func(ctx context.Context, i int) (err error) {
+		firstByte[i], err = downloadExistingObject(ctx, fileTest, endpoint, names[i], expectedHashes[i], progress, timeline, nodeStats)
+		return err
+	}))
+	result.FirstByte = maxDuration(firstByte)
+	result.Timeline = timeline.intervals()
+	result.NodeStats = nodeStats.sum()
+	return err
+}
+
+// downloadExistingObject downloads the named object and verifies its
+// contents against the expected hash, if any, returning the time to its
+// first byte.
+func downloadExistingObject(ctx context.Context, fileTest config.FileTest, endpoint *config.Endpoint, name string, expectedHash []byte, progress *progress, timeline *timeline, nodeStats *nodeStats) (firstByte time.Duration, err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	hash := newHash(fileTest.Checksum)
+	var w io.Writer = hash
+	if expectedHash == nil {
+		w = ioutil.Discard
+	}
+
+	start := time.Now()
+	strm, err := endpoint.Client.Download(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		err = errs.Combine(err, strm.Close())
+		nodeStats.add(strm)
+	}()
+
+	r := &timedReader{Reader: timeline.wrap(progress.wrap(throttle(ctx, fileTest, strm)))}
+	if _, err := backends.Copy(w, r); err != nil {
+		return 0, err
+	}
+	if !r.firstByte.IsZero() {
+		firstByte = r.firstByte.Sub(start)
+	}
+
+	if expectedHash != nil {
+		if digest := hash.Sum(nil); !bytes.Equal(digest, expectedHash) {
+			return firstByte, errChecksum.New("unexpected %q contents: expected %s digest %x; got %x", name, fileTest.Checksum, expectedHash, digest)
+		}
+	}
+	return firstByte, nil
+}
+
+// readManifest reads the digests of the checksum from a file in the format
+// of sha256sum, keyed by name.
+func readManifest(path string, checksum config.Checksum) (_ map[string][]byte, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, file.Close()) }()
+
+	size := newHash(checksum).Size()
+	manifest := make(map[string][]byte)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		fields := strings.SplitN(text, " ", 2)
+		if len(fields) != 2 {
+			return nil, errs.New("manifest %q line %d: expected a digest and a name", path, line)
+		}
+		digest, err := hex.DecodeString(fields[0])
+		if err != nil || len(digest) != size {
+			return nil, errs.New("manifest %q line %d: invalid %s digest %q", path, line, checksum, fields[0])
+		}
+		// sha256sum marks binary files with an asterisk.
+		name := strings.TrimPrefix(strings.TrimLeft(fields[1], " "), "*")
+		manifest[name] = digest
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,20 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"runtime"
+	"time"
+)
+
+// SetResourceSamplingDelay makes reading the memory statistics of the
+// process for resource usage take delay longer, until restore is called.
+func SetResourceSamplingDelay(delay time.Duration) (restore func()) {
+	read := readMemStats
+	readMemStats = func(memStats *runtime.MemStats) {
+		time.Sleep(delay)
+		read(memStats)
+	}
+	return func() { readMemStats = read }
+}
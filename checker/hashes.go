@@ -0,0 +1,74 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"crypto/sha256"
+	"hash"
+	"hash/crc32"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/zeebo/blake3"
+
+	"storj.io/perftester/config"
+)
+
+// castagnoli is the table of CRC-32C, which crc32 computes with the CRC
+// instructions of the CPU where it can.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// newHash returns a new hash of the checksum, defaulting to SHA-256.
+func newHash(checksum config.Checksum) hash.Hash {
+	switch checksum {
+	case config.XXHashChecksum:
+		return xxhash.New()
+	case config.CRC32CChecksum:
+		return crc32.New(castagnoli)
+	case config.BLAKE3Checksum:
+		return blake3.New()
+	default:
+		return sha256.New()
+	}
+}
+
+// hashKey identifies the contents of the objects of a file test. The seed
+// tells apart the contents of runs with random seeds.
+type hashKey struct {
+	fileTestID config.ID
+	seed       int64
+}
+
+// newHashKey returns the key of the objects of the file test.
+func newHashKey(fileTestID config.ID, fileTest config.FileTest) hashKey {
+	return hashKey{fileTestID: fileTestID, seed: fileTest.Seed}
+}
+
+// hashCache keeps the digests of the objects of file tests, which are
+// computed from their deterministic contents before any download is timed,
+// so that every download check of a file test doesn't compute them again.
+type hashCache struct {
+	mu     sync.Mutex
+	hashes map[hashKey][][]byte
+}
+
+// get returns the digests of the first count objects, computing those it
+// doesn't have yet.
+func (cache *hashCache) get(key hashKey, fileTest config.FileTest, count int) ([][]byte, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if hashes := cache.hashes[key]; len(hashes) >= count {
+		return hashes[:count:count], nil
+	}
+	hashes, err := computeExpectedHashes(fileTest, count)
+	if err != nil {
+		return nil, err
+	}
+	if cache.hashes == nil {
+		cache.hashes = make(map[hashKey][][]byte)
+	}
+	cache.hashes[key] = hashes
+	return hashes, nil
+}
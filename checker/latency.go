@@ -0,0 +1,98 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// runLatencyCheck uploads, downloads and deletes NumObjects objects,
+// NumParallel at a time, recording the latency of every single operation.
+func (c *Checker) runLatencyCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	expectedHashes, err := c.expectedHashes(fileTestID, fileTest, endpoint)
+	if err != nil {
+		return err
+	}
+
+	operations := []struct {
+		operation config.Operation
+		run       func(ctx context.Context, i int) error
+	}{
+		{config.Upload, func(ctx context.Context, i int) error {
+			return endpoint.Client.Upload(ctx, pathName(fileTestID, fileTest, i), throttle(ctx, fileTest, fileReader(fileTest, i)))
+		}},
+		{config.Download, func(ctx context.Context, i int) error {
+			_, err := downloadObject(ctx, fileTestID, fileTest, endpoint, i, expectedHashes[i], nil, nil, nil)
+			return err
+		}},
+		{config.Delete, func(ctx context.Context, i int) error {
+			return endpoint.Client.Delete(ctx, pathName(fileTestID, fileTest, i))
+		}},
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		for _, op := range operations {
+			if !fileTest.Runs(op.operation) {
+				continue
+			}
+			if op.operation != config.Download && backends.IsReadOnly(endpoint.Client) {
+				if err := c.reportUnsupported(ctx, op.operation, fileTestID, endpoint); err != nil {
+					return err
+				}
+				continue
+			}
+
+			log.Info(op.operation.String(), zap.Int64("iteration", iteration))
+
+			result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+				err := measureLatencies(ctx, fileTest, op.run, result)
+				if op.operation.TransfersFile() {
+					result.Bytes = int64(len(result.Latencies)) * int64(fileTest.Size)
+				}
+				return err
+			})
+			if err != nil {
+				log.Error(op.operation.String()+" failed", zap.Error(err), zap.Int("attempts", len(result.Attempts)))
+			}
+
+			if err := c.reporter.Report(ctx, op.operation, fileTestID, endpoint.ID, result); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// measureLatencies runs op for each of the file test's objects, NumParallel
+// at a time, and records the latency of each successful call.
+func measureLatencies(ctx context.Context, fileTest config.FileTest, op func(ctx context.Context, i int) error, result *config.Result) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	var mu sync.Mutex
+	latencies := make([]time.Duration, 0, fileTest.NumObjects)
+	err := runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), func(ctx context.Context, i int) error {
+		start := time.Now()
+		if err := op(ctx, i); err != nil {
+			return err
+		}
+		latency := time.Since(start)
+
+		mu.Lock()
+		defer mu.Unlock()
+		latencies = append(latencies, latency)
+		return nil
+	})
+	result.Latencies = latencies
+	return err
+}
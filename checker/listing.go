@@ -0,0 +1,149 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// listingOperations are the operations of listing tests in the order they
+// are run.
+var listingOperations = []config.Operation{config.Upload, config.ListRecursive, config.ListWalk, config.Delete}
+
+// runListingCheck uploads the objects in every iteration, lists them in
+// full with every page size, recursively and by walking their directories,
+// and deletes them again, reporting the selected operations.
+func (c *Checker) runListingCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	if backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupportedOperations(ctx, listingOperations, fileTestID, fileTest, endpoint)
+	}
+
+	pageSizes := fileTest.PageSizes
+	if _, ok := endpoint.Client.(backends.Pager); !ok || len(pageSizes) == 0 {
+		pageSizes = []int64{0}
+	}
+	names := make(map[string]bool, fileTest.NumObjects)
+	for i := 0; i < int(fileTest.NumObjects); i++ {
+		names[pathName(fileTestID, fileTest, i)] = true
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		log.Info("Upload", zap.Int64("iteration", iteration))
+
+		progress := c.startProgress(ctx, config.Upload, fileTestID, endpoint.ID)
+		result, uploadErr := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return upload(ctx, fileTestID, fileTest, endpoint, progress, result)
+		})
+		progress.stop()
+		if err := c.reportSelected(ctx, config.Upload, fileTestID, fileTest, endpoint, result, uploadErr); err != nil {
+			return err
+		}
+
+		// Lists of objects which failed to upload would only fail as well.
+		for _, operation := range []config.Operation{config.ListRecursive, config.ListWalk} {
+			if uploadErr != nil || !fileTest.Runs(operation) {
+				continue
+			}
+			log.Info(operation.String(), zap.Int64("iteration", iteration))
+
+			recursive := operation == config.ListRecursive
+			result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+				return listObjects(ctx, fileTest, endpoint, names, recursive, pageSizes, result)
+			})
+			if err := c.reportSelected(ctx, operation, fileTestID, fileTest, endpoint, result, err); err != nil {
+				return err
+			}
+		}
+
+		// The objects are deleted even if deletes aren't selected.
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return del(ctx, fileTestID, fileTest, endpoint, result)
+		})
+		if err := c.reportSelected(ctx, config.Delete, fileTestID, fileTest, endpoint, result, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listObjects lists the objects under the file test's prefix once with
+// every page size, recording each list in the result, and checks that every
+// list found all the named objects.
+func listObjects(ctx context.Context, fileTest config.FileTest, endpoint *config.Endpoint, names map[string]bool, recursive bool, pageSizes []int64, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	root := strings.Trim(fileTest.Prefix, "/")
+	result.Listings = nil
+	for _, pageSize := range pageSizes {
+		list := func(ctx context.Context, dir string) ([]*backends.ListObject, int, error) {
+			if pager, ok := endpoint.Client.(backends.Pager); ok {
+				return pager.ListPages(ctx, dir, recursive, int(pageSize), 0)
+			}
+			objs, err := endpoint.Client.List(ctx, dir, recursive)
+			return objs, 0, err
+		}
+
+		start := time.Now()
+		found, pages, err := listAll(ctx, root, recursive, list)
+		listing := config.Listing{PageSize: pageSize, Pages: int64(pages), Duration: time.Since(start)}
+		if err != nil {
+			return timedOut(ctx, err)
+		}
+		for name := range found {
+			if names[name] {
+				listing.Objects++
+			}
+		}
+		result.Listings = append(result.Listings, listing)
+
+		if listing.Objects < int64(len(names)) {
+			return errs.New("listed %d of %d objects under %q with page size %d", listing.Objects, len(names), root, pageSize)
+		}
+	}
+	return nil
+}
+
+// listAll returns the names of all objects under root, listing it once if
+// recursive or else every directory on its own, and the number of pages
+// the lists took.
+func listAll(ctx context.Context, root string, recursive bool, list func(ctx context.Context, dir string) ([]*backends.ListObject, int, error)) (names map[string]bool, pages int, err error) {
+	names = make(map[string]bool)
+	dirs := []string{root}
+	for len(dirs) > 0 {
+		dir := dirs[0]
+		dirs = dirs[1:]
+
+		objects, dirPages, err := list(ctx, dir)
+		if err != nil {
+			return nil, pages, err
+		}
+		pages += dirPages
+		for _, object := range objects {
+			switch {
+			case object.IsPre:
+				if !recursive {
+					dirs = append(dirs, path.Join(dir, path.Base(object.Key)))
+				}
+			case recursive:
+				names[object.Key] = true
+			default:
+				names[path.Join(dir, path.Base(object.Key))] = true
+			}
+		}
+	}
+	return names, pages, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// metadataOperations are the operations of metadata checks in the order
+// they are run.
+var metadataOperations = []config.Operation{config.GetMetadata, config.SetMetadata}
+
+// defaultMetadata is the custom metadata of the objects when metadata
+// operations are selected without setting Metadata.
+var defaultMetadata = map[string]string{"perftester": "metadata"}
+
+// objectMetadata returns the custom metadata uploads attach to the objects
+// of the file test, or nil if it doesn't run metadata operations.
+func objectMetadata(fileTest config.FileTest) map[string]string {
+	if len(fileTest.Metadata) > 0 {
+		return fileTest.Metadata
+	}
+	if fileTest.Runs(config.GetMetadata) || fileTest.Runs(config.SetMetadata) {
+		return defaultMetadata
+	}
+	return nil
+}
+
+// Metadata makes a metadata check. It reads the custom metadata of every
+// object, verifying that it is the metadata the objects were uploaded with,
+// and sets it on every object again, reporting the selected operations.
+func (c *Checker) Metadata(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	metadater, ok := endpoint.Client.(backends.Metadater)
+	if !ok || backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupportedOperations(ctx, metadataOperations, fileTestID, fileTest, endpoint)
+	}
+	metadata := objectMetadata(fileTest)
+
+	for _, operation := range metadataOperations {
+		if !fileTest.Runs(operation) {
+			continue
+		}
+		operation := operation
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			if operation == config.GetMetadata {
+				return getMetadata(ctx, fileTestID, fileTest, metadater, metadata, result)
+			}
+			return setMetadata(ctx, fileTestID, fileTest, metadater, metadata, result)
+		})
+		if backends.ErrUnsupported.Has(err) {
+			if err := c.reportUnsupported(ctx, operation, fileTestID, endpoint); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.reportSelected(ctx, operation, fileTestID, fileTest, endpoint, result, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMetadata reads the custom metadata of every object, checking that it
+// has the expected metadata.
+func getMetadata(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, metadater backends.Metadater, expected map[string]string, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeObjects(fileTest, result, func(ctx context.Context, i int) error {
+		name := pathName(fileTestID, fileTest, i)
+		metadata, err := metadater.GetMetadata(ctx, name)
+		if err != nil {
+			return err
+		}
+		for key, value := range expected {
+			if got, ok := metadata[key]; !ok || got != value {
+				return errs.New("unexpected metadata %q of %q: expected %q; got %q", key, name, value, got)
+			}
+		}
+		return nil
+	}))
+}
+
+// setMetadata sets the custom metadata of every object.
+func setMetadata(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, metadater backends.Metadater, metadata map[string]string, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeObjects(fileTest, result, func(ctx context.Context, i int) error {
+		return metadater.SetMetadata(ctx, pathName(fileTestID, fileTest, i), metadata)
+	}))
+}
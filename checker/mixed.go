@@ -0,0 +1,138 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// runMixedCheck measures uploads and downloads in isolation first, and then
+// runs them at the same time, NumParallel operations at once, so that the
+// report can show how they interfere.
+func (c *Checker) runMixedCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	if backends.IsReadOnly(endpoint.Client) {
+		for _, operation := range []config.Operation{config.MixedUpload, config.MixedDownload} {
+			if err := c.reportUnsupported(ctx, operation, fileTestID, endpoint); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	expectedHashes, err := c.expectedHashes(fileTestID, fileTest, endpoint)
+	if err != nil {
+		return err
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		log.Info("Upload", zap.Int64("iteration", iteration))
+		uploaded, err := c.Upload(ctx, fileTestID, fileTest, endpoint)
+		if err != nil {
+			return err
+		}
+		if !uploaded {
+			log.Warn("Skipping operations after failed upload", zap.Int64("iteration", iteration))
+			c.cleanupObjects(fileTestID, fileTest, endpoint)
+			continue
+		}
+
+		log.Info("Download", zap.Int64("iteration", iteration))
+		if err := c.Download(ctx, fileTestID, fileTest, endpoint); err != nil {
+			return err
+		}
+
+		log.Info("Mixed", zap.Int64("iteration", iteration))
+		uploadResult, downloadResult, written := mixed(ctx, fileTestID, fileTest, endpoint, expectedHashes)
+		if uploadResult.Error != "" {
+			log.Error("Mixed failed", zap.String("error", uploadResult.Error))
+		}
+		for operation, result := range map[config.Operation]*config.Result{config.MixedUpload: uploadResult, config.MixedDownload: downloadResult} {
+			// Small runs may not have drawn any operation of a direction.
+			if result.Success && len(result.ObjectDurations) == 0 {
+				continue
+			}
+			if err := c.reporter.Report(ctx, operation, fileTestID, endpoint.ID, result); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range written {
+			if err := endpoint.Client.Delete(ctx, name); err != nil {
+				c.log.Warn("Deleting mixed upload failed", zap.Error(err), zap.String("name", name), zap.String("endpoint", string(endpoint.ID)))
+			}
+		}
+
+		log.Info("Delete", zap.Int64("iteration", iteration))
+		if err := c.Delete(ctx, fileTestID, fileTest, endpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mixed runs one operation per object of the file test, NumParallel at a
+// time, each of which either downloads the uploaded object or uploads a new
+// copy of it next to the original. It returns the results of both
+// directions, which span the whole run, and the names of the written copies.
+func mixed(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint, expectedHashes [][]byte) (uploadResult, downloadResult *config.Result, written []string) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	readPercent := fileTest.Reads()
+	rng := rand.New(rand.NewSource(fileTest.Seed))
+	reads := make([]bool, fileTest.NumObjects)
+	for i := range reads {
+		reads[i] = rng.Int63n(100) < readPercent
+	}
+
+	uploadResult, downloadResult = newResultNow(), newResultNow()
+
+	var mu sync.Mutex
+	err := runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), func(ctx context.Context, i int) (err error) {
+		start := time.Now()
+		if reads[i] {
+			_, err = downloadObject(ctx, fileTestID, fileTest, endpoint, i, expectedHashes[i], nil, nil, nil)
+		} else {
+			name := copyName(fileTestID, fileTest, i)
+			err = endpoint.Client.Upload(ctx, name, throttle(ctx, fileTest, fileReader(fileTest, i)))
+			mu.Lock()
+			written = append(written, name)
+			mu.Unlock()
+		}
+		if err != nil {
+			return err
+		}
+
+		duration := time.Since(start)
+		mu.Lock()
+		defer mu.Unlock()
+		result := uploadResult
+		if reads[i] {
+			result = downloadResult
+		}
+		result.ObjectDurations = append(result.ObjectDurations, duration)
+		result.Bytes += int64(fileTest.Size)
+		return nil
+	})
+
+	for _, result := range []*config.Result{uploadResult, downloadResult} {
+		result.Duration = time.Since(result.StartTime)
+		result.Success = err == nil
+		if err != nil {
+			result.Error = err.Error()
+			result.ErrorCategory = classifyError(err)
+		}
+	}
+	return uploadResult, downloadResult, written
+}
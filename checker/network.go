@@ -0,0 +1,116 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// networkTimeout limits the connection setup to a single endpoint.
+const networkTimeout = 30 * time.Second
+
+// Locator finds where IPs are located.
+type Locator interface {
+	Locate(ip net.IP) (string, error)
+}
+
+// MeasureNetwork measures the DNS lookup, TCP connect and TLS handshake
+// times to every endpoint whose client reports its network address, and
+// records the IPs it resolved to. The IPs are located with locator unless
+// it's nil.
+func (c *Checker) MeasureNetwork(ctx context.Context, locator Locator) map[config.ID]config.NetworkTimings {
+	network := make(map[config.ID]config.NetworkTimings)
+	for _, endpoint := range c.endpoints {
+		addresser, ok := endpoint.Client.(backends.Addresser)
+		if !ok {
+			continue
+		}
+
+		var timings config.NetworkTimings
+		address, useTLS, err := addresser.NetworkAddress()
+		if err == nil {
+			timings.Address = address
+			err = measureNetwork(ctx, address, useTLS, &timings)
+		}
+		if err != nil {
+			timings.Error = err.Error()
+			c.log.Warn("Network diagnostics failed", zap.Error(err), zap.String("endpoint", string(endpoint.ID)))
+		}
+		if locator != nil {
+			for i, ip := range timings.IPs {
+				location, err := locator.Locate(net.ParseIP(ip.IP))
+				if err != nil {
+					c.log.Warn("Locating IP failed", zap.Error(err), zap.String("ip", ip.IP))
+					continue
+				}
+				timings.IPs[i].Location = location
+			}
+		}
+		c.log.Info("Network", zap.String("endpoint", string(endpoint.ID)), zap.String("address", timings.Address), zap.Duration("dns", timings.DNS), zap.Duration("connect", timings.Connect), zap.Duration("tlsHandshake", timings.TLSHandshake))
+		network[endpoint.ID] = timings
+	}
+	return network
+}
+
+// measureNetwork resolves address, connects to its first IP and does a TLS
+// handshake if useTLS is set, recording the time each step took.
+func measureNetwork(ctx context.Context, address string, useTLS bool, timings *config.NetworkTimings) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, networkTimeout)
+	defer cancel()
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+
+	start := time.Now()
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	timings.DNS = time.Since(start)
+	for _, ip := range ips {
+		timings.IPs = append(timings.IPs, config.IPLocation{IP: ip.String()})
+	}
+
+	var dialer net.Dialer
+	start = time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ips[0].String(), port))
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	timings.Connect = time.Since(start)
+	defer func() { err = errs.Combine(err, conn.Close()) }()
+
+	if !useTLS {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+
+	// Only the handshake is timed, so the certificate isn't verified, which
+	// also allows for satellites and their self-signed certificates.
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true,
+	})
+	start = time.Now()
+	if err := tlsConn.Handshake(); err != nil {
+		return errs.Wrap(err)
+	}
+	timings.TLSHandshake = time.Since(start)
+	return nil
+}
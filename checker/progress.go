@@ -0,0 +1,187 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// progress counts the bytes transferred by a running operation and
+// periodically logs them. A nil progress counts nothing.
+type progress struct {
+	bytes int64 // atomic
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startProgress starts logging the progress of an operation every progress
+// interval. It returns nil when progress logging is disabled.
+func (c *Checker) startProgress(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID) *progress {
+	if c.progressInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &progress{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	log := c.log.With(zap.String("operation", operation.String()), zap.String("fileTestID", string(fileTestID)), zap.String("endpointID", string(endpointID)))
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(time.Duration(c.progressInterval))
+		defer ticker.Stop()
+
+		var lastBytes int64
+		lastTime := time.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				bytes := atomic.LoadInt64(&p.bytes)
+				mbps := float64(bytes-lastBytes) * 8 / 1e6 / now.Sub(lastTime).Seconds()
+				log.Info("Progress", zap.Int64("bytes", bytes), zap.Float64("mbps", mbps))
+				lastBytes, lastTime = bytes, now
+			}
+		}
+	}()
+
+	return p
+}
+
+// stop stops logging progress.
+func (p *progress) stop() {
+	if p == nil {
+		return
+	}
+	p.cancel()
+	<-p.done
+}
+
+// wrap returns a reader which counts the bytes read from r.
+func (p *progress) wrap(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+	return &countingReader{Reader: r, progress: p}
+}
+
+// countingReader adds the bytes read to its progress.
+type countingReader struct {
+	io.Reader
+	progress *progress
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	atomic.AddInt64(&r.progress.bytes, int64(n))
+	return n, err
+}
+
+// timeline counts the bytes transferred by an attempt of an operation in
+// every config.TimelineInterval since its start. A nil timeline counts
+// nothing.
+type timeline struct {
+	start time.Time
+
+	mu    sync.Mutex
+	bytes []int64
+}
+
+// newTimeline creates a timeline starting at start.
+func newTimeline(start time.Time) *timeline {
+	return &timeline{start: start}
+}
+
+// wrap returns a reader which counts the bytes read from r.
+func (t *timeline) wrap(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &timelineReader{Reader: r, timeline: t}
+}
+
+// add counts n bytes transferred now.
+func (t *timeline) add(n int) {
+	interval := int(time.Since(t.start) / config.TimelineInterval)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for len(t.bytes) <= interval {
+		t.bytes = append(t.bytes, 0)
+	}
+	t.bytes[interval] += int64(n)
+}
+
+// intervals returns the bytes counted in every interval up to now.
+func (t *timeline) intervals() []int64 {
+	if t == nil {
+		return nil
+	}
+	t.add(0)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]int64(nil), t.bytes...)
+}
+
+// timelineReader adds the bytes read to its timeline.
+type timelineReader struct {
+	io.Reader
+	timeline *timeline
+}
+
+func (r *timelineReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	if n > 0 {
+		r.timeline.add(n)
+	}
+	return n, err
+}
+
+// nodeStats sums the storage node level stats of the objects downloaded by
+// an attempt of an operation. A nil nodeStats sums nothing.
+type nodeStats struct {
+	mu    sync.Mutex
+	stats *backends.NodeStats
+}
+
+// add adds the stats of a closed download stream, if it knows them.
+func (s *nodeStats) add(strm io.ReadCloser) {
+	reader, ok := strm.(backends.NodeStatsReader)
+	if s == nil || !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats == nil {
+		s.stats = new(backends.NodeStats)
+	}
+	s.stats.Add(reader.NodeStats())
+}
+
+// sum returns the summed stats, or nil if no stream knew them.
+func (s *nodeStats) sum() *backends.NodeStats {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
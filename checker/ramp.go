@@ -0,0 +1,45 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"storj.io/perftester/config"
+)
+
+// runRampCheck runs the throughput check once for every parallelism level
+// of the file test, tagging the results with the level.
+func (c *Checker) runRampCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	for _, level := range fileTest.Ramp {
+		c.checkLog(fileTestID, endpoint).Info("Ramp", zap.Int64("parallelism", level))
+
+		levelTest := fileTest
+		levelTest.Type = config.ThroughputTest
+		levelTest.NumParallel = level
+		if fileTest.NumObjects <= 0 {
+			levelTest.NumObjects = level
+		}
+
+		levelChecker := *c
+		levelChecker.reporter = &rampReporter{reporter: c.reporter, parallelism: level}
+		if err := levelChecker.RunCheck(ctx, fileTestID, levelTest, endpoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rampReporter records the parallelism level in every result.
+type rampReporter struct {
+	reporter    Reporter
+	parallelism int64
+}
+
+func (r *rampReporter) Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) error {
+	result.Parallelism = r.parallelism
+	return r.reporter.Report(ctx, operation, fileTestID, endpointID, result)
+}
@@ -0,0 +1,215 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"storj.io/perftester/config"
+)
+
+// resourceSampleInterval is how often the heap is sampled for its peak.
+const resourceSampleInterval = 100 * time.Millisecond
+
+// readMemStats reads the memory statistics of the process, which stops the
+// world, so it is only called outside of the timed part of operations and
+// by the heap sampler.
+var readMemStats = runtime.ReadMemStats
+
+// resourceSampler measures the resource usage of the client during an
+// operation.
+type resourceSampler struct {
+	start    time.Time
+	cpu      time.Duration
+	memStats runtime.MemStats
+	network  map[string]interfaceCounters
+
+	mu       sync.Mutex
+	peakHeap uint64
+}
+
+// heapSampler samples the heap of the process for the peaks of all running
+// measurements with a single goroutine, so that operations running at once
+// don't each stop the world to read it.
+var heapSampler struct {
+	mu       sync.Mutex
+	samplers map[*resourceSampler]struct{}
+	done     chan struct{}
+}
+
+// startResourceSampler starts measuring the resource usage of the client.
+// Its baseline is taken before it returns, so operations start their
+// timing after it.
+func startResourceSampler() *resourceSampler {
+	sampler := &resourceSampler{
+		cpu:     processCPUTime(),
+		network: readNetworkCounters(),
+	}
+	readMemStats(&sampler.memStats)
+	sampler.peakHeap = sampler.memStats.HeapInuse
+	sampler.start = time.Now()
+
+	heapSampler.mu.Lock()
+	defer heapSampler.mu.Unlock()
+	if heapSampler.samplers == nil {
+		heapSampler.samplers = make(map[*resourceSampler]struct{})
+	}
+	heapSampler.samplers[sampler] = struct{}{}
+	if len(heapSampler.samplers) == 1 {
+		heapSampler.done = make(chan struct{})
+		go sampleHeap(heapSampler.done, readMemStats)
+	}
+	return sampler
+}
+
+// sampleHeap updates the peaks of the running measurements with the heap
+// read by read until done is closed.
+func sampleHeap(done chan struct{}, read func(*runtime.MemStats)) {
+	ticker := time.NewTicker(resourceSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			var memStats runtime.MemStats
+			read(&memStats)
+
+			heapSampler.mu.Lock()
+			for sampler := range heapSampler.samplers {
+				sampler.updatePeak(memStats.HeapInuse)
+			}
+			heapSampler.mu.Unlock()
+		}
+	}
+}
+
+func (sampler *resourceSampler) updatePeak(heap uint64) {
+	sampler.mu.Lock()
+	defer sampler.mu.Unlock()
+	if heap > sampler.peakHeap {
+		sampler.peakHeap = heap
+	}
+}
+
+// stop stops measuring and returns the resource usage since the start. It
+// is called once the operation's timing ended.
+func (sampler *resourceSampler) stop() *config.ResourceUsage {
+	elapsed := time.Since(sampler.start)
+
+	heapSampler.mu.Lock()
+	delete(heapSampler.samplers, sampler)
+	if len(heapSampler.samplers) == 0 {
+		close(heapSampler.done)
+	}
+	heapSampler.mu.Unlock()
+
+	var memStats runtime.MemStats
+	readMemStats(&memStats)
+	sampler.updatePeak(memStats.HeapInuse)
+
+	sampler.mu.Lock()
+	usage := &config.ResourceUsage{
+		PeakHeap: int64(sampler.peakHeap),
+		GCCount:  int64(memStats.NumGC - sampler.memStats.NumGC),
+		GCPause:  time.Duration(memStats.PauseTotalNs - sampler.memStats.PauseTotalNs),
+	}
+	sampler.mu.Unlock()
+
+	if elapsed <= 0 {
+		return usage
+	}
+	if cpu := processCPUTime(); cpu > 0 {
+		usage.CPUPercent = 100 * float64(cpu-sampler.cpu) / float64(elapsed) / float64(runtime.NumCPU())
+	}
+
+	for name, counters := range readNetworkCounters() {
+		before, ok := sampler.network[name]
+		if !ok {
+			continue
+		}
+		received := counters.received - before.received
+		sent := counters.sent - before.sent
+		usage.NetworkReceived += received
+		usage.NetworkSent += sent
+
+		if speed := linkSpeed(name); speed > 0 {
+			busiest := received
+			if sent > busiest {
+				busiest = sent
+			}
+			percent := 100 * float64(busiest*8) / (float64(speed) * 1e6 * elapsed.Seconds())
+			if percent > usage.NetworkPercent {
+				usage.NetworkPercent = percent
+			}
+		}
+	}
+	return usage
+}
+
+// interfaceCounters are the bytes a network interface transferred.
+type interfaceCounters struct {
+	received int64
+	sent     int64
+}
+
+// readNetworkCounters returns the counters of the network interfaces other
+// than loopback from /proc/net/dev, which only Linux has.
+func readNetworkCounters() map[string]interfaceCounters {
+	data, err := ioutil.ReadFile("/proc/net/dev")
+	if err != nil {
+		return nil
+	}
+
+	counters := make(map[string]interfaceCounters)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// Lines after the two header lines look like
+		// "eth0: <rx bytes> <rx packets> ... <tx bytes> ...".
+		colon := strings.IndexByte(scanner.Text(), ':')
+		if colon < 0 {
+			continue
+		}
+		name := strings.TrimSpace(scanner.Text()[:colon])
+		fields := strings.Fields(scanner.Text()[colon+1:])
+		if name == "lo" || len(fields) < 9 {
+			continue
+		}
+		received, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		sent, err := strconv.ParseInt(fields[8], 10, 64)
+		if err != nil {
+			continue
+		}
+		counters[name] = interfaceCounters{received: received, sent: sent}
+	}
+	return counters
+}
+
+// linkSpeed returns the link speed of a network interface in Mbps, or zero
+// if it is unknown, like that of most virtual interfaces.
+func linkSpeed(name string) int64 {
+	data, err := ioutil.ReadFile("/sys/class/net/" + name + "/speed")
+	if err != nil {
+		return 0
+	}
+	speed, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil || speed <= 0 {
+		return 0
+	}
+	return speed
+}
+
+// processCPUTime is set by platforms which report the CPU time of the
+// process.
+var processCPUTime = func() time.Duration { return 0 }
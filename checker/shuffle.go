@@ -0,0 +1,130 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/perftester/config"
+)
+
+// scheduledCheck is a check of a file test on an endpoint. Simultaneous
+// runs schedule checks on all endpoints at once, which have no endpoint.
+type scheduledCheck struct {
+	fileTestID config.ID
+	fileTest   config.FileTest
+	endpoint   *config.Endpoint
+}
+
+// schedule returns the checks of every file test on every endpoint, sorted
+// by file test. Shuffled runs split the checks into their iterations and
+// interleave them in a random order.
+func (c *Checker) schedule(endpoints []*config.Endpoint) []scheduledCheck {
+	fileTestIDs := make([]config.ID, 0, len(c.fileTests))
+	for fileTestID := range c.fileTests {
+		fileTestIDs = append(fileTestIDs, fileTestID)
+	}
+	sort.Slice(fileTestIDs, func(i, j int) bool { return fileTestIDs[i] < fileTestIDs[j] })
+
+	var checks []scheduledCheck
+	for _, fileTestID := range fileTestIDs {
+		fileTest := c.fileTests[fileTestID]
+		for _, endpoint := range endpoints {
+			if !c.shuffle {
+				checks = append(checks, scheduledCheck{fileTestID, fileTest, endpoint})
+				continue
+			}
+
+			iterationTest := fileTest
+			iterationTest.Iterations = 1
+			for iteration := int64(0); iteration < fileTest.Iterations || iteration == 0; iteration++ {
+				checks = append(checks, scheduledCheck{fileTestID, iterationTest, endpoint})
+			}
+		}
+	}
+	if !c.shuffle {
+		return checks
+	}
+
+	random := rand.New(rand.NewSource(c.shuffleSeed))
+	random.Shuffle(len(checks), func(i, j int) { checks[i], checks[j] = checks[j], checks[i] })
+
+	// Only the first iteration of each check warms up.
+	type checkKey struct {
+		fileTestID config.ID
+		endpoint   *config.Endpoint
+	}
+	warm := make(map[checkKey]bool)
+	for i, check := range checks {
+		key := checkKey{check.fileTestID, check.endpoint}
+		if warm[key] {
+			checks[i].fileTest.Warmup = 0
+			checks[i].fileTest.WarmupDuration = 0
+		}
+		warm[key] = true
+	}
+	return checks
+}
+
+// runShuffledChecks starts the checks in their shuffled order, each as soon
+// as the concurrency and, if endpoints are serialized, the check running on
+// its endpoint allow.
+func (c *Checker) runShuffledChecks(ctx context.Context) error {
+	var failures checkFailures
+
+	var group errgroup.Group
+	limiter := make(chan struct{}, c.concurrency)
+
+	endpointLocks := make(map[config.ID]*sync.Mutex, len(c.endpoints))
+	for _, endpoint := range c.endpoints {
+		endpointLocks[endpoint.ID] = new(sync.Mutex)
+	}
+
+	for _, check := range c.schedule(c.endpoints) {
+		check := check
+
+		lock := endpointLocks[check.endpoint.ID]
+		if c.serializeEndpoints {
+			lock.Lock()
+		}
+		select {
+		case limiter <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			if c.serializeEndpoints {
+				lock.Unlock()
+			}
+			break
+		}
+
+		group.Go(func() error {
+			defer func() { <-limiter }()
+			if c.serializeEndpoints {
+				defer lock.Unlock()
+			}
+
+			if err := c.cooldown.wait(ctx); err != nil {
+				return err
+			}
+			err := c.RunCheck(ctx, check.fileTestID, check.fileTest, check.endpoint)
+			c.cooldown.finish()
+			return c.recordFailure(ctx, &failures, check.fileTestID, check.endpoint, err)
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+	// Checks which weren't started don't report the cancellation.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return failures.Err()
+}
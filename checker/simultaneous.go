@@ -0,0 +1,136 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// runSimultaneousChecks runs each file test on all endpoints at once, one
+// file test, or in shuffled runs one iteration of a file test, after
+// another. The measured operations of the checks start together, after
+// every endpoint finished its warmup, so that the endpoints share the
+// network conditions of the run.
+func (c *Checker) runSimultaneousChecks(ctx context.Context) error {
+	var failures checkFailures
+
+	for _, check := range c.schedule([]*config.Endpoint{nil}) {
+		if err := c.cooldown.wait(ctx); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		fileTestID, fileTest := check.fileTestID, check.fileTest
+		start := newBarrier(len(c.endpoints))
+
+		var wg sync.WaitGroup
+		runErrs := make([]error, len(c.endpoints))
+		for i, endpoint := range c.endpoints {
+			wg.Add(1)
+			go func(i int, endpoint *config.Endpoint) {
+				defer wg.Done()
+
+				member := start.member()
+				// Checks which fail before they are ready must not hold back
+				// the others.
+				defer member.leave()
+
+				err := c.runCheck(ctx, fileTestID, fileTest, endpoint, member)
+				runErrs[i] = c.recordFailure(ctx, &failures, fileTestID, endpoint, err)
+			}(i, endpoint)
+		}
+		wg.Wait()
+		c.cooldown.finish()
+
+		for _, err := range runErrs {
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return failures.Err()
+}
+
+// checkFailures collects the errors of failed checks.
+type checkFailures struct {
+	mu    sync.Mutex
+	group errs.Group
+}
+
+func (failures *checkFailures) add(err error) {
+	failures.mu.Lock()
+	defer failures.mu.Unlock()
+	failures.group.Add(err)
+}
+
+// Err returns the errors of all failed checks.
+func (failures *checkFailures) Err() error {
+	failures.mu.Lock()
+	defer failures.mu.Unlock()
+	return failures.group.Err()
+}
+
+// barrier holds back a group of checks until all of them are ready to
+// start.
+type barrier struct {
+	mu      sync.Mutex
+	waiting int
+	ready   chan struct{}
+}
+
+func newBarrier(count int) *barrier {
+	b := &barrier{waiting: count, ready: make(chan struct{})}
+	if count <= 0 {
+		close(b.ready)
+	}
+	return b
+}
+
+// member returns the handle of a check waiting at the barrier.
+func (b *barrier) member() *barrierMember {
+	return &barrierMember{barrier: b}
+}
+
+func (b *barrier) arrive() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.waiting--
+	if b.waiting == 0 {
+		close(b.ready)
+	}
+}
+
+// barrierMember is a check waiting at a barrier. Checks which aren't
+// synchronized have a nil member.
+type barrierMember struct {
+	barrier *barrier
+	once    sync.Once
+}
+
+// wait marks the check as ready and waits until the other checks are too.
+func (member *barrierMember) wait(ctx context.Context) error {
+	if member == nil {
+		return nil
+	}
+	member.leave()
+	select {
+	case <-member.barrier.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// leave marks the check as ready without waiting, unless it already was.
+func (member *barrierMember) leave() {
+	member.once.Do(member.barrier.arrive)
+}
@@ -0,0 +1,201 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// defaultSoakInterval is the length of the time buckets of soak tests
+// without a configured interval.
+const defaultSoakInterval = time.Minute
+
+// runSoakCheck keeps uploading and then keeps downloading the file test's
+// objects, NumParallel at a time, for the configured duration each, before
+// deleting them.
+func (c *Checker) runSoakCheck(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	log := c.checkLog(fileTestID, endpoint)
+	expectedHashes, err := c.expectedHashes(fileTestID, fileTest, endpoint)
+	if err != nil {
+		return err
+	}
+
+	// Downloads only get the objects whose upload finished, or all of them
+	// if they were uploaded by an earlier run.
+	var uploadedMu sync.Mutex
+	uploaded := make(map[int]bool)
+	operations := []struct {
+		operation config.Operation
+		run       func(ctx context.Context, i int) error
+	}{
+		{config.Upload, func(ctx context.Context, i int) error {
+			err := endpoint.Client.Upload(ctx, pathName(fileTestID, fileTest, i), throttle(ctx, fileTest, fileReader(fileTest, i)))
+			if err == nil {
+				uploadedMu.Lock()
+				uploaded[i] = true
+				uploadedMu.Unlock()
+			}
+			return err
+		}},
+		{config.Download, func(ctx context.Context, i int) error {
+			_, err := downloadObject(ctx, fileTestID, fileTest, endpoint, i, expectedHashes[i], nil, nil, nil)
+			return err
+		}},
+	}
+
+	for iteration := int64(0); iteration < fileTest.Iterations; iteration++ {
+		for _, op := range operations {
+			if !fileTest.Runs(op.operation) {
+				continue
+			}
+			if op.operation == config.Upload && backends.IsReadOnly(endpoint.Client) {
+				if err := c.reportUnsupported(ctx, op.operation, fileTestID, endpoint); err != nil {
+					return err
+				}
+				continue
+			}
+
+			objects := allObjects(fileTest)
+			if op.operation == config.Download && fileTest.Runs(config.Upload) {
+				objects = uploadedObjects(uploaded)
+				if len(objects) == 0 {
+					log.Warn("Skipping downloads without uploaded objects", zap.Int64("iteration", iteration))
+					continue
+				}
+			}
+
+			log.Info(op.operation.String(), zap.Int64("iteration", iteration), zap.Duration("duration", time.Duration(fileTest.Duration)))
+
+			result := newResultNow()
+			err := soak(ctx, fileTest, objects, op.run, result)
+			result.Duration = time.Since(result.StartTime)
+			result.Success = err == nil
+			if err != nil {
+				result.Error = err.Error()
+				result.ErrorCategory = classifyError(err)
+				log.Error(op.operation.String()+" failed", zap.Error(err))
+			}
+
+			if err := c.reporter.Report(ctx, op.operation, fileTestID, endpoint.ID, result); err != nil {
+				return err
+			}
+		}
+
+		if fileTest.Runs(config.Delete) {
+			log.Info("Delete", zap.Int64("iteration", iteration))
+			if err := c.Delete(ctx, fileTestID, fileTest, endpoint); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// soak keeps running op on the objects with the given indexes with
+// NumParallel workers until the file test's duration has passed, counting
+// the operations which completed in every interval and the bytes of those
+// which succeeded. Every operation has the file test's timeout. Failed
+// operations don't stop the soak; it only fails when no operation succeeded
+// at all.
+func soak(ctx context.Context, fileTest config.FileTest, objects []int, op func(ctx context.Context, i int) error, result *config.Result) error {
+	interval := time.Duration(fileTest.SoakInterval)
+	if interval <= 0 {
+		interval = defaultSoakInterval
+	}
+	deadline := result.StartTime.Add(time.Duration(fileTest.Duration))
+
+	var mu sync.Mutex
+	var lastErr error
+	var next int64
+	group, ctx := errgroup.WithContext(ctx)
+	for worker := int64(0); worker < fileTest.NumParallel; worker++ {
+		group.Go(func() error {
+			for time.Now().Before(deadline) {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+
+				i := objects[(atomic.AddInt64(&next, 1)-1)%int64(len(objects))]
+				start := time.Now()
+				err := timeout(ctx, time.Duration(fileTest.Timeout), func(ctx context.Context) error {
+					return op(ctx, i)
+				})
+				end := time.Now()
+
+				mu.Lock()
+				bucket := addBucket(result, interval, end.Sub(result.StartTime))
+				if err != nil {
+					bucket.Errors++
+					lastErr = err
+				} else {
+					bucket.Objects++
+					result.ObjectDurations = append(result.ObjectDurations, end.Sub(start))
+					result.Bytes += int64(fileTest.Size)
+				}
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	// The last bucket only spans the time until the soak ended.
+	if n := len(result.Buckets); n > 0 {
+		result.Buckets[n-1].Duration = time.Since(result.StartTime) - time.Duration(n-1)*interval
+	}
+
+	if len(result.ObjectDurations) == 0 {
+		return lastErr
+	}
+	return nil
+}
+
+// addBucket returns the bucket of the operation which completed elapsed
+// after the start of the soak, adding buckets as needed.
+func addBucket(result *config.Result, interval, elapsed time.Duration) *config.Bucket {
+	index := int(elapsed / interval)
+	for len(result.Buckets) <= index {
+		result.Buckets = append(result.Buckets, config.Bucket{Duration: interval})
+	}
+	return &result.Buckets[index]
+}
+
+// timeout runs f with a context which times out after timeout.
+func timeout(ctx context.Context, timeout time.Duration, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return f(ctx)
+}
+
+// allObjects returns the indexes of all objects of the file test.
+func allObjects(fileTest config.FileTest) []int {
+	objects := make([]int, fileTest.NumObjects)
+	for i := range objects {
+		objects[i] = i
+	}
+	return objects
+}
+
+// uploadedObjects returns the sorted indexes of the uploaded objects.
+func uploadedObjects(uploaded map[int]bool) []int {
+	objects := make([]int, 0, len(uploaded))
+	for i := range uploaded {
+		objects = append(objects, i)
+	}
+	sort.Ints(objects)
+	return objects
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+
+	"storj.io/perftester/config"
+)
+
+// throttle limits reading from r to the file test's rate limit. It returns r
+// when the file test has no rate limit.
+func throttle(ctx context.Context, fileTest config.FileTest, r io.Reader) io.Reader {
+	if fileTest.RateLimit <= 0 {
+		return r
+	}
+	return &throttledReader{
+		ctx:     ctx,
+		Reader:  r,
+		limiter: rate.NewLimiter(rate.Limit(fileTest.RateLimit), int(fileTest.RateLimit)),
+	}
+}
+
+// throttledReader is a token bucket limited reader.
+type throttledReader struct {
+	ctx context.Context
+	io.Reader
+	limiter *rate.Limiter
+}
+
+func (r *throttledReader) Read(p []byte) (n int, err error) {
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err = r.Reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,157 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package checker
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// defaultVersions is the number of versions of every object when
+// versioning operations are selected without setting Versions.
+const defaultVersions = 2
+
+// versioningOperations are the operations of versioning checks in the
+// order they are run.
+var versioningOperations = []config.Operation{config.PutVersion, config.ListVersions, config.DeleteVersion}
+
+// Versioning makes a versioning check. It uploads versions of every object
+// next to the originals, lists them and deletes them one by one, reporting
+// the selected operations. Versions left behind are deleted afterwards.
+func (c *Checker) Versioning(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, endpoint *config.Endpoint) error {
+	versioner, ok := endpoint.Client.(backends.Versioner)
+	if !ok || backends.IsReadOnly(endpoint.Client) {
+		return c.reportUnsupportedOperations(ctx, versioningOperations, fileTestID, fileTest, endpoint)
+	}
+	if fileTest.Versions <= 0 {
+		fileTest.Versions = defaultVersions
+	}
+	defer c.cleanupVersions(fileTestID, fileTest, versioner, endpoint)
+
+	versionIDs := make([][]string, fileTest.NumObjects)
+	result, putErr := runAttempts(ctx, fileTest, func(result *config.Result) error {
+		return putVersions(ctx, fileTestID, fileTest, versioner, versionIDs, result)
+	})
+	if backends.ErrUnsupported.Has(putErr) {
+		return c.reportUnsupportedOperations(ctx, versioningOperations, fileTestID, fileTest, endpoint)
+	}
+	// Without the versions, the other operations would only fail as well.
+	if err := c.reportSelected(ctx, config.PutVersion, fileTestID, fileTest, endpoint, result, putErr); err != nil || putErr != nil {
+		return err
+	}
+
+	if fileTest.Runs(config.ListVersions) {
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return listVersions(ctx, fileTestID, fileTest, versioner, result)
+		})
+		if err := c.reportSelected(ctx, config.ListVersions, fileTestID, fileTest, endpoint, result, err); err != nil {
+			return err
+		}
+	}
+
+	if fileTest.Runs(config.DeleteVersion) {
+		result, err := runAttempts(ctx, fileTest, func(result *config.Result) error {
+			return deleteVersions(ctx, fileTestID, fileTest, versioner, versionIDs, result)
+		})
+		if err := c.reportSelected(ctx, config.DeleteVersion, fileTestID, fileTest, endpoint, result, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putVersions uploads the versions of every object, timing each upload.
+func putVersions(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, versioner backends.Versioner, versionIDs [][]string, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	for i := range versionIDs {
+		versionIDs[i] = make([]string, fileTest.Versions)
+	}
+
+	// Consecutive uploads go to different objects, so that the versions of
+	// an object aren't uploaded at once.
+	count := int(fileTest.NumObjects * fileTest.Versions)
+	return runPool(ctx, count, int(fileTest.NumParallel), timeCalls(count, int64(fileTest.Size), result, func(ctx context.Context, call int) error {
+		i, version := call%int(fileTest.NumObjects), call/int(fileTest.NumObjects)
+		versionID, err := versioner.UploadVersion(ctx, versionName(fileTestID, fileTest, i), throttle(ctx, fileTest, fileReader(fileTest, i)))
+		versionIDs[i][version] = versionID
+		return err
+	}))
+}
+
+// listVersions lists the versions of every object, checking that all of
+// them are listed.
+func listVersions(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, versioner backends.Versioner, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+	return runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), timeObjects(fileTest, result, func(ctx context.Context, i int) error {
+		name := versionName(fileTestID, fileTest, i)
+		versionIDs, err := versioner.ListVersions(ctx, name)
+		if err != nil {
+			return err
+		}
+		if int64(len(versionIDs)) < fileTest.Versions {
+			return errs.New("listed %d versions of %q instead of %d", len(versionIDs), name, fileTest.Versions)
+		}
+		return nil
+	}))
+}
+
+// deleteVersions deletes the uploaded versions one by one, timing each
+// delete.
+func deleteVersions(ctx context.Context, fileTestID config.ID, fileTest config.FileTest, versioner backends.Versioner, versionIDs [][]string, result *config.Result) (err error) {
+	defer mon.Task()(&ctx)(&err)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(fileTest.Timeout))
+	defer cancel()
+
+	count := int(fileTest.NumObjects * fileTest.Versions)
+	return runPool(ctx, count, int(fileTest.NumParallel), timeCalls(count, 0, result, func(ctx context.Context, call int) error {
+		i, version := call%int(fileTest.NumObjects), call/int(fileTest.NumObjects)
+		return versioner.DeleteVersion(ctx, versionName(fileTestID, fileTest, i), versionIDs[i][version])
+	}))
+}
+
+// cleanupVersions deletes the versions left behind by a versioning check,
+// such as those of failed attempts, using a fresh context so that the
+// versions of cancelled checks are deleted too.
+func (c *Checker) cleanupVersions(fileTestID config.ID, fileTest config.FileTest, versioner backends.Versioner, endpoint *config.Endpoint) {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+
+	err := runPool(ctx, int(fileTest.NumObjects), int(fileTest.NumParallel), func(ctx context.Context, i int) error {
+		name := versionName(fileTestID, fileTest, i)
+		versionIDs, err := versioner.ListVersions(ctx, name)
+		if err != nil {
+			return err
+		}
+		for _, versionID := range versionIDs {
+			if err := versioner.DeleteVersion(ctx, name, versionID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.checkLog(fileTestID, endpoint).Warn("Deleting versions failed", zap.Error(err))
+	}
+}
+
+// versionName returns the name of the object the versions of the i-th
+// object are uploaded to. Versioned objects get indexes after the copies,
+// so that cleanup still finds them.
+func versionName(fileTestID config.ID, fileTest config.FileTest, i int) string {
+	return pathName(fileTestID, fileTest, 2*int(fileTest.NumObjects)+i)
+}
@@ -0,0 +1,344 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/common/uuid"
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/checker"
+	"storj.io/perftester/config"
+	"storj.io/perftester/internal/agent"
+	"storj.io/perftester/report"
+	"storj.io/private/process"
+)
+
+var agentCfg struct {
+	Listen string `default:":7778" help:"address to accept runs from coordinators on"`
+	Token  string `default:"" help:"only accept runs from coordinators with this token; required unless listening on a loopback address"`
+	Region string `default:"" help:"if set, the region the agent runs in, such as eu-central, recorded with its results"`
+
+	Pprof bool `default:"false" help:"also serve pprof profiles on the listen address, to coordinators with the token"`
+}
+
+var coordinateCfg struct {
+	ConfigPath        string        `default:"config.toml" help:"configuration file location, sent to the agents, which refuse references to environment variables and files"`
+	Agents            string        `default:"" help:"comma separated name=address agents to run the checks on, such as frankfurt=10.0.0.1:7778"`
+	Token             string        `default:"" help:"token to authenticate with the agents"`
+	Timeout           time.Duration `default:"0s" help:"if set, give up on agents which didn't finish their run within this time"`
+	OutputFile        string        `default:"" help:"if set, also write an HTML report to this file"`
+	OutputFormat      string        `default:"text" help:"format of the report printed to stdout: text, markdown, html or json"`
+	Units             string        `default:"Mbps" help:"units of throughputs in the report: Mbps, MBps, MiBps or Gbps"`
+	ReferenceEndpoint string        `default:"" help:"if set, show the throughputs of the other endpoints in the report relative to this endpoint, labeled like agent/endpoint"`
+	Columns           string        `default:"" help:"comma separated endpoints and metrics, such as Upload or p95, to show in the text report; all if empty"`
+	Width             int           `default:"0" help:"width in characters to split wide tables of the text report at; the terminal's width if 0, and never if negative"`
+	Suite             string        `default:"" help:"if set, only run the file tests and endpoints of this suite from the config"`
+	FileTests         string        `default:"" help:"comma separated file tests to run, overriding the suite; all if empty"`
+	Endpoints         string        `default:"" help:"comma separated endpoints to run on, overriding the suite; all if empty"`
+	Operations        string        `default:"" help:"comma separated operations to run, overriding the file tests"`
+}
+
+// cmdAgent runs the checks coordinators send until the process is stopped.
+func cmdAgent(cmd *cobra.Command, _ []string) (err error) {
+	ctx, _ := process.Ctx(cmd)
+
+	log := logger
+
+	if err := checkListenToken(agentCfg.Listen, agentCfg.Token, "token"); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", agentCfg.Listen)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", agent.NewServer(log, agentCfg.Token, func(ctx context.Context, spec agent.Spec) (*agent.Response, error) {
+		return runSpec(ctx, log, spec)
+	}))
+	if agentCfg.Pprof {
+		mux.Handle(pprofPath, pprofHandler(agentCfg.Token))
+	}
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	log.Info("Agent listening", zap.Stringer("address", listener.Addr()))
+	err = server.Serve(listener)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// runSpec runs the checks of a spec sent to the agent. Its config came from
+// the network, so it can't reference the environment or files of the agent.
+func runSpec(ctx context.Context, log *zap.Logger, spec agent.Spec) (*agent.Response, error) {
+	conf, err := config.ParseUntrustedConfig([]byte(spec.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.New()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := runOnce(ctx, log, conf, config.Hash([]byte(spec.Config)), id.String(), checkSelection{
+		suite:      spec.Suite,
+		fileTests:  spec.FileTests,
+		endpoints:  spec.Endpoints,
+		operations: spec.Operations,
+	})
+	if results == nil {
+		return nil, err
+	}
+	// The results of the checks which did run are returned with the error.
+	return &agent.Response{RunResults: *results}, err
+}
+
+// checkSelection selects the checks of a config to run like the flags of
+// the same names.
+type checkSelection struct {
+	suite      string
+	fileTests  string
+	endpoints  string
+	operations string
+}
+
+// runOnce runs the selected checks of conf once on endpoints of their own
+// and returns their results. If some checks failed, the results of the
+// others are returned with the error.
+func runOnce(ctx context.Context, log *zap.Logger, conf config.Config, configHash, runID string, selection checkSelection) (_ *report.RunResults, err error) {
+	conf, err = filterConfig(conf, selection.suite, selection.fileTests, selection.endpoints)
+	if err != nil {
+		return nil, err
+	}
+	conf, err = overrideOperations(conf, selection.operations)
+	if err != nil {
+		return nil, err
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	if conf.BufferSize > 0 {
+		cli.SetBufferSize(int(conf.BufferSize))
+	}
+
+	endpoints, err := checker.NewEndpoints(ctx, log, conf)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, endpoint := range endpoints {
+			err = errs.Combine(err, endpoint.Client.Close())
+		}
+	}()
+
+	metadata := report.NewMetadata(configHash)
+	metadata.Region = agentCfg.Region
+	metadata.RunID = runID
+
+	reporter := report.NewJSONReporter(conf.FileTestSizes())
+	c := checker.NewChecker(log.Named("checker"), report.MultiReporter{reporter}, endpoints, conf)
+	c.SetRunID(runID)
+	metadata.Mode = c.Mode()
+	metadata.ShuffleSeed = c.ShuffleSeed()
+	metadata.Settings = endpointSettings(endpoints)
+	metadata.Servers = endpointServers(ctx, endpoints)
+	metadata.Network = c.MeasureNetwork(ctx, nil)
+	checkErr := c.RunChecks(ctx)
+	metadata.EndTime = time.Now()
+	reporter.SetMetadata(metadata)
+
+	return reporter.RunResults(), checkErr
+}
+
+// cmdCoordinate runs the checks of the config on every agent at once and
+// prints a single report of all their results, with the endpoints of each
+// agent prefixed by its name.
+func cmdCoordinate(cmd *cobra.Command, _ []string) (err error) {
+	ctx, _ := process.Ctx(cmd)
+
+	agents, err := parseAgents(coordinateCfg.Agents)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(coordinateCfg.ConfigPath)
+	if err != nil {
+		return err
+	}
+	// Fail on an unknown format before starting any runs.
+	if _, err := report.NewFormatter(coordinateCfg.OutputFormat, nil); err != nil {
+		return err
+	}
+	options, err := reportOptions(coordinateCfg.Units, coordinateCfg.ReferenceEndpoint, coordinateCfg.Columns, coordinateCfg.Width)
+	if err != nil {
+		return err
+	}
+	if coordinateCfg.ReferenceEndpoint != "" {
+		conf, err := config.ParseUntrustedConfig(data)
+		if err != nil {
+			return err
+		}
+		conf, err = filterConfig(conf, coordinateCfg.Suite, coordinateCfg.FileTests, coordinateCfg.Endpoints)
+		if err != nil {
+			return err
+		}
+		endpointIDs, err := coordinatedEndpointIDs(conf, agents)
+		if err != nil {
+			return err
+		}
+		if err := checkReference(coordinateCfg.ReferenceEndpoint, endpointIDs); err != nil {
+			return err
+		}
+	}
+
+	spec := agent.Spec{
+		Config:     string(data),
+		Suite:      coordinateCfg.Suite,
+		FileTests:  coordinateCfg.FileTests,
+		Endpoints:  coordinateCfg.Endpoints,
+		Operations: coordinateCfg.Operations,
+	}
+	if coordinateCfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, coordinateCfg.Timeout)
+		defer cancel()
+	}
+
+	metadata := report.NewMetadata(config.Hash(data))
+	responses := make([]*agent.Response, len(agents))
+	runErrs := make([]error, len(agents))
+
+	var wg sync.WaitGroup
+	for i, a := range agents {
+		wg.Add(1)
+		go func(i int, a agentAddress) {
+			defer wg.Done()
+			responses[i], runErrs[i] = agent.Run(ctx, http.DefaultClient, a.address, coordinateCfg.Token, spec)
+		}(i, a)
+	}
+	wg.Wait()
+	metadata.EndTime = time.Now()
+
+	// Agents running the same config report the same file tests.
+	sizes := make(map[config.ID]int)
+	for _, response := range responses {
+		if response != nil {
+			for fileTestID, size := range response.FileTestSizes {
+				sizes[fileTestID] = size
+			}
+		}
+	}
+
+	reporter, err := report.NewFormatter(coordinateCfg.OutputFormat, sizes)
+	if err != nil {
+		return err
+	}
+	reporter.SetOptions(options)
+	reporters := report.MultiReporter{reporter}
+	var htmlReporter *report.HTMLReporter
+	if coordinateCfg.OutputFile != "" {
+		htmlReporter = report.NewHTMLReporter(sizes)
+		htmlReporter.SetOptions(options)
+		reporters = append(reporters, htmlReporter)
+	}
+
+	var group errs.Group
+	for i, a := range agents {
+		response := responses[i]
+		if runErrs[i] != nil {
+			group.Add(errs.New("agent %q: %v", a.name, runErrs[i]))
+			continue
+		}
+		if response.Error != "" {
+			group.Add(errs.New("agent %q: %s", a.name, response.Error))
+		}
+		if err := response.Replay(ctx, a.name, reporters, &metadata); err != nil {
+			return err
+		}
+	}
+
+	reporter.SetMetadata(metadata)
+	if htmlReporter != nil {
+		htmlReporter.SetMetadata(metadata)
+		page, err := htmlReporter.FormatResults(ctx)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(coordinateCfg.OutputFile, []byte(page), 0644); err != nil {
+			return err
+		}
+	}
+
+	report, err := reporter.FormatResults(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
+	return group.Err()
+}
+
+// coordinatedEndpointIDs returns the IDs the endpoints of the config are
+// reported under by the agents, labeled with their names.
+func coordinatedEndpointIDs(conf config.Config, agents []agentAddress) ([]config.ID, error) {
+	var endpointIDs []config.ID
+	for _, a := range agents {
+		labeled, err := reportedEndpointIDs(conf, a.name)
+		if err != nil {
+			return nil, err
+		}
+		endpointIDs = append(endpointIDs, labeled...)
+	}
+	return endpointIDs, nil
+}
+
+// agentAddress is a named agent.
+type agentAddress struct {
+	name    string
+	address string
+}
+
+// parseAgents parses a comma separated list of name=address agents.
+func parseAgents(list string) ([]agentAddress, error) {
+	var agents []agentAddress
+	names := make(map[string]bool)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errs.New("invalid agent %q: expected name=address", entry)
+		}
+		if names[parts[0]] {
+			return nil, errs.New("duplicate agent %q", parts[0])
+		}
+		names[parts[0]] = true
+		agents = append(agents, agentAddress{name: parts[0], address: parts[1]})
+	}
+	if len(agents) == 0 {
+		return nil, errs.New("no agents to coordinate")
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].name < agents[j].name })
+	return agents, nil
+}
@@ -0,0 +1,58 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/internal/agent"
+)
+
+func TestRunSpecRefusesReferences(t *testing.T) {
+	for _, specConfig := range []string{
+		// References in endpoint tables are refused once they are decoded.
+		`
+[filetest.small]
+size = "1KiB"
+
+[endpoint.s3.leak]
+region = "us-east-1"
+bucket = "bucket"
+address = "https://${file:/etc/passwd}.example.com"
+`,
+		`
+geoip_database = "${file:/etc/passwd}"
+
+[filetest.small]
+size = "1KiB"
+`,
+		`
+[filetest.small]
+size = "1KiB"
+
+[endpoint.s3.leak]
+region = "us-east-1"
+bucket = "${HOME}"
+`,
+	} {
+		response, err := runSpec(context.Background(), zap.NewNop(), agent.Spec{Config: specConfig})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "aren't allowed")
+		require.Nil(t, response)
+	}
+}
+
+func TestCheckListenToken(t *testing.T) {
+	require.Error(t, checkListenToken(":7778", "", "token"))
+	require.Error(t, checkListenToken("0.0.0.0:7778", "", "token"))
+	require.Error(t, checkListenToken("10.0.0.1:7778", "", "token"))
+	require.NoError(t, checkListenToken(":7778", "secret", "token"))
+	require.NoError(t, checkListenToken("localhost:7778", "", "token"))
+	require.NoError(t, checkListenToken("127.0.0.1:7778", "", "token"))
+	require.NoError(t, checkListenToken("[::1]:7778", "", "token"))
+}
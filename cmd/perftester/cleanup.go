@@ -0,0 +1,79 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/checker"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+	"storj.io/private/process"
+)
+
+var cleanupCfg struct {
+	ConfigPath string        `default:"config.toml" help:"configuration file location"`
+	Suite      string        `default:"" help:"if set, only clean up the file tests and endpoints of this suite from the config"`
+	FileTests  string        `default:"" help:"comma separated file tests to clean up, overriding the suite; all if empty"`
+	Endpoints  string        `default:"" help:"comma separated endpoints to clean up, overriding the suite; all if empty"`
+	RunID      string        `default:"" help:"if set, only clean up the objects named after this run ID"`
+	Unprefixed bool          `default:"false" help:"also clean up objects named like those of the file tests without a run ID, such as those of runs without run prefixes, which may not be theirs"`
+	OlderThan  time.Duration `default:"0s" help:"if set, only clean up the objects last modified longer ago than this, keeping those of endpoints which don't list modification times"`
+	DryRun     bool          `default:"false" help:"only list the objects which would be deleted"`
+}
+
+// cmdCleanup deletes the test objects left behind on every endpoint by
+// failed or interrupted runs.
+func cmdCleanup(cmd *cobra.Command, _ []string) (err error) {
+	ctx, _ := process.Ctx(cmd)
+
+	conf, err := config.LoadConfig(cleanupCfg.ConfigPath)
+	if err != nil {
+		return err
+	}
+	conf, err = filterConfig(conf, cleanupCfg.Suite, cleanupCfg.FileTests, cleanupCfg.Endpoints)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := checker.NewEndpoints(ctx, zap.NewNop(), conf)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, endpoint := range endpoints {
+			err = errs.Combine(err, endpoint.Client.Close())
+		}
+	}()
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+
+	var group errs.Group
+	rows := [][]string{{"Endpoint", "Objects", "Status"}}
+	for _, endpoint := range endpoints {
+		names, err := checker.Cleanup(ctx, endpoint, conf.FileTests, cleanupCfg.RunID, cleanupCfg.Unprefixed, cleanupCfg.OlderThan, cleanupCfg.DryRun)
+		status := "deleted"
+		switch {
+		case err != nil:
+			status = err.Error()
+			group.Add(err)
+		case cleanupCfg.DryRun:
+			status = "dry run"
+		}
+		rows = append(rows, []string{string(endpoint.ID), strconv.Itoa(len(names)), status})
+	}
+
+	table, err := report.MakeTable(rows, "-")
+	if err != nil {
+		return err
+	}
+	fmt.Print(table)
+	return group.Err()
+}
@@ -0,0 +1,34 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var configCfg struct {
+	ConfigPath string `default:"config.toml" help:"configuration file location"`
+	Suite      string `default:"" help:"if set, only include the file tests and endpoints of this suite from the config"`
+	FileTests  string `default:"" help:"comma separated file tests to include, overriding the suite; all if empty"`
+	Endpoints  string `default:"" help:"comma separated endpoints to include, overriding the suite; all if empty"`
+}
+
+// cmdConfigShow prints the config as the checks see it: with environment
+// variables and secret files expanded, matrices expanded, defaults applied
+// and secrets redacted.
+func cmdConfigShow(cmd *cobra.Command, _ []string) (err error) {
+	conf, err := loadValidConfig(configCfg.ConfigPath, configCfg.Suite, configCfg.FileTests, configCfg.Endpoints)
+	if err != nil {
+		return err
+	}
+
+	data, err := conf.WithDefaults().EncodeRedacted()
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+	"storj.io/perftester/internal/auth"
+)
+
+// pprofPath is the path the pprof profiles are served under.
+const pprofPath = "/debug/pprof/"
+
+// pprofHandler serves the pprof profiles of the process, so that long
+// running instances can be profiled in place. If token is set, requests
+// need it as their bearer token.
+func pprofHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pprofPath, pprof.Index)
+	mux.HandleFunc(pprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(pprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(pprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(pprofPath+"trace", pprof.Trace)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && !auth.ValidToken(r.Header.Get("Authorization"), token) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// checkPprof returns an error if the pprof profiles would be served to
+// other hosts without a token.
+func checkPprof(monitoring config.Monitoring) error {
+	if !monitoring.Pprof || monitoring.PprofToken != "" || isLoopback(monitoring.PrometheusAddress) {
+		return nil
+	}
+	return errs.New("refusing to serve pprof profiles on %q without monitoring.pprof_token: set a token or serve Prometheus metrics on a loopback address such as localhost:%s", monitoring.PrometheusAddress, port(monitoring.PrometheusAddress))
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/config"
+)
+
+func TestPprofHandler(t *testing.T) {
+	get := func(handler http.Handler, path, authorization string) int {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	handler := pprofHandler("secret")
+	require.Equal(t, http.StatusUnauthorized, get(handler, "/debug/pprof/", ""))
+	require.Equal(t, http.StatusUnauthorized, get(handler, "/debug/pprof/cmdline", "Bearer wrong"))
+	require.Equal(t, http.StatusOK, get(handler, "/debug/pprof/", "Bearer secret"))
+	require.Equal(t, http.StatusOK, get(handler, "/debug/pprof/cmdline", "Bearer secret"))
+
+	// Without a token, which is only allowed on loopback addresses, anyone
+	// may profile.
+	require.Equal(t, http.StatusOK, get(pprofHandler(""), "/debug/pprof/", ""))
+}
+
+func TestCheckPprof(t *testing.T) {
+	require.NoError(t, checkPprof(config.Monitoring{PrometheusAddress: ":9090"}))
+	require.NoError(t, checkPprof(config.Monitoring{PrometheusAddress: "localhost:9090", Pprof: true}))
+	require.NoError(t, checkPprof(config.Monitoring{PrometheusAddress: ":9090", Pprof: true, PprofToken: "secret"}))
+	require.Error(t, checkPprof(config.Monitoring{PrometheusAddress: ":9090", Pprof: true}))
+	require.Error(t, checkPprof(config.Monitoring{PrometheusAddress: "10.0.0.1:9090", Pprof: true}))
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	// Register the endpoint types.
+	_ "storj.io/perftester/backends/all"
+	"storj.io/perftester/config"
+)
+
+// endpointFactory creates the client of a configured endpoint.
+type endpointFactory struct {
+	ID        config.ID
+	Bucket    string
+	Path      string
+	Defaults  config.EndpointDefaults
+	newClient func(ctx context.Context) (cli.Client, error)
+}
+
+// endpointFactories returns a factory for every configured endpoint.
+func endpointFactories(log *zap.Logger, conf config.Config) ([]endpointFactory, error) {
+	endpoints, err := conf.DecodeEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	factories := make([]endpointFactory, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		endpoint := endpoint
+		factories = append(factories, endpointFactory{
+			ID:       endpoint.ID,
+			Bucket:   endpoint.Bucket,
+			Path:     endpoint.Path,
+			Defaults: endpoint.Defaults,
+			newClient: func(ctx context.Context) (cli.Client, error) {
+				return endpoint.Config.NewClient(ctx, log.Named(endpoint.Type+"client"))
+			},
+		})
+	}
+	return factories, nil
+}
+
+// endpointSettings returns the transfer settings of the endpoints whose
+// clients are tunable.
+func endpointSettings(endpoints []*config.Endpoint) map[config.ID]string {
+	settings := make(map[config.ID]string)
+	for _, endpoint := range endpoints {
+		if tuned, ok := endpoint.Client.(cli.Tuned); ok {
+			settings[endpoint.ID] = tuned.Settings()
+		}
+	}
+	return settings
+}
+
+// endpointServers describes the servers of the endpoints whose clients can
+// describe them. Servers which fail to be described are recorded with their
+// error.
+func endpointServers(ctx context.Context, endpoints []*config.Endpoint) map[config.ID]string {
+	servers := make(map[config.ID]string)
+	for _, endpoint := range endpoints {
+		describer, ok := endpoint.Client.(cli.ServerDescriber)
+		if !ok {
+			continue
+		}
+		description, err := describer.DescribeServer(ctx)
+		if err != nil {
+			description = "error: " + err.Error()
+		}
+		servers[endpoint.ID] = description
+	}
+	return servers
+}
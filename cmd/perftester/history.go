@@ -0,0 +1,75 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+	"storj.io/perftester/internal/store"
+	"storj.io/perftester/report"
+	"storj.io/private/process"
+)
+
+var historyCfg struct {
+	StorePath string        `default:"perftester.db" help:"SQLite database holding the stored results"`
+	Since     time.Duration `default:"720h" help:"only summarize results newer than this"`
+	Endpoint  string        `default:"" help:"only summarize results of this endpoint"`
+}
+
+func cmdHistory(cmd *cobra.Command, _ []string) (err error) {
+	ctx, _ := process.Ctx(cmd)
+
+	db, err := store.Open(ctx, historyCfg.StorePath)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errs.Combine(err, db.Close()) }()
+
+	trends, err := db.History(ctx, store.HistoryFilter{
+		Since:      time.Now().Add(-historyCfg.Since),
+		EndpointID: config.ID(historyCfg.Endpoint),
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{{"Endpoint", "File", "Operation", "Day", "Samples", "Errors", "Mean"}}
+	for _, trend := range trends {
+		rows = append(rows, []string{
+			string(trend.EndpointID),
+			string(trend.FileTestID),
+			trend.Operation,
+			trend.Day,
+			strconv.Itoa(trend.Samples),
+			strconv.Itoa(trend.Errors),
+			formatTrendMean(trend),
+		})
+	}
+
+	table, err := report.MakeTable(rows, "-")
+	if err != nil {
+		return err
+	}
+	fmt.Print(table)
+	return nil
+}
+
+// formatTrendMean formats the mean throughput of a trend, or its mean
+// duration for operations which don't transfer the whole file.
+func formatTrendMean(trend store.Trend) string {
+	operation, _ := config.ParseOperation(trend.Operation)
+	switch {
+	case trend.Samples == trend.Errors:
+		return "-"
+	case !operation.TransfersFile():
+		return trend.MeanDuration.String()
+	}
+	return fmt.Sprintf("%s Mbps", strconv.FormatFloat(trend.Mbps(), 'f', 2, 64))
+}
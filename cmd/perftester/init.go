@@ -0,0 +1,212 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+var initCfg struct {
+	Output      string `default:"config.toml" help:"file to write the config to"`
+	Providers   string `default:"" help:"comma separated endpoint types to add an endpoint of each for, such as s3,storj"`
+	Interactive bool   `default:"false" help:"prompt for the providers and the settings of their endpoints"`
+	Force       bool   `default:"false" help:"overwrite the output if it exists"`
+}
+
+// starterSetting is a setting of the endpoint of a provider in a starter
+// config.
+type starterSetting struct {
+	key    string
+	prompt string
+	value  string
+}
+
+// starterEndpoints are the settings which endpoints of each type need to
+// run, with defaults reading credentials from the environment.
+var starterEndpoints = map[string][]starterSetting{
+	"drive": {
+		{"client_id", "OAuth client ID", "${DRIVE_CLIENT_ID}"},
+		{"client_secret", "OAuth client secret", "${DRIVE_CLIENT_SECRET}"},
+		{"refresh_token", "OAuth refresh token", "${DRIVE_REFRESH_TOKEN}"},
+		{"bucket", "folder to test in", "perftester"},
+	},
+	"dropbox": {
+		{"app_key", "app key", "${DROPBOX_APP_KEY}"},
+		{"app_secret", "app secret", "${DROPBOX_APP_SECRET}"},
+		{"refresh_token", "OAuth refresh token", "${DROPBOX_REFRESH_TOKEN}"},
+		{"bucket", "folder to test in", "perftester"},
+	},
+	"ftp": {
+		{"host", "host", "localhost"},
+		{"user", "user", "anonymous"},
+		{"password", "password", "${FTP_PASSWORD}"},
+		{"path", "directory to test in", "perftester"},
+	},
+	"gcs": {
+		{"credentials_file", "service account JSON key file", "${GOOGLE_APPLICATION_CREDENTIALS}"},
+		{"bucket", "bucket", "perftester"},
+	},
+	"http": {
+		{"url", "URL of a file of the size of the file tests", "https://example.com/1MiB.bin"},
+	},
+	"ipfs": {
+		{"api", "API (kubo or pinata)", "kubo"},
+		{"api_url", "API URL", "http://localhost:5001"},
+		{"gateway_url", "gateway URL", "https://ipfs.io"},
+	},
+	"minio": {
+		{"address", "address", "http://localhost:9000"},
+		{"access_key", "access key", "${MINIO_ACCESS_KEY}"},
+		{"secret_key", "secret key", "${MINIO_SECRET_KEY}"},
+		{"bucket", "bucket", "perftester"},
+	},
+	"rclone": {
+		{"remote", "remote and bucket to test in", "remote:perftester"},
+	},
+	"s3": {
+		{"region", "region", "us-east-1"},
+		{"access_key", "access key", "${AWS_ACCESS_KEY_ID}"},
+		{"secret_key", "secret key", "${AWS_SECRET_ACCESS_KEY}"},
+		{"bucket", "bucket", "perftester"},
+	},
+	"storj": {
+		{"access", "access grant", "${STORJ_ACCESS}"},
+		{"bucket", "bucket", "perftester"},
+	},
+	"webdav": {
+		{"url", "URL of the share", "https://example.com/remote.php/dav/files/user"},
+		{"username", "username", "${WEBDAV_USERNAME}"},
+		{"password", "password", "${WEBDAV_PASSWORD}"},
+		{"path", "existing collection to test in", "perftester"},
+	},
+}
+
+// starterFileTests is the test matrix of starter configs: the latency of
+// small objects and the throughput of medium and large files at a few
+// levels of parallelism.
+const starterFileTests = `timeout = "30m"
+
+[filetest.small]
+type = "latency"
+size = "4KiB"
+numparallel = 4
+numobjects = 100
+
+[filetest.transfer]
+sizes = ["1MiB", "64MiB", "512MiB"]
+parallelism = [1, 4]
+iterations = 3
+retries = 1
+`
+
+// cmdInit writes a starter config with an endpoint of each chosen provider
+// and a default test matrix.
+func cmdInit(cmd *cobra.Command, _ []string) (err error) {
+	if _, err := os.Stat(initCfg.Output); err == nil && !initCfg.Force {
+		return errs.New("%s already exists; use --force to overwrite it", initCfg.Output)
+	}
+
+	prompter := &prompter{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()}
+
+	providers := initCfg.Providers
+	if providers == "" && initCfg.Interactive {
+		providers, err = prompter.ask(fmt.Sprintf("Providers (%s)", strings.Join(starterTypes(), ", ")), "s3")
+		if err != nil {
+			return err
+		}
+	}
+	if providers == "" {
+		return errs.New("no providers given; use --providers or --interactive")
+	}
+
+	var data strings.Builder
+	writeLine := func(line string) { _, _ = data.WriteString(line + "\n") }
+	writeLine("# Generated by perftester init. Strings reference environment variables")
+	writeLine("# with ${NAME} and secret files with ${file:PATH}.")
+	writeLine("")
+	_, _ = data.WriteString(starterFileTests)
+
+	seen := make(map[string]bool)
+	for _, provider := range strings.Split(providers, ",") {
+		provider = strings.TrimSpace(provider)
+		settings, ok := starterEndpoints[provider]
+		if !ok {
+			return errs.New("no starter endpoint for provider %q; choose from %s", provider, strings.Join(starterTypes(), ", "))
+		}
+		if seen[provider] {
+			continue
+		}
+		seen[provider] = true
+
+		writeLine("")
+		writeLine(fmt.Sprintf("[endpoint.%s.%s]", provider, provider))
+		for _, setting := range settings {
+			value := setting.value
+			if initCfg.Interactive {
+				value, err = prompter.ask(provider+" "+setting.prompt, setting.value)
+				if err != nil {
+					return err
+				}
+			}
+			writeLine(fmt.Sprintf("%s = %s", setting.key, strconv.Quote(value)))
+		}
+	}
+
+	// Catch values which don't survive quoting before writing them.
+	if _, err := config.ParseConfig([]byte(data.String())); err != nil {
+		return errs.New("generated an invalid config: %v", err)
+	}
+	if err := ioutil.WriteFile(initCfg.Output, []byte(data.String()), 0600); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s; check it with: perftester validate --config-path %s\n", initCfg.Output, initCfg.Output)
+	return nil
+}
+
+// starterTypes returns the sorted endpoint types of starter configs.
+func starterTypes() []string {
+	types := make([]string, 0, len(starterEndpoints))
+	for endpointType := range starterEndpoints {
+		types = append(types, endpointType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// prompter asks for values on the terminal.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask prompts for a value, which defaults to value if the answer is empty.
+func (p *prompter) ask(prompt, value string) (string, error) {
+	if value != "" {
+		_, _ = fmt.Fprintf(p.out, "%s [%s]: ", prompt, value)
+	} else {
+		_, _ = fmt.Fprintf(p.out, "%s: ", prompt)
+	}
+
+	answer, err := p.in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", errs.New("reading answer: %v", err)
+	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer, nil
+	}
+	return value, nil
+}
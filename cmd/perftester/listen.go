@@ -0,0 +1,43 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"net"
+
+	"github.com/zeebo/errs"
+)
+
+// checkListenToken returns an error if a server on address would accept
+// requests from other hosts without a token, which the flag named
+// tokenFlag sets.
+func checkListenToken(address, token, tokenFlag string) error {
+	if token != "" || isLoopback(address) {
+		return nil
+	}
+	return errs.New("refusing to listen on %q without --%s: set a token or listen on a loopback address such as localhost:%s", address, tokenFlag, port(address))
+}
+
+// isLoopback returns whether address only accepts connections from the
+// local host.
+func isLoopback(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// port returns the port of address.
+func port(address string) string {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "0"
+	}
+	return port
+}
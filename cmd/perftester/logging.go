@@ -0,0 +1,99 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"flag"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"storj.io/private/cfgstruct"
+)
+
+var logCfg struct {
+	LogLevel  string `default:"info" help:"minimum level of logged messages: debug, info, warn or error"`
+	LogFormat string `default:"json" help:"format of logged messages: console or json"`
+	Quiet     bool   `default:"false" help:"if set, log nothing and only print the report"`
+}
+
+// logger is the logger of all commands, which setupLogging creates from the
+// logging flags before they run.
+var logger = zap.NewNop()
+
+// bindLogging adds the logging flags to cmd and all its subcommands, and
+// sets up logging before any of them runs.
+func bindLogging(cmd *cobra.Command) {
+	cfgstruct.Bind(cmd.PersistentFlags(), &logCfg)
+	cmd.PersistentPreRunE = setupLogging
+	cmd.PersistentPostRunE = func(*cobra.Command, []string) error {
+		_ = logger.Sync()
+		return nil
+	}
+}
+
+// setupLogging creates the logger of the commands and makes the logger
+// process.Exec creates for cmd follow the logging flags too.
+func setupLogging(cmd *cobra.Command, _ []string) (err error) {
+	if err := processLogging(cmd, logCfg.LogLevel, logCfg.LogFormat, logCfg.Quiet); err != nil {
+		return err
+	}
+	logger, err = newLogger(logCfg.LogLevel, logCfg.LogFormat, logCfg.Quiet)
+	return err
+}
+
+// newLogger creates a logger logging at level in the console or json
+// format. Quiet loggers discard everything, so only the report is printed.
+func newLogger(level, format string, quiet bool) (*zap.Logger, error) {
+	if quiet {
+		return zap.NewNop(), nil
+	}
+
+	zapLevel, err := parseLogFlags(level, format)
+	if err != nil {
+		return nil, err
+	}
+
+	logConfig := zap.NewProductionConfig()
+	logConfig.Level = zap.NewAtomicLevelAt(zapLevel)
+	if format == "console" {
+		logConfig.Encoding = "console"
+		logConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		logConfig.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	}
+	return logConfig.Build()
+}
+
+// processLogging makes the logger process.Exec creates for cmd follow the
+// logging flags too, unless its own log.* flags are set.
+func processLogging(cmd *cobra.Command, level, format string, quiet bool) error {
+	if _, err := parseLogFlags(level, format); err != nil {
+		return err
+	}
+	if quiet {
+		level = "fatal"
+	}
+	for name, value := range map[string]string{"log.level": level, "log.encoding": format} {
+		if cmd.Flags().Changed(name) {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseLogFlags(level, format string) (zapcore.Level, error) {
+	var zapLevel zapcore.Level
+	if err := zapLevel.UnmarshalText([]byte(level)); err != nil {
+		return zapLevel, errs.New("invalid log level %q", level)
+	}
+	if format != "console" && format != "json" {
+		return zapLevel, errs.New("unknown log format %q", format)
+	}
+	return zapLevel, nil
+}
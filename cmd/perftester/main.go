@@ -6,23 +6,60 @@ package main
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zeebo/errs"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 
-	"storj.io/perftester/internal/check"
-	s3 "storj.io/perftester/internal/client/s3client"
-	"storj.io/perftester/internal/client/storjclient"
-	"storj.io/perftester/internal/config"
-	"storj.io/perftester/internal/report"
+	"storj.io/common/uuid"
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/checker"
+	"storj.io/perftester/config"
+	"storj.io/perftester/internal/api"
+	"storj.io/perftester/internal/geoip"
+	"storj.io/perftester/internal/store"
+	"storj.io/perftester/report"
+	"storj.io/perftester/report/prometheus"
 	"storj.io/private/cfgstruct"
 	"storj.io/private/process"
 )
 
 var cfg struct {
-	ConfigPath string `default:"config.toml" help:"configuration file location"`
+	ConfigPath        string        `default:"config.toml" help:"configuration file location"`
+	Interval          time.Duration `default:"0s" help:"if set, keep running and repeat all checks at this interval"`
+	OutputFile        string        `default:"" help:"if set, also write an HTML report to this file"`
+	OutputFormat      string        `default:"text" help:"format of the report printed to stdout: text, markdown, html, json or any other registered format, overriding the config"`
+	Units             string        `default:"Mbps" help:"units of throughputs in the report: Mbps, MBps, MiBps or Gbps"`
+	ReferenceEndpoint string        `default:"" help:"if set, show the throughputs of the other endpoints in the report relative to this endpoint"`
+	Columns           string        `default:"" help:"comma separated endpoints and metrics, such as Upload or p95, to show in the text report; all if empty"`
+	Width             int           `default:"0" help:"width in characters to split wide tables of the text report at; the terminal's width if 0 when printing to one, and never if negative"`
+	Output            string        `default:"" help:"if set, also write the report in the output format to this file, or to a timestamped file if it is a directory or ends with a separator"`
+	Stdout            bool          `default:"true" help:"print the report to stdout; disable to only write it to the output"`
+	StorePath         string        `default:"" help:"if set, append all results to this SQLite database"`
+	Region            string        `default:"" help:"if set, the region the run is from, such as eu-central, which merged results are told apart by"`
+	Suite             string        `default:"" help:"if set, only run the file tests and endpoints of this suite from the config"`
+	FileTests         string        `default:"" help:"comma separated file tests to run, overriding the suite; all if empty"`
+	Endpoints         string        `default:"" help:"comma separated endpoints to run on, overriding the suite; all if empty"`
+	Operations        string        `default:"" help:"comma separated operations to run (upload, download, copy, range_download, delete), overriding the file tests"`
+
+	FailOnError   bool    `default:"false" help:"exit with status 2 if any operation failed"`
+	MaxErrorRate  float64 `default:"0" help:"if set, exit with status 2 if more than this percentage of an endpoint's operations failed"`
+	MinThroughput string  `default:"" help:"comma separated endpoint=Mbps minimum throughputs, overriding the config; exit with status 2 if a transfer was slower"`
+
+	APIAddress string `default:"" help:"if set with an interval, serve an HTTP API for triggering runs and fetching results on this address"`
+	APIToken   string `default:"" help:"only accept API requests with this bearer token; required unless the API listens on a loopback address"`
+
+	CPUProfile string `default:"" help:"if set, write a CPU profile of the run to this file"`
+	MemProfile string `default:"" help:"if set, write a heap profile to this file at the end of the run"`
 }
 
 func main() {
@@ -32,15 +69,115 @@ func main() {
 		RunE:  Main,
 	}
 	process.Bind(cmd, &cfg, cfgstruct.DefaultsFlag(cmd))
+	bindLogging(cmd)
+
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "summarize stored results per endpoint and day",
+		RunE:  cmdHistory,
+	}
+	process.Bind(historyCmd, &historyCfg, cfgstruct.DefaultsFlag(historyCmd))
+	cmd.AddCommand(historyCmd)
+
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "serve a web UI over the stored results",
+		RunE:  cmdServe,
+	}
+	process.Bind(serveCmd, &serveCfg, cfgstruct.DefaultsFlag(serveCmd))
+	cmd.AddCommand(serveCmd)
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "write a starter config for the chosen providers",
+		RunE:  cmdInit,
+	}
+	process.Bind(initCmd, &initCfg, cfgstruct.DefaultsFlag(initCmd))
+	cmd.AddCommand(initCmd)
+
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "check the config and endpoint connectivity without running any checks",
+		RunE:  cmdValidate,
+	}
+	process.Bind(validateCmd, &validateCfg, cfgstruct.DefaultsFlag(validateCmd))
+	cmd.AddCommand(validateCmd)
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "inspect the config",
+	}
+	configShowCmd := &cobra.Command{
+		Use:   "show",
+		Short: "print the resolved config with defaults applied and secrets redacted",
+		RunE:  cmdConfigShow,
+	}
+	process.Bind(configShowCmd, &configCfg, cfgstruct.DefaultsFlag(configShowCmd))
+	configCmd.AddCommand(configShowCmd)
+	cmd.AddCommand(configCmd)
+
+	cleanupCmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "delete test objects left behind by failed or interrupted runs",
+		RunE:  cmdCleanup,
+	}
+	process.Bind(cleanupCmd, &cleanupCfg, cfgstruct.DefaultsFlag(cleanupCmd))
+	cmd.AddCommand(cleanupCmd)
+
+	mergeCmd := &cobra.Command{
+		Use:   "merge [flags] [label=]results.json...",
+		Short: "combine results written with the json format into one report",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  cmdMerge,
+	}
+	process.Bind(mergeCmd, &mergeCfg, cfgstruct.DefaultsFlag(mergeCmd))
+	cmd.AddCommand(mergeCmd)
+
+	resultsCmd := &cobra.Command{
+		Use:   "results",
+		Short: "work with results written with the json format",
+	}
+	resultsConvertCmd := &cobra.Command{
+		Use:   "convert [flags] input [output]",
+		Short: "convert results between versions of the results schema and between JSON and CSV",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  cmdResultsConvert,
+	}
+	process.Bind(resultsConvertCmd, &resultsConvertCfg, cfgstruct.DefaultsFlag(resultsConvertCmd))
+	resultsCmd.AddCommand(resultsConvertCmd)
+	cmd.AddCommand(resultsCmd)
+
+	agentCmd := &cobra.Command{
+		Use:   "agent",
+		Short: "run the checks remote coordinators send",
+		RunE:  cmdAgent,
+	}
+	process.Bind(agentCmd, &agentCfg, cfgstruct.DefaultsFlag(agentCmd))
+	cmd.AddCommand(agentCmd)
+
+	coordinateCmd := &cobra.Command{
+		Use:   "coordinate",
+		Short: "run the checks on remote agents and report all their results together",
+		RunE:  cmdCoordinate,
+	}
+	process.Bind(coordinateCmd, &coordinateCfg, cfgstruct.DefaultsFlag(coordinateCmd))
+	cmd.AddCommand(coordinateCmd)
+
 	process.Exec(cmd)
 }
 
 // Main is the main function run
 func Main(cmd *cobra.Command, _ []string) (err error) {
 	// Errors returned from here result in the "usage" being shown, so only
-	// the error will be logged and the program explicitly exited.
-	if err := run(context.Background(), cmd); err != nil {
+	// the error will be logged and the program explicitly exited. Runs which
+	// only violated their thresholds or SLA exit with a distinct status.
+	ctx, _ := process.Ctx(cmd)
+	if err := run(ctx, cmd); err != nil {
+		_ = logger.Sync()
 		_, _ = fmt.Fprintf(os.Stderr, "Execution failed: %+v\n", err)
+		if report.ErrThreshold.Has(err) || report.ErrSLA.Has(err) {
+			os.Exit(2)
+		}
 		os.Exit(1)
 	}
 	return nil
@@ -50,60 +187,555 @@ func run(ctx context.Context, cmd *cobra.Command) (err error) {
 	if cfg.ConfigPath == "" {
 		return errs.New("empty config path")
 	}
-	log, err := zap.NewProduction()
+	if !cfg.Stdout && cfg.Output == "" {
+		return errs.New("the report is neither printed nor written without an output")
+	}
+	if cfg.APIAddress != "" && cfg.Interval <= 0 {
+		return errs.New("the API is only served in daemon mode, which needs an interval")
+	}
+	if cfg.APIAddress != "" {
+		if err := checkListenToken(cfg.APIAddress, cfg.APIToken, "api-token"); err != nil {
+			return err
+		}
+	}
+	log := logger
+	zap.ReplaceGlobals(log)
+
+	stopProfiling, err := startProfiling(log, cfg.CPUProfile, cfg.MemProfile)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = log.Sync() }()
-	zap.ReplaceGlobals(log)
+	defer func() { err = errs.Combine(err, stopProfiling()) }()
 
 	conf, err := config.LoadConfig(cfg.ConfigPath)
 	if err != nil {
 		return err
 	}
+	if !cmd.Flags().Changed("output-format") && conf.OutputFormat != "" {
+		cfg.OutputFormat = conf.OutputFormat
+	}
+	// Fail on an unknown format before running any checks.
+	if _, err := report.NewFormatter(cfg.OutputFormat, nil); err != nil {
+		return err
+	}
+	if _, err := report.ParseUnits(cfg.Units); err != nil {
+		return err
+	}
+	conf, err = filterConfig(conf, cfg.Suite, cfg.FileTests, cfg.Endpoints)
+	if err != nil {
+		return err
+	}
+	if err := checkReferenceEndpoint(conf, cfg.ReferenceEndpoint); err != nil {
+		return err
+	}
+
+	conf.Thresholds, err = thresholdFlags(conf.Thresholds)
+	if err != nil {
+		return err
+	}
+
+	conf, err = overrideOperations(conf, cfg.Operations)
+	if err != nil {
+		return err
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+	if err := checkPprof(conf.Monitoring); err != nil {
+		return err
+	}
+	if conf.BufferSize > 0 {
+		cli.SetBufferSize(int(conf.BufferSize))
+	}
 
-	var endpoints []*config.Endpoint
-	for id, endpoint := range conf.Endpoints.S3 {
-		client, err := s3.New(endpoint)
+	endpoints, err := checker.NewEndpoints(ctx, log, conf)
+	if err != nil {
+		return err
+	}
+
+	fileTestSizes := conf.FileTestSizes()
+
+	configHash, err := config.HashFile(cfg.ConfigPath)
+	if err != nil {
+		return err
+	}
+
+	r := &runner{
+		log:           log,
+		conf:          conf,
+		configHash:    configHash,
+		endpoints:     endpoints,
+		fileTestSizes: fileTestSizes,
+	}
+
+	if cfg.StorePath != "" {
+		r.store, err = store.Open(ctx, cfg.StorePath)
 		if err != nil {
 			return err
 		}
-		endpoints = append(endpoints, &config.Endpoint{
-			ID:     id,
-			Bucket: endpoint.Bucket,
-			Path:   endpoint.Path,
-			Client: client,
-		})
+		defer func() { err = errs.Combine(err, r.store.Close()) }()
 	}
-	for id, endpoint := range conf.Endpoints.Storj {
-		client, err := storjclient.New(ctx, log.Named("storjclient"), endpoint)
+
+	if conf.GeoIPDatabase != "" {
+		db, err := geoip.Open(conf.GeoIPDatabase)
 		if err != nil {
 			return err
 		}
-		endpoints = append(endpoints, &config.Endpoint{
-			ID:     id,
-			Bucket: endpoint.Bucket,
-			Path:   endpoint.Path,
-			Client: client,
+		defer func() { err = errs.Combine(err, db.Close()) }()
+		r.geoIP = db
+	}
+
+	if conf.Monitoring.Address != "" {
+		stopMetrics, err := startMetrics(ctx, log, conf.Monitoring)
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+	}
+
+	if conf.Monitoring.TracingURL != "" {
+		stopTracing, err := startTracing(ctx, log, conf.Monitoring)
+		if err != nil {
+			return err
+		}
+		defer stopTracing()
+	}
+
+	if conf.Monitoring.PrometheusAddress != "" || conf.Monitoring.PushgatewayURL != "" {
+		r.promReporter = prometheus.New(fileTestSizes)
+	}
+
+	if conf.Monitoring.PrometheusAddress != "" {
+		listener, err := net.Listen("tcp", conf.Monitoring.PrometheusAddress)
+		if err != nil {
+			return err
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		// Profiles are only worth serving from long running daemons.
+		var other http.Handler
+		if conf.Monitoring.Pprof && cfg.Interval > 0 {
+			other = pprofHandler(conf.Monitoring.PprofToken)
+		}
+		go func() {
+			if err := r.promReporter.Serve(ctx, listener, other); err != nil {
+				log.Error("Prometheus server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	if cfg.Interval <= 0 {
+		return r.runChecks(ctx)
+	}
+
+	if cfg.APIAddress != "" {
+		configData, err := ioutil.ReadFile(cfg.ConfigPath)
+		if err != nil {
+			return err
+		}
+		listener, err := net.Listen("tcp", cfg.APIAddress)
+		if err != nil {
+			return err
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+
+		r.api = api.NewServer(ctx, log, cfg.APIToken, func(ctx context.Context, runID string, request api.Request) (*report.RunResults, error) {
+			return r.runRequest(ctx, configData, runID, request)
 		})
+		defer func() {
+			cancel()
+			r.api.Wait()
+		}()
+
+		server := &http.Server{Handler: r.api}
+		go func() {
+			<-ctx.Done()
+			_ = server.Close()
+		}()
+		go func() {
+			if err := server.Serve(listener); err != nil && ctx.Err() == nil {
+				log.Error("API server failed", zap.Error(err))
+			}
+		}()
+	}
+
+	// In daemon mode a failed run is logged and retried on the next tick
+	// rather than stopping the process.
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		r.mu.Lock()
+		err := r.runChecks(ctx)
+		r.mu.Unlock()
+		if err != nil {
+			log.Error("Check run failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runner runs all checks and hands their results to the configured outputs.
+type runner struct {
+	log           *zap.Logger
+	conf          config.Config
+	configHash    string
+	endpoints     []*config.Endpoint
+	fileTestSizes map[config.ID]int
+
+	promReporter *prometheus.Reporter
+	store        *store.Store
+	geoIP        checker.Locator
+	api          *api.Server
+
+	// mu keeps runs triggered through the API from skewing the scheduled
+	// runs and each other.
+	mu sync.Mutex
+}
+
+// runChecks runs every check once and prints the text report of the run.
+func (r *runner) runChecks(ctx context.Context) error {
+	reporter, err := report.NewFormatter(cfg.OutputFormat, r.fileTestSizes)
+	if err != nil {
+		return err
+	}
+	options, err := reportOptions(cfg.Units, cfg.ReferenceEndpoint, cfg.Columns, cfg.Width)
+	if err != nil {
+		return err
+	}
+	reporter.SetOptions(options)
+	reporters := report.MultiReporter{reporter}
+	if r.promReporter != nil {
+		reporters = append(reporters, r.promReporter)
 	}
 
-	fileTestSizes := make(map[config.ID]int)
-	for fileTestID, fileTest := range conf.FileTests {
-		fileTestSizes[fileTestID] = int(fileTest.Size)
+	thresholdReporter := report.NewThresholdReporter(r.fileTestSizes, r.conf.Thresholds)
+	reporters = append(reporters, thresholdReporter)
+
+	var slaReporter *report.SLAReporter
+	if len(r.conf.SLA.Rules) > 0 {
+		slaReporter = report.NewSLAReporter(r.fileTestSizes, r.conf.SLA)
+		reporters = append(reporters, slaReporter)
+	}
+
+	var htmlReporter *report.HTMLReporter
+	if cfg.OutputFile != "" {
+		htmlReporter = report.NewHTMLReporter(r.fileTestSizes)
+		htmlReporter.SetOptions(options)
+		reporters = append(reporters, htmlReporter)
 	}
-	reporter := report.NewTextReporter(fileTestSizes)
 
-	checker := check.NewChecker(log.Named("checker"), reporter, endpoints, conf.FileTests, conf.Timeout)
-	if err := checker.RunChecks(ctx); err != nil {
+	var jsonReporter *report.JSONReporter
+	if r.api != nil {
+		jsonReporter = report.NewJSONReporter(r.fileTestSizes)
+		reporters = append(reporters, jsonReporter)
+	}
+
+	metadata := report.NewMetadata(r.configHash)
+	metadata.Region = cfg.Region
+
+	id, err := uuid.New()
+	if err != nil {
 		return err
 	}
+	runID := id.String()
+	metadata.RunID = runID
+
+	if r.store != nil {
+		err := r.store.CreateRun(ctx, store.Run{
+			ID:         runID,
+			StartTime:  metadata.StartTime,
+			ConfigHash: r.configHash,
+			Hostname:   metadata.Hostname,
+			OS:         metadata.OS,
+			GoVersion:  metadata.GoVersion,
+			Version:    metadata.Version,
+		})
+		if err != nil {
+			return err
+		}
+		reporters = append(reporters, r.store.Reporter(runID, r.fileTestSizes))
+	}
+
+	// Failed checks still leave the results of the other checks to report.
+	c := checker.NewChecker(r.log.Named("checker"), reporters, r.endpoints, r.conf)
+	c.SetRunID(runID)
+	metadata.Mode = c.Mode()
+	metadata.ShuffleSeed = c.ShuffleSeed()
+	metadata.Settings = endpointSettings(r.endpoints)
+	metadata.Servers = endpointServers(ctx, r.endpoints)
+	metadata.Network = c.MeasureNetwork(ctx, r.geoIP)
+	checkErr := c.RunChecks(ctx)
+	if ctx.Err() != nil {
+		return checkErr
+	}
+
+	metadata.EndTime = time.Now()
+	reporter.SetMetadata(metadata)
+	if jsonReporter != nil {
+		jsonReporter.SetMetadata(metadata)
+		r.api.SetLatest(jsonReporter.RunResults())
+	}
+	if r.store != nil {
+		if err := r.store.FinishRun(ctx, runID, metadata.EndTime); err != nil {
+			return err
+		}
+	}
+
+	if r.conf.Monitoring.PushgatewayURL != "" {
+		if err := r.promReporter.Push(r.conf.Monitoring.PushgatewayURL, r.conf.Monitoring.InstanceID); err != nil {
+			return err
+		}
+	}
+
+	if htmlReporter != nil {
+		htmlReporter.SetMetadata(metadata)
+		page, err := htmlReporter.FormatResults(ctx)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(cfg.OutputFile, []byte(page), 0644); err != nil {
+			return err
+		}
+	}
 
 	report, err := reporter.FormatResults(ctx)
 	if err != nil {
 		return err
 	}
 
-	fmt.Print(report)
+	if cfg.Output != "" {
+		path, err := writeReport(cfg.Output, cfg.OutputFormat, report, metadata.StartTime, metadata.RunID)
+		if err != nil {
+			return err
+		}
+		r.log.Info("Report written", zap.String("path", path))
+	}
+	if cfg.Stdout {
+		// The written report isn't split at the width of the terminal.
+		if stdoutOptions := withTerminalWidth(options); stdoutOptions.Width != options.Width {
+			reporter.SetOptions(stdoutOptions)
+			if report, err = reporter.FormatResults(ctx); err != nil {
+				return err
+			}
+		}
+		fmt.Print(report)
+	}
+
+	if slaReporter != nil {
+		slaReport, err := slaReporter.FormatResults(ctx)
+		if err != nil {
+			return err
+		}
+		// Keep the JSON report on stdout parseable.
+		if cfg.Stdout && cfg.OutputFormat != "json" {
+			fmt.Print(slaReport)
+		} else {
+			_, _ = fmt.Fprint(os.Stderr, slaReport)
+		}
+	}
+
+	if checkErr != nil {
+		return checkErr
+	}
+	if err := thresholdReporter.Check(); err != nil {
+		return err
+	}
+	if slaReporter != nil {
+		return slaReporter.Check()
+	}
 	return nil
 }
+
+// runRequest runs a request triggered through the API, with its config
+// fragment added to the config, once no other run is running.
+func (r *runner) runRequest(ctx context.Context, configData []byte, runID string, request api.Request) (*report.RunResults, error) {
+	conf, err := config.ParseConfig(configData)
+	if err != nil {
+		return nil, err
+	}
+	conf, err = conf.Extend([]byte(request.Config))
+	if err != nil {
+		return nil, err
+	}
+	configHash := r.configHash
+	if request.Config != "" {
+		configHash = config.Hash([]byte(string(configData) + "\n" + request.Config))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return runOnce(ctx, r.log, conf, configHash, runID, checkSelection{
+		suite:      request.Suite,
+		fileTests:  request.FileTests,
+		endpoints:  request.Endpoints,
+		operations: request.Operations,
+	})
+}
+
+// reportOptions returns the options of the reports with throughputs in the
+// named units, compared to those of the reference endpoint if it is set.
+// The text report shows the comma separated columns, or all if empty, and
+// is split at width. Reports printed to stdout use withTerminalWidth.
+func reportOptions(units, reference, columns string, width int) (report.Options, error) {
+	parsed, err := report.ParseUnits(units)
+	if err != nil {
+		return report.Options{}, err
+	}
+	options := report.Options{Units: parsed, ReferenceEndpoint: config.ID(reference), Width: width}
+	if columns != "" {
+		for _, column := range strings.Split(columns, ",") {
+			options.Columns = append(options.Columns, strings.TrimSpace(column))
+		}
+	}
+	return options, nil
+}
+
+// withTerminalWidth returns the options with the width of the terminal if
+// they don't set a width and stdout is a terminal, for reports printed
+// there.
+func withTerminalWidth(options report.Options) report.Options {
+	if options.Width == 0 && term.IsTerminal(int(os.Stdout.Fd())) {
+		if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			options.Width = width
+		}
+	}
+	return options
+}
+
+// checkReferenceEndpoint returns an error if the reference endpoint is set
+// but no endpoint of the config is reported under its ID. The IDs are those
+// of the report, which renamed endpoints such as accelerated S3 ones don't
+// share with their tables in the config.
+func checkReferenceEndpoint(conf config.Config, reference string) error {
+	if reference == "" {
+		return nil
+	}
+	endpointIDs, err := reportedEndpointIDs(conf, "")
+	if err != nil {
+		return err
+	}
+	return checkReference(reference, endpointIDs)
+}
+
+// reportedEndpointIDs returns the IDs the endpoints of the config are
+// reported under, labeled with label if it is set.
+func reportedEndpointIDs(conf config.Config, label string) ([]config.ID, error) {
+	endpoints, err := conf.DecodeEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	endpointIDs := make([]config.ID, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if label != "" {
+			endpoint.ID = report.LabeledEndpointID(label, endpoint.ID)
+		}
+		endpointIDs = append(endpointIDs, endpoint.ID)
+	}
+	return endpointIDs, nil
+}
+
+// checkReference returns an error if the reference endpoint is set but
+// isn't one of the endpoint IDs of the report.
+func checkReference(reference string, endpointIDs []config.ID) error {
+	if reference == "" {
+		return nil
+	}
+	for _, endpointID := range endpointIDs {
+		if endpointID == config.ID(reference) {
+			return nil
+		}
+	}
+	return errs.New("unknown reference endpoint %q: expected one of %q", reference, endpointIDs)
+}
+
+// thresholdFlags overrides the thresholds of the config with the ones set
+// on the command line.
+func thresholdFlags(thresholds config.Thresholds) (config.Thresholds, error) {
+	if cfg.FailOnError {
+		thresholds.FailOnError = true
+	}
+	if cfg.MaxErrorRate > 0 {
+		thresholds.MaxErrorRate = cfg.MaxErrorRate
+	}
+	if cfg.MinThroughput != "" {
+		thresholds.MinThroughput = make(map[config.ID]float64)
+		for _, entry := range strings.Split(cfg.MinThroughput, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			if len(parts) != 2 {
+				return thresholds, errs.New("invalid min throughput %q: expected endpoint=Mbps", entry)
+			}
+			mbps, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return thresholds, errs.New("invalid min throughput %q: %v", entry, err)
+			}
+			thresholds.MinThroughput[config.ID(parts[0])] = mbps
+		}
+	}
+	return thresholds, nil
+}
+
+// filterConfig limits the config to the named suite and the comma
+// separated file tests and endpoints selected on the command line.
+func filterConfig(conf config.Config, suiteID, fileTests, endpoints string) (config.Config, error) {
+	var suite config.Suite
+	if suiteID != "" {
+		var ok bool
+		suite, ok = conf.Suites[config.ID(suiteID)]
+		if !ok {
+			return config.Config{}, errs.New("unknown suite %q", suiteID)
+		}
+	}
+
+	fileTestIDs := suite.FileTests
+	if fileTests != "" {
+		fileTestIDs = splitIDs(fileTests)
+	}
+	endpointIDs := suite.Endpoints
+	if endpoints != "" {
+		endpointIDs = splitIDs(endpoints)
+	}
+
+	return conf.Filter(fileTestIDs, endpointIDs)
+}
+
+// overrideOperations overrides the operations of every file test with the
+// comma separated operations selected on the command line, if any, failing
+// on unknown ones.
+func overrideOperations(conf config.Config, operations string) (config.Config, error) {
+	if operations == "" {
+		return conf, nil
+	}
+	names := strings.Split(operations, ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+		if _, ok := config.OperationNames[names[i]]; !ok {
+			return config.Config{}, errs.New("unknown operation %q", names[i])
+		}
+	}
+	for id, fileTest := range conf.FileTests {
+		fileTest.Operations = names
+		conf.FileTests[id] = fileTest
+	}
+	return conf, nil
+}
+
+// splitIDs splits a comma separated list of IDs.
+func splitIDs(list string) []config.ID {
+	var ids []config.ID
+	for _, id := range strings.Split(list, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, config.ID(id))
+		}
+	}
+	return ids
+}
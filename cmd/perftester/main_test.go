@@ -0,0 +1,111 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+func TestCheckReferenceEndpoint(t *testing.T) {
+	conf, err := config.ParseConfig([]byte(`
+[endpoint.s3.plain]
+bucket = "bucket"
+
+[endpoint.s3.fast]
+bucket = "bucket"
+accelerate = true
+`))
+	require.NoError(t, err)
+
+	require.NoError(t, checkReferenceEndpoint(conf, ""))
+	require.NoError(t, checkReferenceEndpoint(conf, "plain"))
+	// Accelerated endpoints are reported under another ID than their table.
+	require.NoError(t, checkReferenceEndpoint(conf, "fast+accelerate"))
+	require.Error(t, checkReferenceEndpoint(conf, "fast"))
+	require.Error(t, checkReferenceEndpoint(conf, "unknown"))
+}
+
+func TestOverrideOperations(t *testing.T) {
+	conf := config.Config{FileTests: map[config.ID]config.FileTest{"small": {}}}
+
+	conf, err := overrideOperations(conf, "upload, download")
+	require.NoError(t, err)
+	require.Equal(t, []string{"upload", "download"}, conf.FileTests["small"].Operations)
+
+	_, err = overrideOperations(conf, "upload,downlaod")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"downlaod"`)
+}
+
+func TestCheckMergedReference(t *testing.T) {
+	runs := []labeledRun{
+		{label: "eu", results: &report.RunResults{Results: []report.OperationResult{{EndpointID: "s3"}}}},
+		{label: "us", results: &report.RunResults{Results: []report.OperationResult{{EndpointID: "s3"}, {EndpointID: "storj"}}}},
+	}
+	endpointIDs := mergedEndpointIDs(runs)
+	require.Equal(t, []config.ID{"eu/s3", "us/s3", "us/storj"}, endpointIDs)
+
+	require.NoError(t, checkReference("", endpointIDs))
+	require.NoError(t, checkReference("us/storj", endpointIDs))
+	require.Error(t, checkReference("storj", endpointIDs))
+	require.Error(t, checkReference("eu/storj", endpointIDs))
+}
+
+func TestLoadRunsByRegion(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	write := func(name, hostname, region string) string {
+		path := ctx.File(name + ".json")
+		data := fmt.Sprintf(`{"Metadata": {"Hostname": %q, "Region": %q}, "Results": []}`, hostname, region)
+		require.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+		return path
+	}
+
+	// Runs of a host in different regions are told apart by their region.
+	runs, err := loadRuns([]string{
+		write("a", "host1", "eu-central"),
+		write("b", "host1", "us-east"),
+		write("c", "host2", ""),
+	})
+	require.NoError(t, err)
+	var labels []string
+	for _, run := range runs {
+		labels = append(labels, run.label)
+	}
+	require.Equal(t, []string{"host1@eu-central", "host1@us-east", "host2"}, labels)
+	require.Equal(t, "eu-central, us-east", mergeMetadata(runs).Region)
+
+	// Runs which share their host and region fall back to their file names.
+	runs, err = loadRuns([]string{write("d", "host1", "eu-central"), write("e", "host1", "eu-central")})
+	require.NoError(t, err)
+	require.Equal(t, "d", runs[0].label)
+	require.Equal(t, "e", runs[1].label)
+}
+
+func TestCoordinatedEndpointIDs(t *testing.T) {
+	conf, err := config.ParseUntrustedConfig([]byte(`
+[endpoint.s3.plain]
+bucket = "bucket"
+
+[endpoint.s3.fast]
+bucket = "bucket"
+accelerate = true
+`))
+	require.NoError(t, err)
+
+	endpointIDs, err := coordinatedEndpointIDs(conf, []agentAddress{{name: "a1"}, {name: "a2"}})
+	require.NoError(t, err)
+	require.ElementsMatch(t, []config.ID{"a1/fast+accelerate", "a1/plain", "a2/fast+accelerate", "a2/plain"}, endpointIDs)
+	require.NoError(t, checkReference("a2/plain", endpointIDs))
+	require.Error(t, checkReference("plain", endpointIDs))
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+	"storj.io/private/process"
+)
+
+var mergeCfg struct {
+	OutputFile        string `default:"" help:"if set, also write an HTML report to this file"`
+	OutputFormat      string `default:"text" help:"format of the report printed to stdout: text, markdown, html or json"`
+	Units             string `default:"Mbps" help:"units of throughputs in the report: Mbps, MBps, MiBps or Gbps"`
+	ReferenceEndpoint string `default:"" help:"if set, show the throughputs of the other endpoints in the report relative to this endpoint, labeled like label/endpoint"`
+	Columns           string `default:"" help:"comma separated endpoints and metrics, such as Upload or p95, to show in the text report; all if empty"`
+	Width             int    `default:"0" help:"width in characters to split wide tables of the text report at; the terminal's width if 0 when printing to one, and never if negative"`
+}
+
+// cmdMerge combines the results of runs written with the json format,
+// such as runs from several hosts, into one report. The endpoints of each
+// run are labeled with the run's host and region, or with the label of a
+// label=path argument.
+func cmdMerge(cmd *cobra.Command, args []string) (err error) {
+	ctx, _ := process.Ctx(cmd)
+
+	// Fail on an unknown format before reading any results.
+	if _, err := report.NewFormatter(mergeCfg.OutputFormat, nil); err != nil {
+		return err
+	}
+	options, err := reportOptions(mergeCfg.Units, mergeCfg.ReferenceEndpoint, mergeCfg.Columns, mergeCfg.Width)
+	if err != nil {
+		return err
+	}
+
+	runs, err := loadRuns(args)
+	if err != nil {
+		return err
+	}
+	if err := checkReference(mergeCfg.ReferenceEndpoint, mergedEndpointIDs(runs)); err != nil {
+		return err
+	}
+
+	sizes := make(map[config.ID]int)
+	for _, run := range runs {
+		for fileTestID, size := range run.results.FileTestSizes {
+			if existing, ok := sizes[fileTestID]; ok && existing != size {
+				return errs.New("file test %q has different sizes in the merged runs", fileTestID)
+			}
+			sizes[fileTestID] = size
+		}
+	}
+
+	reporter, err := report.NewFormatter(mergeCfg.OutputFormat, sizes)
+	if err != nil {
+		return err
+	}
+	reporter.SetOptions(options)
+	reporters := report.MultiReporter{reporter}
+	var htmlReporter *report.HTMLReporter
+	if mergeCfg.OutputFile != "" {
+		htmlReporter = report.NewHTMLReporter(sizes)
+		htmlReporter.SetOptions(options)
+		reporters = append(reporters, htmlReporter)
+	}
+
+	metadata := mergeMetadata(runs)
+	for _, run := range runs {
+		if err := run.results.Replay(ctx, run.label, reporters, &metadata); err != nil {
+			return err
+		}
+	}
+
+	reporter.SetMetadata(metadata)
+	if htmlReporter != nil {
+		htmlReporter.SetMetadata(metadata)
+		page, err := htmlReporter.FormatResults(ctx)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(mergeCfg.OutputFile, []byte(page), 0644); err != nil {
+			return err
+		}
+	}
+
+	reporter.SetOptions(withTerminalWidth(options))
+	report, err := reporter.FormatResults(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report)
+	return nil
+}
+
+// labeledRun are the results of a run and the label of their endpoints.
+type labeledRun struct {
+	label   string
+	results *report.RunResults
+}
+
+// loadRuns reads the results of the path or label=path arguments, sorted
+// by label. Runs without a label of their own are labeled by their run key,
+// or by their file name if several runs share the key.
+func loadRuns(args []string) ([]labeledRun, error) {
+	runs := make([]labeledRun, 0, len(args))
+	labeled := make([]bool, 0, len(args))
+	keys := make(map[string]int)
+	for _, arg := range args {
+		label, path := "", arg
+		if parts := strings.SplitN(arg, "=", 2); len(parts) == 2 {
+			label, path = parts[0], parts[1]
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		results, err := report.ParseRunResults(data)
+		if err != nil {
+			return nil, errs.New("%s: %v", path, err)
+		}
+
+		labeled = append(labeled, label != "")
+		if label == "" && results.Metadata != nil && results.Metadata.Hostname != "" {
+			label = runKey(*results.Metadata)
+			keys[label]++
+		}
+		if label == "" {
+			label = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		runs = append(runs, labeledRun{label: label, results: results})
+	}
+
+	for i := range runs {
+		if !labeled[i] && keys[runs[i].label] > 1 {
+			path := args[i]
+			runs[i].label = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, run := range runs {
+		if seen[run.label] {
+			return nil, errs.New("duplicate label %q: label the runs with label=path", run.label)
+		}
+		seen[run.label] = true
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].label < runs[j].label })
+	return runs, nil
+}
+
+// runKey returns the key of a run labeled by its metadata: its host, and
+// its region if it has one, such as "host1@eu-central", since hosts of
+// different regions may share a name.
+func runKey(metadata report.Metadata) string {
+	if metadata.Region == "" {
+		return metadata.Hostname
+	}
+	return metadata.Hostname + "@" + metadata.Region
+}
+
+// mergeMetadata returns the metadata of the merged runs, which spans all of
+// them and lists the distinct values of their fields.
+func mergeMetadata(runs []labeledRun) report.Metadata {
+	var merged report.Metadata
+	var hosts, regions, oses, goVersions, versions, uplinkVersions, awsSDKVersions, configHashes []string
+	for _, run := range runs {
+		metadata := run.results.Metadata
+		if metadata == nil {
+			continue
+		}
+		hosts = appendUnique(hosts, metadata.Hostname)
+		regions = appendUnique(regions, metadata.Region)
+		oses = appendUnique(oses, metadata.OS)
+		goVersions = appendUnique(goVersions, metadata.GoVersion)
+		versions = appendUnique(versions, metadata.Version)
+		uplinkVersions = appendUnique(uplinkVersions, metadata.UplinkVersion)
+		awsSDKVersions = appendUnique(awsSDKVersions, metadata.AWSSDKVersion)
+		configHashes = appendUnique(configHashes, metadata.ConfigHash)
+
+		if merged.StartTime.IsZero() || metadata.StartTime.Before(merged.StartTime) {
+			merged.StartTime = metadata.StartTime
+		}
+		if metadata.EndTime.After(merged.EndTime) {
+			merged.EndTime = metadata.EndTime
+		}
+	}
+
+	merged.Hostname = strings.Join(hosts, ", ")
+	merged.Region = strings.Join(regions, ", ")
+	merged.OS = strings.Join(oses, ", ")
+	merged.GoVersion = strings.Join(goVersions, ", ")
+	merged.Version = strings.Join(versions, ", ")
+	merged.UplinkVersion = strings.Join(uplinkVersions, ", ")
+	merged.AWSSDKVersion = strings.Join(awsSDKVersions, ", ")
+	merged.ConfigHash = strings.Join(configHashes, ", ")
+	return merged
+}
+
+// appendUnique appends value to values unless it is empty or already in
+// them.
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// mergedEndpointIDs returns the sorted, labeled IDs of the endpoints of the
+// runs, which the merged report shows.
+func mergedEndpointIDs(runs []labeledRun) []config.ID {
+	seen := make(map[config.ID]bool)
+	var endpointIDs []config.ID
+	for _, run := range runs {
+		for _, result := range run.results.Results {
+			endpointID := report.LabeledEndpointID(run.label, result.EndpointID)
+			if !seen[endpointID] {
+				seen[endpointID] = true
+				endpointIDs = append(endpointIDs, endpointID)
+			}
+		}
+	}
+	sort.Slice(endpointIDs, func(i, j int) bool { return endpointIDs[i] < endpointIDs[j] })
+	return endpointIDs
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"github.com/spacemonkeygo/monkit/v3/environment"
+	"go.uber.org/zap"
+
+	"storj.io/common/telemetry"
+	"storj.io/perftester/config"
+)
+
+// startMetrics periodically pushes the monkit stats of the checks and
+// clients to the telemetry collector at the monitoring's address. The
+// returned function stops pushing after a final push, so that runs shorter
+// than the interval are reported as well.
+func startMetrics(ctx context.Context, log *zap.Logger, monitoring config.Monitoring) (stop func(), err error) {
+	environment.Register(monkit.Default)
+
+	client, err := telemetry.NewClient(monitoring.Address, telemetry.ClientOpts{
+		Interval:    time.Duration(monitoring.MetricsInterval),
+		Application: "perftester",
+		Instance:    monitoring.InstanceID,
+		Registry:    monkit.Default,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		client.Run(ctx)
+	}()
+
+	log.Info("Metrics enabled", zap.String("address", monitoring.Address))
+	return func() {
+		client.Stop()
+		wg.Wait()
+		if err := client.Report(context.Background()); err != nil {
+			log.Warn("Failed to send metrics", zap.Error(err))
+		}
+	}, nil
+}
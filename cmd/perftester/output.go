@@ -0,0 +1,46 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// reportExtensions are the file extensions of the built-in output formats.
+// Other formats use their name.
+var reportExtensions = map[string]string{
+	"text":     "txt",
+	"markdown": "md",
+	"html":     "html",
+	"json":     "json",
+}
+
+// writeReport writes the report of the run runID which started at start to
+// output. An output which is a directory or ends with a separator gets a
+// file in it named after the start in UTC, to the second, and the run ID,
+// such as report-20200501T120000Z-1a2b3c.json, so that runs starting
+// within the same minute or in different time zones don't overwrite each
+// other's reports.
+func writeReport(output, format, report string, start time.Time, runID string) (path string, err error) {
+	path = output
+	if info, err := os.Stat(output); strings.HasSuffix(output, string(filepath.Separator)) || (err == nil && info.IsDir()) {
+		if err := os.MkdirAll(output, 0755); err != nil {
+			return "", err
+		}
+		extension, ok := reportExtensions[format]
+		if !ok {
+			extension = format
+		}
+		name := "report-" + start.UTC().Format("20060102T150405Z")
+		if runID != "" {
+			name += "-" + runID
+		}
+		path = filepath.Join(output, name+"."+extension)
+	}
+	return path, ioutil.WriteFile(path, []byte(report), 0644)
+}
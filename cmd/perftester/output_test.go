@@ -0,0 +1,52 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+)
+
+func TestWriteReportNames(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	dir := ctx.Dir("reports")
+	start := time.Date(2020, 5, 1, 14, 0, 30, 0, time.FixedZone("CEST", 2*60*60))
+
+	// The name is the start in UTC, to the second, and the run ID.
+	path, err := writeReport(dir, "json", "first", start, "run1")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "report-20200501T120030Z-run1.json"), path)
+
+	// Runs within the same minute don't overwrite each other's reports.
+	second, err := writeReport(dir, "json", "second", start.Add(time.Second), "run2")
+	require.NoError(t, err)
+	require.NotEqual(t, path, second)
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "first", string(data))
+
+	// Nor do runs starting at the same second.
+	third, err := writeReport(dir, "text", "third", start, "run3")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "report-20200501T120030Z-run3.txt"), third)
+
+	// Runs without a run ID are named after their start.
+	fourth, err := writeReport(dir, "csv", "fourth", start, "")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "report-20200501T120030Z.csv"), fourth)
+
+	// Other outputs are written as they are.
+	file := ctx.File("report.md")
+	written, err := writeReport(file, "markdown", "fifth", start, "run5")
+	require.NoError(t, err)
+	require.Equal(t, file, written)
+}
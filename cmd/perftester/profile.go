@@ -0,0 +1,52 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+)
+
+// startProfiling profiles the CPU usage of the run into cpuPath, if set.
+// The returned function stops it and writes a heap profile into memPath,
+// if set, so that a client limiting the results can be looked into.
+func startProfiling(log *zap.Logger, cpuPath, memPath string) (stop func() error, err error) {
+	var cpuFile *os.File
+	if cpuPath != "" {
+		cpuFile, err = os.Create(cpuPath)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			return nil, errs.Combine(errs.Wrap(err), cpuFile.Close())
+		}
+		log.Info("CPU profiling enabled", zap.String("path", cpuPath))
+	}
+
+	return func() (err error) {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				return errs.Wrap(err)
+			}
+		}
+		if memPath == "" {
+			return nil
+		}
+
+		memFile, err := os.Create(memPath)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		defer func() { err = errs.Combine(err, memFile.Close()) }()
+
+		// Collecting first makes the profile show the live heap.
+		runtime.GC()
+		return errs.Wrap(pprof.WriteHeapProfile(memFile))
+	}, nil
+}
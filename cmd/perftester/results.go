@@ -0,0 +1,78 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/pkg/results"
+)
+
+var resultsConvertCfg struct {
+	SchemaVersion int    `default:"2" help:"version of the results schema to write JSON in"`
+	Format        string `default:"" help:"format to write: json or csv; inferred from the output file's extension if empty, json otherwise"`
+}
+
+// cmdResultsConvert converts results written by the json format, or in any
+// version of the results schema as JSON or CSV, to another version or
+// format.
+func cmdResultsConvert(cmd *cobra.Command, args []string) (err error) {
+	input, output := args[0], ""
+	if len(args) > 1 {
+		output = args[1]
+	}
+
+	format := resultsConvertCfg.Format
+	if format == "" {
+		format = "json"
+		if strings.EqualFold(filepath.Ext(output), ".csv") {
+			format = "csv"
+		}
+	}
+
+	data, err := ioutil.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	run, err := parseResults(data)
+	if err != nil {
+		return errs.New("%s: %v", input, err)
+	}
+
+	var converted []byte
+	switch format {
+	case "json":
+		converted, err = run.MarshalVersion(resultsConvertCfg.SchemaVersion)
+	case "csv":
+		var buf bytes.Buffer
+		err = run.WriteCSV(&buf)
+		converted = buf.Bytes()
+	default:
+		return errs.New("unknown format %q, must be json or csv", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Print(string(converted))
+		return nil
+	}
+	return ioutil.WriteFile(output, converted, 0644)
+}
+
+// parseResults parses results written as JSON or CSV.
+func parseResults(data []byte) (*results.Run, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return results.Parse(data)
+	}
+	return results.ReadCSV(bytes.NewReader(data))
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/internal/store"
+	"storj.io/perftester/internal/web"
+	"storj.io/private/process"
+)
+
+var serveCfg struct {
+	StorePath string        `default:"perftester.db" help:"SQLite database holding the stored results"`
+	Listen    string        `default:"localhost:8080" help:"address to serve the web UI on"`
+	Token     string        `default:"" help:"only serve requests with this token, as their bearer token or basic authentication password; required unless listening on a loopback address"`
+	Since     time.Duration `default:"720h" help:"default period of the throughput trends"`
+}
+
+// cmdServe serves a web UI over the stored results until the process is
+// stopped.
+func cmdServe(cmd *cobra.Command, _ []string) (err error) {
+	ctx, _ := process.Ctx(cmd)
+
+	log := logger
+
+	if err := checkListenToken(serveCfg.Listen, serveCfg.Token, "token"); err != nil {
+		return err
+	}
+
+	db, err := store.Open(ctx, serveCfg.StorePath)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errs.Combine(err, db.Close()) }()
+
+	listener, err := net.Listen("tcp", serveCfg.Listen)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: web.NewServer(log, db, serveCfg.Token, serveCfg.Since)}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	log.Info("Serving web UI", zap.Stringer("address", listener.Addr()))
+	err = server.Serve(listener)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
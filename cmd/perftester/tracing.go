@@ -0,0 +1,49 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"context"
+	"sync"
+
+	monkit "github.com/spacemonkeygo/monkit/v3"
+	"go.uber.org/zap"
+
+	jaeger "storj.io/monkit-jaeger"
+	"storj.io/perftester/config"
+)
+
+// startTracing sends the spans of every check, operation and client call to
+// the Jaeger agent at the monitoring's tracing URL. The returned function
+// stops tracing and flushes the remaining spans.
+func startTracing(ctx context.Context, log *zap.Logger, monitoring config.Monitoring) (stop func(), err error) {
+	var tags []jaeger.Tag
+	if monitoring.InstanceID != "" {
+		tags = append(tags, jaeger.Tag{Key: "instanceID", Value: monitoring.InstanceID})
+	}
+
+	collector, err := jaeger.NewUDPCollector(log, monitoring.TracingURL, "perftester", tags, 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	unregister := jaeger.RegisterJaeger(monkit.Default, collector, jaeger.Options{Fraction: 1})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		collector.Run(ctx)
+	}()
+
+	log.Info("Tracing enabled", zap.String("agent", monitoring.TracingURL))
+	return func() {
+		unregister()
+		collector.Stop()
+		wg.Wait()
+		if err := collector.Send(context.Background()); err != nil {
+			log.Warn("Failed to send remaining spans", zap.Error(err))
+		}
+		_ = collector.Close()
+	}, nil
+}
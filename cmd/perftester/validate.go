@@ -0,0 +1,154 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	cli "storj.io/perftester/backends"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+	"storj.io/private/process"
+)
+
+var validateCfg struct {
+	ConfigPath string        `default:"config.toml" help:"configuration file location"`
+	Suite      string        `default:"" help:"if set, only validate the file tests and endpoints of this suite from the config"`
+	FileTests  string        `default:"" help:"comma separated file tests to validate, overriding the suite; all if empty"`
+	Endpoints  string        `default:"" help:"comma separated endpoints to validate, overriding the suite; all if empty"`
+	Timeout    time.Duration `default:"30s" help:"timeout of the connectivity check of each endpoint"`
+	Offline    bool          `default:"false" help:"only validate the config, without connecting to the endpoints"`
+}
+
+// cmdValidate loads and validates the config, checks that every endpoint
+// can be reached unless offline and prints what would run, without
+// uploading anything.
+func cmdValidate(cmd *cobra.Command, _ []string) (err error) {
+	ctx, _ := process.Ctx(cmd)
+
+	conf, err := loadValidConfig(validateCfg.ConfigPath, validateCfg.Suite, validateCfg.FileTests, validateCfg.Endpoints)
+	if err != nil {
+		return err
+	}
+
+	fileTestIDs := make([]config.ID, 0, len(conf.FileTests))
+	for id := range conf.FileTests {
+		fileTestIDs = append(fileTestIDs, id)
+	}
+	sort.Slice(fileTestIDs, func(i, j int) bool { return fileTestIDs[i] < fileTestIDs[j] })
+
+	fileTestRows := [][]string{{"File", "Type", "Size", "Objects", "Parallel", "Iterations"}}
+	for _, id := range fileTestIDs {
+		fileTest := conf.FileTests[id].WithDefaults(conf.Timeout)
+		objects := strconv.FormatInt(fileTest.NumObjects, 10)
+		parallel := strconv.FormatInt(fileTest.NumParallel, 10)
+		switch fileTest.Type {
+		case config.ExistingTest:
+			if fileTest.NumObjects <= 0 {
+				objects = "all"
+			}
+		case config.RampTest:
+			var levels []string
+			for _, level := range fileTest.Ramp {
+				levels = append(levels, strconv.FormatInt(level, 10))
+			}
+			objects, parallel = "-", strings.Join(levels, ",")
+		}
+		fileTestRows = append(fileTestRows, []string{
+			string(id),
+			string(fileTest.Type),
+			strconv.FormatInt(int64(fileTest.Size), 10),
+			objects,
+			parallel,
+			strconv.FormatInt(fileTest.Iterations, 10),
+		})
+	}
+
+	factories, err := endpointFactories(zap.NewNop(), conf)
+	if err != nil {
+		return err
+	}
+	sort.Slice(factories, func(i, j int) bool { return factories[i].ID < factories[j].ID })
+
+	var failed []string
+	endpointRows := [][]string{{"Endpoint", "Status"}}
+	for _, factory := range factories {
+		status := "ok"
+		if validateCfg.Offline {
+			status = "not checked"
+		} else if err := checkEndpoint(ctx, factory); err != nil {
+			status = err.Error()
+			failed = append(failed, string(factory.ID))
+		}
+		endpointRows = append(endpointRows, []string{string(factory.ID), status})
+	}
+
+	for _, rows := range [][][]string{fileTestRows, endpointRows} {
+		table, err := report.MakeTable(rows, "-")
+		if err != nil {
+			return err
+		}
+		fmt.Println(table)
+	}
+
+	if len(failed) > 0 {
+		return errs.New("unreachable endpoints: %q", failed)
+	}
+	return nil
+}
+
+// loadValidConfig loads the config at path with the selected file tests and
+// endpoints, and validates it.
+func loadValidConfig(path, suite, fileTests, endpoints string) (config.Config, error) {
+	conf, err := config.LoadConfig(path)
+	if err != nil {
+		return conf, err
+	}
+	conf, err = filterConfig(conf, suite, fileTests, endpoints)
+	if err != nil {
+		return conf, err
+	}
+	if err := conf.Validate(); err != nil {
+		return conf, err
+	}
+	if conf.OutputFormat != "" {
+		if _, err := report.NewFormatter(conf.OutputFormat, nil); err != nil {
+			return conf, err
+		}
+	}
+	return conf, nil
+}
+
+// checkEndpoint creates the client of an endpoint and makes a lightweight
+// request to it.
+func checkEndpoint(ctx context.Context, factory endpointFactory) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, validateCfg.Timeout)
+	defer cancel()
+
+	client, err := factory.newClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errs.Combine(err, client.Close()) }()
+
+	if cli.IsReadOnly(client) {
+		strm, err := client.DownloadRange(ctx, "", 0, 1)
+		if err != nil {
+			return err
+		}
+		return strm.Close()
+	}
+
+	_, err = client.List(ctx, "", false)
+	return err
+}
@@ -0,0 +1,1248 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package config defines the config of perftester, with its file tests and
+// endpoints, and the results of operations which checks report.
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zeebo/errs"
+
+	"storj.io/common/memory"
+	"storj.io/perftester/backends"
+)
+
+// An ID is any arbitrary sring
+type ID string
+
+// Config is the config for the tests.
+type Config struct {
+	FileTests  map[ID]FileTest `toml:"filetest"`
+	Endpoints  Endpoints       `toml:"endpoint"`
+	Suites     map[ID]Suite    `toml:"suite"`
+	Monitoring Monitoring      `toml:"monitoring"`
+	Timeout    Duration        `toml:"timeout"`
+	Thresholds Thresholds      `toml:"thresholds"`
+	SLA        SLA             `toml:"sla"`
+
+	// Concurrency is the number of checks run at once. Defaults to 1.
+	Concurrency int `toml:"concurrency"`
+	// SerializeEndpoints limits each endpoint to one running check at a
+	// time, even when Concurrency allows more.
+	SerializeEndpoints bool `toml:"serialize_endpoints"`
+	// Simultaneous runs each file test on all endpoints at once, starting
+	// their measured operations together so that the endpoints share the
+	// same network conditions. File tests run one after another, and
+	// Concurrency and SerializeEndpoints are ignored.
+	Simultaneous bool `toml:"simultaneous"`
+	// Shuffle runs the checks of the file tests on the endpoints in a
+	// random order, with the iterations of each check run as separate
+	// checks interleaved with the others, so that neither the time of day
+	// nor warm caches favor the endpoints which would run first.
+	Shuffle bool `toml:"shuffle"`
+	// Cooldown is the pause between checks, from the end of one check to
+	// the start of the next.
+	Cooldown Duration `toml:"cooldown"`
+	// ShuffleSeed seeds the order of shuffled runs, to repeat the order of
+	// an earlier run. A random seed is used and reported if it is zero.
+	ShuffleSeed int64 `toml:"shuffle_seed"`
+	// ProgressInterval is how often the progress of running transfers is
+	// logged. Progress is not logged when it is zero.
+	ProgressInterval Duration `toml:"progress_interval"`
+	// GeoIPDatabase is a MaxMind City or Country database to locate the
+	// IPs of the endpoints with in the report.
+	GeoIPDatabase string `toml:"geoip_database"`
+	// BufferSize is the size of the buffers transfers are copied through.
+	// Defaults to 1MiB.
+	BufferSize ByteSize `toml:"buffer_size"`
+	// RunPrefix puts the objects of every run under a directory named by
+	// its run ID, so that runs sharing a bucket don't overwrite or delete
+	// each other's objects.
+	RunPrefix bool `toml:"run_prefix"`
+	// OutputFormat is the name of the registered output format of the
+	// report, unless it is given on the command line. Defaults to text.
+	OutputFormat string `toml:"output_format"`
+
+	// matrices are the IDs of the file tests generated from each matrix
+	// file test.
+	matrices map[ID][]ID
+	// meta decodes the endpoints.
+	meta toml.MetaData
+	// untrusted configs came from the network, so references to
+	// environment variables and files in their strings are refused.
+	untrusted bool
+	// sources are where the endpoints of extended configs were parsed
+	// from, by type and ID.
+	sources map[string]map[ID]endpointSource
+}
+
+// Thresholds define when a run counts as failed.
+type Thresholds struct {
+	// FailOnError fails the run if any operation failed.
+	FailOnError bool `toml:"fail_on_error"`
+	// MaxErrorRate fails the run if more than this percentage of the
+	// operations on an endpoint failed. It is disabled when zero.
+	MaxErrorRate float64 `toml:"max_error_rate"`
+	// MinThroughput fails the run if the mean throughput of any transfer
+	// on an endpoint is below this many Mbps.
+	MinThroughput map[ID]float64 `toml:"min_throughput"`
+}
+
+// SLA are the service level rules the results of a run are checked
+// against, such as:
+//
+//	[[sla.rule]]
+//	endpoint = "storj"
+//	operation = "download"
+//	min_size = "1MB"
+//	min_throughput = 100
+//	max_latency = "2s"
+//
+// Rules which match no results fail, since a misspelled rule would pass
+// unnoticed, unless AllowNoData is set for runs of parts of the config.
+type SLA struct {
+	Rules       []SLARule `toml:"rule"`
+	AllowNoData bool      `toml:"allow_no_data"`
+}
+
+// SLARule is a minimum throughput and a maximum latency of the results of
+// an endpoint, operation and size class, each of which matches all results
+// when unset.
+type SLARule struct {
+	// Name labels the rule in the report. Defaults to a description of
+	// the rule.
+	Name      string `toml:"name"`
+	Endpoint  ID     `toml:"endpoint"`
+	Operation string `toml:"operation"` // Name of an operation, as in FileTest.Operations.
+	// MinSize and MaxSize limit the rule to file tests of these sizes. An
+	// unset MaxSize doesn't limit the size.
+	MinSize ByteSize `toml:"min_size"`
+	MaxSize ByteSize `toml:"max_size"`
+
+	// MinThroughput is the minimum mean throughput in Mbps of transfers of
+	// whole files.
+	MinThroughput float64 `toml:"min_throughput"`
+	// MaxLatency is the maximum mean latency of the individual operations,
+	// or of whole results where they don't time their objects.
+	MaxLatency Duration `toml:"max_latency"`
+}
+
+// Matches returns whether the rule applies to the results of the operation
+// of a file test of size on endpoint.
+func (rule SLARule) Matches(endpointID ID, operation Operation, size int64) bool {
+	if rule.Endpoint != "" && rule.Endpoint != endpointID {
+		return false
+	}
+	if rule.Operation != "" {
+		if operation == MultipartUpload {
+			operation = Upload
+		}
+		if named, ok := OperationNames[rule.Operation]; !ok || named != operation {
+			return false
+		}
+	}
+	return size >= int64(rule.MinSize) && (rule.MaxSize == 0 || size <= int64(rule.MaxSize))
+}
+
+// FileTest defines a test to run on a file.
+type FileTest struct {
+	Type        TestType `toml:"type"`
+	NumParallel int64    `toml:"numparallel"`
+	NumObjects  int64    `toml:"numobjects"` // Number of objects to transfer, NumParallel at a time. Defaults to NumParallel.
+	Iterations  int64    `toml:"iterations"` // Number of times to repeat each operation.
+	Timeout     Duration `toml:"timeout"`
+	Size        ByteSize `toml:"size"` // Size to test in bytes, such as 1000, "100MB" or "5GiB".
+	Seed        int64    `toml:"seed"` // Custom seed to make file unique.
+
+	// Sizes and Parallelism turn the file test into a matrix, which is
+	// expanded into one file test for every combination of their values.
+	Sizes       []ByteSize `toml:"sizes"`
+	Parallelism []int64    `toml:"parallelism"`
+	// KeyDistributions turn the file test into a matrix as well, with one
+	// file test per key distribution, so that their throughput is
+	// reported side by side.
+	KeyDistributions []KeyDistribution `toml:"key_distributions"`
+
+	// Content selects the generator of the file contents. Defaults to
+	// pseudo-random bytes.
+	Content ContentType `toml:"content"`
+	// ContentDir is the directory of real files used by corpus contents.
+	ContentDir string `toml:"content_dir"`
+	// Verify can be set to false to only check the size of downloads
+	// instead of hashing their contents. Defaults to true.
+	Verify *bool `toml:"verify"`
+	// Checksum selects the hash downloads are verified with. Defaults to
+	// SHA-256, whose CPU cost can bottleneck the transfers of large files,
+	// which the faster non-cryptographic hashes avoid.
+	Checksum Checksum `toml:"checksum"`
+	// SeparateVerify verifies the downloads of download checks on a second,
+	// untimed read of every object, so that hashing never counts against
+	// the measured throughput. The timed downloads only check the size of
+	// the objects, which are transferred twice then.
+	SeparateVerify bool `toml:"separate_verify"`
+
+	// Warmup is the number of unrecorded cycles to run before measuring.
+	Warmup int64 `toml:"warmup"`
+	// WarmupDuration keeps running unrecorded cycles for at least this long.
+	WarmupDuration Duration `toml:"warmup_duration"`
+
+	// Retries is the number of times a failed operation is retried.
+	Retries int64 `toml:"retries"`
+	// RetryBackoff is the delay before the first retry, doubled for each
+	// further retry.
+	RetryBackoff Duration `toml:"retry_backoff"`
+	// Cooldown is the pause before every measured operation, so that the
+	// previous operation doesn't interfere with its measurement through
+	// the NIC or CPU of the client, and provider rate limits are kept.
+	Cooldown Duration `toml:"cooldown"`
+
+	// Ranges are the byte ranges fetched by the range download check.
+	Ranges []Range `toml:"ranges"`
+
+	// Copy enables the server-side copy check.
+	Copy bool `toml:"copy"`
+	// Versions enables the versioning check, which uploads this many
+	// versions of every object, lists them and deletes them one by one.
+	// Defaults to 2 when versioning operations are selected explicitly.
+	Versions int64 `toml:"versions"`
+	// Metadata enables the metadata check. Uploads attach this custom
+	// metadata to the objects, which the check reads back and sets on them
+	// again. A single key is used when metadata operations are selected
+	// explicitly without any. Keys must be lower case, since some backends
+	// lowercase them. S3 stores the metadata in object tags, so there can
+	// be at most 10 keys, or 9 with a TTL, whose tag takes the last one.
+	Metadata map[string]string `toml:"metadata"`
+
+	// Operations selects the operations to run, such as ["download"] to
+	// benchmark objects uploaded by an earlier run with the same seed, or
+	// ["upload"] to leave the objects in place. Defaults to all of them.
+	// Downloads are only verified with a fixed seed, and objects uploaded by
+	// other tools need verify = false.
+	Operations []string `toml:"operations"`
+
+	// PartSize switches uploads to multipart uploads with parts of this
+	// many bytes.
+	PartSize int64 `toml:"part_size"`
+	// PartConcurrency is the number of parts uploaded at once, where the
+	// backend supports it.
+	PartConcurrency int `toml:"part_concurrency"`
+
+	// Ramp are the parallelism levels of ramp tests. Defaults to 1, 2, 4,
+	// 8 and 16.
+	Ramp []int64 `toml:"ramp"`
+
+	// Duration is how long soak tests keep running each operation.
+	Duration Duration `toml:"duration"`
+	// SoakInterval is the length of the time buckets soak tests report
+	// throughput in. Defaults to one minute.
+	SoakInterval Duration `toml:"soak_interval"`
+
+	// ReadPercent is the percentage of the operations of mixed tests which
+	// are downloads. Defaults to 70, and can be set to 0 for uploads only.
+	ReadPercent *int64 `toml:"read_percent"`
+
+	// CacheDelay is the delay between the first and the repeated downloads
+	// of cache tests. They follow each other right away by default.
+	CacheDelay Duration `toml:"cache_delay"`
+
+	// PollInterval is the pause between the checks of consistency tests for
+	// whether an object became visible or disappeared. Defaults to 10ms.
+	PollInterval Duration `toml:"poll_interval"`
+
+	// PageSizes are the numbers of entries per page the lists of listing
+	// tests request, each page size listed once per iteration. The
+	// backend's default page size is listed when there are none, and
+	// backends which can't choose the page size always list with it.
+	PageSizes []int64 `toml:"page_sizes"`
+
+	// Prefix is the directory of the objects which existing tests
+	// download. All of its objects are downloaded, unless NumObjects
+	// limits them to the first ones by name. Other tests upload their
+	// objects to it.
+	Prefix string `toml:"prefix"`
+	// NameTemplate is a text/template of the object names of tests other
+	// than existing ones, such as "{{.RunID}}/{{.FileTestID}}/{{.Index}}.bin",
+	// to test key layouts like deep or hashed prefixes. See ObjectName for
+	// its fields. The names are put under Prefix and the prefix of the key
+	// distribution. Cleanup only finds objects with the default names
+	// which aren't distributed.
+	NameTemplate string `toml:"name_template"`
+	// KeyDistribution spreads the objects over prefixes, to test how
+	// backends handle hot and spread key spaces. Objects aren't spread by
+	// default.
+	KeyDistribution KeyDistribution `toml:"key_distribution"`
+	// NumPrefixes is the number of prefixes sequential and hashed key
+	// distributions spread the objects over. Defaults to 16.
+	NumPrefixes int64 `toml:"num_prefixes"`
+	// RunID is the ID of the run the file test is checked in, which the
+	// checker sets for name templates.
+	RunID string `toml:"-"`
+	// Manifest is a file of the digests of the objects of existing tests,
+	// hashed with the file test's checksum, in the format of sha256sum with
+	// names relative to the prefix. Downloads aren't verified without one.
+	Manifest string `toml:"manifest"`
+
+	// RateLimit throttles every upload and download stream to this rate.
+	// Streams are not throttled when it is zero.
+	RateLimit Rate `toml:"rate_limit"`
+
+	// TTL makes the backend delete uploaded objects this long after their
+	// upload, so that the objects of crashed runs don't linger. Backends
+	// without expiration keep them.
+	TTL Duration `toml:"ttl"`
+}
+
+// OperationNames are the names of the operations which can be selected in
+// FileTest.Operations. Upload selects multipart uploads as well.
+var OperationNames = map[string]Operation{
+	"upload":            Upload,
+	"download":          Download,
+	"copy":              Copy,
+	"range_download":    RangeDownload,
+	"delete":            Delete,
+	"put_version":       PutVersion,
+	"list_versions":     ListVersions,
+	"delete_version":    DeleteVersion,
+	"repeat_download":   RepeatDownload,
+	"create_bucket":     CreateBucket,
+	"list_buckets":      ListBuckets,
+	"delete_bucket":     DeleteBucket,
+	"get_metadata":      GetMetadata,
+	"set_metadata":      SetMetadata,
+	"read_after_write":  ReadAfterWrite,
+	"list_after_write":  ListAfterWrite,
+	"read_after_delete": ReadAfterDelete,
+	"list_after_delete": ListAfterDelete,
+	"conflict_write":    ConflictWrite,
+	"conflict_verify":   ConflictVerify,
+	"list_recursive":    ListRecursive,
+	"list_walk":         ListWalk,
+}
+
+// Runs returns whether the operation is selected by the file test. Copies,
+// versioning and metadata operations are only run when enabled or selected
+// explicitly. Unknown names, which Validate rejects, select nothing.
+func (fileTest FileTest) Runs(operation Operation) bool {
+	if operation == MultipartUpload {
+		operation = Upload
+	}
+	if len(fileTest.Operations) == 0 {
+		switch operation {
+		case Copy:
+			return fileTest.Copy
+		case PutVersion, ListVersions, DeleteVersion:
+			return fileTest.Versions > 0
+		case GetMetadata, SetMetadata:
+			return len(fileTest.Metadata) > 0
+		default:
+			return true
+		}
+	}
+	for _, name := range fileTest.Operations {
+		if named, ok := OperationNames[name]; ok && named == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifies returns whether downloads of the file test are verified against
+// the hashes of the expected contents.
+func (fileTest FileTest) Verifies() bool {
+	return fileTest.Verify == nil || *fileTest.Verify
+}
+
+// defaultReadPercent is the share of downloads of mixed tests without a
+// configured read percent.
+const defaultReadPercent = 70
+
+// Reads returns the percentage of the operations of mixed tests which are
+// downloads.
+func (fileTest FileTest) Reads() int64 {
+	if fileTest.ReadPercent == nil {
+		return defaultReadPercent
+	}
+	return *fileTest.ReadPercent
+}
+
+// TestType selects how a FileTest is run.
+type TestType string
+
+const (
+	// ThroughputTest transfers whole files and measures throughput. It is
+	// the default.
+	ThroughputTest TestType = "throughput"
+	// LatencyTest transfers many small objects and measures the rate and
+	// latency of individual operations.
+	LatencyTest TestType = "latency"
+	// RampTest repeats the throughput test at increasing parallelism, to
+	// find where an endpoint saturates.
+	RampTest TestType = "ramp"
+	// SoakTest keeps uploading and downloading for a duration and measures
+	// the sustained throughput over time.
+	SoakTest TestType = "soak"
+	// MixedTest runs uploads and downloads at the same time and compares
+	// their throughput to isolated runs.
+	MixedTest TestType = "mixed"
+	// CacheTest downloads the objects twice in a row and compares the time
+	// to first byte and throughput of the repeated downloads, to quantify
+	// the effect of edge and CDN caches.
+	CacheTest TestType = "cache"
+	// BucketTest creates NumObjects buckets, NumParallel at a time, lists
+	// the buckets and deletes them again, measuring the latency of each
+	// bucket operation. It transfers no files.
+	BucketTest TestType = "bucket"
+	// ExistingTest downloads objects which already exist on the endpoints,
+	// such as real data sets, instead of uploading its own. Their
+	// throughput is reported for objects of the file test's size, if set,
+	// so objects of other sizes fail the test on backends which list sizes.
+	ExistingTest TestType = "existing"
+	// ConsistencyTest uploads and deletes objects, NumParallel at a time,
+	// and measures how long it takes until stats and lists of the objects
+	// reflect the change, which distinguishes strongly consistent
+	// endpoints from eventually consistent ones.
+	ConsistencyTest TestType = "consistency"
+	// ConflictTest writes every object from NumParallel writers at once,
+	// each with different contents, and then downloads the objects to find
+	// out which writer won and whether the writes corrupted them.
+	ConflictTest TestType = "conflict"
+	// ListingTest uploads NumObjects objects, NumParallel at a time, and
+	// measures how long full lists of them take, with a single recursive
+	// list and by walking their directories, at each of the PageSizes.
+	ListingTest TestType = "listing"
+)
+
+// ContentType selects how the contents of a FileTest's files are generated.
+type ContentType string
+
+const (
+	// RandomContent are fast pseudo-random bytes generated by xoshiro256**.
+	// It is the default.
+	RandomContent ContentType = "random"
+	// ZeroContent are zero bytes.
+	ZeroContent ContentType = "zero"
+	// TextContent is highly compressible text.
+	TextContent ContentType = "text"
+	// CryptoContent are cryptographically random bytes, which defeat any
+	// compression or deduplication.
+	CryptoContent ContentType = "crypto"
+	// CorpusContent are the concatenated files of ContentDir.
+	CorpusContent ContentType = "corpus"
+)
+
+// Checksum selects the hash the downloads of a FileTest are verified with.
+type Checksum string
+
+const (
+	// SHA256Checksum is SHA-256, which uses the SHA extensions of CPUs
+	// that have them. It is the default.
+	SHA256Checksum Checksum = "sha256"
+	// XXHashChecksum is the 64-bit xxHash, a fast non-cryptographic hash.
+	XXHashChecksum Checksum = "xxhash"
+	// CRC32CChecksum is CRC-32 with the Castagnoli polynomial, which uses
+	// the CRC instructions of amd64 and arm64 CPUs.
+	CRC32CChecksum Checksum = "crc32c"
+	// BLAKE3Checksum is BLAKE3, a cryptographic hash which uses the SIMD
+	// instructions of the CPU.
+	BLAKE3Checksum Checksum = "blake3"
+)
+
+// KeyDistribution selects how the objects of a FileTest are spread over
+// prefixes.
+type KeyDistribution string
+
+const (
+	// SequentialKeys put consecutive objects under the same prefix, so
+	// that the transfers running at once hit the same key range.
+	SequentialKeys KeyDistribution = "sequential"
+	// HashedKeys put the objects under prefixes chosen by a hash of their
+	// names, which is the same for every run.
+	HashedKeys KeyDistribution = "hashed"
+	// RandomKeys put every object under its own random prefix, which is
+	// different for every seed.
+	RandomKeys KeyDistribution = "random"
+)
+
+// Range is a byte range of a file.
+type Range struct {
+	Offset int64 `toml:"offset"`
+	Length int64 `toml:"length"` // Negative to read until the end of the file.
+}
+
+// Endpoints are the undecoded tables of the remote endpoints by type and
+// ID. Each is decoded into the config of the client registered for its
+// type, see DecodeEndpoints.
+type Endpoints map[string]map[ID]toml.Primitive
+
+// Endpoint is a generic endpoint.
+type Endpoint struct {
+	ID       ID
+	Bucket   string
+	Path     string
+	Client   backends.Client
+	Defaults EndpointDefaults
+}
+
+// EndpointDefaults override the settings of the file tests run on an
+// endpoint, such as longer timeouts for a slow on-prem gateway. Zero values
+// don't override anything.
+type EndpointDefaults struct {
+	Timeout Duration `toml:"timeout"`
+	// MaxParallel caps the number of objects transferred at once.
+	MaxParallel  int64    `toml:"max_parallel"`
+	Retries      int64    `toml:"retries"`
+	RetryBackoff Duration `toml:"retry_backoff"`
+	Cooldown     Duration `toml:"cooldown"`
+}
+
+// Apply returns the file test with the endpoint defaults applied.
+func (defaults EndpointDefaults) Apply(fileTest FileTest) FileTest {
+	if defaults.Timeout > 0 {
+		fileTest.Timeout = defaults.Timeout
+	}
+	if defaults.MaxParallel > 0 && fileTest.NumParallel > defaults.MaxParallel {
+		fileTest.NumParallel = defaults.MaxParallel
+	}
+	if defaults.Retries > 0 {
+		fileTest.Retries = defaults.Retries
+	}
+	if defaults.RetryBackoff > 0 {
+		fileTest.RetryBackoff = defaults.RetryBackoff
+	}
+	if defaults.Cooldown > 0 {
+		fileTest.Cooldown = defaults.Cooldown
+	}
+	return fileTest
+}
+
+// StorjEndpoint represents a storj endpoint.
+type StorjEndpoint struct {
+	Access string    `toml:"access" secret:"true"`
+	Bucket string    `toml:"bucket"`
+	Path   string    `toml:"path"`
+	Mode   StorjMode `toml:"mode"`
+	Client backends.Client
+
+	// GatewayAddress, GatewayAccessKey and GatewaySecretKey are the S3
+	// gateway address and the credentials registered for the access, used
+	// in gateway mode.
+	GatewayAddress   string `toml:"gateway_address"`
+	GatewayAccessKey string `toml:"gateway_access_key"`
+	GatewaySecretKey string `toml:"gateway_secret_key" secret:"true"`
+
+	// LinkshareURL is the raw linkshare URL of the bucket, such as
+	// https://link.us1.storjshare.io/raw/<access key>/<bucket>, used in
+	// linkshare mode.
+	LinkshareURL string `toml:"linkshare_url" secret:"true"`
+
+	// Presign transfers objects of the gateway mode through presigned
+	// URLs, like S3 endpoints with Presign.
+	Presign bool `toml:"presign"`
+
+	// DialTimeout, UserAgent, SegmentSize and Transport tune the uplink of
+	// the native and linkshare modes, so that runs can compare them. Unset
+	// ones keep uplink's defaults.
+	DialTimeout Duration       `toml:"dial_timeout"`
+	UserAgent   string         `toml:"user_agent"`
+	SegmentSize ByteSize       `toml:"segment_size"` // Maximum size of the segments of uploads.
+	Transport   StorjTransport `toml:"transport"`
+
+	// Parallelism is the number of consecutive chunks of ChunkSize bytes,
+	// 64MiB by default, an object is downloaded in at once, like uplink's
+	// --parallelism. It is ignored for range downloads, and uploads stay
+	// sequential, since uplink uploads one segment at a time. Chunks are
+	// buffered until they are read, so each download holds up to
+	// Parallelism times ChunkSize bytes of memory, which must not exceed
+	// 1GiB.
+	Parallelism int      `toml:"parallelism"`
+	ChunkSize   ByteSize `toml:"chunk_size"`
+
+	EndpointDefaults
+}
+
+// StorjMode selects how a storj endpoint is accessed.
+type StorjMode string
+
+const (
+	// StorjNative uses uplink for every operation. It is the default.
+	StorjNative StorjMode = "native"
+	// StorjGateway uses the S3 compatible gateway for every operation.
+	StorjGateway StorjMode = "gateway"
+	// StorjLinkshare uses uplink, but downloads through linksharing.
+	StorjLinkshare StorjMode = "linkshare"
+)
+
+// StorjTransport selects the transport uplink connects to satellites and
+// storage nodes over.
+type StorjTransport string
+
+const (
+	// StorjTCP connects over TLS on TCP. It is the default.
+	StorjTCP StorjTransport = "tcp"
+	// StorjQUIC connects over QUIC, which the vendored uplink doesn't
+	// support yet, so it is rejected by validation.
+	StorjQUIC StorjTransport = "quic"
+)
+
+// S3Endpoint is the represents an S3 endpoint.
+type S3Endpoint struct {
+	Region    string `toml:"region"`
+	AccessKey string `toml:"access_key"`
+	SecretKey string `toml:"secret_key" secret:"true"`
+	Bucket    string `toml:"bucket"`
+	Path      string `toml:"path"`
+	Address   string `toml:"address"`
+	Client    backends.Client
+
+	// PathStyle addresses buckets in the URL path instead of the host name,
+	// as MinIO and most on-prem gateways require.
+	PathStyle bool `toml:"path_style"`
+	// MaxIdleConns is the number of idle connections kept open. Defaults
+	// to Go's default of 2.
+	MaxIdleConns       int  `toml:"max_idle_conns"`
+	DisableHTTP2       bool `toml:"disable_http2"`
+	InsecureSkipVerify bool `toml:"insecure_skip_verify"` // Don't verify the TLS certificate.
+	// ConnectTimeout limits how long connecting may take and ReadTimeout
+	// how long a connection may wait for data. Both are unlimited when zero.
+	ConnectTimeout Duration `toml:"connect_timeout"`
+	ReadTimeout    Duration `toml:"read_timeout"`
+
+	// Accelerate uses S3 Transfer Acceleration, which must be enabled on
+	// the bucket. Dualstack uses the IPv4 and IPv6 endpoints.
+	Accelerate bool `toml:"accelerate"`
+	Dualstack  bool `toml:"dualstack"`
+
+	// DownloadConcurrency switches downloads from a single streamed request
+	// to s3manager's downloader, which gets that many ranges of
+	// DownloadPartSize bytes, 5MiB by default, at once. UploadConcurrency
+	// and UploadPartSize tune the uploader, which defaults to 5 parts of
+	// 5MiB at once.
+	DownloadConcurrency int      `toml:"download_concurrency"`
+	DownloadPartSize    ByteSize `toml:"download_part_size"`
+	UploadConcurrency   int      `toml:"upload_concurrency"`
+	UploadPartSize      ByteSize `toml:"upload_part_size"`
+
+	// Presign transfers objects with a plain HTTP client through presigned
+	// URLs, like browsers do, instead of the SDK. Lists, deletes and copies
+	// still use the SDK.
+	Presign bool `toml:"presign"`
+
+	// ManageLifecycle adds a lifecycle rule expiring the objects of file
+	// tests with a TTL to the bucket, keeping its other rules. Otherwise
+	// such objects only expire if the bucket has the rule already.
+	ManageLifecycle bool `toml:"manage_lifecycle"`
+
+	EndpointDefaults
+}
+
+// GCSEndpoint represents a Google Cloud Storage endpoint.
+type GCSEndpoint struct {
+	CredentialsFile string `toml:"credentials_file"`               // Path to a service account JSON key.
+	CredentialsJSON string `toml:"credentials_json" secret:"true"` // Inline service account JSON key.
+	Bucket          string `toml:"bucket"`
+	Path            string `toml:"path"`
+	// ProjectID is the project buckets are created in and listed from by
+	// bucket tests, which don't run without it.
+	ProjectID string `toml:"project_id"`
+
+	EndpointDefaults
+}
+
+// WebDAVEndpoint represents a WebDAV endpoint, such as Nextcloud or ownCloud.
+type WebDAVEndpoint struct {
+	URL      string `toml:"url"` // Root of the WebDAV share.
+	Username string `toml:"username"`
+	Password string `toml:"password" secret:"true"`
+	Path     string `toml:"path"` // Existing collection to test in.
+
+	EndpointDefaults
+}
+
+// HTTPEndpoint represents a read-only endpoint which serves a single file
+// over HTTP, such as a CDN edge or a linkshare URL. The file test size must
+// match the size of the file. The URL is a secret, since linkshare and
+// presigned URLs grant access.
+type HTTPEndpoint struct {
+	URL string `toml:"url" secret:"true"`
+
+	EndpointDefaults
+}
+
+// Monitoring is the monitoring config information.
+type Monitoring struct {
+	Address    string `toml:"address"`     // Telemetry collector to push monkit stats to.
+	InstanceID string `toml:"instance_id"` // Identifies this instance in metrics and traces.
+	TracingURL string `toml:"tracing_url"` // Jaeger agent address to send the spans of all operations to.
+
+	MetricsInterval Duration `toml:"metrics_interval"` // How often monkit stats are pushed. Defaults to one minute.
+
+	PrometheusAddress string `toml:"prometheus_address"` // Address to serve Prometheus metrics on.
+	PushgatewayURL    string `toml:"pushgateway_url"`    // Pushgateway to push Prometheus metrics to.
+
+	Pprof      bool   `toml:"pprof"`                     // Also serve pprof profiles on the Prometheus address in daemon mode.
+	PprofToken string `toml:"pprof_token" secret:"true"` // Bearer token pprof requests need, unless the Prometheus address is a loopback address.
+}
+
+// Duration assists in parsing duration data in the toml file.
+type Duration time.Duration
+
+// UnmarshalText unmarshals the duration text from toml.
+func (d *Duration) UnmarshalText(data []byte) error {
+	duration, err := time.ParseDuration(string(data))
+	if err == nil {
+		*d = Duration(duration)
+	}
+	return err
+}
+
+// MarshalText marshals the duration into toml.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// ByteSize assists in parsing sizes with units, such as "100MB" or "5GiB",
+// in the toml file. Plain numbers are bytes.
+type ByteSize int64
+
+// UnmarshalText unmarshals the size text from toml.
+func (size *ByteSize) UnmarshalText(data []byte) error {
+	var parsed memory.Size
+	if err := parsed.Set(strings.TrimSpace(string(data))); err != nil {
+		return errs.New("invalid size %q: %v", data, err)
+	}
+	*size = ByteSize(parsed)
+	return nil
+}
+
+// MarshalText marshals the size into toml, in the largest unit which
+// represents it exactly.
+func (size ByteSize) MarshalText() ([]byte, error) {
+	return []byte(sizeLabel(size)), nil
+}
+
+// Rate assists in parsing transfer rates in the toml file. It is in bytes
+// per second, but can be given in bits per second, such as "50Mbps", or in
+// bytes per second, such as "10MB/s". Plain numbers are bytes per second.
+type Rate int64
+
+// UnmarshalText unmarshals the rate text from toml.
+func (rate *Rate) UnmarshalText(data []byte) error {
+	text := strings.TrimSpace(string(data))
+
+	var parsed memory.Size
+	if bits := strings.TrimSuffix(text, "bps"); bits != text {
+		if err := parsed.Set(bits + "B"); err != nil {
+			return errs.New("invalid rate %q: %v", data, err)
+		}
+		*rate = Rate(parsed / 8)
+		return nil
+	}
+
+	if err := parsed.Set(strings.TrimSuffix(text, "/s")); err != nil {
+		return errs.New("invalid rate %q: %v", data, err)
+	}
+	*rate = Rate(parsed)
+	return nil
+}
+
+// MarshalText marshals the rate into toml in bytes per second.
+func (rate Rate) MarshalText() ([]byte, error) {
+	return []byte(sizeLabel(ByteSize(rate)) + "/s"), nil
+}
+
+// LoadConfig loads the toml config. References to environment variables
+// and secret files in its strings are expanded, see expandString.
+func LoadConfig(path string) (config Config, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	return ParseConfig(data)
+}
+
+// ParseConfig parses the contents of a config file.
+func ParseConfig(data []byte) (config Config, err error) {
+	return parseConfig(data, false)
+}
+
+// ParseUntrustedConfig parses the contents of a config file sent by a
+// remote caller. References to environment variables and files in its
+// strings are refused rather than expanded, so that callers can't read the
+// secrets of the process, including those in endpoint tables, which are
+// refused once they are decoded.
+func ParseUntrustedConfig(data []byte) (config Config, err error) {
+	return parseConfig(data, true)
+}
+
+func parseConfig(data []byte, untrusted bool) (config Config, err error) {
+	config.meta, err = toml.Decode(string(data), &config)
+	if err != nil {
+		return config, err
+	}
+	config.untrusted = untrusted
+	if err := mapStrings(reflect.ValueOf(&config).Elem(), stringMapper(untrusted)); err != nil {
+		return config, err
+	}
+	err = config.expandMatrices()
+	return config, err
+}
+
+// Extend returns a copy of the config extended with the tables of the
+// config fragment a remote caller sent, such as additional file tests,
+// endpoints or suites. The fragment is parsed like ParseUntrustedConfig and
+// can neither redefine the tables of the config nor set its other
+// settings.
+func (config Config) Extend(fragment []byte) (Config, error) {
+	extension, err := ParseUntrustedConfig(fragment)
+	if err != nil {
+		return Config{}, err
+	}
+	for _, key := range extension.meta.Keys() {
+		switch key[0] {
+		case "filetest", "endpoint", "suite":
+		default:
+			return Config{}, errs.New("config fragment: only file test, endpoint and suite tables can be added, not %q", key[0])
+		}
+	}
+
+	fileTests := make(map[ID]FileTest, len(config.FileTests)+len(extension.FileTests))
+	for id, fileTest := range config.FileTests {
+		fileTests[id] = fileTest
+	}
+	for id, fileTest := range extension.FileTests {
+		if _, ok := fileTests[id]; ok {
+			return Config{}, errs.New("config fragment: file test %q already exists", id)
+		}
+		fileTests[id] = fileTest
+	}
+
+	matrices := make(map[ID][]ID, len(config.matrices)+len(extension.matrices))
+	for id, generated := range config.matrices {
+		matrices[id] = generated
+	}
+	for id, generated := range extension.matrices {
+		matrices[id] = generated
+	}
+
+	suites := make(map[ID]Suite, len(config.Suites)+len(extension.Suites))
+	for id, suite := range config.Suites {
+		suites[id] = suite
+	}
+	for id, suite := range extension.Suites {
+		if _, ok := suites[id]; ok {
+			return Config{}, errs.New("config fragment: suite %q already exists", id)
+		}
+		suites[id] = suite
+	}
+
+	endpoints := make(Endpoints, len(config.Endpoints)+len(extension.Endpoints))
+	sources := make(map[string]map[ID]endpointSource)
+	for endpointType, tables := range config.Endpoints {
+		endpoints[endpointType] = make(map[ID]toml.Primitive, len(tables))
+		sources[endpointType] = make(map[ID]endpointSource, len(tables))
+		for id, table := range tables {
+			endpoints[endpointType][id] = table
+			sources[endpointType][id] = config.source(endpointType, id)
+		}
+	}
+	for endpointType, tables := range extension.Endpoints {
+		if endpoints[endpointType] == nil {
+			endpoints[endpointType] = make(map[ID]toml.Primitive, len(tables))
+			sources[endpointType] = make(map[ID]endpointSource, len(tables))
+		}
+		for id, table := range tables {
+			if _, ok := endpoints[endpointType][id]; ok {
+				return Config{}, errs.New("config fragment: %s endpoint %q already exists", endpointType, id)
+			}
+			endpoints[endpointType][id] = table
+			sources[endpointType][id] = extension.source(endpointType, id)
+		}
+	}
+
+	config.FileTests = fileTests
+	config.matrices = matrices
+	config.Suites = suites
+	config.Endpoints = endpoints
+	config.sources = sources
+	return config, nil
+}
+
+// HashFile returns the hex encoded sha256 digest of the config file at path,
+// identifying which configuration produced a set of results.
+func HashFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return Hash(data), nil
+}
+
+// Hash returns the hex encoded sha256 digest of the contents of a config
+// file.
+func Hash(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+// Result represents a single result.
+type Result struct {
+	StartTime time.Time
+	Duration  time.Duration
+	Success   bool
+	Error     string
+	// ErrorCategory classifies the Error of failed results.
+	ErrorCategory ErrorCategory
+
+	// FirstByte is the time until the first byte of a download was received.
+	FirstByte time.Duration
+	// Finalize is the time an upload took to commit after its data was streamed.
+	Finalize time.Duration
+
+	// Parts are the timings of each part of a multipart upload.
+	Parts []backends.Part
+
+	// Parallelism is the number of objects transferred at once, recorded
+	// by ramp tests.
+	Parallelism int64
+
+	// ObjectDurations are the durations of the individual objects of the
+	// operation, which transfers them NumParallel at a time.
+	ObjectDurations []time.Duration
+
+	// Succeeded is the number of objects the operation succeeded on, and
+	// FailedObjects are the indexes of those it failed on, so that a batch
+	// with failed objects still shows how much of it went through. Objects
+	// which weren't started or were cancelled after the first failure are
+	// in neither.
+	Succeeded     int
+	FailedObjects []int
+	// Bytes are the bytes of the objects which were transferred
+	// successfully.
+	Bytes int64
+
+	// Timeline are the bytes transferred by an upload or download in each
+	// consecutive TimelineInterval since its start, which shows the stalls
+	// its average throughput hides.
+	Timeline []int64
+
+	// NodeStats are the storage node level stats of a download, summed
+	// over its objects, for clients which know them.
+	NodeStats *backends.NodeStats
+
+	// Buckets are the operations of a soak test which completed in each
+	// consecutive time interval.
+	Buckets []Bucket
+
+	// Latencies are the durations of the individual successful operations
+	// of a latency test, whose Duration spans all of them.
+	Latencies []time.Duration
+
+	// Unsupported is set when the operation was skipped, because the
+	// endpoint's client doesn't support it.
+	Unsupported bool
+
+	// Attempts records every attempt made, the last of which is described
+	// by the result itself.
+	Attempts []Attempt
+
+	// Resources is the resource usage of the client during the last
+	// attempt.
+	Resources *ResourceUsage
+
+	// Conflicts are the outcomes of the concurrent writes of the objects
+	// of a conflict test which were downloaded successfully.
+	Conflicts []Conflict
+
+	// Listings are the full lists of the objects of a listing test, one
+	// per page size.
+	Listings []Listing
+}
+
+// Listing is a full list of the objects of a listing test.
+type Listing struct {
+	// PageSize is the number of entries per page the list requested, or
+	// zero for the backend's default.
+	PageSize int64
+	// Pages is the number of pages the list took, summed over the
+	// directories it listed, or zero for backends which don't report it.
+	Pages int64
+	// Objects is the number of objects listed.
+	Objects int64
+	// Duration is how long the list took.
+	Duration time.Duration
+}
+
+// Conflict is the outcome of concurrent writes of different contents to
+// the same object.
+type Conflict struct {
+	// Object is the index of the object.
+	Object int
+	// Winner is the writer whose contents the object ended up with, or -1
+	// if it has none of their contents, which means the writes corrupted
+	// it.
+	Winner int
+	// LastWriter is the writer whose write finished last, which wins on
+	// backends with last-writer-wins semantics.
+	LastWriter int
+}
+
+// ResourceUsage is the resource usage of the client machine during an
+// operation, which tells whether the client rather than the endpoint
+// limited it. It covers the whole process, including the operations of
+// other checks running at the same time.
+type ResourceUsage struct {
+	// CPUPercent is the CPU time of the process as a percentage of the time
+	// of all CPUs of the machine, where the platform reports it.
+	CPUPercent float64
+	// PeakHeap is the most bytes the heap had in use.
+	PeakHeap int64
+	// GCCount is the number of garbage collections, which stopped the
+	// process for GCPause in total.
+	GCCount int64
+	GCPause time.Duration
+	// NetworkReceived and NetworkSent are the bytes transferred by the
+	// network interfaces of the machine other than loopback, and
+	// NetworkPercent the utilization of the link speed of the busiest
+	// interface and direction, where the platform reports them.
+	NetworkReceived int64
+	NetworkSent     int64
+	NetworkPercent  float64
+}
+
+// ErrorCategory classifies why an operation failed, so that the
+// reliability of endpoints can be compared across runs.
+type ErrorCategory string
+
+const (
+	// TimeoutError is an operation which didn't finish in time.
+	TimeoutError ErrorCategory = "timeout"
+	// AuthError is an operation the endpoint didn't authenticate or
+	// authorize.
+	AuthError ErrorCategory = "auth"
+	// ClientError is an operation the endpoint rejected with any other 4xx
+	// status.
+	ClientError ErrorCategory = "4xx"
+	// ServerError is an operation the endpoint failed with a 5xx status.
+	ServerError ErrorCategory = "5xx"
+	// ConnectionError is an operation whose connection was refused, reset
+	// or closed early.
+	ConnectionError ErrorCategory = "connection"
+	// ChecksumError is a download whose contents didn't match the expected
+	// ones.
+	ChecksumError ErrorCategory = "checksum"
+	// SizeError is a download which didn't return as many bytes as the
+	// object has.
+	SizeError ErrorCategory = "size"
+	// OtherError is any other failure.
+	OtherError ErrorCategory = "other"
+)
+
+// ErrorCategories are all error categories, in the order they are
+// reported.
+var ErrorCategories = []ErrorCategory{TimeoutError, AuthError, ClientError, ServerError, ConnectionError, ChecksumError, SizeError, OtherError}
+
+// NetworkTimings are the times it took to set up a connection to an
+// endpoint, which often dominate the latency of small objects.
+type NetworkTimings struct {
+	Address      string
+	IPs          []IPLocation // All IPs the address resolved to.
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration // Zero for endpoints without TLS.
+	Error        string
+}
+
+// IPLocation is an IP of an endpoint and where it is located, if known.
+type IPLocation struct {
+	IP       string
+	Location string
+}
+
+// TimelineInterval is the length of the intervals of a result's timeline.
+const TimelineInterval = time.Second
+
+// Bucket counts the operations of a soak test which completed in one time
+// interval.
+type Bucket struct {
+	Duration time.Duration
+	Objects  int
+	Errors   int
+}
+
+// Retries returns the number of times the operation was retried.
+func (result *Result) Retries() int {
+	if len(result.Attempts) == 0 {
+		return 0
+	}
+	return len(result.Attempts) - 1
+}
+
+// Attempt is a single try of an operation.
+type Attempt struct {
+	StartTime time.Time
+	Duration  time.Duration
+	Error     string
+}
+
+// Operation represents the type of operation done for the test.
+type Operation int
+
+const (
+	// Upload operation.
+	Upload Operation = iota
+	// MultipartUpload operation.
+	MultipartUpload
+	// Download operation.
+	Download
+	// RangeDownload operation.
+	RangeDownload
+	// Delete operation.
+	Delete
+	// MixedUpload is the upload direction of a mixed workload.
+	MixedUpload
+	// MixedDownload is the download direction of a mixed workload.
+	MixedDownload
+	// Copy operation.
+	Copy
+	// PutVersion uploads a new version of an object.
+	PutVersion
+	// ListVersions lists the versions of an object.
+	ListVersions
+	// DeleteVersion deletes a single version of an object.
+	DeleteVersion
+	// RepeatDownload is the repeated download of cache tests.
+	RepeatDownload
+	// CreateBucket creates a bucket.
+	CreateBucket
+	// ListBuckets lists all buckets.
+	ListBuckets
+	// DeleteBucket deletes a bucket.
+	DeleteBucket
+	// GetMetadata reads the custom metadata of an object.
+	GetMetadata
+	// SetMetadata sets the custom metadata of an existing object.
+	SetMetadata
+	// ReadAfterWrite is the delay until a stat finds an uploaded object.
+	ReadAfterWrite
+	// ListAfterWrite is the delay until a list includes an uploaded object.
+	ListAfterWrite
+	// ReadAfterDelete is the delay until a stat no longer finds a deleted
+	// object.
+	ReadAfterDelete
+	// ListAfterDelete is the delay until a list no longer includes a
+	// deleted object.
+	ListAfterDelete
+	// ConflictWrite writes the same objects from several writers at once.
+	ConflictWrite
+	// ConflictVerify downloads the objects written by conflicting writers.
+	ConflictVerify
+	// ListRecursive lists all objects with a single recursive list.
+	ListRecursive
+	// ListWalk lists all objects by listing every directory on its own.
+	ListWalk
+)
+
+func (o Operation) String() string {
+	switch o {
+	case Upload:
+		return "Upload"
+	case MultipartUpload:
+		return "MultipartUpload"
+	case Download:
+		return "Download"
+	case RangeDownload:
+		return "RangeDownload"
+	case Delete:
+		return "Delete"
+	case MixedUpload:
+		return "MixedUpload"
+	case MixedDownload:
+		return "MixedDownload"
+	case Copy:
+		return "Copy"
+	case PutVersion:
+		return "PutVersion"
+	case ListVersions:
+		return "ListVersions"
+	case DeleteVersion:
+		return "DeleteVersion"
+	case RepeatDownload:
+		return "RepeatDownload"
+	case CreateBucket:
+		return "CreateBucket"
+	case ListBuckets:
+		return "ListBuckets"
+	case DeleteBucket:
+		return "DeleteBucket"
+	case GetMetadata:
+		return "GetMetadata"
+	case SetMetadata:
+		return "SetMetadata"
+	case ReadAfterWrite:
+		return "ReadAfterWrite"
+	case ListAfterWrite:
+		return "ListAfterWrite"
+	case ReadAfterDelete:
+		return "ReadAfterDelete"
+	case ListAfterDelete:
+		return "ListAfterDelete"
+	case ConflictWrite:
+		return "ConflictWrite"
+	case ConflictVerify:
+		return "ConflictVerify"
+	case ListRecursive:
+		return "ListRecursive"
+	case ListWalk:
+		return "ListWalk"
+	default:
+		return ""
+	}
+}
+
+// ParseOperation returns the operation whose String is name.
+func ParseOperation(name string) (Operation, bool) {
+	for o := Upload; o.String() != ""; o++ {
+		if o.String() == name {
+			return o, true
+		}
+	}
+	return 0, false
+}
+
+// MarshalText marshals the operation as its name.
+func (o Operation) MarshalText() ([]byte, error) {
+	if o.String() == "" {
+		return nil, errs.New("unknown operation %d", int(o))
+	}
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText parses an operation name.
+func (o *Operation) UnmarshalText(data []byte) error {
+	operation, ok := ParseOperation(string(data))
+	if !ok {
+		return errs.New("unknown operation %q", string(data))
+	}
+	*o = operation
+	return nil
+}
+
+// TransfersFile returns whether the operation transfers whole files, so
+// that its results measure throughput rather than just durations.
+func (o Operation) TransfersFile() bool {
+	switch o {
+	case Delete, RangeDownload, ListVersions, DeleteVersion, CreateBucket, ListBuckets, DeleteBucket, GetMetadata, SetMetadata,
+		ReadAfterWrite, ListAfterWrite, ReadAfterDelete, ListAfterDelete, ListRecursive, ListWalk:
+		return false
+	default:
+		return true
+	}
+}
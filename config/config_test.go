@@ -0,0 +1,116 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	_ "storj.io/perftester/backends/all"
+	"storj.io/perftester/config"
+)
+
+func TestExtend(t *testing.T) {
+	require.NoError(t, os.Setenv("PERFTESTER_TEST_SECRET", "secret"))
+	defer func() { _ = os.Unsetenv("PERFTESTER_TEST_SECRET") }()
+
+	base, err := config.ParseConfig([]byte(`
+[filetest.small]
+size = "1KiB"
+
+[endpoint.s3.base]
+region = "us-east-1"
+bucket = "${PERFTESTER_TEST_SECRET}"
+`))
+	require.NoError(t, err)
+
+	extended, err := base.Extend([]byte(`
+[filetest.large]
+size = "1MiB"
+
+[endpoint.s3.extra]
+region = "us-east-1"
+bucket = "other"
+
+[suite.extra]
+filetests = ["large"]
+endpoints = ["extra"]
+`))
+	require.NoError(t, err)
+	require.Len(t, extended.FileTests, 2)
+	require.Len(t, base.FileTests, 1)
+
+	endpoints, err := extended.DecodeEndpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	// The references of the base config are still expanded.
+	require.Equal(t, config.ID("base"), endpoints[0].ID)
+	require.Equal(t, "secret", endpoints[0].Bucket)
+	require.Equal(t, "other", endpoints[1].Bucket)
+
+	for _, fragment := range []string{
+		// Top-level keys would have ended up in the last table of the
+		// base config if the fragment was appended to it.
+		`timeout = "1s"`,
+		"[monitoring]\naddress = \"localhost:9000\"",
+		"[filetest.small]\nsize = \"1MiB\"",
+		"[endpoint.s3.base]\nregion = \"us-east-1\"\nbucket = \"bucket\"",
+		`[filetest.leak]
+size = "1KiB"
+manifest = "${file:/etc/passwd}"`,
+	} {
+		_, err := base.Extend([]byte(fragment))
+		require.Error(t, err, fragment)
+	}
+
+	extended, err = base.Extend([]byte(`
+[endpoint.s3.leak]
+region = "us-east-1"
+bucket = "${PERFTESTER_TEST_SECRET}"
+`))
+	require.NoError(t, err)
+	_, err = extended.DecodeEndpoints()
+	require.Error(t, err)
+}
+
+func TestValidateMetadata(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config string
+		err    bool
+	}{
+		{name: "lower case", config: `metadata = {team = "storage"}`},
+		{name: "upper case", config: `metadata = {Team = "storage"}`, err: true},
+		{name: "ten keys", config: `metadata = {a = "1", b = "2", c = "3", d = "4", e = "5", f = "6", g = "7", h = "8", i = "9", j = "10"}`},
+		{name: "eleven keys", config: `metadata = {a = "1", b = "2", c = "3", d = "4", e = "5", f = "6", g = "7", h = "8", i = "9", j = "10", k = "11"}`, err: true},
+		{name: "ten keys with a ttl", config: "ttl = \"1h\"\n" + `metadata = {a = "1", b = "2", c = "3", d = "4", e = "5", f = "6", g = "7", h = "8", i = "9", j = "10"}`, err: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := config.ParseConfig([]byte("[endpoint.s3.base]\nregion = \"us-east-1\"\nbucket = \"bucket\"\n\n[filetest.small]\nsize = \"1KiB\"\n" + tt.config + "\n"))
+			require.NoError(t, err)
+			if tt.err {
+				require.Error(t, conf.Validate())
+			} else {
+				require.NoError(t, conf.Validate())
+			}
+		})
+	}
+}
+
+func TestUnknownOperations(t *testing.T) {
+	conf, err := config.ParseConfig([]byte("[endpoint.s3.base]\nregion = \"us-east-1\"\nbucket = \"bucket\"\n\n[filetest.small]\nsize = \"1KiB\"\noperations = [\"download\", \"uplaod\"]\n"))
+	require.NoError(t, err)
+
+	err = conf.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `"uplaod"`)
+
+	// Unknown names don't select the first operation.
+	fileTest := conf.FileTests["small"]
+	require.True(t, fileTest.Runs(config.Download))
+	require.False(t, fileTest.Runs(config.Upload))
+	require.False(t, config.SLARule{Operation: "uplaod", MaxLatency: 1}.Matches("base", config.Upload, 1024))
+}
@@ -0,0 +1,182 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/backends"
+)
+
+// decodeMu serializes decoding endpoints, since the metadata of a config,
+// which all of its copies share, isn't safe for concurrent use.
+var decodeMu sync.Mutex
+
+// DecodedEndpoint is the decoded config of a configured endpoint.
+type DecodedEndpoint struct {
+	// ID is the ID the results of the endpoint are reported under.
+	ID       ID
+	Type     string
+	Bucket   string
+	Path     string
+	Defaults EndpointDefaults
+	Config   backends.EndpointConfig
+}
+
+// endpointSource is the config an endpoint table was parsed from, which
+// decodes it.
+type endpointSource struct {
+	meta      toml.MetaData
+	untrusted bool
+}
+
+// source returns the source of an endpoint table.
+func (config Config) source(endpointType string, id ID) endpointSource {
+	if source, ok := config.sources[endpointType][id]; ok {
+		return source
+	}
+	return endpointSource{meta: config.meta, untrusted: config.untrusted}
+}
+
+// endpointCommon are the settings shared by endpoints of all types.
+type endpointCommon struct {
+	Bucket string `toml:"bucket"`
+	Path   string `toml:"path"`
+
+	EndpointDefaults
+}
+
+// DecodeEndpoints decodes the config of every endpoint, sorted by type and
+// ID. The clients of their types must be registered with backends.Register,
+// usually by importing storj.io/perftester/backends/all; endpoints of other
+// types fail to decode.
+func (config Config) DecodeEndpoints() ([]DecodedEndpoint, error) {
+	var endpoints []DecodedEndpoint
+	for _, endpointType := range sortedTypes(config.Endpoints) {
+		for _, id := range sortedIDs(config.Endpoints[endpointType]) {
+			endpoint, err := config.decodeEndpoint(endpointType, id)
+			if err != nil {
+				return nil, err
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+func (config Config) decodeEndpoint(endpointType string, id ID) (DecodedEndpoint, error) {
+	factory, ok := backends.Lookup(endpointType)
+	if !ok {
+		types := backends.Types()
+		if len(types) == 0 {
+			return DecodedEndpoint{}, errs.New("endpoint %q: unknown type %q: no endpoint types are registered; import storj.io/perftester/backends/all", id, endpointType)
+		}
+		return DecodedEndpoint{}, errs.New("endpoint %q: unknown type %q: registered types are %s", id, endpointType, strings.Join(types, ", "))
+	}
+
+	endpointConfig := factory()
+	var common endpointCommon
+	if err := config.source(endpointType, id).decodePrimitive(config.Endpoints[endpointType][id], endpointConfig, &common); err != nil {
+		return DecodedEndpoint{}, errs.New("%s endpoint %q: %v", endpointType, id, err)
+	}
+
+	reportID := id
+	if renamer, ok := endpointConfig.(backends.Renamer); ok {
+		reportID = ID(renamer.ReportID(string(id)))
+	}
+	return DecodedEndpoint{
+		ID:       reportID,
+		Type:     endpointType,
+		Bucket:   common.Bucket,
+		Path:     common.Path,
+		Defaults: common.EndpointDefaults,
+		Config:   endpointConfig,
+	}, nil
+}
+
+// decodePrimitive decodes an endpoint table into each of values and
+// maps their strings, like parsing the config does for the rest of it.
+func (source endpointSource) decodePrimitive(primitive toml.Primitive, values ...interface{}) error {
+	decodeMu.Lock()
+	defer decodeMu.Unlock()
+
+	for _, value := range values {
+		if err := source.meta.PrimitiveDecode(primitive, value); err != nil {
+			return err
+		}
+		if err := mapStrings(reflect.ValueOf(value), stringMapper(source.untrusted)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedTypes(endpoints Endpoints) []string {
+	types := make([]string, 0, len(endpoints))
+	for endpointType := range endpoints {
+		types = append(types, endpointType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func sortedIDs(tables map[ID]toml.Primitive) []ID {
+	ids := make([]ID, 0, len(tables))
+	for id := range tables {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Validate checks the settings of a storj endpoint.
+func (endpoint StorjEndpoint) Validate() error {
+	var group errs.Group
+	switch endpoint.Mode {
+	case "", StorjNative, StorjGateway, StorjLinkshare:
+	default:
+		group.Add(errs.New("unknown mode %q", endpoint.Mode))
+	}
+	if endpoint.Presign && endpoint.Mode != StorjGateway {
+		group.Add(errs.New("only the gateway mode presigns URLs"))
+	}
+	switch endpoint.Transport {
+	case "", StorjTCP:
+	case StorjQUIC:
+		group.Add(errs.New("transport %q is not supported by this uplink version", endpoint.Transport))
+	default:
+		group.Add(errs.New("unknown transport %q", endpoint.Transport))
+	}
+	if endpoint.DialTimeout < 0 {
+		group.Add(errs.New("dial timeout must not be negative"))
+	}
+	if endpoint.SegmentSize < 0 {
+		group.Add(errs.New("segment size must not be negative"))
+	}
+	if endpoint.Parallelism < 0 {
+		group.Add(errs.New("parallelism must not be negative"))
+	}
+	if endpoint.ChunkSize < 0 {
+		group.Add(errs.New("chunk size must not be negative"))
+	}
+	return group.Err()
+}
+
+// Validate checks the settings of an S3 endpoint.
+func (endpoint S3Endpoint) Validate() error {
+	var group errs.Group
+	if endpoint.DownloadConcurrency < 0 || endpoint.UploadConcurrency < 0 {
+		group.Add(errs.New("concurrency must not be negative"))
+	}
+	if endpoint.DownloadPartSize < 0 || endpoint.UploadPartSize < 0 {
+		group.Add(errs.New("part size must not be negative"))
+	}
+	return group.Err()
+}
@@ -0,0 +1,88 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/config"
+)
+
+func TestDecodeEndpoints(t *testing.T) {
+	conf, err := config.ParseConfig([]byte(`
+[endpoint.webdav.dav]
+address = "http://localhost:8080"
+
+[endpoint.s3.b]
+region = "us-east-1"
+bucket = "bucket-b"
+path = "bench"
+timeout = "10m"
+
+[endpoint.s3.a]
+region = "us-east-1"
+bucket = "bucket-a"
+accelerate = true
+`))
+	require.NoError(t, err)
+
+	endpoints, err := conf.DecodeEndpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 3)
+
+	// Endpoints are sorted by type and ID, and reported under the IDs
+	// their clients choose.
+	require.Equal(t, config.ID("a+accelerate"), endpoints[0].ID)
+	require.Equal(t, config.ID("b"), endpoints[1].ID)
+	require.Equal(t, config.ID("dav"), endpoints[2].ID)
+	require.Equal(t, []string{"s3", "s3", "webdav"}, []string{endpoints[0].Type, endpoints[1].Type, endpoints[2].Type})
+
+	// The settings shared by all types are decoded next to the client's.
+	require.Equal(t, "bucket-b", endpoints[1].Bucket)
+	require.Equal(t, "bench", endpoints[1].Path)
+	require.Equal(t, config.Duration(10*time.Minute), endpoints[1].Defaults.Timeout)
+	require.NotNil(t, endpoints[1].Config)
+	require.NotSame(t, endpoints[0].Config, endpoints[1].Config)
+}
+
+func TestDecodeEndpointsUnregistered(t *testing.T) {
+	conf, err := config.ParseConfig([]byte("[endpoint.nosuch.test]\naddress = \"localhost\"\n\n[filetest.small]\nsize = \"1KiB\"\n"))
+	require.NoError(t, err)
+
+	// Endpoints of types without a registered client fail to decode and to
+	// validate, naming the registered types.
+	_, err = conf.DecodeEndpoints()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unknown type "nosuch"`)
+	require.Contains(t, err.Error(), "s3")
+
+	err = conf.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `unknown type "nosuch"`)
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	conf, err := config.ParseConfig([]byte(`
+[filetest.small]
+size = "1KiB"
+
+[endpoint.s3.negative]
+region = "us-east-1"
+bucket = "bucket"
+upload_concurrency = -1
+
+[endpoint.storj.quic]
+transport = "quic"
+`))
+	require.NoError(t, err)
+
+	// The registered clients check the settings of their endpoints.
+	err = conf.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `s3 endpoint "negative": concurrency must not be negative`)
+	require.Contains(t, err.Error(), `storj endpoint "quic"`)
+}
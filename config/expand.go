@@ -0,0 +1,104 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+// referencePattern matches ${NAME} and ${file:PATH} references.
+var referencePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// expandString replaces ${NAME} with the value of the environment variable
+// NAME and ${file:PATH} with the contents of the file at PATH, without
+// trailing newlines, so that credentials don't have to be committed to the
+// config file.
+func expandString(s string) (string, error) {
+	var firstErr error
+	expanded := referencePattern.ReplaceAllStringFunc(s, func(reference string) string {
+		name := referencePattern.FindStringSubmatch(reference)[1]
+
+		if path := strings.TrimPrefix(name, "file:"); path != name {
+			data, err := ioutil.ReadFile(path)
+			if err != nil && firstErr == nil {
+				firstErr = errs.New("reading secret file %q: %v", path, err)
+			}
+			return strings.TrimRight(string(data), "\r\n")
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok && firstErr == nil {
+			firstErr = errs.New("environment variable %q is not set", name)
+		}
+		return value
+	})
+	return expanded, firstErr
+}
+
+// refuseReferences returns an error if s references an environment
+// variable or a file, for configs from untrusted sources, which mustn't read
+// the secrets of the process.
+func refuseReferences(s string) (string, error) {
+	if reference := referencePattern.FindString(s); reference != "" {
+		return s, errs.New("references such as %q aren't allowed in this config", reference)
+	}
+	return s, nil
+}
+
+// stringMapper returns the mapping of the strings of a config, which
+// expands their references, or refuses them if the config is untrusted.
+func stringMapper(untrusted bool) func(string) (string, error) {
+	if untrusted {
+		return refuseReferences
+	}
+	return expandString
+}
+
+// mapStrings replaces every string reachable from v in place with its
+// mapping by fn.
+func mapStrings(v reflect.Value, fn func(string) (string, error)) error {
+	switch v.Kind() {
+	case reflect.String:
+		mapped, err := fn(v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(mapped)
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return mapStrings(v.Elem(), fn)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				if err := mapStrings(field, fn); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := mapStrings(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// Map values aren't addressable, so they are mapped in a copy.
+		for _, key := range v.MapKeys() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(v.MapIndex(key))
+			if err := mapStrings(value, fn); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, value)
+		}
+	}
+	return nil
+}
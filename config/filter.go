@@ -0,0 +1,77 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zeebo/errs"
+)
+
+// Suite is a named subset of the configured file tests and endpoints.
+type Suite struct {
+	FileTests []ID `toml:"filetests"`
+	Endpoints []ID `toml:"endpoints"`
+}
+
+// Filter returns a copy of the config with only the given file tests and
+// endpoints. An empty list selects all of them.
+func (config Config) Filter(fileTestIDs, endpointIDs []ID) (Config, error) {
+	if len(fileTestIDs) > 0 {
+		fileTests := make(map[ID]FileTest)
+		for _, id := range fileTestIDs {
+			// A matrix selects all of its file tests.
+			if generated, ok := config.matrices[id]; ok {
+				for _, generatedID := range generated {
+					fileTests[generatedID] = config.FileTests[generatedID]
+				}
+				continue
+			}
+
+			fileTest, ok := config.FileTests[id]
+			if !ok {
+				return Config{}, errs.New("unknown file test %q", id)
+			}
+			fileTests[id] = fileTest
+		}
+		config.FileTests = fileTests
+	}
+
+	if len(endpointIDs) > 0 {
+		selected := make(map[ID]bool)
+		for _, id := range endpointIDs {
+			selected[id] = true
+		}
+
+		endpoints := make(Endpoints)
+		found := make(map[ID]bool)
+		for endpointType, tables := range config.Endpoints {
+			for id, table := range tables {
+				if !selected[id] {
+					continue
+				}
+				if endpoints[endpointType] == nil {
+					endpoints[endpointType] = make(map[ID]toml.Primitive)
+				}
+				endpoints[endpointType][id] = table
+				found[id] = true
+			}
+		}
+
+		var unknown []string
+		for _, id := range endpointIDs {
+			if !found[id] {
+				unknown = append(unknown, string(id))
+			}
+		}
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return Config{}, errs.New("unknown endpoints %q", unknown)
+		}
+		config.Endpoints = endpoints
+	}
+
+	return config, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"strconv"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/common/memory"
+)
+
+// expandMatrices replaces every matrix file test with one file test per
+// combination of its sizes, parallelism and key distributions. The
+// generated IDs append them to the matrix ID, such as "big-64MiB-p8" or
+// "small-p4-hashed".
+func (config *Config) expandMatrices() error {
+	for id, fileTest := range config.FileTests {
+		if len(fileTest.Sizes) == 0 && len(fileTest.Parallelism) == 0 && len(fileTest.KeyDistributions) == 0 {
+			continue
+		}
+		delete(config.FileTests, id)
+
+		sizes := fileTest.Sizes
+		if len(sizes) == 0 {
+			sizes = []ByteSize{fileTest.Size}
+		}
+		parallelism := fileTest.Parallelism
+		if len(parallelism) == 0 {
+			parallelism = []int64{fileTest.NumParallel}
+		}
+		distributions := fileTest.KeyDistributions
+		if len(distributions) == 0 {
+			distributions = []KeyDistribution{fileTest.KeyDistribution}
+		}
+
+		var generated []ID
+		for _, size := range sizes {
+			for _, numParallel := range parallelism {
+				for _, distribution := range distributions {
+					generatedID := id
+					if len(fileTest.Sizes) > 0 {
+						generatedID += "-" + ID(sizeLabel(size))
+					}
+					if len(fileTest.Parallelism) > 0 {
+						generatedID += "-p" + ID(strconv.FormatInt(numParallel, 10))
+					}
+					if len(fileTest.KeyDistributions) > 0 {
+						generatedID += "-" + ID(distribution)
+					}
+					if _, ok := config.FileTests[generatedID]; ok {
+						return errs.New("file test %q of matrix %q already exists", generatedID, id)
+					}
+
+					expanded := fileTest
+					expanded.Sizes, expanded.Parallelism, expanded.KeyDistributions = nil, nil, nil
+					expanded.Size = size
+					expanded.NumParallel = numParallel
+					expanded.KeyDistribution = distribution
+					config.FileTests[generatedID] = expanded
+					generated = append(generated, generatedID)
+				}
+			}
+		}
+
+		if config.matrices == nil {
+			config.matrices = make(map[ID][]ID)
+		}
+		config.matrices[id] = generated
+	}
+	return nil
+}
+
+// sizeLabel formats size with the largest unit which divides it, such as
+// "64MiB" or "100MB", which parses back to the same size. It labels the
+// file tests of matrices and marshals sizes and rates.
+func sizeLabel(size ByteSize) string {
+	units := []struct {
+		name string
+		size memory.Size
+	}{
+		{"GiB", memory.GiB}, {"GB", memory.GB},
+		{"MiB", memory.MiB}, {"MB", memory.MB},
+		{"KiB", memory.KiB}, {"KB", memory.KB},
+	}
+	for _, unit := range units {
+		if size > 0 && int64(size)%int64(unit.size) == 0 {
+			return strconv.FormatInt(int64(size)/int64(unit.size), 10) + unit.name
+		}
+	}
+	return strconv.FormatInt(int64(size), 10) + "B"
+}
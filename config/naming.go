@@ -0,0 +1,206 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/zeebo/errs"
+)
+
+// ObjectName are the fields of the name templates of file tests.
+type ObjectName struct {
+	RunID      string
+	FileTestID ID
+	Index      int
+	// Hash is a hex hash of the file test ID and the index, which spreads
+	// the objects over prefixes.
+	Hash string
+}
+
+// nameTemplates caches the parsed name templates by their text.
+var nameTemplates sync.Map
+
+// parseNameTemplate returns the parsed name template.
+func parseNameTemplate(text string) (*template.Template, error) {
+	if tmpl, ok := nameTemplates.Load(text); ok {
+		return tmpl.(*template.Template), nil
+	}
+	tmpl, err := template.New("name").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	nameTemplates.Store(text, tmpl)
+	return tmpl, nil
+}
+
+// executeNameTemplate returns the name of the i-th object of the file test
+// according to its name template.
+func (fileTest FileTest) executeNameTemplate(fileTestID ID, i int) (string, error) {
+	tmpl, err := parseNameTemplate(fileTest.NameTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var name strings.Builder
+	err = tmpl.Execute(&name, ObjectName{
+		RunID:      fileTest.RunID,
+		FileTestID: fileTestID,
+		Index:      i,
+		Hash:       fmt.Sprintf("%08x", objectHash(fileTestID, i)),
+	})
+	if err != nil {
+		return "", err
+	}
+	if strings.Trim(name.String(), "/") == "" {
+		return "", errs.New("empty name for object %d", i)
+	}
+	return name.String(), nil
+}
+
+// validateNameTemplate checks that the name template of the file test
+// executes and tells the objects apart.
+func (fileTest FileTest) validateNameTemplate(fileTestID ID) error {
+	first, err := fileTest.executeNameTemplate(fileTestID, 0)
+	if err != nil {
+		return err
+	}
+	second, err := fileTest.executeNameTemplate(fileTestID, 1)
+	if err != nil {
+		return err
+	}
+	if first == second {
+		return errs.New("all objects are named %q", first)
+	}
+	return nil
+}
+
+// defaultNumPrefixes is the number of prefixes of key distributions which
+// don't set NumPrefixes.
+const defaultNumPrefixes = 16
+
+// ObjectName returns the name of the i-th object of the file test, relative
+// to its prefix. Names are the file test ID followed by the index unless
+// the file test has a name template, under the prefix of its key
+// distribution. Validate rejects templates which fail, so this falls back
+// to the default name only for unvalidated configs.
+func (fileTest FileTest) ObjectName(fileTestID ID, i int) string {
+	name := string(fileTestID) + strconv.Itoa(i)
+	if fileTest.NameTemplate != "" {
+		if templated, err := fileTest.executeNameTemplate(fileTestID, i); err == nil {
+			name = strings.Trim(templated, "/")
+		}
+	}
+	if prefix := fileTest.keyPrefix(fileTestID, i); prefix != "" {
+		name = prefix + "/" + name
+	}
+	return name
+}
+
+// keyPrefix returns the prefix the key distribution puts the i-th object
+// under.
+func (fileTest FileTest) keyPrefix(fileTestID ID, i int) string {
+	numPrefixes := fileTest.NumPrefixes
+	if numPrefixes <= 0 {
+		numPrefixes = defaultNumPrefixes
+	}
+
+	switch fileTest.KeyDistribution {
+	case SequentialKeys:
+		numObjects := fileTest.NumObjects
+		if numObjects <= 0 {
+			numObjects = 1
+		}
+		// Copies are numbered after the objects, so they wrap around.
+		return fmt.Sprintf("%04x", int64(i)*numPrefixes/numObjects%numPrefixes)
+	case HashedKeys:
+		return fmt.Sprintf("%04x", int64(objectHash(fileTestID, i)%uint32(numPrefixes)))
+	case RandomKeys:
+		return fmt.Sprintf("%08x", rand.New(rand.NewSource(ObjectSeed(fileTest.Seed, i))).Uint32())
+	default:
+		return ""
+	}
+}
+
+// ObjectSeed returns the seed of the i-th object of a file test seeded with
+// seed. It hashes both, so that the objects of file tests with nearby seeds,
+// such as 1 and 2, don't share seeds like they would by adding i to seed.
+func ObjectSeed(seed int64, i int) int64 {
+	var input [16]byte
+	binary.BigEndian.PutUint64(input[:8], uint64(seed))
+	binary.BigEndian.PutUint64(input[8:], uint64(i))
+	sum := sha256.Sum256(input[:])
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
+
+// objectHash returns the hash of the name of the i-th object of a file test.
+func objectHash(fileTestID ID, i int) uint32 {
+	hash := fnv.New32a()
+	_, _ = fmt.Fprintf(hash, "%s%d", fileTestID, i)
+	return hash.Sum32()
+}
+
+// RunIDPattern is a regular expression matching the IDs of runs, which
+// are UUIDs.
+const RunIDPattern = `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`
+
+// templateIndex and templateRunID stand in for the index and run ID of
+// name templates in ObjectNamePattern.
+const (
+	templateIndex = 987654321
+	templateRunID = "perftester-template-run-id"
+)
+
+// NamesIncludeRunID returns whether the name template of the file test
+// puts the run ID in the names of its objects.
+func (fileTest FileTest) NamesIncludeRunID(fileTestID ID) bool {
+	if fileTest.NameTemplate == "" {
+		return false
+	}
+	fileTest.RunID = templateRunID
+	name, err := fileTest.executeNameTemplate(fileTestID, templateIndex)
+	return err == nil && strings.Contains(name, templateRunID)
+}
+
+// ObjectNamePattern returns an unanchored regular expression matching the
+// names ObjectName returns for any index. Name templates including the run
+// ID match it with the regular expression runID, such as RunIDPattern, or
+// without any run ID if runID is empty.
+func (fileTest FileTest) ObjectNamePattern(fileTestID ID, runID string) string {
+	name := regexp.QuoteMeta(string(fileTestID)) + `[0-9]+`
+	if fileTest.NameTemplate != "" {
+		fileTest.RunID = templateRunID
+		if templated, err := fileTest.executeNameTemplate(fileTestID, templateIndex); err == nil {
+			name = regexp.QuoteMeta(strings.Trim(templated, "/"))
+			name = strings.ReplaceAll(name, fmt.Sprintf("%08x", objectHash(fileTestID, templateIndex)), `[0-9a-f]{8}`)
+			name = strings.ReplaceAll(name, strconv.Itoa(templateIndex), `[0-9]+`)
+			if runID != "" {
+				name = strings.ReplaceAll(name, regexp.QuoteMeta(templateRunID), `(?:`+runID+`)`)
+			} else {
+				// Names are cleaned, so an empty run ID takes its slash
+				// along.
+				name = strings.ReplaceAll(name, regexp.QuoteMeta(templateRunID+"/"), "")
+				name = strings.ReplaceAll(name, regexp.QuoteMeta(templateRunID), "")
+			}
+		}
+	}
+
+	switch fileTest.KeyDistribution {
+	case SequentialKeys, HashedKeys:
+		return `[0-9a-f]{4}/` + name
+	case RandomKeys:
+		return `[0-9a-f]{8}/` + name
+	default:
+		return name
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/config"
+)
+
+func TestObjectSeed(t *testing.T) {
+	// The seeds are pinned, since the contents and names of objects
+	// uploaded by earlier versions depend on them.
+	require.Equal(t, int64(8662715124235083362), config.ObjectSeed(1, 0))
+	require.Equal(t, int64(5993704787448863924), config.ObjectSeed(1, 1))
+	require.Equal(t, int64(1371816949406847272), config.ObjectSeed(2, 0))
+
+	// Nearby seeds don't share the seeds of their objects.
+	require.NotEqual(t, config.ObjectSeed(1, 1), config.ObjectSeed(2, 0))
+}
+
+func TestRandomKeysGolden(t *testing.T) {
+	fileTest := config.FileTest{Seed: 1, KeyDistribution: config.RandomKeys}
+	var names []string
+	for i := 0; i < 3; i++ {
+		names = append(names, fileTest.ObjectName("ft", i))
+	}
+	require.Equal(t, []string{"98bdfa67/ft0", "b8c28f92/ft1", "a3eaf996/ft2"}, names)
+
+	// The prefixes of nearby seeds differ.
+	fileTest.Seed = 2
+	require.NotEqual(t, names[1][:8], fileTest.ObjectName("ft", 0)[:8])
+}
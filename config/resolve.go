@@ -0,0 +1,295 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/backends"
+)
+
+// defaultRamp are the parallelism levels of ramp tests without configured
+// levels.
+var defaultRamp = []int64{1, 2, 4, 8, 16}
+
+// WithDefaults returns the file test with the defaults of its unset
+// settings, which checks run it with. Its timeout defaults to timeout, that
+// of the config.
+func (fileTest FileTest) WithDefaults(timeout Duration) FileTest {
+	if fileTest.Timeout <= 0 {
+		fileTest.Timeout = timeout
+	}
+	if fileTest.Type == "" {
+		fileTest.Type = ThroughputTest
+	}
+	if fileTest.Checksum == "" {
+		fileTest.Checksum = SHA256Checksum
+	}
+	if fileTest.Iterations <= 0 {
+		fileTest.Iterations = 1
+	}
+
+	// Ramp tests set the parallelism of each of their levels.
+	if fileTest.Type == RampTest {
+		if len(fileTest.Ramp) == 0 {
+			fileTest.Ramp = defaultRamp
+		}
+		return fileTest
+	}
+
+	if fileTest.NumParallel <= 0 {
+		fileTest.NumParallel = 1
+	}
+	// Existing tests download all objects under their prefix by default.
+	if fileTest.NumObjects <= 0 && fileTest.Type != ExistingTest {
+		fileTest.NumObjects = fileTest.NumParallel
+	}
+	return fileTest
+}
+
+// WithDefaults returns a copy of the config with the defaults of its unset
+// settings and those of its file tests.
+func (config Config) WithDefaults() Config {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = backends.DefaultBufferSize
+	}
+	if config.OutputFormat == "" {
+		config.OutputFormat = "text"
+	}
+
+	fileTests := make(map[ID]FileTest, len(config.FileTests))
+	for id, fileTest := range config.FileTests {
+		fileTests[id] = fileTest.WithDefaults(config.Timeout)
+	}
+	config.FileTests = fileTests
+	return config
+}
+
+// FileTestSizes returns the sizes of the files of the file tests by ID,
+// which reporters compute throughput with.
+func (config Config) FileTestSizes() map[ID]int {
+	sizes := make(map[ID]int, len(config.FileTests))
+	for id, fileTest := range config.FileTests {
+		sizes[id] = int(fileTest.Size)
+	}
+	return sizes
+}
+
+// Redacted is the value secrets are replaced with by EncodeRedacted.
+const Redacted = "REDACTED"
+
+// EncodeRedacted encodes the config as TOML with its endpoints decoded and
+// their secrets, the settings tagged secret:"true" and the passwords of
+// URLs, replaced with Redacted. Unset settings are left out. The file tests
+// of endpoints with defaults are added with the defaults applied under
+// effective.<endpoint>.<file test>, since they run with other settings than
+// the configured ones there.
+func (config Config) EncodeRedacted() ([]byte, error) {
+	endpoints, err := config.DecodeEndpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	config.Endpoints = nil
+	tables, _ := tomlValue(reflect.ValueOf(config)).(map[string]interface{})
+	if tables == nil {
+		tables = make(map[string]interface{})
+	}
+
+	decoded := make(map[string]interface{})
+	for _, endpoint := range endpoints {
+		ids, _ := decoded[endpoint.Type].(map[string]interface{})
+		if ids == nil {
+			ids = make(map[string]interface{})
+			decoded[endpoint.Type] = ids
+		}
+		if table := tomlValue(reflect.ValueOf(endpoint.Config)); table != nil {
+			ids[string(endpoint.ID)] = table
+		} else {
+			ids[string(endpoint.ID)] = map[string]interface{}{}
+		}
+	}
+	if len(decoded) > 0 {
+		tables["endpoint"] = decoded
+	}
+
+	effective := make(map[string]interface{})
+	for _, endpoint := range endpoints {
+		if endpoint.Defaults == (EndpointDefaults{}) {
+			continue
+		}
+		fileTests := make(map[ID]FileTest, len(config.FileTests))
+		for id, fileTest := range config.FileTests {
+			fileTests[id] = endpoint.Defaults.Apply(fileTest)
+		}
+		if table := tomlValue(reflect.ValueOf(fileTests)); table != nil {
+			effective[string(endpoint.ID)] = table
+		}
+	}
+	if len(effective) > 0 {
+		tables["effective"] = effective
+	}
+
+	redactURLs(tables)
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(tables); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// tomlValue returns the value v decodes from in TOML, with structs as
+// tables keyed like their toml tags. It returns nil for unset values and
+// for values which aren't settings, like clients. Pointers to false are
+// set, since they override defaults of true.
+func tomlValue(v reflect.Value) interface{} {
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok && v.Kind() != reflect.Ptr {
+		if v.IsZero() {
+			return nil
+		}
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil
+		}
+		return string(text)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if !v.Bool() {
+			return nil
+		}
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 {
+			return nil
+		}
+		return v.Int()
+	case reflect.Float32, reflect.Float64:
+		if v.Float() == 0 {
+			return nil
+		}
+		return v.Float()
+	case reflect.String:
+		if v.String() == "" {
+			return nil
+		}
+		return v.String()
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() == reflect.Bool {
+			return v.Elem().Bool()
+		}
+		return tomlValue(v.Elem())
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Struct {
+			tables := make([]map[string]interface{}, 0, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				table, _ := tomlValue(v.Index(i)).(map[string]interface{})
+				if table == nil {
+					table = map[string]interface{}{}
+				}
+				tables = append(tables, table)
+			}
+			return tables
+		}
+		values := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if value := tomlValue(v.Index(i)); value != nil {
+				values = append(values, value)
+			}
+		}
+		return values
+	case reflect.Map:
+		table := make(map[string]interface{})
+		for _, key := range v.MapKeys() {
+			if value := tomlValue(v.MapIndex(key)); value != nil {
+				table[key.String()] = value
+			}
+		}
+		if len(table) == 0 {
+			return nil
+		}
+		return table
+	case reflect.Struct:
+		table := make(map[string]interface{})
+		addFields(table, v)
+		if len(table) == 0 {
+			return nil
+		}
+		return table
+	default:
+		return nil
+	}
+}
+
+// addFields adds the settings of the fields of the struct v to table,
+// including those of its embedded structs.
+func addFields(table map[string]interface{}, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := strings.Split(field.Tag.Get("toml"), ",")[0]
+		switch {
+		case name == "-" || field.PkgPath != "":
+			continue
+		case field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct:
+			addFields(table, v.Field(i))
+			continue
+		case name == "":
+			name = field.Name
+		}
+		if value := tomlValue(v.Field(i)); value != nil {
+			if field.Tag.Get("secret") == "true" {
+				value = Redacted
+			}
+			table[name] = value
+		}
+	}
+}
+
+// redactURLs replaces the passwords of the URLs in the tables in place.
+func redactURLs(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = redactURLs(v)
+		}
+		return value
+	case []map[string]interface{}:
+		for _, table := range value {
+			redactURLs(table)
+		}
+		return value
+	case []interface{}:
+		for i, v := range value {
+			value[i] = redactURLs(v)
+		}
+		return value
+	case string:
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), Redacted)
+				return u.String()
+			}
+		}
+		return value
+	default:
+		return value
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/backends"
+)
+
+// Validate checks the config for mistakes which would otherwise only
+// surface in the middle of a run. Endpoints are decoded and checked by the
+// clients of their types, so these must be registered like for
+// DecodeEndpoints; endpoints of unregistered types are reported as unknown.
+func (config Config) Validate() error {
+	var group errs.Group
+
+	if len(config.FileTests) == 0 {
+		group.Add(errs.New("no file tests configured"))
+	}
+	numEndpoints := 0
+	for _, tables := range config.Endpoints {
+		numEndpoints += len(tables)
+	}
+	if numEndpoints == 0 {
+		group.Add(errs.New("no endpoints configured"))
+	}
+
+	fileTestIDs := make([]ID, 0, len(config.FileTests))
+	for id := range config.FileTests {
+		fileTestIDs = append(fileTestIDs, id)
+	}
+	sort.Slice(fileTestIDs, func(i, j int) bool { return fileTestIDs[i] < fileTestIDs[j] })
+
+	for _, id := range fileTestIDs {
+		fileTest := config.FileTests[id]
+		switch fileTest.Type {
+		case "", ThroughputTest, LatencyTest, RampTest, SoakTest, MixedTest, CacheTest, ConsistencyTest, ConflictTest, ListingTest:
+			if fileTest.Manifest != "" {
+				group.Add(errs.New("file test %q: only existing tests have a manifest", id))
+			}
+			if fileTest.NameTemplate != "" {
+				if err := fileTest.validateNameTemplate(id); err != nil {
+					group.Add(errs.New("file test %q: invalid name template: %v", id, err))
+				}
+			}
+		case BucketTest:
+			if fileTest.NameTemplate != "" || fileTest.KeyDistribution != "" {
+				group.Add(errs.New("file test %q: bucket tests don't name objects", id))
+			}
+		case ExistingTest:
+			if strings.Trim(fileTest.Prefix, "/") == "" {
+				group.Add(errs.New("file test %q: existing tests need a prefix", id))
+			}
+			if fileTest.NameTemplate != "" {
+				group.Add(errs.New("file test %q: existing tests don't have a name template", id))
+			}
+			if fileTest.KeyDistribution != "" {
+				group.Add(errs.New("file test %q: existing tests don't have a key distribution", id))
+			}
+		default:
+			group.Add(errs.New("file test %q: unknown type %q", id, fileTest.Type))
+		}
+		switch fileTest.Content {
+		case "", RandomContent, ZeroContent, TextContent, CryptoContent:
+		case CorpusContent:
+			if fileTest.ContentDir == "" {
+				group.Add(errs.New("file test %q: corpus content needs a content dir", id))
+			}
+		default:
+			group.Add(errs.New("file test %q: unknown content %q", id, fileTest.Content))
+		}
+		switch fileTest.Checksum {
+		case "", SHA256Checksum, XXHashChecksum, CRC32CChecksum, BLAKE3Checksum:
+		default:
+			group.Add(errs.New("file test %q: unknown checksum %q", id, fileTest.Checksum))
+		}
+		switch fileTest.KeyDistribution {
+		case "", SequentialKeys, HashedKeys, RandomKeys:
+		default:
+			group.Add(errs.New("file test %q: unknown key distribution %q", id, fileTest.KeyDistribution))
+		}
+		if fileTest.Versions < 0 {
+			group.Add(errs.New("file test %q: number of versions must not be negative", id))
+		}
+		for key := range fileTest.Metadata {
+			if key == "" {
+				group.Add(errs.New("file test %q: metadata keys must not be empty", id))
+			}
+			if key != strings.ToLower(key) {
+				group.Add(errs.New("file test %q: metadata key %q must be lower case", id, key))
+			}
+		}
+		if maxKeys := maxMetadataKeys(fileTest); len(fileTest.Metadata) > maxKeys {
+			group.Add(errs.New("file test %q: at most %d metadata keys are allowed, since S3 stores them in object tags", id, maxKeys))
+		}
+		if fileTest.NumPrefixes < 0 {
+			group.Add(errs.New("file test %q: number of prefixes must not be negative", id))
+		}
+		for _, name := range fileTest.Operations {
+			if _, ok := OperationNames[name]; !ok {
+				group.Add(errs.New("file test %q: unknown operation %q", id, name))
+			}
+		}
+		if fileTest.Size <= 0 && fileTest.Type != BucketTest {
+			group.Add(errs.New("file test %q: size must be positive", id))
+		}
+		if fileTest.PartSize < 0 {
+			group.Add(errs.New("file test %q: part size must not be negative", id))
+		}
+		for _, level := range fileTest.Ramp {
+			if level <= 0 {
+				group.Add(errs.New("file test %q: ramp levels must be positive", id))
+				break
+			}
+		}
+		if fileTest.Type == SoakTest && fileTest.Duration <= 0 {
+			group.Add(errs.New("file test %q: soak tests need a positive duration", id))
+		}
+		if fileTest.SoakInterval < 0 {
+			group.Add(errs.New("file test %q: soak interval must not be negative", id))
+		}
+		if reads := fileTest.Reads(); reads < 0 || reads > 100 {
+			group.Add(errs.New("file test %q: read percent must be between 0 and 100", id))
+		}
+		if fileTest.CacheDelay < 0 {
+			group.Add(errs.New("file test %q: cache delay must not be negative", id))
+		}
+		if fileTest.Type == ConflictTest {
+			if fileTest.NumParallel < 2 {
+				group.Add(errs.New("file test %q: conflict tests need at least 2 parallel writers", id))
+			}
+			if fileTest.Content == ZeroContent {
+				group.Add(errs.New("file test %q: conflict tests can't tell apart the writers of zero content", id))
+			}
+		}
+		for _, pageSize := range fileTest.PageSizes {
+			if pageSize <= 0 {
+				group.Add(errs.New("file test %q: page sizes must be positive", id))
+				break
+			}
+		}
+		if fileTest.PollInterval < 0 {
+			group.Add(errs.New("file test %q: poll interval must not be negative", id))
+		}
+		if fileTest.Cooldown < 0 {
+			group.Add(errs.New("file test %q: cooldown must not be negative", id))
+		}
+		if fileTest.RateLimit < 0 {
+			group.Add(errs.New("file test %q: rate limit must not be negative", id))
+		}
+		if fileTest.TTL < 0 {
+			group.Add(errs.New("file test %q: ttl must not be negative", id))
+		}
+		for _, byteRange := range fileTest.Ranges {
+			if byteRange.Offset < 0 || byteRange.Offset > int64(fileTest.Size) {
+				group.Add(errs.New("file test %q: range offset %d outside of the file", id, byteRange.Offset))
+			}
+		}
+	}
+
+	if config.Thresholds.MaxErrorRate < 0 || config.Thresholds.MaxErrorRate > 100 {
+		group.Add(errs.New("max error rate must be between 0 and 100"))
+	}
+	for id, mbps := range config.Thresholds.MinThroughput {
+		if mbps < 0 {
+			group.Add(errs.New("endpoint %q: min throughput must not be negative", id))
+		}
+	}
+
+	for i, rule := range config.SLA.Rules {
+		if rule.Operation != "" {
+			if _, ok := OperationNames[rule.Operation]; !ok {
+				group.Add(errs.New("sla rule %d: unknown operation %q", i+1, rule.Operation))
+			}
+		}
+		if rule.MinThroughput <= 0 && rule.MaxLatency <= 0 {
+			group.Add(errs.New("sla rule %d: needs a min throughput or a max latency", i+1))
+		}
+		if rule.MinThroughput < 0 || rule.MaxLatency < 0 || rule.MinSize < 0 || rule.MaxSize < 0 {
+			group.Add(errs.New("sla rule %d: limits must not be negative", i+1))
+		}
+		if rule.MaxSize > 0 && rule.MaxSize < rule.MinSize {
+			group.Add(errs.New("sla rule %d: max size below min size", i+1))
+		}
+	}
+
+	for _, endpointType := range sortedTypes(config.Endpoints) {
+		for _, id := range sortedIDs(config.Endpoints[endpointType]) {
+			endpoint, err := config.decodeEndpoint(endpointType, id)
+			if err != nil {
+				group.Add(err)
+				continue
+			}
+			if validator, ok := endpoint.Config.(backends.Validator); ok {
+				if err := validator.Validate(); err != nil {
+					group.Add(errs.New("%s endpoint %q: %v", endpointType, id, err))
+				}
+			}
+			validateDefaults(&group, endpointType, id, endpoint.Defaults)
+		}
+	}
+
+	if config.Cooldown < 0 {
+		group.Add(errs.New("cooldown must not be negative"))
+	}
+	if config.BufferSize < 0 {
+		group.Add(errs.New("buffer size must not be negative"))
+	}
+	if config.Monitoring.MetricsInterval < 0 {
+		group.Add(errs.New("monitoring: metrics interval must not be negative"))
+	}
+	if config.Monitoring.Pprof && config.Monitoring.PrometheusAddress == "" {
+		group.Add(errs.New("monitoring: pprof profiles are served on the Prometheus address, which isn't set"))
+	}
+
+	return group.Err()
+}
+
+// validateDefaults checks the file test overrides of an endpoint.
+func validateDefaults(group *errs.Group, kind string, id ID, defaults EndpointDefaults) {
+	if defaults.Timeout < 0 || defaults.MaxParallel < 0 || defaults.Retries < 0 || defaults.RetryBackoff < 0 || defaults.Cooldown < 0 {
+		group.Add(errs.New("%s endpoint %q: timeout, max parallel, retries and cooldown must not be negative", kind, id))
+	}
+}
+
+// maxObjectTags is the number of tags S3 objects can have.
+const maxObjectTags = 10
+
+// maxMetadataKeys returns the number of custom metadata keys the objects of
+// the file test can have, which S3 stores in their tags next to the tag
+// expiring them.
+func maxMetadataKeys(fileTest FileTest) int {
+	if fileTest.TTL > 0 {
+		return maxObjectTags - 1
+	}
+	return maxObjectTags
+}
@@ -3,17 +3,29 @@ module storj.io/perftester
 go 1.14
 
 require (
+	cloud.google.com/go/storage v1.10.0
 	github.com/BurntSushi/toml v0.3.1
-	github.com/aws/aws-sdk-go v1.34.24
-	github.com/btcsuite/btcutil v1.0.1
-	github.com/gogo/protobuf v1.2.1
-	github.com/spacemonkeygo/monkit/v3 v3.0.7-0.20200515175308-072401d8c752
-	github.com/spf13/cobra v1.0.0
-	github.com/stretchr/testify v1.5.1
+	github.com/aws/aws-sdk-go v1.38.22
+	github.com/btcsuite/btcutil v1.0.3-0.20201208143702-a53e38424cce
+	github.com/cespare/xxhash/v2 v2.1.1
+	github.com/gogo/protobuf v1.3.2
+	github.com/mattn/go-sqlite3 v1.14.6
+	github.com/oschwald/maxminddb-golang v1.3.1
+	github.com/prometheus/client_golang v1.10.0
+	github.com/rclone/rclone v1.56.0
+	github.com/spacemonkeygo/monkit/v3 v3.0.11
+	github.com/spf13/cobra v1.1.3
+	github.com/stretchr/testify v1.7.0
+	github.com/zeebo/blake3 v0.2.3
 	github.com/zeebo/errs v1.2.2
 	go.uber.org/zap v1.16.0
-	golang.org/x/sync v0.0.0-20200317015054-43a5402ce75a
-	storj.io/common v0.0.0-20200818131620-f9cddf66b4be
-	storj.io/private v0.0.0-20200910221144-9fa0a1f43adf
-	storj.io/uplink v1.3.0
+	golang.org/x/oauth2 v0.0.0-20210413134643-5e61552d6c78
+	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
+	golang.org/x/term v0.0.0-20210406210042-72f3dc4e9b72
+	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
+	google.golang.org/api v0.44.0
+	storj.io/common v0.0.0-20210419115916-eabb53ea1332
+	storj.io/monkit-jaeger v0.0.0-20200518165323-80778fc3f91b
+	storj.io/private v0.0.0-20210203200143-9d2ec06f0d3c
+	storj.io/uplink v1.4.6
 )
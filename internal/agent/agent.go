@@ -0,0 +1,37 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package agent runs checks on remote hosts for a coordinator, which
+// measures the same endpoints from several vantage points in one run.
+package agent
+
+import (
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/report"
+)
+
+// Error is the error class of this package.
+var Error = errs.Class("agent")
+
+// Spec is a run requested from an agent. The agent parses the config
+// itself, so references to environment variables and secret files are
+// expanded on the agent.
+type Spec struct {
+	// Config is the contents of the config file.
+	Config string
+	// Suite, FileTests, Endpoints and Operations select the checks to run
+	// like the flags of the same names.
+	Suite      string
+	FileTests  string
+	Endpoints  string
+	Operations string
+}
+
+// Response is the outcome of a run of an agent.
+type Response struct {
+	report.RunResults
+	// Error is set if the run failed, in which case the results are those
+	// of the checks which did run.
+	Error string `json:",omitempty"`
+}
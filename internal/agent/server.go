@@ -0,0 +1,89 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"storj.io/perftester/internal/auth"
+)
+
+// RunPath is the path specs are posted to.
+const RunPath = "/run"
+
+// RunFunc runs the checks of a spec.
+type RunFunc func(ctx context.Context, spec Spec) (*Response, error)
+
+// Server is the HTTP server of an agent. It runs one spec at a time, since
+// concurrent runs would skew each other's measurements.
+type Server struct {
+	log   *zap.Logger
+	token string
+	run   RunFunc
+	busy  chan struct{}
+}
+
+// NewServer creates the server of an agent which runs specs with run. If
+// token is set, requests need it as their bearer token.
+func NewServer(log *zap.Logger, token string, run RunFunc) *Server {
+	return &Server{
+		log:   log,
+		token: token,
+		run:   run,
+		busy:  make(chan struct{}, 1),
+	}
+}
+
+// ServeHTTP runs the spec posted to RunPath and responds with its results.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != RunPath {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if server.token != "" && !auth.ValidToken(r.Header.Get("Authorization"), server.token) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var spec Spec
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		http.Error(w, "invalid spec: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case server.busy <- struct{}{}:
+		defer func() { <-server.busy }()
+	default:
+		http.Error(w, "agent is busy with another run", http.StatusConflict)
+		return
+	}
+
+	server.log.Info("Running spec", zap.String("remote", r.RemoteAddr))
+	response, err := server.run(r.Context(), spec)
+	if response == nil {
+		response = new(Response)
+	}
+	if err != nil {
+		server.log.Error("Run failed", zap.Error(err))
+		response.Error = err.Error()
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(response); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf.Bytes())
+}
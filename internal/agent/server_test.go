@@ -0,0 +1,37 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package agent_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/internal/agent"
+)
+
+func TestServerToken(t *testing.T) {
+	var runs int
+	server := httptest.NewServer(agent.NewServer(zap.NewNop(), "secret", func(ctx context.Context, spec agent.Spec) (*agent.Response, error) {
+		runs++
+		return new(agent.Response), nil
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	for _, token := range []string{"", "wrong", "secre", "secret2"} {
+		_, err := agent.Run(ctx, http.DefaultClient, server.URL, token, agent.Spec{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "401")
+	}
+	require.Zero(t, runs)
+
+	_, err := agent.Run(ctx, http.DefaultClient, server.URL, "secret", agent.Spec{})
+	require.NoError(t, err)
+	require.Equal(t, 1, runs)
+}
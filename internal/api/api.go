@@ -0,0 +1,233 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package api serves an HTTP API for triggering runs of a daemon and
+// fetching their results, so that perftester can be driven by other test
+// orchestration.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"storj.io/common/uuid"
+	"storj.io/perftester/internal/auth"
+	"storj.io/perftester/report"
+)
+
+// maxRuns is the number of triggered runs whose results are kept.
+const maxRuns = 100
+
+// Request is a run triggered through the API.
+type Request struct {
+	// Config is a config fragment with file test, endpoint and suite
+	// tables added to the daemon's config for this run. It can't reference
+	// the environment variables or files of the daemon.
+	Config string
+	// Suite, FileTests, Endpoints and Operations select the checks to run
+	// like the flags of the same names.
+	Suite      string
+	FileTests  string
+	Endpoints  string
+	Operations string
+}
+
+// Status is the status of a triggered run.
+type Status string
+
+const (
+	// Running runs are still running.
+	Running Status = "running"
+	// Finished runs ran all their checks successfully.
+	Finished Status = "finished"
+	// Failed runs couldn't start or had failed checks.
+	Failed Status = "failed"
+)
+
+// Run is a run triggered through the API.
+type Run struct {
+	ID     string
+	Status Status
+	Error  string `json:",omitempty"`
+	// Results are set once the run stopped. The results of failed runs
+	// are those of the checks which did run.
+	Results *report.RunResults `json:",omitempty"`
+}
+
+// RunFunc runs the checks of a request under runID.
+type RunFunc func(ctx context.Context, runID string, request Request) (*report.RunResults, error)
+
+// Server serves the API:
+//
+//	POST /runs       triggers a run of the posted Request and responds with
+//	                 the Run, which runs in the background
+//	GET  /runs/{id}  responds with the Run
+//	GET  /results    responds with the RunResults of the latest scheduled run
+type Server struct {
+	log   *zap.Logger
+	ctx   context.Context
+	token string
+	run   RunFunc
+	wg    sync.WaitGroup
+
+	mu     sync.Mutex
+	runs   map[string]*Run
+	order  []string // IDs of the runs, oldest first.
+	latest *report.RunResults
+}
+
+// NewServer creates the API server, which runs requests with run until ctx
+// is canceled. If token is set, requests need it as their bearer token.
+func NewServer(ctx context.Context, log *zap.Logger, token string, run RunFunc) *Server {
+	return &Server{
+		log:   log,
+		ctx:   ctx,
+		token: token,
+		run:   run,
+		runs:  make(map[string]*Run),
+	}
+}
+
+// SetLatest sets the results of the latest scheduled run.
+func (server *Server) SetLatest(results *report.RunResults) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.latest = results
+}
+
+// Wait waits for the triggered runs to stop.
+func (server *Server) Wait() {
+	server.wg.Wait()
+}
+
+// ServeHTTP implements http.Handler.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if server.token != "" && !auth.ValidToken(r.Header.Get("Authorization"), server.token) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/runs" && r.Method == http.MethodPost:
+		server.startRun(w, r)
+	case strings.HasPrefix(r.URL.Path, "/runs/") && r.Method == http.MethodGet:
+		server.getRun(w, r, strings.TrimPrefix(r.URL.Path, "/runs/"))
+	case r.URL.Path == "/results" && r.Method == http.MethodGet:
+		server.getResults(w)
+	case r.URL.Path == "/runs" || strings.HasPrefix(r.URL.Path, "/runs/") || r.URL.Path == "/results":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// startRun starts a run of the posted request.
+func (server *Server) startRun(w http.ResponseWriter, r *http.Request) {
+	var request Request
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := uuid.New()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	run := Run{ID: id.String(), Status: Running}
+	server.addRun(&run)
+
+	server.log.Info("Run triggered", zap.String("run", run.ID), zap.String("remote", r.RemoteAddr))
+	server.wg.Add(1)
+	go func(runID string) {
+		defer server.wg.Done()
+		results, err := server.run(server.ctx, runID, request)
+		server.finishRun(runID, results, err)
+	}(run.ID)
+
+	server.respond(w, http.StatusAccepted, Run{ID: run.ID, Status: Running})
+}
+
+// getRun responds with the run.
+func (server *Server) getRun(w http.ResponseWriter, r *http.Request, id string) {
+	server.mu.Lock()
+	run, ok := server.runs[id]
+	var copied Run
+	if ok {
+		copied = *run
+	}
+	server.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	server.respond(w, http.StatusOK, copied)
+}
+
+// getResults responds with the results of the latest scheduled run.
+func (server *Server) getResults(w http.ResponseWriter) {
+	server.mu.Lock()
+	latest := server.latest
+	server.mu.Unlock()
+
+	if latest == nil {
+		http.Error(w, "no scheduled run finished yet", http.StatusNotFound)
+		return
+	}
+	server.respond(w, http.StatusOK, latest)
+}
+
+// addRun adds a run, forgetting the oldest stopped runs beyond maxRuns.
+func (server *Server) addRun(run *Run) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.runs[run.ID] = run
+	server.order = append(server.order, run.ID)
+
+	kept := server.order[:0]
+	excess := len(server.order) - maxRuns
+	for _, id := range server.order {
+		if excess > 0 && server.runs[id].Status != Running {
+			delete(server.runs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	server.order = kept
+}
+
+// finishRun records the outcome of a run.
+func (server *Server) finishRun(id string, results *report.RunResults, err error) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	run := server.runs[id]
+	run.Status = Finished
+	run.Results = results
+	if err != nil {
+		server.log.Error("Triggered run failed", zap.String("run", id), zap.Error(err))
+		run.Status = Failed
+		run.Error = err.Error()
+	}
+}
+
+// respond responds with the JSON of v.
+func (server *Server) respond(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+	"go.uber.org/zap"
+
+	"storj.io/perftester/internal/api"
+	"storj.io/perftester/report"
+)
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := make(chan api.Request, 2)
+	server := api.NewServer(ctx, zap.NewNop(), "secret", func(ctx context.Context, runID string, request api.Request) (*report.RunResults, error) {
+		requests <- request
+		if request.Suite == "failing" {
+			return nil, errs.New("run failed")
+		}
+		return &report.RunResults{Metadata: &report.Metadata{RunID: runID}}, nil
+	})
+	defer server.Wait()
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	do := func(method, path, token string, body interface{}) *http.Response {
+		var data []byte
+		if body != nil {
+			var err error
+			data, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req, err := http.NewRequest(method, httpServer.URL+path, bytes.NewReader(data))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+	decode := func(resp *http.Response, v interface{}) {
+		defer func() { _ = resp.Body.Close() }()
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	waitRun := func(id string) api.Run {
+		for {
+			resp := do(http.MethodGet, "/runs/"+id, "secret", nil)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			var run api.Run
+			decode(resp, &run)
+			if run.Status != api.Running {
+				return run
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		for _, path := range []string{"/runs", "/runs/id", "/results"} {
+			resp := do(http.MethodGet, path, token, nil)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode, path)
+		}
+		resp := do(http.MethodPost, "/runs", token, api.Request{})
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	}
+	require.Empty(t, requests)
+
+	resp := do(http.MethodGet, "/results", "secret", nil)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/runs", "secret", api.Request{Suite: "nightly", FileTests: "small"})
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var started api.Run
+	decode(resp, &started)
+	require.Equal(t, api.Request{Suite: "nightly", FileTests: "small"}, <-requests)
+
+	run := waitRun(started.ID)
+	require.Equal(t, api.Finished, run.Status)
+	require.Empty(t, run.Error)
+	require.Equal(t, started.ID, run.Results.Metadata.RunID)
+
+	resp = do(http.MethodPost, "/runs", "secret", api.Request{Suite: "failing"})
+	decode(resp, &started)
+	<-requests
+	run = waitRun(started.ID)
+	require.Equal(t, api.Failed, run.Status)
+	require.Equal(t, "run failed", run.Error)
+
+	resp = do(http.MethodGet, "/runs/unknown", "secret", nil)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = do(http.MethodDelete, "/runs", "secret", nil)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp = do(http.MethodPost, "/runs", "secret", nil)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	server.SetLatest(&report.RunResults{Metadata: &report.Metadata{RunID: "scheduled"}})
+	resp = do(http.MethodGet, "/results", "secret", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var latest report.RunResults
+	decode(resp, &latest)
+	require.Equal(t, "scheduled", latest.Metadata.RunID)
+}
@@ -0,0 +1,17 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package auth authenticates the requests of the servers perftester runs.
+package auth
+
+import (
+	"crypto/subtle"
+	"strings"
+)
+
+// ValidToken returns whether the Authorization header carries token as its
+// bearer token, comparing them in constant time.
+func ValidToken(header, token string) bool {
+	bearer := strings.TrimPrefix(header, "Bearer ")
+	return bearer != header && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
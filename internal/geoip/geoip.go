@@ -0,0 +1,70 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package geoip locates IP addresses with a MaxMind database.
+package geoip
+
+import (
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/zeebo/errs"
+)
+
+// Error is the error for this package.
+var Error = errs.Class("geoip")
+
+// DB is a MaxMind GeoIP2 or GeoLite2 City or Country database.
+type DB struct {
+	reader *maxminddb.Reader
+}
+
+// Open opens the database at path.
+func Open(path string) (*DB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return &DB{reader: reader}, nil
+}
+
+// record are the fields of a database entry used to describe a location.
+type record struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Locate returns the location of ip as the city, region and country code
+// known to the database, such as "Ashburn, VA, US". It returns an empty
+// string for IPs which aren't in the database.
+func (db *DB) Locate(ip net.IP) (string, error) {
+	var entry record
+	if err := db.reader.Lookup(ip, &entry); err != nil {
+		return "", Error.Wrap(err)
+	}
+
+	var parts []string
+	if city := entry.City.Names["en"]; city != "" {
+		parts = append(parts, city)
+	}
+	if len(entry.Subdivisions) > 0 && entry.Subdivisions[0].ISOCode != "" {
+		parts = append(parts, entry.Subdivisions[0].ISOCode)
+	}
+	if entry.Country.ISOCode != "" {
+		parts = append(parts, entry.Country.ISOCode)
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// Close closes the database.
+func (db *DB) Close() error {
+	return Error.Wrap(db.reader.Close())
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// Trend summarizes the results of one operation on one endpoint for a day.
+type Trend struct {
+	Day        string
+	EndpointID config.ID
+	FileTestID config.ID
+	Operation  string
+	Size       int64
+
+	Samples int
+	Errors  int
+	// MeanDuration is the mean duration of the successful results.
+	MeanDuration time.Duration
+}
+
+// HistoryFilter restricts which results are summarized.
+type HistoryFilter struct {
+	Since      time.Time
+	EndpointID config.ID // All endpoints when empty.
+}
+
+// History summarizes the stored results per endpoint, file test, operation
+// and day.
+func (store *Store) History(ctx context.Context, filter HistoryFilter) (trends []Trend, err error) {
+	rows, err := store.db.QueryContext(ctx, `
+		SELECT
+			date(started_at, 'unixepoch') AS day, endpoint, filetest, operation, size,
+			COUNT(*),
+			SUM(error != ''),
+			COALESCE(AVG(CASE WHEN error = '' THEN duration_ns END), 0)
+		FROM results
+		WHERE started_at >= ? AND (? = '' OR endpoint = ?)
+		GROUP BY day, endpoint, filetest, operation, size
+		ORDER BY endpoint, filetest, operation, day`,
+		filter.Since.Unix(), string(filter.EndpointID), string(filter.EndpointID))
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(rows.Close())) }()
+
+	for rows.Next() {
+		var trend Trend
+		var meanDuration float64
+		err := rows.Scan(&trend.Day, &trend.EndpointID, &trend.FileTestID, &trend.Operation, &trend.Size,
+			&trend.Samples, &trend.Errors, &meanDuration)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		trend.MeanDuration = time.Duration(meanDuration)
+		trends = append(trends, trend)
+	}
+	return trends, Error.Wrap(rows.Err())
+}
+
+// Mbps returns the mean throughput of the successful results, or zero if
+// there were none or the operation doesn't transfer the whole file.
+func (trend Trend) Mbps() float64 {
+	return mbps(trend.Operation, trend.Size, trend.MeanDuration)
+}
+
+// mbps returns the throughput of transferring size bytes in duration, or
+// zero for operations which don't transfer the whole file.
+func mbps(operationName string, size int64, duration time.Duration) float64 {
+	operation, ok := config.ParseOperation(operationName)
+	if !ok || !operation.TransfersFile() || duration <= 0 {
+		return 0
+	}
+	return float64(size) * 8 / 1000 / 1000 / duration.Seconds()
+}
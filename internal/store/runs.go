@@ -0,0 +1,139 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// ErrNotFound is returned for runs which aren't stored.
+var ErrNotFound = errs.Class("not found")
+
+// RunSummary is a stored run with the number of its results.
+type RunSummary struct {
+	Run
+	// EndTime is zero for runs which didn't finish.
+	EndTime time.Time
+
+	Results int
+	Errors  int
+}
+
+const runSummaryQuery = `
+	SELECT
+		runs.id, runs.started_at, runs.config_hash, runs.finished_at,
+		runs.hostname, runs.os, runs.go_version, runs.version,
+		COUNT(results.run_id), COALESCE(SUM(results.error != ''), 0)
+	FROM runs LEFT JOIN results ON results.run_id = runs.id`
+
+// Runs returns the summaries of the latest runs, newest first.
+func (store *Store) Runs(ctx context.Context, limit int) (runs []RunSummary, err error) {
+	rows, err := store.db.QueryContext(ctx, runSummaryQuery+`
+		GROUP BY runs.id
+		ORDER BY runs.started_at DESC, runs.rowid DESC
+		LIMIT ?`,
+		limit)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(rows.Close())) }()
+
+	for rows.Next() {
+		run, err := scanRunSummary(rows)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, Error.Wrap(rows.Err())
+}
+
+// GetRun returns the summary of a run.
+func (store *Store) GetRun(ctx context.Context, runID string) (RunSummary, error) {
+	row := store.db.QueryRowContext(ctx, runSummaryQuery+`
+		WHERE runs.id = ?
+		GROUP BY runs.id`,
+		runID)
+	run, err := scanRunSummary(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return RunSummary{}, ErrNotFound.New("run %q", runID)
+	}
+	return run, Error.Wrap(err)
+}
+
+// scanRunSummary scans a row of runSummaryQuery.
+func scanRunSummary(row interface{ Scan(...interface{}) error }) (RunSummary, error) {
+	var run RunSummary
+	var startedAt, finishedAt int64
+	err := row.Scan(&run.ID, &startedAt, &run.ConfigHash, &finishedAt,
+		&run.Hostname, &run.OS, &run.GoVersion, &run.Version,
+		&run.Results, &run.Errors)
+	if err != nil {
+		return RunSummary{}, err
+	}
+	run.StartTime = time.Unix(startedAt, 0)
+	if finishedAt != 0 {
+		run.EndTime = time.Unix(finishedAt, 0)
+	}
+	return run, nil
+}
+
+// Result is a stored result.
+type Result struct {
+	FileTestID config.ID
+	EndpointID config.ID
+	Operation  string
+	Size       int64
+	StartTime  time.Time
+
+	Duration  time.Duration
+	FirstByte time.Duration
+	Finalize  time.Duration
+	Retries   int
+	Error     string
+}
+
+// Mbps returns the throughput of the result, or zero if it failed or its
+// operation doesn't transfer the whole file.
+func (result Result) Mbps() float64 {
+	if result.Error != "" {
+		return 0
+	}
+	return mbps(result.Operation, result.Size, result.Duration)
+}
+
+// Results returns the results of a run in the order they were reported.
+func (store *Store) Results(ctx context.Context, runID string) (results []Result, err error) {
+	rows, err := store.db.QueryContext(ctx, `
+		SELECT filetest, endpoint, operation, size, started_at,
+			duration_ns, first_byte_ns, finalize_ns, retries, error
+		FROM results
+		WHERE run_id = ?
+		ORDER BY rowid`,
+		runID)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(rows.Close())) }()
+
+	for rows.Next() {
+		var result Result
+		var startedAt int64
+		err := rows.Scan(&result.FileTestID, &result.EndpointID, &result.Operation, &result.Size, &startedAt,
+			&result.Duration, &result.FirstByte, &result.Finalize, &result.Retries, &result.Error)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		result.StartTime = time.Unix(startedAt, 0)
+		results = append(results, result)
+	}
+	return results, Error.Wrap(rows.Err())
+}
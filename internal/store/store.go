@@ -0,0 +1,312 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // register the sqlite3 driver
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// Error is the error for this package.
+var Error = errs.Class("store")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          TEXT PRIMARY KEY,
+	started_at  INTEGER NOT NULL,
+	config_hash TEXT NOT NULL,
+	finished_at INTEGER NOT NULL DEFAULT 0,
+	hostname    TEXT NOT NULL DEFAULT '',
+	os          TEXT NOT NULL DEFAULT '',
+	go_version  TEXT NOT NULL DEFAULT '',
+	version     TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS results (
+	run_id        TEXT NOT NULL REFERENCES runs(id),
+	filetest      TEXT NOT NULL,
+	endpoint      TEXT NOT NULL,
+	operation     TEXT NOT NULL,
+	size          INTEGER NOT NULL,
+	started_at    INTEGER NOT NULL,
+	duration_ns   INTEGER NOT NULL,
+	first_byte_ns INTEGER NOT NULL,
+	finalize_ns   INTEGER NOT NULL,
+	retries       INTEGER NOT NULL,
+	error         TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_endpoint_started_at ON results (endpoint, started_at);
+CREATE TABLE IF NOT EXISTS timelines (
+	result_id INTEGER NOT NULL REFERENCES results(rowid),
+	offset_ns INTEGER NOT NULL,
+	bytes     INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS timelines_result_id ON timelines (result_id);
+CREATE TABLE IF NOT EXISTS node_stats (
+	result_id INTEGER PRIMARY KEY REFERENCES results(rowid),
+	nodes     INTEGER NOT NULL,
+	failed    INTEGER NOT NULL,
+	cancelled INTEGER NOT NULL,
+	bytes     INTEGER NOT NULL
+);
+`
+
+// Store persists the results of every run in a SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the database at path, creating it if needed.
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, Error.Wrap(errs.Combine(err, db.Close()))
+	}
+	if err := migrateRuns(ctx, db); err != nil {
+		return nil, Error.Wrap(errs.Combine(err, db.Close()))
+	}
+
+	return &Store{db: db}, nil
+}
+
+// runColumns are the columns added to the runs table after its first
+// release, which databases created before need to be migrated to.
+var runColumns = map[string]string{
+	"finished_at": "INTEGER NOT NULL DEFAULT 0",
+	"hostname":    "TEXT NOT NULL DEFAULT ''",
+	"os":          "TEXT NOT NULL DEFAULT ''",
+	"go_version":  "TEXT NOT NULL DEFAULT ''",
+	"version":     "TEXT NOT NULL DEFAULT ''",
+}
+
+// migrateRuns adds the missing runColumns to the runs table.
+func migrateRuns(ctx context.Context, db *sql.DB) (err error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_table_info('runs')`)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errs.Combine(err, rows.Close()) }()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for name, definition := range runColumns {
+		if existing[name] {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, `ALTER TABLE runs ADD COLUMN `+name+` `+definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the database.
+func (store *Store) Close() error {
+	return Error.Wrap(store.db.Close())
+}
+
+// Run identifies a single run of all checks.
+type Run struct {
+	ID         string
+	StartTime  time.Time
+	ConfigHash string
+
+	// Hostname, OS, GoVersion and Version describe the environment of the
+	// run.
+	Hostname  string
+	OS        string
+	GoVersion string
+	Version   string
+}
+
+// CreateRun records the start of a run.
+func (store *Store) CreateRun(ctx context.Context, run Run) error {
+	_, err := store.db.ExecContext(ctx, `
+		INSERT INTO runs (id, started_at, config_hash, hostname, os, go_version, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		run.ID, run.StartTime.Unix(), run.ConfigHash,
+		run.Hostname, run.OS, run.GoVersion, run.Version)
+	return Error.Wrap(err)
+}
+
+// FinishRun records the end of a run.
+func (store *Store) FinishRun(ctx context.Context, runID string, endTime time.Time) error {
+	_, err := store.db.ExecContext(ctx,
+		`UPDATE runs SET finished_at = ? WHERE id = ?`,
+		endTime.Unix(), runID)
+	return Error.Wrap(err)
+}
+
+// Reporter appends the results of a run to the store.
+type Reporter struct {
+	store         *Store
+	runID         string
+	fileTestSizes map[config.ID]int
+}
+
+// Reporter returns a reporter which appends results to the run.
+func (store *Store) Reporter(runID string, fileTestSizes map[config.ID]int) *Reporter {
+	return &Reporter{
+		store:         store,
+		runID:         runID,
+		fileTestSizes: fileTestSizes,
+	}
+}
+
+// Report accepts a single report, storing its timeline and node stats
+// along with it.
+func (reporter *Reporter) Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) (err error) {
+	if result.Unsupported {
+		return nil
+	}
+
+	tx, err := reporter.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			err = errs.Combine(err, Error.Wrap(tx.Rollback()))
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO results (
+			run_id, filetest, endpoint, operation, size, started_at,
+			duration_ns, first_byte_ns, finalize_ns, retries, error
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		reporter.runID, string(fileTestID), string(endpointID), operation.String(),
+		reporter.fileTestSizes[fileTestID], result.StartTime.Unix(),
+		int64(result.Duration), int64(result.FirstByte), int64(result.Finalize),
+		result.Retries(), result.Error)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	resultID, err := res.LastInsertId()
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	for i, bytes := range result.Timeline {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO timelines (result_id, offset_ns, bytes) VALUES (?, ?, ?)`,
+			resultID, int64(i)*int64(config.TimelineInterval), bytes)
+		if err != nil {
+			return Error.Wrap(err)
+		}
+	}
+
+	if stats := result.NodeStats; stats != nil {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO node_stats (result_id, nodes, failed, cancelled, bytes) VALUES (?, ?, ?, ?, ?)`,
+			resultID, stats.Nodes, stats.Failed, stats.Cancelled, stats.Bytes)
+		if err != nil {
+			return Error.Wrap(err)
+		}
+	}
+
+	return Error.Wrap(tx.Commit())
+}
+
+// Timeline is the timeline of a stored result.
+type Timeline struct {
+	FileTestID config.ID
+	EndpointID config.ID
+	Operation  string
+	StartTime  time.Time
+
+	// Bytes are the bytes transferred in each consecutive
+	// config.TimelineInterval of the operation.
+	Bytes []int64
+}
+
+// Timelines returns the timelines of the results of a run.
+func (store *Store) Timelines(ctx context.Context, runID string) (timelines []Timeline, err error) {
+	rows, err := store.db.QueryContext(ctx, `
+		SELECT results.rowid, filetest, endpoint, operation, started_at, bytes
+		FROM results JOIN timelines ON timelines.result_id = results.rowid
+		WHERE run_id = ?
+		ORDER BY results.rowid, offset_ns`,
+		runID)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(rows.Close())) }()
+
+	lastResultID := int64(-1)
+	for rows.Next() {
+		var resultID, startedAt, bytes int64
+		var timeline Timeline
+		err := rows.Scan(&resultID, &timeline.FileTestID, &timeline.EndpointID, &timeline.Operation, &startedAt, &bytes)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		if resultID != lastResultID {
+			timeline.StartTime = time.Unix(startedAt, 0)
+			timelines = append(timelines, timeline)
+			lastResultID = resultID
+		}
+		last := &timelines[len(timelines)-1]
+		last.Bytes = append(last.Bytes, bytes)
+	}
+	return timelines, Error.Wrap(rows.Err())
+}
+
+// NodeStats are the storage node level stats of a stored download.
+type NodeStats struct {
+	FileTestID config.ID
+	EndpointID config.ID
+	Operation  string
+	StartTime  time.Time
+	Size       int
+
+	backends.NodeStats
+}
+
+// NodeStats returns the node stats of the results of a run.
+func (store *Store) NodeStats(ctx context.Context, runID string) (nodeStats []NodeStats, err error) {
+	rows, err := store.db.QueryContext(ctx, `
+		SELECT filetest, endpoint, operation, started_at, size, nodes, failed, cancelled, bytes
+		FROM results JOIN node_stats ON node_stats.result_id = results.rowid
+		WHERE run_id = ?
+		ORDER BY results.rowid`,
+		runID)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, Error.Wrap(rows.Close())) }()
+
+	for rows.Next() {
+		var startedAt int64
+		var stats NodeStats
+		err := rows.Scan(&stats.FileTestID, &stats.EndpointID, &stats.Operation, &startedAt, &stats.Size,
+			&stats.Nodes, &stats.Failed, &stats.Cancelled, &stats.Bytes)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		stats.StartTime = time.Unix(startedAt, 0)
+		nodeStats = append(nodeStats, stats)
+	}
+	return nodeStats, Error.Wrap(rows.Err())
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+	"storj.io/perftester/internal/store"
+)
+
+func TestHistory(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db, err := store.Open(ctx, ctx.File("perftester.db"))
+	require.NoError(t, err)
+	defer ctx.Check(db.Close)
+
+	now := time.Now()
+	require.NoError(t, db.CreateRun(ctx, store.Run{ID: "run1", StartTime: now, ConfigHash: "hash"}))
+
+	reporter := db.Reporter("run1", map[config.ID]int{"ft1": 1000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{StartTime: now, Duration: 2 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{StartTime: now, Duration: 4 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{StartTime: now, Duration: time.Second, Error: "failed"}))
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end2", &config.Result{StartTime: now.Add(-48 * time.Hour), Duration: time.Second, Success: true}))
+
+	trends, err := db.History(ctx, store.HistoryFilter{Since: now.Add(-time.Hour)})
+	require.NoError(t, err)
+	require.Equal(t, []store.Trend{{
+		Day:          now.UTC().Format("2006-01-02"),
+		EndpointID:   "end1",
+		FileTestID:   "ft1",
+		Operation:    "Upload",
+		Size:         1000,
+		Samples:      3,
+		Errors:       1,
+		MeanDuration: 3 * time.Second,
+	}}, trends)
+
+	trends, err = db.History(ctx, store.HistoryFilter{Since: now.Add(-72 * time.Hour), EndpointID: "end2"})
+	require.NoError(t, err)
+	require.Len(t, trends, 1)
+	require.Equal(t, config.ID("end2"), trends[0].EndpointID)
+}
+
+func TestTimelines(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db, err := store.Open(ctx, ctx.File("perftester.db"))
+	require.NoError(t, err)
+	defer ctx.Check(db.Close)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	require.NoError(t, db.CreateRun(ctx, store.Run{ID: "run1", StartTime: now, ConfigHash: "hash"}))
+
+	reporter := db.Reporter("run1", map[config.ID]int{"ft1": 1000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{StartTime: now, Success: true, Timeline: []int64{300, 0, 700}}))
+	require.NoError(t, reporter.Report(ctx, config.Delete, "ft1", "end1", &config.Result{StartTime: now, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Download, "ft1", "end1", &config.Result{StartTime: now, Success: true, Timeline: []int64{1000}}))
+
+	timelines, err := db.Timelines(ctx, "run1")
+	require.NoError(t, err)
+	require.Equal(t, []store.Timeline{
+		{FileTestID: "ft1", EndpointID: "end1", Operation: "Upload", StartTime: now, Bytes: []int64{300, 0, 700}},
+		{FileTestID: "ft1", EndpointID: "end1", Operation: "Download", StartTime: now, Bytes: []int64{1000}},
+	}, timelines)
+}
+
+func TestNodeStats(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db, err := store.Open(ctx, ctx.File("perftester.db"))
+	require.NoError(t, err)
+	defer ctx.Check(db.Close)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	require.NoError(t, db.CreateRun(ctx, store.Run{ID: "run1", StartTime: now, ConfigHash: "hash"}))
+
+	stats := backends.NodeStats{Nodes: 39, Failed: 1, Cancelled: 10, Bytes: 2900}
+	reporter := db.Reporter("run1", map[config.ID]int{"ft1": 1000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{StartTime: now, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Download, "ft1", "end1", &config.Result{StartTime: now, Success: true, NodeStats: &stats}))
+
+	nodeStats, err := db.NodeStats(ctx, "run1")
+	require.NoError(t, err)
+	require.Equal(t, []store.NodeStats{
+		{FileTestID: "ft1", EndpointID: "end1", Operation: "Download", StartTime: now, Size: 1000, NodeStats: stats},
+	}, nodeStats)
+}
+
+func TestRuns(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db, err := store.Open(ctx, ctx.File("perftester.db"))
+	require.NoError(t, err)
+	defer ctx.Check(db.Close)
+
+	now := time.Unix(time.Now().Unix(), 0)
+	require.NoError(t, db.CreateRun(ctx, store.Run{ID: "run1", StartTime: now.Add(-time.Hour), ConfigHash: "hash", Hostname: "host1"}))
+	require.NoError(t, db.CreateRun(ctx, store.Run{ID: "run2", StartTime: now, ConfigHash: "hash"}))
+	require.NoError(t, db.FinishRun(ctx, "run1", now))
+
+	reporter := db.Reporter("run1", map[config.ID]int{"ft1": 1000000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{StartTime: now, Duration: 2 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Delete, "ft1", "end1", &config.Result{StartTime: now, Duration: time.Second, Error: "failed"}))
+
+	runs, err := db.Runs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, "run2", runs[0].ID)
+	require.True(t, runs[0].EndTime.IsZero())
+	require.Equal(t, 0, runs[0].Results)
+
+	run, err := db.GetRun(ctx, "run1")
+	require.NoError(t, err)
+	require.Equal(t, "host1", run.Hostname)
+	require.Equal(t, now.Add(-time.Hour), run.StartTime)
+	require.Equal(t, now, run.EndTime)
+	require.Equal(t, 2, run.Results)
+	require.Equal(t, 1, run.Errors)
+
+	_, err = db.GetRun(ctx, "missing")
+	require.True(t, store.ErrNotFound.Has(err))
+
+	results, err := db.Results(ctx, "run1")
+	require.NoError(t, err)
+	require.Equal(t, []store.Result{
+		{FileTestID: "ft1", EndpointID: "end1", Operation: "Upload", Size: 1000000, StartTime: now, Duration: 2 * time.Second},
+		{FileTestID: "ft1", EndpointID: "end1", Operation: "Delete", Size: 1000000, StartTime: now, Duration: time.Second, Error: "failed"},
+	}, results)
+	require.Equal(t, 4.0, results[0].Mbps())
+	require.Equal(t, 0.0, results[1].Mbps())
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package web
+
+import "html/template"
+
+// layoutTemplate is the layout shared by all pages, which define their
+// title and content.
+const layoutTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{template "title" .}} - perftester</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+a { color: #2683ff; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; white-space: pre; }
+th { background: #eee; }
+.series { display: flex; align-items: flex-start; gap: 2em; }
+.chart { border: 1px solid #ccc; margin-bottom: 1.5em; }
+.chart polyline { fill: none; stroke: #2683ff; stroke-width: 2; }
+.chart text { font-size: 11px; fill: #666; }
+.error { color: #c00; }
+</style>
+</head>
+<body>
+<h1><a href="/">perftester</a></h1>
+{{template "content" .}}
+</body>
+</html>
+`
+
+var indexTemplate = template.Must(template.Must(template.New("index").Parse(layoutTemplate)).Parse(`
+{{define "title"}}Trends{{end}}
+{{define "content"}}
+<form method="get" action="/">
+<label>Since <input name="since" value="{{.Since}}"></label>
+<label>Endpoint <input name="endpoint" value="{{.Endpoint}}" placeholder="all"></label>
+<button type="submit">Show</button>
+</form>
+{{- range .Endpoints}}
+<h2>Endpoint: {{.EndpointID}}</h2>
+{{- range .Series}}
+<h3>{{.Operation}} {{.FileTestID}}</h3>
+<div class="series">
+{{- if not .Chart.Empty}}
+<svg class="chart" width="{{.Chart.Width}}" height="{{.Chart.Height}}" viewBox="-4 -14 {{.Chart.Width}} {{.Chart.Height}}">
+<text x="0" y="-3">{{.Chart.Max}}</text>
+<polyline points="{{.Chart.Points}}"/>
+</svg>
+{{- end}}
+<table>
+<thead><tr><th>Day</th><th>Samples</th><th>Errors</th><th>Mean</th></tr></thead>
+<tbody>
+{{- range .Days}}
+<tr><td>{{.Day}}</td><td>{{.Samples}}</td><td>{{.Errors}}</td><td>{{.Mean}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</div>
+{{- end}}
+{{- else}}
+<p>No results in this period.</p>
+{{- end}}
+<h2>Recent runs</h2>
+<table>
+<thead><tr><th>Run</th><th>Started</th><th>Duration</th><th>Host</th><th>Version</th><th>Results</th><th>Errors</th></tr></thead>
+<tbody>
+{{- range .Runs}}
+<tr><td><a href="/runs/{{.ID}}">{{.ID}}</a></td><td>{{.Started}}</td><td>{{.Duration}}</td><td>{{.Hostname}}</td><td>{{.Version}}</td><td>{{.Results}}</td><td>{{.Errors}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{end}}
+`))
+
+var runTemplate = template.Must(template.Must(template.New("run").Parse(layoutTemplate)).Parse(`
+{{define "title"}}Run {{.ID}}{{end}}
+{{define "content"}}
+<h2>Run {{.ID}}</h2>
+<table>
+<tr><th>Started</th><td>{{.Started}}</td></tr>
+<tr><th>Duration</th><td>{{.Duration}}</td></tr>
+<tr><th>Host</th><td>{{.Hostname}}</td></tr>
+<tr><th>OS</th><td>{{.OS}}</td></tr>
+<tr><th>Go</th><td>{{.GoVersion}}</td></tr>
+<tr><th>perftester</th><td>{{.Version}}</td></tr>
+<tr><th>Config hash</th><td>{{.ConfigHash}}</td></tr>
+</table>
+<h2>Results</h2>
+<table>
+<thead><tr><th>File</th><th>Endpoint</th><th>Operation</th><th>Started</th><th>Duration</th><th>Throughput</th><th>TTFB</th><th>Finalize</th><th>Retries</th><th>Error</th></tr></thead>
+<tbody>
+{{- range .Results}}
+<tr><td>{{.FileTestID}}</td><td>{{.EndpointID}}</td><td>{{.Operation}}</td><td>{{.Started}}</td><td>{{.Duration}}</td><td>{{.Throughput}}</td><td>{{.FirstByte}}</td><td>{{.Finalize}}</td><td>{{.Retries}}</td><td class="error">{{.Error}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{end}}
+`))
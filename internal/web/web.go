@@ -0,0 +1,386 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package web serves a web UI over the stored results of past runs.
+package web
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"storj.io/perftester/config"
+	"storj.io/perftester/internal/auth"
+	"storj.io/perftester/internal/store"
+)
+
+// recentRuns is the number of runs listed on the index page.
+const recentRuns = 50
+
+// Server serves the trends of the stored results, the recent runs and the
+// results of each run.
+type Server struct {
+	log   *zap.Logger
+	db    *store.Store
+	token string
+	since time.Duration
+	mux   *http.ServeMux
+}
+
+// NewServer creates a server for the results in db, showing the trends of
+// the last since unless a request asks for another period. If token is
+// set, requests need it as their bearer token or, since browsers can't
+// send those, as the password of their basic authentication.
+func NewServer(log *zap.Logger, db *store.Store, token string, since time.Duration) *Server {
+	server := &Server{
+		log:   log,
+		db:    db,
+		token: token,
+		since: since,
+		mux:   http.NewServeMux(),
+	}
+	server.mux.HandleFunc("/", server.index)
+	server.mux.HandleFunc("/runs/", server.run)
+	return server
+}
+
+// ServeHTTP implements http.Handler.
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if server.token != "" && !server.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="perftester"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	server.mux.ServeHTTP(w, r)
+}
+
+// authorized returns whether the request carries the token of the server.
+func (server *Server) authorized(r *http.Request) bool {
+	if _, password, ok := r.BasicAuth(); ok {
+		return subtle.ConstantTimeCompare([]byte(password), []byte(server.token)) == 1
+	}
+	return auth.ValidToken(r.Header.Get("Authorization"), server.token)
+}
+
+// indexPage is the data rendered for the index page.
+type indexPage struct {
+	Since     string
+	Endpoint  string
+	Endpoints []endpointTrends
+	Runs      []runRow
+}
+
+// endpointTrends are the trends of the results of an endpoint.
+type endpointTrends struct {
+	EndpointID config.ID
+	Series     []trendSeries
+}
+
+// trendSeries are the daily results of an operation of a file test.
+type trendSeries struct {
+	FileTestID config.ID
+	Operation  string
+	Days       []trendDay
+	Chart      chart
+}
+
+// trendDay is the row of a day of a series.
+type trendDay struct {
+	Day     string
+	Samples int
+	Errors  int
+	Mean    string
+}
+
+// runRow is the row of a run in the list of recent runs.
+type runRow struct {
+	ID       string
+	Started  string
+	Duration string
+	Hostname string
+	Version  string
+	Results  int
+	Errors   int
+}
+
+// index renders the trends per endpoint and the recent runs. The period of
+// the trends and their endpoint can be chosen with the since and endpoint
+// query parameters.
+func (server *Server) index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	ctx := r.Context()
+
+	since := server.since
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			http.Error(w, fmt.Sprintf("invalid since %q", value), http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+	endpointID := config.ID(r.URL.Query().Get("endpoint"))
+
+	now := time.Now()
+	trends, err := server.db.History(ctx, store.HistoryFilter{
+		Since:      now.Add(-since),
+		EndpointID: endpointID,
+	})
+	if err != nil {
+		server.fail(w, err)
+		return
+	}
+	runs, err := server.db.Runs(ctx, recentRuns)
+	if err != nil {
+		server.fail(w, err)
+		return
+	}
+
+	page := indexPage{
+		Since:     since.String(),
+		Endpoint:  string(endpointID),
+		Endpoints: groupTrends(trends, now.Add(-since), now),
+	}
+	for _, run := range runs {
+		page.Runs = append(page.Runs, newRunRow(run))
+	}
+	server.render(w, indexTemplate, page)
+}
+
+// runPage is the data rendered for the page of a run.
+type runPage struct {
+	runRow
+	ConfigHash string
+	OS         string
+	GoVersion  string
+	Results    []resultRow
+}
+
+// resultRow is the row of a single result.
+type resultRow struct {
+	FileTestID config.ID
+	EndpointID config.ID
+	Operation  string
+	Started    string
+	Duration   time.Duration
+	Throughput string
+	FirstByte  string
+	Finalize   string
+	Retries    int
+	Error      string
+}
+
+// run renders the results of the run at /runs/<id>.
+func (server *Server) run(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	runID := strings.TrimPrefix(r.URL.Path, "/runs/")
+
+	run, err := server.db.GetRun(ctx, runID)
+	if store.ErrNotFound.Has(err) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		server.fail(w, err)
+		return
+	}
+	results, err := server.db.Results(ctx, runID)
+	if err != nil {
+		server.fail(w, err)
+		return
+	}
+
+	page := runPage{
+		runRow:     newRunRow(run),
+		ConfigHash: run.ConfigHash,
+		OS:         run.OS,
+		GoVersion:  run.GoVersion,
+	}
+	for _, result := range results {
+		row := resultRow{
+			FileTestID: result.FileTestID,
+			EndpointID: result.EndpointID,
+			Operation:  result.Operation,
+			Started:    formatTime(result.StartTime),
+			Duration:   result.Duration,
+			Throughput: "-",
+			FirstByte:  formatOptional(result.FirstByte),
+			Finalize:   formatOptional(result.Finalize),
+			Retries:    result.Retries,
+			Error:      result.Error,
+		}
+		if mbps := result.Mbps(); mbps > 0 {
+			row.Throughput = formatMbps(mbps)
+		}
+		page.Results = append(page.Results, row)
+	}
+	server.render(w, runTemplate, page)
+}
+
+// render renders a page, failing the request if it can't.
+func (server *Server) render(w http.ResponseWriter, tmpl *template.Template, data interface{}) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		server.fail(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// fail responds with an internal server error.
+func (server *Server) fail(w http.ResponseWriter, err error) {
+	server.log.Error("Request failed", zap.Error(err))
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// groupTrends groups the trends, which are ordered by endpoint, file test,
+// operation and day, into series. The charts of all series span the days
+// from since to now.
+func groupTrends(trends []store.Trend, since, now time.Time) []endpointTrends {
+	firstDay := since.UTC().Truncate(24 * time.Hour)
+	days := int(now.UTC().Sub(firstDay)/(24*time.Hour)) + 1
+
+	var endpoints []endpointTrends
+	for _, trend := range trends {
+		if len(endpoints) == 0 || endpoints[len(endpoints)-1].EndpointID != trend.EndpointID {
+			endpoints = append(endpoints, endpointTrends{EndpointID: trend.EndpointID})
+		}
+		endpoint := &endpoints[len(endpoints)-1]
+
+		n := len(endpoint.Series)
+		if n == 0 || endpoint.Series[n-1].FileTestID != trend.FileTestID || endpoint.Series[n-1].Operation != trend.Operation {
+			endpoint.Series = append(endpoint.Series, trendSeries{
+				FileTestID: trend.FileTestID,
+				Operation:  trend.Operation,
+				Chart:      chart{Days: days},
+			})
+		}
+		series := &endpoint.Series[len(endpoint.Series)-1]
+
+		mbps := trend.Mbps()
+		mean := "-"
+		switch {
+		case mbps > 0:
+			mean = formatMbps(mbps)
+		case trend.Samples > trend.Errors:
+			mean = trend.MeanDuration.String()
+		}
+		series.Days = append(series.Days, trendDay{
+			Day:     trend.Day,
+			Samples: trend.Samples,
+			Errors:  trend.Errors,
+			Mean:    mean,
+		})
+
+		if day, err := time.Parse("2006-01-02", trend.Day); err == nil && mbps > 0 {
+			series.Chart.add(int(day.Sub(firstDay)/(24*time.Hour)), mbps)
+		}
+	}
+
+	// The tables list the newest days first.
+	for _, endpoint := range endpoints {
+		for _, series := range endpoint.Series {
+			days := series.Days
+			sort.Slice(days, func(i, j int) bool { return days[i].Day > days[j].Day })
+		}
+	}
+	return endpoints
+}
+
+// chart dimensions in pixels.
+const (
+	chartWidth  = 480
+	chartHeight = 120
+	chartMargin = 4
+	chartLabel  = 10
+)
+
+// chart is a line chart of the daily mean throughput of a series.
+type chart struct {
+	Days   int
+	points []chartPoint
+	max    float64
+}
+
+type chartPoint struct {
+	day  int
+	mbps float64
+}
+
+func (c *chart) add(day int, mbps float64) {
+	c.points = append(c.points, chartPoint{day: day, mbps: mbps})
+	if mbps > c.max {
+		c.max = mbps
+	}
+}
+
+// Empty returns whether the chart has no points to draw.
+func (c chart) Empty() bool { return len(c.points) == 0 }
+
+// Width returns the width of the chart with its margins.
+func (c chart) Width() int { return chartWidth + 2*chartMargin }
+
+// Height returns the height of the chart with its margins and the label
+// above it.
+func (c chart) Height() int { return chartHeight + 2*chartMargin + chartLabel }
+
+// Max returns the label of the top of the chart.
+func (c chart) Max() string { return formatMbps(c.max) }
+
+// Points returns the SVG polyline points of the chart.
+func (c chart) Points() string {
+	span := c.Days - 1
+	if span < 1 {
+		span = 1
+	}
+	points := make([]string, 0, len(c.points))
+	for _, point := range c.points {
+		x := float64(point.day) * chartWidth / float64(span)
+		y := chartHeight - point.mbps*chartHeight/c.max
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	return strings.Join(points, " ")
+}
+
+func newRunRow(run store.RunSummary) runRow {
+	row := runRow{
+		ID:       run.ID,
+		Started:  formatTime(run.StartTime),
+		Duration: "unfinished",
+		Hostname: run.Hostname,
+		Version:  run.Version,
+		Results:  run.Results,
+		Errors:   run.Errors,
+	}
+	if !run.EndTime.IsZero() {
+		row.Duration = run.EndTime.Sub(run.StartTime).String()
+	}
+	return row
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatOptional(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.String()
+}
+
+func formatMbps(mbps float64) string {
+	return strconv.FormatFloat(mbps, 'f', 2, 64) + " Mbps"
+}
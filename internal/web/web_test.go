@@ -0,0 +1,95 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/internal/store"
+	"storj.io/perftester/internal/web"
+)
+
+// newStore returns a store with a run of an upload and a failed delete.
+func newStore(ctx *testcontext.Context, t *testing.T) *store.Store {
+	db, err := store.Open(ctx, ctx.File("perftester.db"))
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, db.CreateRun(ctx, store.Run{ID: "run1", StartTime: now.Add(-time.Minute), ConfigHash: "hash", Hostname: "host1"}))
+	reporter := db.Reporter("run1", map[config.ID]int{"ft1": 1000000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{StartTime: now, Duration: 2 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Delete, "ft1", "end1", &config.Result{StartTime: now, Duration: time.Second, Error: "delete failed"}))
+	require.NoError(t, db.FinishRun(ctx, "run1", now))
+	return db
+}
+
+// get requests path from handler, authenticating with header if set.
+func get(handler http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestServer(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db := newStore(ctx, t)
+	defer ctx.Check(db.Close)
+	server := web.NewServer(zaptest.NewLogger(t), db, "", 24*time.Hour)
+
+	// The index shows the trends of the endpoints and the recent runs.
+	index := get(server, "/", nil)
+	require.Equal(t, http.StatusOK, index.Code)
+	require.Contains(t, index.Body.String(), "end1")
+	require.Contains(t, index.Body.String(), "/runs/run1")
+	require.Equal(t, http.StatusOK, get(server, "/?since=1h&endpoint=end1", nil).Code)
+	require.Equal(t, http.StatusBadRequest, get(server, "/?since=yesterday", nil).Code)
+	require.Equal(t, http.StatusNotFound, get(server, "/missing", nil).Code)
+
+	// The page of a run lists its results.
+	run := get(server, "/runs/run1", nil)
+	require.Equal(t, http.StatusOK, run.Code)
+	require.Contains(t, run.Body.String(), "host1")
+	require.Contains(t, run.Body.String(), "delete failed")
+	require.Equal(t, http.StatusNotFound, get(server, "/runs/missing", nil).Code)
+}
+
+func TestServerToken(t *testing.T) {
+	ctx := testcontext.New(t)
+	defer ctx.Cleanup()
+
+	db := newStore(ctx, t)
+	defer ctx.Check(db.Close)
+	server := web.NewServer(zaptest.NewLogger(t), db, "secret", 24*time.Hour)
+
+	unauthorized := get(server, "/", nil)
+	require.Equal(t, http.StatusUnauthorized, unauthorized.Code)
+	// Browsers prompt for the token as a password.
+	require.Contains(t, unauthorized.Header().Get("WWW-Authenticate"), "Basic")
+	require.NotContains(t, unauthorized.Body.String(), "run1")
+
+	require.Equal(t, http.StatusUnauthorized, get(server, "/runs/run1", http.Header{"Authorization": {"Bearer wrong"}}).Code)
+	require.Equal(t, http.StatusOK, get(server, "/runs/run1", http.Header{"Authorization": {"Bearer secret"}}).Code)
+
+	basic := func(user, password string) http.Header {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.SetBasicAuth(user, password)
+		return request.Header
+	}
+	require.Equal(t, http.StatusUnauthorized, get(server, "/", basic("admin", "wrong")).Code)
+	require.Equal(t, http.StatusOK, get(server, "/", basic("admin", "secret")).Code)
+}
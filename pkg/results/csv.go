@@ -0,0 +1,212 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package results
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvColumns are the columns of results written as CSV, one row per result.
+// CSV leaves out the metadata and the lists of a result, such as the
+// durations of its objects, which only JSON keeps, and only counts their
+// entries.
+var csvColumns = []string{
+	"schema_version", "operation", "file_test", "endpoint", "size",
+	"start_time", "duration_ns", "success", "error", "error_category", "unsupported",
+	"first_byte_ns", "finalize_ns", "parallelism", "objects", "succeeded", "bytes", "attempts",
+	"cpu_percent", "peak_heap", "gc_count", "gc_pause_ns", "network_received", "network_sent", "network_percent",
+}
+
+// WriteCSV writes the results as CSV with a header row.
+func (run *Run) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return Error.Wrap(err)
+	}
+
+	version := strconv.Itoa(Version)
+	for _, result := range run.Results {
+		var resources Resources
+		if result.Resources != nil {
+			resources = *result.Resources
+		}
+		row := []string{
+			version, result.Operation, result.FileTestID, result.EndpointID,
+			strconv.FormatInt(run.FileTestSizes[result.FileTestID], 10),
+			result.StartTime.Format(time.RFC3339Nano),
+			strconv.FormatInt(result.DurationNanos, 10),
+			strconv.FormatBool(result.Success),
+			result.Error,
+			result.ErrorCategory,
+			strconv.FormatBool(result.Unsupported),
+			strconv.FormatInt(result.FirstByteNanos, 10),
+			strconv.FormatInt(result.FinalizeNanos, 10),
+			strconv.FormatInt(result.Parallelism, 10),
+			strconv.Itoa(len(result.ObjectNanos)),
+			strconv.Itoa(result.Succeeded),
+			strconv.FormatInt(result.Bytes, 10),
+			strconv.Itoa(len(result.Attempts)),
+			strconv.FormatFloat(resources.CPUPercent, 'f', -1, 64),
+			strconv.FormatInt(resources.PeakHeap, 10),
+			strconv.FormatInt(resources.GCCount, 10),
+			strconv.FormatInt(resources.GCPauseNanos, 10),
+			strconv.FormatInt(resources.NetworkReceived, 10),
+			strconv.FormatInt(resources.NetworkSent, 10),
+			strconv.FormatFloat(resources.NetworkPercent, 'f', -1, 64),
+		}
+		if err := writer.Write(row); err != nil {
+			return Error.Wrap(err)
+		}
+	}
+
+	writer.Flush()
+	return Error.Wrap(writer.Error())
+}
+
+// ReadCSV reads results written by WriteCSV. Their columns may be in any
+// order, and columns which are missing are left unset. The lists CSV only
+// counts the entries of, such as the durations of the objects, are left
+// empty.
+func ReadCSV(r io.Reader) (*Run, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, Error.New("invalid CSV results: %v", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[column] = i
+	}
+
+	run := &Run{
+		SchemaVersion: Version,
+		FileTestSizes: make(map[string]int64),
+		Results:       []Result{},
+	}
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return run, nil
+		}
+		if err != nil {
+			return nil, Error.New("invalid CSV results: %v", err)
+		}
+
+		p := csvRow{index: index, row: row}
+		if version := p.int("schema_version"); version > Version {
+			return nil, Error.New("line %d: unsupported schema version %d", line, version)
+		}
+		result := Result{
+			Operation:      p.string("operation"),
+			FileTestID:     p.string("file_test"),
+			EndpointID:     p.string("endpoint"),
+			StartTime:      p.time("start_time"),
+			DurationNanos:  p.int("duration_ns"),
+			Success:        p.bool("success"),
+			Error:          p.string("error"),
+			ErrorCategory:  p.string("error_category"),
+			Unsupported:    p.bool("unsupported"),
+			FirstByteNanos: p.int("first_byte_ns"),
+			FinalizeNanos:  p.int("finalize_ns"),
+			Parallelism:    p.count("parallelism"),
+			Succeeded:      int(p.count("succeeded")),
+			Bytes:          p.count("bytes"),
+		}
+		// The counts of the lists are checked, but the lists can't be
+		// restored from them.
+		p.count("objects")
+		p.count("attempts")
+		if _, ok := index["cpu_percent"]; ok {
+			result.Resources = &Resources{
+				CPUPercent:      p.float("cpu_percent"),
+				PeakHeap:        p.int("peak_heap"),
+				GCCount:         p.int("gc_count"),
+				GCPauseNanos:    p.int("gc_pause_ns"),
+				NetworkReceived: p.int("network_received"),
+				NetworkSent:     p.int("network_sent"),
+				NetworkPercent:  p.float("network_percent"),
+			}
+		}
+		if p.err != nil {
+			return nil, Error.New("line %d: %v", line, p.err)
+		}
+		run.FileTestSizes[result.FileTestID] = p.int("size")
+		run.Results = append(run.Results, result)
+	}
+}
+
+// csvRow parses the values of a row, keeping the first error.
+type csvRow struct {
+	index map[string]int
+	row   []string
+	err   error
+}
+
+func (p *csvRow) string(column string) string {
+	i, ok := p.index[column]
+	if !ok || i >= len(p.row) {
+		return ""
+	}
+	return p.row[i]
+}
+
+func (p *csvRow) int(column string) int64 {
+	value := p.string(column)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil && p.err == nil {
+		p.err = Error.New("invalid %s %q", column, value)
+	}
+	return n
+}
+
+// count parses a value which can't be negative.
+func (p *csvRow) count(column string) int64 {
+	n := p.int(column)
+	if n < 0 && p.err == nil {
+		p.err = Error.New("invalid %s %d: must not be negative", column, n)
+	}
+	return n
+}
+
+func (p *csvRow) float(column string) float64 {
+	value := p.string(column)
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil && p.err == nil {
+		p.err = Error.New("invalid %s %q", column, value)
+	}
+	return f
+}
+
+func (p *csvRow) bool(column string) bool {
+	value := p.string(column)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil && p.err == nil {
+		p.err = Error.New("invalid %s %q", column, value)
+	}
+	return b
+}
+
+func (p *csvRow) time(column string) time.Time {
+	value := p.string(column)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil && p.err == nil {
+		p.err = Error.New("invalid %s %q", column, value)
+	}
+	return t
+}
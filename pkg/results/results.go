@@ -0,0 +1,218 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package results defines the versioned schema of the results of a run,
+// which tools reading them can rely on, and converts results between the
+// versions of the schema and between JSON and CSV.
+//
+// Version 1 is the unversioned output of the json format. Version 2 names
+// its fields in snake case, records durations in nanoseconds in fields
+// ending with _ns and carries its version in schema_version. Fields are
+// only ever added to a version.
+package results
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// Error is the error class of this package.
+var Error = errs.Class("results")
+
+// Version is the current version of the schema.
+const Version = 2
+
+// Run are the results of a run.
+type Run struct {
+	SchemaVersion int       `json:"schema_version"`
+	Metadata      *Metadata `json:"metadata,omitempty"`
+	// FileTestSizes are the sizes of the files of the file tests by ID.
+	FileTestSizes map[string]int64 `json:"file_test_sizes"`
+	Results       []Result         `json:"results"`
+}
+
+// Metadata describes the machine and the run the results come from.
+type Metadata struct {
+	Hostname      string    `json:"hostname"`
+	Region        string    `json:"region,omitempty"`
+	OS            string    `json:"os"`
+	GoVersion     string    `json:"go_version"`
+	Version       string    `json:"version"` // Version of perftester.
+	UplinkVersion string    `json:"uplink_version"`
+	AWSSDKVersion string    `json:"aws_sdk_version"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	ConfigHash    string    `json:"config_hash"`
+	RunID         string    `json:"run_id"`
+	Mode          string    `json:"mode,omitempty"`
+	ShuffleSeed   int64     `json:"shuffle_seed,omitempty"`
+
+	// Network, Settings and Servers are the connection setup times, the
+	// transfer settings and the servers of the endpoints by ID.
+	Network  map[string]Network `json:"network,omitempty"`
+	Settings map[string]string  `json:"settings,omitempty"`
+	Servers  map[string]string  `json:"servers,omitempty"`
+}
+
+// Network are the connection setup times of an endpoint.
+type Network struct {
+	Address           string       `json:"address"`
+	IPs               []IPLocation `json:"ips,omitempty"`
+	DNSNanos          int64        `json:"dns_ns"`
+	ConnectNanos      int64        `json:"connect_ns"`
+	TLSHandshakeNanos int64        `json:"tls_handshake_ns,omitempty"`
+	Error             string       `json:"error,omitempty"`
+}
+
+// IPLocation is an IP of an endpoint and where it is located, if known.
+type IPLocation struct {
+	IP       string `json:"ip"`
+	Location string `json:"location,omitempty"`
+}
+
+// Result is the result of an operation of a file test on an endpoint.
+type Result struct {
+	Operation     string    `json:"operation"`
+	FileTestID    string    `json:"file_test"`
+	EndpointID    string    `json:"endpoint"`
+	StartTime     time.Time `json:"start_time"`
+	DurationNanos int64     `json:"duration_ns"`
+	Success       bool      `json:"success"`
+	Error         string    `json:"error,omitempty"`
+	ErrorCategory string    `json:"error_category,omitempty"`
+	Unsupported   bool      `json:"unsupported,omitempty"`
+
+	FirstByteNanos int64 `json:"first_byte_ns,omitempty"`
+	FinalizeNanos  int64 `json:"finalize_ns,omitempty"`
+	Parallelism    int64 `json:"parallelism,omitempty"`
+
+	ObjectNanos   []int64 `json:"object_durations_ns,omitempty"`
+	Succeeded     int     `json:"succeeded,omitempty"`
+	FailedObjects []int   `json:"failed_objects,omitempty"`
+	Bytes         int64   `json:"bytes,omitempty"`
+	LatencyNanos  []int64 `json:"latencies_ns,omitempty"`
+	// Timeline are the bytes transferred in each second of the operation.
+	Timeline []int64 `json:"timeline,omitempty"`
+
+	Parts     []Part     `json:"parts,omitempty"`
+	NodeStats *NodeStats `json:"node_stats,omitempty"`
+	Buckets   []Bucket   `json:"buckets,omitempty"`
+	Attempts  []Attempt  `json:"attempts,omitempty"`
+	Resources *Resources `json:"resources,omitempty"`
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	Listings  []Listing  `json:"listings,omitempty"`
+}
+
+// Part is the timing of a part of a multipart upload.
+type Part struct {
+	Number        int   `json:"number"`
+	Size          int64 `json:"size"`
+	DurationNanos int64 `json:"duration_ns"`
+}
+
+// NodeStats are the storage node level stats of a download.
+type NodeStats struct {
+	Nodes     int64 `json:"nodes"`
+	Failed    int64 `json:"failed"`
+	Cancelled int64 `json:"cancelled"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// Bucket counts the operations of a soak test which completed in one time
+// interval.
+type Bucket struct {
+	DurationNanos int64 `json:"duration_ns"`
+	Objects       int   `json:"objects"`
+	Errors        int   `json:"errors"`
+}
+
+// Attempt is a single try of an operation.
+type Attempt struct {
+	StartTime     time.Time `json:"start_time"`
+	DurationNanos int64     `json:"duration_ns"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Resources are the resource usage of the client machine during an
+// operation.
+type Resources struct {
+	CPUPercent      float64 `json:"cpu_percent"`
+	PeakHeap        int64   `json:"peak_heap"`
+	GCCount         int64   `json:"gc_count"`
+	GCPauseNanos    int64   `json:"gc_pause_ns"`
+	NetworkReceived int64   `json:"network_received"`
+	NetworkSent     int64   `json:"network_sent"`
+	NetworkPercent  float64 `json:"network_percent"`
+}
+
+// Conflict is the outcome of concurrent writes of different contents to an
+// object of a conflict test. Winner is -1 if the writes corrupted it.
+type Conflict struct {
+	Object     int `json:"object"`
+	Winner     int `json:"winner"`
+	LastWriter int `json:"last_writer"`
+}
+
+// Listing is a full list of the objects of a listing test. PageSize is
+// zero for the backend's default page size.
+type Listing struct {
+	PageSize      int64 `json:"page_size"`
+	Pages         int64 `json:"pages"`
+	Objects       int64 `json:"objects"`
+	DurationNanos int64 `json:"duration_ns"`
+}
+
+// Parse parses results of any version of the schema written as JSON and
+// upgrades them to the current version.
+func Parse(data []byte) (*Run, error) {
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, Error.New("invalid results: %v", err)
+	}
+
+	switch header.SchemaVersion {
+	case 0, 1:
+		var v1 runV1
+		if err := json.Unmarshal(data, &v1); err != nil {
+			return nil, Error.New("invalid results: %v", err)
+		}
+		return v1.upgrade(), nil
+	case Version:
+		var run Run
+		if err := json.Unmarshal(data, &run); err != nil {
+			return nil, Error.New("invalid results: %v", err)
+		}
+		return &run, nil
+	default:
+		return nil, Error.New("unsupported schema version %d, this version of perftester supports up to %d", header.SchemaVersion, Version)
+	}
+}
+
+// MarshalVersion returns the indented JSON of the results in a version of
+// the schema.
+func (run *Run) MarshalVersion(version int) ([]byte, error) {
+	var v interface{}
+	switch version {
+	case 1:
+		v = downgrade(run)
+	case Version:
+		upgraded := *run
+		upgraded.SchemaVersion = Version
+		if upgraded.Results == nil {
+			upgraded.Results = []Result{}
+		}
+		v = upgraded
+	default:
+		return nil, Error.New("unsupported schema version %d", version)
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package results_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/pkg/results"
+)
+
+const resultsV1 = `{
+  "Metadata": {"Hostname": "host1", "Network": {"end1": {"Address": "end1.example.com:443", "DNS": 2000000}}},
+  "FileTestSizes": {"ft1": 10000000},
+  "Results": [
+    {"Operation": "Upload", "FileTestID": "ft1", "EndpointID": "end1",
+     "Result": {"StartTime": "2020-06-01T12:00:00Z", "Duration": 5000000000, "Success": true,
+                "ObjectDurations": [5000000000], "Attempts": [{"Duration": 1000000000, "Error": "reset"}, {"Duration": 5000000000}]}},
+    {"Operation": "Download", "FileTestID": "ft1", "EndpointID": "end1",
+     "Result": {"Duration": 4000000000, "Error": "failed", "ErrorCategory": "timeout"}}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	run, err := results.Parse([]byte(resultsV1))
+	require.NoError(t, err)
+	require.Equal(t, results.Version, run.SchemaVersion)
+	require.Equal(t, "host1", run.Metadata.Hostname)
+	require.Equal(t, int64(2*time.Millisecond), run.Metadata.Network["end1"].DNSNanos)
+	require.Equal(t, map[string]int64{"ft1": 10000000}, run.FileTestSizes)
+	require.Len(t, run.Results, 2)
+	require.Equal(t, "Upload", run.Results[0].Operation)
+	require.Equal(t, int64(5*time.Second), run.Results[0].DurationNanos)
+	require.Len(t, run.Results[0].Attempts, 2)
+	require.Equal(t, "timeout", run.Results[1].ErrorCategory)
+
+	// Results survive a roundtrip through the current version.
+	data, err := run.MarshalVersion(results.Version)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"schema_version": 2`)
+	require.Contains(t, string(data), `"duration_ns": 5000000000`)
+	reparsed, err := results.Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, run, reparsed)
+
+	// And back to version 1.
+	data, err = reparsed.MarshalVersion(1)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "schema_version")
+	reparsed, err = results.Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, run, reparsed)
+
+	_, err = run.MarshalVersion(results.Version + 1)
+	require.Error(t, err)
+	_, err = results.Parse([]byte(`{"schema_version": 99}`))
+	require.Error(t, err)
+	_, err = results.Parse([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestCSV(t *testing.T) {
+	run, err := results.Parse([]byte(resultsV1))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, run.WriteCSV(&buf))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	require.True(t, bytes.HasPrefix(lines[0], []byte("schema_version,operation,file_test,endpoint,size,")))
+	require.True(t, bytes.HasPrefix(lines[1], []byte("2,Upload,ft1,end1,10000000,2020-06-01T12:00:00Z,5000000000,true,")))
+
+	read, err := results.ReadCSV(&buf)
+	require.NoError(t, err)
+	require.Nil(t, read.Metadata)
+	require.Equal(t, run.FileTestSizes, read.FileTestSizes)
+	require.Len(t, read.Results, 2)
+	require.Equal(t, run.Results[0].StartTime, read.Results[0].StartTime)
+	require.Equal(t, run.Results[0].DurationNanos, read.Results[0].DurationNanos)
+	// The lists CSV only counts aren't made up.
+	require.Empty(t, read.Results[0].ObjectNanos)
+	require.Empty(t, read.Results[0].Attempts)
+	require.Equal(t, "failed", read.Results[1].Error)
+	require.False(t, read.Results[1].Success)
+
+	for _, data := range []string{
+		"operation,duration_ns\nUpload,fast\n",
+		"operation,objects\nUpload,-1\n",
+		"operation,attempts\nUpload,-3\n",
+		"operation,bytes\nUpload,-1\n",
+	} {
+		_, err = results.ReadCSV(bytes.NewReader([]byte(data)))
+		require.Error(t, err, data)
+	}
+}
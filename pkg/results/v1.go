@@ -0,0 +1,287 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package results
+
+import (
+	"time"
+
+	"storj.io/perftester/backends"
+	"storj.io/perftester/config"
+)
+
+// runV1 are the results of a run in version 1 of the schema, which is the
+// JSON of the results perftester keeps in memory, so its results are
+// config.Results rather than a copy of them.
+type runV1 struct {
+	Metadata      *metadataV1 `json:",omitempty"`
+	FileTestSizes map[string]int64
+	Results       []operationResultV1
+}
+
+type metadataV1 struct {
+	Hostname      string
+	Region        string `json:",omitempty"`
+	OS            string
+	GoVersion     string
+	Version       string
+	UplinkVersion string
+	AWSSDKVersion string
+
+	StartTime   time.Time
+	EndTime     time.Time
+	ConfigHash  string
+	RunID       string
+	Mode        string
+	ShuffleSeed int64
+
+	Network  map[string]config.NetworkTimings
+	Settings map[string]string
+	Servers  map[string]string
+}
+
+type operationResultV1 struct {
+	Operation  string
+	FileTestID string
+	EndpointID string
+	Result     *config.Result
+}
+
+// upgrade converts the results to the current version of the schema.
+func (v1 *runV1) upgrade() *Run {
+	run := &Run{
+		SchemaVersion: Version,
+		FileTestSizes: v1.FileTestSizes,
+		Results:       make([]Result, 0, len(v1.Results)),
+	}
+
+	if m := v1.Metadata; m != nil {
+		run.Metadata = &Metadata{
+			Hostname:      m.Hostname,
+			Region:        m.Region,
+			OS:            m.OS,
+			GoVersion:     m.GoVersion,
+			Version:       m.Version,
+			UplinkVersion: m.UplinkVersion,
+			AWSSDKVersion: m.AWSSDKVersion,
+			StartTime:     m.StartTime,
+			EndTime:       m.EndTime,
+			ConfigHash:    m.ConfigHash,
+			RunID:         m.RunID,
+			Mode:          m.Mode,
+			ShuffleSeed:   m.ShuffleSeed,
+			Settings:      m.Settings,
+			Servers:       m.Servers,
+		}
+		if m.Network != nil {
+			run.Metadata.Network = make(map[string]Network, len(m.Network))
+		}
+		for id, network := range m.Network {
+			upgraded := Network{
+				Address:           network.Address,
+				DNSNanos:          int64(network.DNS),
+				ConnectNanos:      int64(network.Connect),
+				TLSHandshakeNanos: int64(network.TLSHandshake),
+				Error:             network.Error,
+			}
+			for _, ip := range network.IPs {
+				upgraded.IPs = append(upgraded.IPs, IPLocation(ip))
+			}
+			run.Metadata.Network[id] = upgraded
+		}
+	}
+
+	for _, operationResult := range v1.Results {
+		r := operationResult.Result
+		if r == nil {
+			r = &config.Result{}
+		}
+		run.Results = append(run.Results, fromConfig(operationResult.Operation, operationResult.FileTestID, operationResult.EndpointID, r))
+	}
+	return run
+}
+
+// downgrade converts the results to version 1 of the schema.
+func downgrade(run *Run) *runV1 {
+	v1 := &runV1{
+		FileTestSizes: run.FileTestSizes,
+		Results:       make([]operationResultV1, 0, len(run.Results)),
+	}
+
+	if m := run.Metadata; m != nil {
+		v1.Metadata = &metadataV1{
+			Hostname:      m.Hostname,
+			Region:        m.Region,
+			OS:            m.OS,
+			GoVersion:     m.GoVersion,
+			Version:       m.Version,
+			UplinkVersion: m.UplinkVersion,
+			AWSSDKVersion: m.AWSSDKVersion,
+			StartTime:     m.StartTime,
+			EndTime:       m.EndTime,
+			ConfigHash:    m.ConfigHash,
+			RunID:         m.RunID,
+			Mode:          m.Mode,
+			ShuffleSeed:   m.ShuffleSeed,
+			Settings:      m.Settings,
+			Servers:       m.Servers,
+		}
+		if m.Network != nil {
+			v1.Metadata.Network = make(map[string]config.NetworkTimings, len(m.Network))
+		}
+		for id, network := range m.Network {
+			downgraded := config.NetworkTimings{
+				Address:      network.Address,
+				DNS:          time.Duration(network.DNSNanos),
+				Connect:      time.Duration(network.ConnectNanos),
+				TLSHandshake: time.Duration(network.TLSHandshakeNanos),
+				Error:        network.Error,
+			}
+			for _, ip := range network.IPs {
+				downgraded.IPs = append(downgraded.IPs, config.IPLocation(ip))
+			}
+			v1.Metadata.Network[id] = downgraded
+		}
+	}
+
+	for _, result := range run.Results {
+		v1.Results = append(v1.Results, operationResultV1{
+			Operation:  result.Operation,
+			FileTestID: result.FileTestID,
+			EndpointID: result.EndpointID,
+			Result:     result.toConfig(),
+		})
+	}
+	return v1
+}
+
+// fromConfig converts the result of an operation of a file test on an
+// endpoint to the current version of the schema.
+func fromConfig(operation, fileTestID, endpointID string, r *config.Result) Result {
+	result := Result{
+		Operation:      operation,
+		FileTestID:     fileTestID,
+		EndpointID:     endpointID,
+		StartTime:      r.StartTime,
+		DurationNanos:  int64(r.Duration),
+		Success:        r.Success,
+		Error:          r.Error,
+		ErrorCategory:  string(r.ErrorCategory),
+		Unsupported:    r.Unsupported,
+		FirstByteNanos: int64(r.FirstByte),
+		FinalizeNanos:  int64(r.Finalize),
+		Parallelism:    r.Parallelism,
+		ObjectNanos:    nanos(r.ObjectDurations),
+		Succeeded:      r.Succeeded,
+		FailedObjects:  r.FailedObjects,
+		Bytes:          r.Bytes,
+		LatencyNanos:   nanos(r.Latencies),
+		Timeline:       r.Timeline,
+	}
+	if r.NodeStats != nil {
+		nodeStats := NodeStats(*r.NodeStats)
+		result.NodeStats = &nodeStats
+	}
+	for _, part := range r.Parts {
+		result.Parts = append(result.Parts, Part{Number: part.Number, Size: part.Size, DurationNanos: int64(part.Duration)})
+	}
+	for _, bucket := range r.Buckets {
+		result.Buckets = append(result.Buckets, Bucket{DurationNanos: int64(bucket.Duration), Objects: bucket.Objects, Errors: bucket.Errors})
+	}
+	for _, attempt := range r.Attempts {
+		result.Attempts = append(result.Attempts, Attempt{StartTime: attempt.StartTime, DurationNanos: int64(attempt.Duration), Error: attempt.Error})
+	}
+	for _, conflict := range r.Conflicts {
+		result.Conflicts = append(result.Conflicts, Conflict(conflict))
+	}
+	for _, listing := range r.Listings {
+		result.Listings = append(result.Listings, Listing{PageSize: listing.PageSize, Pages: listing.Pages, Objects: listing.Objects, DurationNanos: int64(listing.Duration)})
+	}
+	if resources := r.Resources; resources != nil {
+		result.Resources = &Resources{
+			CPUPercent:      resources.CPUPercent,
+			PeakHeap:        resources.PeakHeap,
+			GCCount:         resources.GCCount,
+			GCPauseNanos:    int64(resources.GCPause),
+			NetworkReceived: resources.NetworkReceived,
+			NetworkSent:     resources.NetworkSent,
+			NetworkPercent:  resources.NetworkPercent,
+		}
+	}
+	return result
+}
+
+// toConfig converts the result back to the result perftester keeps in
+// memory.
+func (result Result) toConfig() *config.Result {
+	r := &config.Result{
+		StartTime:       result.StartTime,
+		Duration:        time.Duration(result.DurationNanos),
+		Success:         result.Success,
+		Error:           result.Error,
+		ErrorCategory:   config.ErrorCategory(result.ErrorCategory),
+		FirstByte:       time.Duration(result.FirstByteNanos),
+		Finalize:        time.Duration(result.FinalizeNanos),
+		Parallelism:     result.Parallelism,
+		ObjectDurations: durations(result.ObjectNanos),
+		Succeeded:       result.Succeeded,
+		FailedObjects:   result.FailedObjects,
+		Bytes:           result.Bytes,
+		Timeline:        result.Timeline,
+		Latencies:       durations(result.LatencyNanos),
+		Unsupported:     result.Unsupported,
+	}
+	if result.NodeStats != nil {
+		nodeStats := backends.NodeStats(*result.NodeStats)
+		r.NodeStats = &nodeStats
+	}
+	for _, part := range result.Parts {
+		r.Parts = append(r.Parts, backends.Part{Number: part.Number, Size: part.Size, Duration: time.Duration(part.DurationNanos)})
+	}
+	for _, bucket := range result.Buckets {
+		r.Buckets = append(r.Buckets, config.Bucket{Duration: time.Duration(bucket.DurationNanos), Objects: bucket.Objects, Errors: bucket.Errors})
+	}
+	for _, attempt := range result.Attempts {
+		r.Attempts = append(r.Attempts, config.Attempt{StartTime: attempt.StartTime, Duration: time.Duration(attempt.DurationNanos), Error: attempt.Error})
+	}
+	for _, conflict := range result.Conflicts {
+		r.Conflicts = append(r.Conflicts, config.Conflict(conflict))
+	}
+	for _, listing := range result.Listings {
+		r.Listings = append(r.Listings, config.Listing{PageSize: listing.PageSize, Pages: listing.Pages, Objects: listing.Objects, Duration: time.Duration(listing.DurationNanos)})
+	}
+	if resources := result.Resources; resources != nil {
+		r.Resources = &config.ResourceUsage{
+			CPUPercent:      resources.CPUPercent,
+			PeakHeap:        resources.PeakHeap,
+			GCCount:         resources.GCCount,
+			GCPause:         time.Duration(resources.GCPauseNanos),
+			NetworkReceived: resources.NetworkReceived,
+			NetworkSent:     resources.NetworkSent,
+			NetworkPercent:  resources.NetworkPercent,
+		}
+	}
+	return r
+}
+
+func nanos(durations []time.Duration) []int64 {
+	if durations == nil {
+		return nil
+	}
+	values := make([]int64, len(durations))
+	for i, duration := range durations {
+		values[i] = int64(duration)
+	}
+	return values
+}
+
+func durations(nanos []int64) []time.Duration {
+	if nanos == nil {
+		return nil
+	}
+	values := make([]time.Duration, len(nanos))
+	for i, n := range nanos {
+		values[i] = time.Duration(n)
+	}
+	return values
+}
@@ -0,0 +1,171 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// columnSelection holds the endpoints and metrics the text report shows.
+// Nil sets show everything.
+type columnSelection struct {
+	endpoints map[string]bool
+	metrics   map[string]bool
+}
+
+// newColumnSelection sorts the selected columns into the endpoints among
+// endpointIDs and metrics, which are matched against the names of rows
+// regardless of case. It fails on columns which are neither an endpoint
+// nor the name of one of the rows, rather than showing nothing for them.
+func newColumnSelection(columns []string, endpointIDs []config.ID, rows [][]string) (columnSelection, error) {
+	known := make(map[string]bool, len(endpointIDs))
+	for _, endpointID := range endpointIDs {
+		known[string(endpointID)] = true
+	}
+	metrics := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		metrics[strings.ToLower(strings.TrimSpace(row[0]))] = true
+	}
+
+	var selection columnSelection
+	var unknown []string
+	for _, column := range columns {
+		if known[column] {
+			if selection.endpoints == nil {
+				selection.endpoints = make(map[string]bool)
+			}
+			selection.endpoints[column] = true
+			continue
+		}
+		metric := strings.ToLower(column)
+		if !metrics[metric] {
+			unknown = append(unknown, column)
+			continue
+		}
+		if selection.metrics == nil {
+			selection.metrics = make(map[string]bool)
+		}
+		selection.metrics[metric] = true
+	}
+	if len(unknown) > 0 {
+		return columnSelection{}, errs.New("unknown columns %q: columns are endpoints or metrics of the report", unknown)
+	}
+	return selection, nil
+}
+
+// selectColumns returns the header and rows with only the first column and
+// those of the selected endpoints.
+func (selection columnSelection) selectColumns(header []string, rows [][]string) ([]string, [][]string) {
+	if selection.endpoints == nil {
+		return header, rows
+	}
+
+	keep := []int{0}
+	for i, endpointID := range header[1:] {
+		if selection.endpoints[endpointID] {
+			keep = append(keep, i+1)
+		}
+	}
+
+	project := func(row []string) []string {
+		projected := make([]string, 0, len(keep))
+		for _, i := range keep {
+			projected = append(projected, row[i])
+		}
+		return projected
+	}
+	selected := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		selected = append(selected, project(row))
+	}
+	return project(header), selected
+}
+
+// selectRows returns the rows of the selected metrics of a file test
+// table. Selecting an operation, such as "Upload", keeps its row and all
+// its indented detail rows. Selecting a detail, such as "p95", keeps it
+// under every operation along with the operation's row.
+func (selection columnSelection) selectRows(rows [][]string) [][]string {
+	if selection.metrics == nil {
+		return rows
+	}
+
+	var selected [][]string
+	var operation []string
+	var operationSelected, operationAdded bool
+	for _, row := range rows {
+		name := strings.ToLower(strings.TrimSpace(row[0]))
+		if !strings.HasPrefix(row[0], " ") {
+			operation = row
+			operationSelected = selection.metrics[name]
+			operationAdded = operationSelected
+			if operationSelected {
+				selected = append(selected, row)
+			}
+			continue
+		}
+
+		if !operationSelected && !selection.metrics[name] {
+			continue
+		}
+		if !operationAdded && operation != nil {
+			selected = append(selected, operation)
+			operationAdded = true
+		}
+		selected = append(selected, row)
+	}
+	return selected
+}
+
+// splitColumns splits a table whose rows are wider than width characters
+// into several tables, each repeating the first column and holding as many
+// of the other columns as fit. Columns wider than width on their own get a
+// table of their own. A width of zero or below doesn't split the table.
+func splitColumns(rows [][]string, width int) [][][]string {
+	if width <= 0 || len(rows) == 0 {
+		return [][][]string{rows}
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, item := range row {
+			if itemWidth := utf8.RuneCountInString(item); itemWidth > widths[i] {
+				widths[i] = itemWidth
+			}
+		}
+	}
+
+	var groups [][]int
+	var group []int
+	used := widths[0]
+	for i := 1; i < len(widths); i++ {
+		if len(group) > 0 && used+tablePadding+widths[i] > width {
+			groups = append(groups, group)
+			group = nil
+			used = widths[0]
+		}
+		group = append(group, i)
+		used += tablePadding + widths[i]
+	}
+	groups = append(groups, group)
+
+	tables := make([][][]string, 0, len(groups))
+	for _, group := range groups {
+		table := make([][]string, 0, len(rows))
+		for _, row := range rows {
+			part := []string{row[0]}
+			for _, i := range group {
+				part = append(part, row[i])
+			}
+			table = append(table, part)
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package report gathers the results of checks and formats them as text,
+// markdown, HTML or JSON, and registers the output formats by name.
+package report
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// Formatter is a reporter which formats all gathered results once the
+// checks have finished.
+type Formatter interface {
+	Reporter
+	SetMetadata(metadata Metadata)
+	SetOptions(options Options)
+	FormatResults(ctx context.Context) (string, error)
+}
+
+// Options are the settings of how formatters present the results.
+type Options struct {
+	// Units are the units of throughputs. Defaults to Mbps.
+	Units Units
+	// ReferenceEndpoint, if set, is the endpoint the throughputs of the
+	// other endpoints are compared to, such as "(0.84× of s3)".
+	ReferenceEndpoint config.ID
+
+	// Columns, if set, are the endpoints and metrics, such as "Upload" or
+	// "p95", the text report shows.
+	Columns []string
+	// Width, if positive, is the width in characters the text report splits
+	// wider tables at.
+	Width int
+}
+
+// FormatterFactory creates a Formatter for file tests of the given sizes.
+type FormatterFactory func(fileTestSizes map[config.ID]int) Formatter
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]FormatterFactory{}
+)
+
+func init() {
+	Register("text", func(fileTestSizes map[config.ID]int) Formatter { return NewTextReporter(fileTestSizes) })
+	Register("markdown", func(fileTestSizes map[config.ID]int) Formatter { return NewMarkdownReporter(fileTestSizes) })
+	Register("html", func(fileTestSizes map[config.ID]int) Formatter { return NewHTMLReporter(fileTestSizes) })
+	Register("json", func(fileTestSizes map[config.ID]int) Formatter { return NewJSONReporter(fileTestSizes) })
+}
+
+// Register makes an output format available under name, usually from the
+// init function of the package implementing it. It panics if the name is
+// already registered.
+func Register(name string, factory FormatterFactory) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+
+	if _, ok := formatters[name]; ok {
+		panic("report: output format registered twice: " + name)
+	}
+	formatters[name] = factory
+}
+
+// Formats returns the sorted names of the registered output formats.
+func Formats() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	names := make([]string, 0, len(formatters))
+	for name := range formatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewFormatter creates the Formatter for the named output format.
+func NewFormatter(format string, fileTestSizes map[config.ID]int) (Formatter, error) {
+	formattersMu.RLock()
+	factory, ok := formatters[format]
+	formattersMu.RUnlock()
+
+	if !ok {
+		return nil, errs.New("unknown output format %q", format)
+	}
+	return factory(fileTestSizes), nil
+}
@@ -0,0 +1,36 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+func TestRegister(t *testing.T) {
+	require.Subset(t, report.Formats(), []string{"html", "json", "markdown", "text"})
+
+	// Formats stay registered, so only register once when tests repeat.
+	if _, err := report.NewFormatter("custom", nil); err != nil {
+		report.Register("custom", func(fileTestSizes map[config.ID]int) report.Formatter {
+			return report.NewTextReporter(fileTestSizes)
+		})
+	}
+	require.Contains(t, report.Formats(), "custom")
+
+	formatter, err := report.NewFormatter("custom", nil)
+	require.NoError(t, err)
+	require.IsType(t, &report.TextReporter{}, formatter)
+
+	require.Panics(t, func() {
+		report.Register("text", func(fileTestSizes map[config.ID]int) report.Formatter { return nil })
+	})
+
+	_, err = report.NewFormatter("unknown", nil)
+	require.Error(t, err)
+}
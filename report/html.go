@@ -0,0 +1,228 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"context"
+	"html/template"
+	"sort"
+	"strings"
+
+	"storj.io/perftester/config"
+)
+
+// HTMLReporter gathers reports and renders them as a standalone HTML page
+// with sortable tables and throughput bar charts.
+type HTMLReporter struct {
+	collector
+}
+
+// NewHTMLReporter creates an HTMLReporter.
+func NewHTMLReporter(fileTestSizes map[config.ID]int) *HTMLReporter {
+	return &HTMLReporter{
+		collector: newCollector(fileTestSizes),
+	}
+}
+
+// FormatResults returns an HTML page of all reported results.
+func (s *HTMLReporter) FormatResults(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return formatHTMLResults(s.metadata, s.fileTestSizes, s.results, s.options)
+}
+
+// htmlPage is the data rendered for the whole page.
+type htmlPage struct {
+	Metadata  [][]string
+	FileTests []htmlFileTest
+	Errors    *errorTable
+	Summary   *summaryTable
+}
+
+// htmlFileTest is the data rendered for a single file test.
+type htmlFileTest struct {
+	fileTestTable
+	Charts []htmlChart
+}
+
+// htmlChart is a bar chart of the throughput of one operation.
+type htmlChart struct {
+	Operation string
+	Bars      []htmlBar
+}
+
+type htmlBar struct {
+	EndpointID string
+	Label      string
+	Percent    float64
+}
+
+func formatHTMLResults(metadata *Metadata, fileTestSizes map[config.ID]int, results fileTestResults, options Options) (string, error) {
+	tables, err := buildTables(fileTestSizes, results, options)
+	if err != nil {
+		return "", err
+	}
+
+	fileTests := make([]htmlFileTest, 0, len(tables))
+	for _, table := range tables {
+		fileTests = append(fileTests, htmlFileTest{
+			fileTestTable: table,
+			Charts:        buildCharts(options.Units, fileTestSizes[table.FileTestID], results[table.FileTestID]),
+		})
+	}
+
+	data := htmlPage{
+		FileTests: fileTests,
+		Errors:    buildErrorTable(results),
+		Summary:   buildSummaryTable(fileTestSizes, results, options),
+	}
+	if metadata != nil {
+		data.Metadata = metadata.rows()
+	}
+
+	var page strings.Builder
+	if err := htmlTemplate.Execute(&page, data); err != nil {
+		return "", err
+	}
+	return page.String(), nil
+}
+
+// buildCharts returns a chart of the mean throughput per endpoint for every
+// operation which transfers the whole file, leaving out latency and ramp
+// tests.
+func buildCharts(units Units, fileTestSize int, results operationResults) []htmlChart {
+	operations := make([]config.Operation, 0, len(results))
+	for operation := range results {
+		if !measuresThroughput(operation, results[operation]) {
+			continue
+		}
+		operations = append(operations, operation)
+	}
+	sort.Slice(operations, func(i, j int) bool { return operations[i] < operations[j] })
+
+	var charts []htmlChart
+	for _, operation := range operations {
+		endpointIDs := make([]config.ID, 0, len(results[operation]))
+		for endpointID := range results[operation] {
+			endpointIDs = append(endpointIDs, endpointID)
+		}
+		sort.Slice(endpointIDs, func(i, j int) bool { return endpointIDs[i] < endpointIDs[j] })
+
+		chart := htmlChart{Operation: operation.String()}
+		var maxMbps float64
+		throughputs := make([]float64, len(endpointIDs))
+		for i, endpointID := range endpointIDs {
+			stats := NewStats(results[operation][endpointID])
+			if stats.Successes() > 0 {
+				throughputs[i] = megabits(fileTestSize*stats.Objects) / stats.Mean.Seconds()
+			}
+			if throughputs[i] > maxMbps {
+				maxMbps = throughputs[i]
+			}
+		}
+
+		for i, endpointID := range endpointIDs {
+			bar := htmlBar{EndpointID: string(endpointID), Label: "ERR"}
+			if stats := NewStats(results[operation][endpointID]); stats.Count == 0 && stats.Unsupported > 0 {
+				bar.Label = "N/A"
+			}
+			if throughputs[i] > 0 {
+				bar.Label = units.format(throughputs[i])
+				bar.Percent = 100 * throughputs[i] / maxMbps
+			}
+			chart.Bars = append(chart.Bars, bar)
+		}
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>perftester report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; white-space: pre; }
+th { background: #eee; cursor: pointer; }
+.chart { margin-bottom: 1.5em; }
+.bar-row { display: flex; align-items: center; margin: 0.2em 0; }
+.bar-label { width: 12em; }
+.bar { background: #2683ff; height: 1.2em; margin-right: 0.5em; }
+</style>
+</head>
+<body>
+<h1>perftester report</h1>
+{{- with .Metadata}}
+<table class="metadata">
+{{- range .}}
+<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- range .FileTests}}
+<h2>File: {{.FileTestID}}</h2>
+<table class="sortable">
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- range .Charts}}
+<div class="chart">
+<h3>{{.Operation}} throughput</h3>
+{{- range .Bars}}
+<div class="bar-row"><span class="bar-label">{{.EndpointID}}</span><span class="bar" style="width: {{printf "%.1f" .Percent}}%"></span><span>{{.Label}}</span></div>
+{{- end}}
+</div>
+{{- end}}
+{{- end}}
+{{- with .Errors}}
+<h2>Errors</h2>
+<table class="sortable">
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+{{- with .Summary}}
+<h2>Summary</h2>
+<table>
+<thead><tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+<script>
+document.querySelectorAll("table.sortable").forEach(function(table) {
+	table.querySelectorAll("th").forEach(function(th, column) {
+		var ascending = true;
+		th.addEventListener("click", function() {
+			var tbody = table.tBodies[0];
+			var rows = Array.prototype.slice.call(tbody.rows);
+			rows.sort(function(a, b) {
+				var x = a.cells[column].textContent, y = b.cells[column].textContent;
+				var nx = parseFloat(x), ny = parseFloat(y);
+				var order = (!isNaN(nx) && !isNaN(ny)) ? nx - ny : x.localeCompare(y);
+				return ascending ? order : -order;
+			});
+			ascending = !ascending;
+			rows.forEach(function(row) { tbody.appendChild(row); });
+		});
+	});
+});
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,34 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+func TestHTMLReporter(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	reporter := report.NewHTMLReporter(map[config.ID]int{"ft1": 10000000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Duration: 5 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end2", &config.Result{Duration: 10 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Delete, "ft1", "end2", &config.Result{Error: "failed"}))
+
+	page, err := reporter.FormatResults(ctx)
+	require.NoError(t, err)
+
+	require.Contains(t, page, "<h2>File: ft1</h2>")
+	require.Contains(t, page, "<tr><td>Upload</td><td>16.00 Mbps</td><td>8.00 Mbps</td></tr>")
+	require.Contains(t, page, "<tr><td>Delete</td><td>-</td><td>ERR</td></tr>")
+	require.Contains(t, page, `<span class="bar" style="width: 100.0%"></span><span>16.00 Mbps</span>`)
+	require.Contains(t, page, `<span class="bar" style="width: 50.0%"></span><span>8.00 Mbps</span>`)
+	require.NotContains(t, page, "<h3>Delete throughput</h3>")
+}
@@ -0,0 +1,157 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+	"storj.io/perftester/pkg/results"
+)
+
+// RunResults are the results of a run in version 1 of the results schema,
+// which agents and the API respond with and which can be merged with the
+// results of other runs.
+type RunResults struct {
+	Metadata      *Metadata `json:",omitempty"`
+	FileTestSizes map[config.ID]int
+	Results       []OperationResult
+}
+
+// OperationResult is a single reported result.
+type OperationResult struct {
+	Operation  config.Operation
+	FileTestID config.ID
+	EndpointID config.ID
+	Result     *config.Result
+}
+
+// ParseRunResults parses results written by the json format or in any
+// other version of the results schema.
+func ParseRunResults(data []byte) (*RunResults, error) {
+	run, err := results.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	// RunResults are version 1 of the schema.
+	data, err = run.MarshalVersion(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var runResults RunResults
+	if err := json.Unmarshal(data, &runResults); err != nil {
+		return nil, errs.New("invalid results: %v", err)
+	}
+	return &runResults, nil
+}
+
+// LabeledEndpointID returns the ID the results of an endpoint are merged
+// under, such as "frankfurt/storj".
+func LabeledEndpointID(label string, endpointID config.ID) config.ID {
+	return config.ID(label) + "/" + endpointID
+}
+
+// Replay reports the results to reporter and adds the network timings,
+// settings and servers of their endpoints to metadata, all under endpoint
+// IDs labeled with label. The mode and shuffle seed of the first results
+// replayed are kept.
+func (results *RunResults) Replay(ctx context.Context, label string, reporter Reporter, metadata *Metadata) error {
+	for _, result := range results.Results {
+		err := reporter.Report(ctx, result.Operation, result.FileTestID, LabeledEndpointID(label, result.EndpointID), result.Result)
+		if err != nil {
+			return err
+		}
+	}
+
+	if results.Metadata == nil {
+		return nil
+	}
+	if metadata.Mode == "" {
+		metadata.Mode = results.Metadata.Mode
+		metadata.ShuffleSeed = results.Metadata.ShuffleSeed
+	}
+	for endpointID, timings := range results.Metadata.Network {
+		if metadata.Network == nil {
+			metadata.Network = make(map[config.ID]config.NetworkTimings)
+		}
+		metadata.Network[LabeledEndpointID(label, endpointID)] = timings
+	}
+	for endpointID, settings := range results.Metadata.Settings {
+		if metadata.Settings == nil {
+			metadata.Settings = make(map[config.ID]string)
+		}
+		metadata.Settings[LabeledEndpointID(label, endpointID)] = settings
+	}
+	for endpointID, server := range results.Metadata.Servers {
+		if metadata.Servers == nil {
+			metadata.Servers = make(map[config.ID]string)
+		}
+		metadata.Servers[LabeledEndpointID(label, endpointID)] = server
+	}
+	return nil
+}
+
+// JSONReporter gathers reports and writes them in the current version of
+// the results schema.
+type JSONReporter struct {
+	collector
+}
+
+// NewJSONReporter creates a JSONReporter.
+func NewJSONReporter(fileTestSizes map[config.ID]int) *JSONReporter {
+	return &JSONReporter{
+		collector: newCollector(fileTestSizes),
+	}
+}
+
+// RunResults returns the reported results.
+func (s *JSONReporter) RunResults() *RunResults {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	runResults := &RunResults{
+		Metadata:      s.metadata,
+		FileTestSizes: s.fileTestSizes,
+		Results:       []OperationResult{},
+	}
+	fileTestIDs, endpointIDs, operations := uniqueSortedIDs(s.results)
+	for _, fileTestID := range fileTestIDs {
+		for _, operation := range operations {
+			for _, endpointID := range endpointIDs {
+				for _, result := range s.results[fileTestID][operation][endpointID] {
+					runResults.Results = append(runResults.Results, OperationResult{
+						Operation:  operation,
+						FileTestID: fileTestID,
+						EndpointID: endpointID,
+						Result:     result,
+					})
+				}
+			}
+		}
+	}
+	return runResults
+}
+
+// FormatResults returns the indented JSON of the RunResults in the
+// current version of the results schema.
+func (s *JSONReporter) FormatResults(ctx context.Context) (string, error) {
+	// RunResults are version 1 of the schema, which is upgraded.
+	data, err := json.Marshal(s.RunResults())
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	run, err := results.Parse(data)
+	if err != nil {
+		return "", err
+	}
+	data, err = run.MarshalVersion(results.Version)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+func TestJSONReporter(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	sizes := map[config.ID]int{"ft1": 10000000}
+	reporter := report.NewJSONReporter(sizes)
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Duration: 5 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Error: "failed"}))
+	require.NoError(t, reporter.Report(ctx, config.Download, "ft1", "end1", &config.Result{Duration: 4 * time.Second, Success: true}))
+	reporter.SetMetadata(report.Metadata{
+		Hostname: "host1",
+		Network:  map[config.ID]config.NetworkTimings{"end1": {Address: "end1.example.com:443"}},
+	})
+
+	data, err := reporter.FormatResults(ctx)
+	require.NoError(t, err)
+	require.Contains(t, data, `"schema_version": 2`)
+	require.Contains(t, data, `"operation": "Upload"`)
+
+	results, err := report.ParseRunResults([]byte(data))
+	require.NoError(t, err)
+	require.Equal(t, sizes, results.FileTestSizes)
+	require.Equal(t, "host1", results.Metadata.Hostname)
+	require.Len(t, results.Results, 3)
+
+	// Replaying the results labels their endpoints.
+	text := report.NewTextReporter(results.FileTestSizes)
+	var metadata report.Metadata
+	require.NoError(t, results.Replay(ctx, "frankfurt", text, &metadata))
+	require.Equal(t, "end1.example.com:443", metadata.Network["frankfurt/end1"].Address)
+
+	str, err := text.FormatResults(ctx)
+	require.NoError(t, err)
+	require.Contains(t, str, "frankfurt/end1")
+	require.Contains(t, str, "16.00 Mbps")
+	require.Contains(t, str, "20.00 Mbps")
+
+	_, err = report.ParseRunResults([]byte(`{"Results": [{"Operation": "Teleport"}]}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"context"
+	"strings"
+
+	"storj.io/perftester/config"
+)
+
+// MarkdownReporter gathers reports and generates GitHub-flavored Markdown
+// tables.
+type MarkdownReporter struct {
+	collector
+}
+
+// NewMarkdownReporter creates a MarkdownReporter.
+func NewMarkdownReporter(fileTestSizes map[config.ID]int) *MarkdownReporter {
+	return &MarkdownReporter{
+		collector: newCollector(fileTestSizes),
+	}
+}
+
+// FormatResults returns a Markdown report of all reported results.
+func (s *MarkdownReporter) FormatResults(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return formatMarkdownResults(s.metadata, s.fileTestSizes, s.results, s.options)
+}
+
+func formatMarkdownResults(metadata *Metadata, fileTestSizes map[config.ID]int, results fileTestResults, options Options) (string, error) {
+	var reportString strings.Builder
+
+	if metadata != nil {
+		for _, row := range metadata.rows() {
+			writeWithBreak(&reportString, "- **"+row[0]+":** "+escapeMarkdown(row[1]))
+		}
+		writeBreak(&reportString)
+	}
+
+	tables, err := buildTables(fileTestSizes, results, options)
+	if err != nil {
+		return "", err
+	}
+
+	for _, table := range tables {
+		writeWithBreak(&reportString, "### File: "+escapeMarkdown(string(table.FileTestID)))
+		writeBreak(&reportString)
+
+		writeMarkdownTable(&reportString, table.Header, table.Rows)
+	}
+
+	if errorTable := buildErrorTable(results); errorTable != nil {
+		writeWithBreak(&reportString, "### Errors")
+		writeBreak(&reportString)
+		writeMarkdownTable(&reportString, errorTable.Header, errorTable.Rows)
+	}
+
+	if summaryTable := buildSummaryTable(fileTestSizes, results, options); summaryTable != nil {
+		writeWithBreak(&reportString, "### Summary")
+		writeBreak(&reportString)
+		writeMarkdownTable(&reportString, summaryTable.Header, summaryTable.Rows)
+	}
+
+	return reportString.String(), nil
+}
+
+func writeMarkdownTable(builder *strings.Builder, header []string, rows [][]string) {
+	writeMarkdownRow(builder, header)
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeMarkdownRow(builder, separator)
+	for _, row := range rows {
+		writeMarkdownRow(builder, row)
+	}
+	writeBreak(builder)
+}
+
+func writeMarkdownRow(builder *strings.Builder, row []string) {
+	cells := make([]string, 0, len(row))
+	for _, cell := range row {
+		// Markdown trims cells, so keep the indentation of detail rows.
+		trimmed := strings.TrimLeft(cell, " ")
+		indent := strings.Repeat("&nbsp;", len(cell)-len(trimmed))
+		cells = append(cells, indent+escapeMarkdown(trimmed))
+	}
+	writeWithBreak(builder, "| "+strings.Join(cells, " | ")+" |")
+}
+
+func escapeMarkdown(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+func TestMarkdownReporter(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	reporter := report.NewMarkdownReporter(map[config.ID]int{"ft1": 10000000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Duration: 5 * time.Second, Success: true, Finalize: time.Second}))
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end|2", &config.Result{Error: "failed"}))
+
+	str, err := reporter.FormatResults(ctx)
+	require.NoError(t, err)
+	require.Equal(t, `### File: ft1
+
+| Operation | end1 | end\|2 |
+| --- | --- | --- |
+| Upload | 16.00 Mbps | ERR |
+| &nbsp;&nbsp;streaming | 4s | - |
+| &nbsp;&nbsp;finalize | 1s | - |
+
+`, str)
+}
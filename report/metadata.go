@@ -0,0 +1,180 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"storj.io/perftester/config"
+)
+
+// Metadata describes the environment and configuration of a run, so that
+// archived results can be attributed to it.
+type Metadata struct {
+	Hostname      string
+	Region        string // Region the run ran from, if set.
+	OS            string
+	GoVersion     string
+	Version       string // Version of perftester.
+	UplinkVersion string
+	AWSSDKVersion string
+
+	StartTime  time.Time
+	EndTime    time.Time
+	ConfigHash string
+	// RunID identifies the run in the result store and prefixes the names
+	// of its objects if run prefixes are enabled.
+	RunID string
+	// Mode describes how the checks were scheduled, such as simultaneous
+	// runs on all endpoints.
+	Mode string
+	// ShuffleSeed seeds the order of shuffled runs.
+	ShuffleSeed int64
+
+	// Network are the connection setup times of the endpoints.
+	Network map[config.ID]config.NetworkTimings
+	// Settings are the transfer settings of the endpoints with tunable
+	// clients.
+	Settings map[config.ID]string
+	// Servers describe the servers of the endpoints whose clients can
+	// describe them, such as the version and health of a MinIO cluster.
+	Servers map[config.ID]string
+}
+
+// NewMetadata returns the metadata of a run starting now.
+func NewMetadata(configHash string) Metadata {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	metadata := Metadata{
+		Hostname:      hostname,
+		OS:            runtime.GOOS + "/" + runtime.GOARCH,
+		GoVersion:     runtime.Version(),
+		Version:       "unknown",
+		UplinkVersion: "unknown",
+		AWSSDKVersion: "unknown",
+		StartTime:     time.Now(),
+		ConfigHash:    configHash,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		metadata.Version = info.Main.Version
+		for _, dep := range info.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			switch dep.Path {
+			case "storj.io/uplink":
+				metadata.UplinkVersion = dep.Version
+			case "github.com/aws/aws-sdk-go":
+				metadata.AWSSDKVersion = dep.Version
+			}
+		}
+	}
+
+	return metadata
+}
+
+// rows returns a label and value row for every field.
+func (metadata *Metadata) rows() [][]string {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	rows := [][]string{
+		{"Host", metadata.Hostname},
+		{"OS", metadata.OS},
+		{"Go", metadata.GoVersion},
+		{"perftester", metadata.Version},
+		{"uplink", metadata.UplinkVersion},
+		{"aws-sdk-go", metadata.AWSSDKVersion},
+		{"Started", formatTime(metadata.StartTime)},
+		{"Finished", formatTime(metadata.EndTime)},
+		{"Config hash", metadata.ConfigHash},
+	}
+	if metadata.Region != "" {
+		rows = append(rows, []string{"Region", metadata.Region})
+	}
+	if metadata.RunID != "" {
+		rows = append(rows, []string{"Run ID", metadata.RunID})
+	}
+	if metadata.Mode != "" {
+		rows = append(rows, []string{"Mode", metadata.Mode})
+	}
+	if metadata.ShuffleSeed != 0 {
+		rows = append(rows, []string{"Shuffle seed", strconv.FormatInt(metadata.ShuffleSeed, 10)})
+	}
+
+	settingsIDs := make([]config.ID, 0, len(metadata.Settings))
+	for endpointID := range metadata.Settings {
+		settingsIDs = append(settingsIDs, endpointID)
+	}
+	sort.Slice(settingsIDs, func(i, j int) bool { return settingsIDs[i] < settingsIDs[j] })
+	for _, endpointID := range settingsIDs {
+		rows = append(rows, []string{"Settings " + string(endpointID), metadata.Settings[endpointID]})
+	}
+
+	serverIDs := make([]config.ID, 0, len(metadata.Servers))
+	for endpointID := range metadata.Servers {
+		serverIDs = append(serverIDs, endpointID)
+	}
+	sort.Slice(serverIDs, func(i, j int) bool { return serverIDs[i] < serverIDs[j] })
+	for _, endpointID := range serverIDs {
+		rows = append(rows, []string{"Server " + string(endpointID), metadata.Servers[endpointID]})
+	}
+
+	endpointIDs := make([]config.ID, 0, len(metadata.Network))
+	for endpointID := range metadata.Network {
+		endpointIDs = append(endpointIDs, endpointID)
+	}
+	sort.Slice(endpointIDs, func(i, j int) bool { return endpointIDs[i] < endpointIDs[j] })
+	for _, endpointID := range endpointIDs {
+		timings := metadata.Network[endpointID]
+		rows = append(rows, []string{"Network " + string(endpointID), formatNetworkTimings(timings)})
+		if len(timings.IPs) > 0 {
+			rows = append(rows, []string{"IPs " + string(endpointID), formatIPs(timings.IPs)})
+		}
+	}
+
+	return rows
+}
+
+// formatNetworkTimings formats the connection setup times of an endpoint.
+func formatNetworkTimings(timings config.NetworkTimings) string {
+	if timings.Error != "" {
+		return fmt.Sprintf("%s error: %s", timings.Address, timings.Error)
+	}
+
+	round := func(d time.Duration) time.Duration { return d.Round(100 * time.Microsecond) }
+	formatted := fmt.Sprintf("%s dns %v, connect %v", timings.Address, round(timings.DNS), round(timings.Connect))
+	if timings.TLSHandshake > 0 {
+		formatted += fmt.Sprintf(", tls %v", round(timings.TLSHandshake))
+	}
+	return formatted
+}
+
+// formatIPs formats the IPs of an endpoint with their locations.
+func formatIPs(ips []config.IPLocation) string {
+	formatted := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if ip.Location != "" {
+			formatted = append(formatted, fmt.Sprintf("%s (%s)", ip.IP, ip.Location))
+		} else {
+			formatted = append(formatted, ip.IP)
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
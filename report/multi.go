@@ -0,0 +1,29 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"context"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// Reporter accepts the result of each operation as it finishes.
+type Reporter interface {
+	Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) error
+}
+
+// MultiReporter forwards every report to each of its reporters.
+type MultiReporter []Reporter
+
+// Report accepts a single report.
+func (reporters MultiReporter) Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) error {
+	var group errs.Group
+	for _, reporter := range reporters {
+		group.Add(reporter.Report(ctx, operation, fileTestID, endpointID, result))
+	}
+	return group.Err()
+}
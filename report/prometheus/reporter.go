@@ -0,0 +1,183 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/perftester/config"
+)
+
+// Error is the error for this package.
+var Error = errs.Class("prometheus")
+
+// labels are the labels every metric is partitioned by.
+var labels = []string{"endpoint", "operation", "filetest", "size"}
+
+// failureLabels partition failures by their error category as well.
+var failureLabels = []string{"endpoint", "operation", "filetest", "size", "category"}
+
+// Reporter exposes results as Prometheus metrics.
+type Reporter struct {
+	fileTestSizes map[config.ID]int
+
+	registry  *prom.Registry
+	durations *prom.HistogramVec
+	firstByte *prom.HistogramVec
+	rates     *prom.HistogramVec
+	failures  *prom.CounterVec
+
+	nodes         *prom.CounterVec
+	nodeFailures  *prom.CounterVec
+	nodeCancelled *prom.CounterVec
+	nodeBytes     *prom.CounterVec
+}
+
+// New creates a Reporter with its own metrics registry.
+func New(fileTestSizes map[config.ID]int) *Reporter {
+	reporter := &Reporter{
+		fileTestSizes: fileTestSizes,
+		registry:      prom.NewRegistry(),
+		durations: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: "perftester",
+			Name:      "operation_duration_seconds",
+			Help:      "Duration of successful operations.",
+			Buckets:   prom.ExponentialBuckets(0.01, 2, 16),
+		}, labels),
+		firstByte: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: "perftester",
+			Name:      "operation_first_byte_seconds",
+			Help:      "Time to first byte of successful downloads.",
+			Buckets:   prom.ExponentialBuckets(0.01, 2, 12),
+		}, labels),
+		rates: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: "perftester",
+			Name:      "transfer_rate_bytes_per_second",
+			Help:      "Throughput of successful transfers in each interval of their timeline.",
+			Buckets:   prom.ExponentialBuckets(1e5, 2, 16),
+		}, labels),
+		failures: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "perftester",
+			Name:      "operation_failures_total",
+			Help:      "Number of failed operations.",
+		}, failureLabels),
+		nodes: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "perftester",
+			Name:      "node_piece_downloads_total",
+			Help:      "Number of piece downloads started on storage nodes.",
+		}, labels),
+		nodeFailures: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "perftester",
+			Name:      "node_piece_download_failures_total",
+			Help:      "Number of failed piece downloads from storage nodes.",
+		}, labels),
+		nodeCancelled: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "perftester",
+			Name:      "node_piece_downloads_cancelled_total",
+			Help:      "Number of long tail piece downloads cancelled once enough pieces arrived.",
+		}, labels),
+		nodeBytes: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "perftester",
+			Name:      "node_received_bytes_total",
+			Help:      "Bytes received from storage nodes, including the erasure overhead.",
+		}, labels),
+	}
+	reporter.registry.MustRegister(reporter.durations, reporter.firstByte, reporter.rates, reporter.failures,
+		reporter.nodes, reporter.nodeFailures, reporter.nodeCancelled, reporter.nodeBytes)
+	return reporter
+}
+
+// Report accepts a single report.
+func (reporter *Reporter) Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) error {
+	if result.Unsupported {
+		return nil
+	}
+
+	values := prom.Labels{
+		"endpoint":  string(endpointID),
+		"operation": operation.String(),
+		"filetest":  string(fileTestID),
+		"size":      strconv.Itoa(reporter.fileTestSizes[fileTestID]),
+	}
+
+	if result.Error != "" {
+		category := result.ErrorCategory
+		if category == "" {
+			category = config.OtherError
+		}
+		reporter.failures.With(prom.Labels{
+			"endpoint":  values["endpoint"],
+			"operation": values["operation"],
+			"filetest":  values["filetest"],
+			"size":      values["size"],
+			"category":  string(category),
+		}).Inc()
+		return nil
+	}
+
+	reporter.durations.With(values).Observe(result.Duration.Seconds())
+	if result.FirstByte > 0 {
+		reporter.firstByte.With(values).Observe(result.FirstByte.Seconds())
+	}
+	for _, bytes := range result.Timeline {
+		reporter.rates.With(values).Observe(float64(bytes) / config.TimelineInterval.Seconds())
+	}
+	if stats := result.NodeStats; stats != nil {
+		reporter.nodes.With(values).Add(float64(stats.Nodes))
+		reporter.nodeFailures.With(values).Add(float64(stats.Failed))
+		reporter.nodeCancelled.With(values).Add(float64(stats.Cancelled))
+		reporter.nodeBytes.With(values).Add(float64(stats.Bytes))
+	}
+	return nil
+}
+
+// Handler returns an http.Handler serving the metrics.
+func (reporter *Reporter) Handler() http.Handler {
+	return promhttp.HandlerFor(reporter.registry, promhttp.HandlerOpts{})
+}
+
+// Serve serves the metrics on listener until ctx is canceled. If other is
+// set, it serves the requests to other paths.
+func (reporter *Reporter) Serve(ctx context.Context, listener net.Listener, other http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", reporter.Handler())
+	if other != nil {
+		mux.Handle("/", other)
+	}
+	server := &http.Server{Handler: mux}
+
+	var group errgroup.Group
+	group.Go(func() error {
+		<-ctx.Done()
+		return server.Close()
+	})
+	group.Go(func() error {
+		err := server.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	})
+	return Error.Wrap(group.Wait())
+}
+
+// Push pushes the metrics to the Pushgateway at url, grouped by instance
+// when instanceID is set.
+func (reporter *Reporter) Push(url, instanceID string) error {
+	pusher := push.New(url, "perftester").Gatherer(reporter.registry)
+	if instanceID != "" {
+		pusher = pusher.Grouping("instance", instanceID)
+	}
+	return Error.Wrap(pusher.Push())
+}
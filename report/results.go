@@ -0,0 +1,115 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"storj.io/perftester/config"
+)
+
+// endpointResults is keyed by the endpointID and holds one result per iteration.
+type endpointResults map[config.ID][]*config.Result
+
+type operationResults map[config.Operation]endpointResults
+
+// fileTestResults is keyed by the fileTestID
+type fileTestResults map[config.ID]operationResults
+
+// collector gathers reports for the reporters which format them once all
+// checks have finished.
+type collector struct {
+	lock          sync.Mutex
+	results       fileTestResults
+	fileTestSizes map[config.ID]int
+	metadata      *Metadata
+	options       Options
+}
+
+func newCollector(fileTestSizes map[config.ID]int) collector {
+	return collector{
+		results:       make(fileTestResults),
+		fileTestSizes: fileTestSizes,
+	}
+}
+
+// SetMetadata sets the metadata of the run, which is included in the
+// formatted results.
+func (s *collector) SetMetadata(metadata Metadata) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.metadata = &metadata
+}
+
+// SetOptions sets how the formatted results are presented.
+func (s *collector) SetOptions(options Options) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.options = options
+}
+
+// Report accepts a single report.
+func (s *collector) Report(ctx context.Context, operation config.Operation, fileTestID config.ID, endpointID config.ID, result *config.Result) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	_, ok := s.results[fileTestID]
+	if !ok {
+		s.results[fileTestID] = make(operationResults)
+	}
+
+	_, ok = s.results[fileTestID][operation]
+	if !ok {
+		s.results[fileTestID][operation] = make(endpointResults)
+	}
+
+	s.results[fileTestID][operation][endpointID] = append(s.results[fileTestID][operation][endpointID], result)
+
+	return nil
+}
+
+func uniqueSortedIDs(results fileTestResults) (fileTestIDs []config.ID, endpointIDs []config.ID, operations []config.Operation) {
+	var (
+		seenFileTestIDs = make(map[config.ID]struct{})
+		seenEndpointIDs = make(map[config.ID]struct{})
+		seenOperations  = make(map[config.Operation]struct{})
+	)
+
+	for fileTestID, operationResults := range results {
+		_, ok := seenFileTestIDs[fileTestID]
+		if !ok {
+			fileTestIDs = append(fileTestIDs, fileTestID)
+			seenFileTestIDs[fileTestID] = struct{}{}
+		}
+
+		for operation, endpointResults := range operationResults {
+			_, ok := seenOperations[operation]
+			if !ok {
+				operations = append(operations, operation)
+				seenOperations[operation] = struct{}{}
+			}
+			for endpointID := range endpointResults {
+				_, ok := seenEndpointIDs[endpointID]
+				if !ok {
+					endpointIDs = append(endpointIDs, endpointID)
+					seenEndpointIDs[endpointID] = struct{}{}
+				}
+			}
+		}
+	}
+
+	sortDescendingFunc := func(slice []config.ID) func(i, j int) bool {
+		return func(i, j int) bool {
+			return slice[i] < slice[j]
+		}
+	}
+
+	sort.Slice(fileTestIDs, sortDescendingFunc(fileTestIDs))
+	sort.Slice(endpointIDs, sortDescendingFunc(endpointIDs))
+	sort.Slice(operations, func(i, j int) bool { return operations[i] < operations[j] })
+
+	return fileTestIDs, endpointIDs, operations
+}
@@ -0,0 +1,208 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/common/memory"
+	"storj.io/perftester/config"
+)
+
+// ErrSLA is the error class of runs which violated their SLA.
+var ErrSLA = errs.Class("sla")
+
+// SLAReporter gathers reports and evaluates them against the SLA rules of
+// the run.
+type SLAReporter struct {
+	collector
+	rules       []config.SLARule
+	allowNoData bool
+}
+
+// NewSLAReporter creates an SLAReporter.
+func NewSLAReporter(fileTestSizes map[config.ID]int, sla config.SLA) *SLAReporter {
+	return &SLAReporter{
+		collector:   newCollector(fileTestSizes),
+		rules:       sla.Rules,
+		allowNoData: sla.AllowNoData,
+	}
+}
+
+// SLAOutcome is the outcome of an SLA rule.
+type SLAOutcome struct {
+	Rule config.SLARule
+	// Checked is the number of operations of file tests on endpoints the
+	// rule was checked against.
+	Checked    int
+	Violations []string
+}
+
+// Passed returns whether no results violated the rule.
+func (outcome SLAOutcome) Passed() bool {
+	return len(outcome.Violations) == 0
+}
+
+// Evaluate checks the results against every rule.
+func (s *SLAReporter) Evaluate() []SLAOutcome {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	fileTestIDs, endpointIDs, operations := uniqueSortedIDs(s.results)
+	outcomes := make([]SLAOutcome, 0, len(s.rules))
+	for _, rule := range s.rules {
+		outcome := SLAOutcome{Rule: rule}
+		for _, fileTestID := range fileTestIDs {
+			size := s.fileTestSizes[fileTestID]
+			for _, operation := range operations {
+				results := s.results[fileTestID][operation]
+				for _, endpointID := range endpointIDs {
+					stats := NewStats(results[endpointID])
+					if stats.Count == 0 || !rule.Matches(endpointID, operation, int64(size)) {
+						continue
+					}
+
+					throughput := rule.MinThroughput > 0 && measuresThroughput(operation, results)
+					latency := rule.MaxLatency > 0
+					if !throughput && !latency {
+						continue
+					}
+					outcome.Checked++
+
+					if stats.Successes() == 0 {
+						outcome.Violations = append(outcome.Violations, fmt.Sprintf("%s %s on %s failed", fileTestID, operation, endpointID))
+						continue
+					}
+					if throughput {
+						if mbps := megabits(size*stats.Objects) / stats.Mean.Seconds(); mbps < rule.MinThroughput {
+							outcome.Violations = append(outcome.Violations, fmt.Sprintf("%s %s on %s ran at %s, below %s", fileTestID, operation, endpointID, formatMbps(mbps), formatMbps(rule.MinThroughput)))
+						}
+					}
+					if latency {
+						if mean := meanLatency(results[endpointID]); mean > time.Duration(rule.MaxLatency) {
+							outcome.Violations = append(outcome.Violations, fmt.Sprintf("%s %s on %s took %s, above %s", fileTestID, operation, endpointID, mean, time.Duration(rule.MaxLatency)))
+						}
+					}
+				}
+			}
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// FormatResults returns a table with the outcome of every rule.
+func (s *SLAReporter) FormatResults(ctx context.Context) (string, error) {
+	const title = "SLA"
+
+	rows := [][]string{{"Rule", "Result", "Details"}}
+	for _, outcome := range s.Evaluate() {
+		result, details := "PASS", ""
+		switch {
+		case outcome.Checked == 0 && s.allowNoData:
+			result, details = "NO DATA", "no matching results"
+		case outcome.Checked == 0:
+			result, details = "FAIL", "no matching results"
+		case !outcome.Passed():
+			result, details = "FAIL", strings.Join(outcome.Violations, "; ")
+		}
+		rows = append(rows, []string{describeRule(outcome.Rule), result, details})
+	}
+
+	table, err := MakeTable(rows, "-")
+	if err != nil {
+		return "", err
+	}
+
+	var reportString strings.Builder
+	stars := strings.Repeat("*", len(title))
+	writeWithBreak(&reportString, stars)
+	writeWithBreak(&reportString, title)
+	writeWithBreak(&reportString, stars)
+	writeBreak(&reportString)
+	writeWithBreak(&reportString, table)
+	return reportString.String(), nil
+}
+
+// Check returns an ErrSLA error listing the violations of every failed
+// rule. Rules without matching results fail too, unless the SLA allows
+// them.
+func (s *SLAReporter) Check() error {
+	var violations []string
+	for _, outcome := range s.Evaluate() {
+		if outcome.Checked == 0 && !s.allowNoData {
+			violations = append(violations, fmt.Sprintf("%s matched no results", describeRule(outcome.Rule)))
+		}
+		violations = append(violations, outcome.Violations...)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return ErrSLA.New("%s", strings.Join(violations, "; "))
+}
+
+// describeRule returns the name of a rule, or describes it if it has none,
+// such as "Download on storj, 1.0 MB and up: >= 100.00 Mbps, <= 2s".
+func describeRule(rule config.SLARule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+
+	scope := "All operations"
+	if rule.Operation != "" {
+		scope = config.OperationNames[rule.Operation].String()
+	}
+	if rule.Endpoint != "" {
+		scope += " on " + string(rule.Endpoint)
+	}
+	switch {
+	case rule.MaxSize > 0:
+		scope += fmt.Sprintf(", %s to %s", memory.Size(rule.MinSize).Base10String(), memory.Size(rule.MaxSize).Base10String())
+	case rule.MinSize > 0:
+		scope += fmt.Sprintf(", %s and up", memory.Size(rule.MinSize).Base10String())
+	}
+
+	var limits []string
+	if rule.MinThroughput > 0 {
+		limits = append(limits, ">= "+formatMbps(rule.MinThroughput))
+	}
+	if rule.MaxLatency > 0 {
+		limits = append(limits, "<= "+time.Duration(rule.MaxLatency).String())
+	}
+	return scope + ": " + strings.Join(limits, ", ")
+}
+
+// meanLatency returns the mean latency of the successful results: that of
+// the individual operations of latency tests, of the objects of results
+// which timed them, or of the whole results otherwise.
+func meanLatency(results []*config.Result) time.Duration {
+	var sum time.Duration
+	var count int
+	add := func(durations ...time.Duration) {
+		for _, duration := range durations {
+			sum += duration
+			count++
+		}
+	}
+	for _, result := range results {
+		switch {
+		case result.Error != "" || result.Unsupported:
+		case len(result.Latencies) > 0:
+			add(result.Latencies...)
+		case len(result.ObjectDurations) > 0:
+			add(result.ObjectDurations...)
+		default:
+			add(result.Duration)
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / time.Duration(count)
+}
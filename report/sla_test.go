@@ -0,0 +1,74 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+func TestSLAReporter(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	reporter := report.NewSLAReporter(map[config.ID]int{"ft1": 1250000}, config.SLA{
+		Rules: []config.SLARule{
+			{Name: "uploads", Operation: "upload", MinThroughput: 5},
+			{Name: "fast uploads", Endpoint: "end2", Operation: "upload", MinThroughput: 20},
+			{Name: "latency", Operation: "download", MaxLatency: config.Duration(time.Second)},
+			{Name: "large files", MinSize: 1 << 30, MinThroughput: 5},
+		},
+	})
+	// A 10 Mbps upload on each endpoint and a 2s download on end1.
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Duration: time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end2", &config.Result{Duration: time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Download, "ft1", "end1", &config.Result{Duration: 2 * time.Second, Success: true}))
+
+	outcomes := reporter.Evaluate()
+	require.Len(t, outcomes, 4)
+	require.True(t, outcomes[0].Passed())
+	require.Equal(t, 2, outcomes[0].Checked)
+	require.Equal(t, []string{"ft1 Upload on end2 ran at 10.00 Mbps, below 20.00 Mbps"}, outcomes[1].Violations)
+	require.Equal(t, []string{"ft1 Download on end1 took 2s, above 1s"}, outcomes[2].Violations)
+	require.Zero(t, outcomes[3].Checked)
+
+	formatted, err := reporter.FormatResults(ctx)
+	require.NoError(t, err)
+	require.Contains(t, formatted, "PASS")
+	require.Contains(t, formatted, "FAIL")
+	require.NotContains(t, formatted, "NO DATA")
+
+	err = reporter.Check()
+	require.True(t, report.ErrSLA.Has(err))
+	require.Contains(t, err.Error(), "below 20.00 Mbps")
+	require.Contains(t, err.Error(), "above 1s")
+	require.Contains(t, err.Error(), "large files matched no results")
+}
+
+func TestSLAReporterNoData(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	rules := []config.SLARule{{Name: "misspelled", Endpoint: "edn1", MinThroughput: 5}}
+	result := &config.Result{Duration: time.Second, Success: true}
+
+	// A rule which matches no results fails.
+	reporter := report.NewSLAReporter(map[config.ID]int{"ft1": 1250000}, config.SLA{Rules: rules})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", result))
+	err := reporter.Check()
+	require.True(t, report.ErrSLA.Has(err))
+	require.Contains(t, err.Error(), "misspelled matched no results")
+
+	// Unless the SLA allows it, for runs of parts of the config.
+	reporter = report.NewSLAReporter(map[config.ID]int{"ft1": 1250000}, config.SLA{Rules: rules, AllowNoData: true})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", result))
+	require.NoError(t, reporter.Check())
+	formatted, err := reporter.FormatResults(ctx)
+	require.NoError(t, err)
+	require.Contains(t, formatted, "NO DATA")
+}
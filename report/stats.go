@@ -0,0 +1,223 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"storj.io/perftester/config"
+)
+
+// Stats summarizes the durations of repeated results for one operation.
+type Stats struct {
+	Count  int // Number of results, including failed ones.
+	Errors int // Number of failed results.
+
+	ErrorCategories map[config.ErrorCategory]int // Number of classified failed results per category.
+
+	Retries               int // Total number of retries.
+	FirstAttemptSuccesses int // Number of results which succeeded without retries.
+
+	Unsupported int // Number of skipped results, which aren't counted otherwise.
+
+	Objects int // Number of objects transferred by each successful result.
+
+	Min    time.Duration
+	Max    time.Duration
+	Mean   time.Duration
+	Median time.Duration
+	P95    time.Duration
+	P99    time.Duration
+}
+
+// NewStats computes the duration statistics of the successful results.
+func NewStats(results []*config.Result) Stats {
+	var stats Stats
+
+	var durations []time.Duration
+	for _, result := range results {
+		if result.Unsupported {
+			stats.Unsupported++
+			continue
+		}
+
+		stats.Count++
+		stats.Retries += result.Retries()
+		if result.Error == "" && result.Retries() == 0 {
+			stats.FirstAttemptSuccesses++
+		}
+
+		if result.Error != "" {
+			stats.Errors++
+			if result.ErrorCategory != "" {
+				if stats.ErrorCategories == nil {
+					stats.ErrorCategories = make(map[config.ErrorCategory]int)
+				}
+				stats.ErrorCategories[result.ErrorCategory]++
+			}
+			continue
+		}
+		durations = append(durations, result.Duration)
+		if objects := objectCount(result); objects > stats.Objects {
+			stats.Objects = objects
+		}
+	}
+
+	stats.setDurations(durations)
+	return stats
+}
+
+// objectCount returns the number of objects transferred by a result.
+func objectCount(result *config.Result) int {
+	if len(result.ObjectDurations) > 1 {
+		return len(result.ObjectDurations)
+	}
+	return 1
+}
+
+// ObjectStats summarizes the durations of the individual objects of
+// results.
+type ObjectStats struct {
+	Count  int
+	Min    time.Duration
+	Max    time.Duration
+	StdDev time.Duration
+}
+
+// NewObjectStats computes the statistics of the object durations of the
+// successful results.
+func NewObjectStats(results []*config.Result) ObjectStats {
+	var stats ObjectStats
+	var sum float64
+	var durations []time.Duration
+	for _, result := range results {
+		if result.Error != "" {
+			continue
+		}
+		for _, duration := range result.ObjectDurations {
+			if stats.Count == 0 || duration < stats.Min {
+				stats.Min = duration
+			}
+			if duration > stats.Max {
+				stats.Max = duration
+			}
+			stats.Count++
+			sum += float64(duration)
+			durations = append(durations, duration)
+		}
+	}
+	if stats.Count == 0 {
+		return stats
+	}
+
+	mean := sum / float64(stats.Count)
+	var variance float64
+	for _, duration := range durations {
+		variance += (float64(duration) - mean) * (float64(duration) - mean)
+	}
+	stats.StdDev = time.Duration(math.Sqrt(variance / float64(stats.Count)))
+	return stats
+}
+
+// BucketStats summarizes the throughput of the time buckets of soak test
+// results, in Mbps.
+type BucketStats struct {
+	Count      int
+	Operations int
+	Errors     int
+	Min        float64
+	Max        float64
+	StdDev     float64
+}
+
+// NewBucketStats computes the statistics of the time buckets of the
+// results, whose objects are fileTestSize bytes each.
+func NewBucketStats(fileTestSize int, results []*config.Result) BucketStats {
+	var stats BucketStats
+	var throughputs []float64
+	var sum float64
+	for _, result := range results {
+		for _, bucket := range result.Buckets {
+			stats.Operations += bucket.Objects + bucket.Errors
+			stats.Errors += bucket.Errors
+			if bucket.Duration <= 0 {
+				continue
+			}
+
+			throughput := megabits(fileTestSize*bucket.Objects) / bucket.Duration.Seconds()
+			if stats.Count == 0 || throughput < stats.Min {
+				stats.Min = throughput
+			}
+			if throughput > stats.Max {
+				stats.Max = throughput
+			}
+			stats.Count++
+			sum += throughput
+			throughputs = append(throughputs, throughput)
+		}
+	}
+	if stats.Count == 0 {
+		return stats
+	}
+
+	mean := sum / float64(stats.Count)
+	var variance float64
+	for _, throughput := range throughputs {
+		variance += (throughput - mean) * (throughput - mean)
+	}
+	stats.StdDev = math.Sqrt(variance / float64(stats.Count))
+	return stats
+}
+
+// newLatencyStats computes the statistics of the latencies of the
+// successful results of latency tests.
+func newLatencyStats(results []*config.Result) Stats {
+	var latencies []time.Duration
+	for _, result := range results {
+		if result.Error == "" {
+			latencies = append(latencies, result.Latencies...)
+		}
+	}
+
+	stats := Stats{Count: len(latencies)}
+	stats.setDurations(latencies)
+	return stats
+}
+
+// setDurations sets the duration statistics.
+func (stats *Stats) setDurations(durations []time.Duration) {
+	if len(durations) == 0 {
+		return
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, duration := range durations {
+		total += duration
+	}
+
+	stats.Min = durations[0]
+	stats.Max = durations[len(durations)-1]
+	stats.Mean = total / time.Duration(len(durations))
+	stats.Median = percentile(durations, 50)
+	stats.P95 = percentile(durations, 95)
+	stats.P99 = percentile(durations, 99)
+}
+
+// Successes returns the number of successful results.
+func (stats Stats) Successes() int {
+	return stats.Count - stats.Errors
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
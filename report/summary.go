@@ -0,0 +1,134 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"storj.io/common/memory"
+	"storj.io/perftester/config"
+)
+
+// summaryTable holds the totals of every endpoint over all file tests and
+// operations, with one column per endpoint.
+type summaryTable struct {
+	Header []string
+	Rows   [][]string
+}
+
+// endpointSummary accumulates the totals of one endpoint.
+type endpointSummary struct {
+	logMbps       float64
+	throughputs   int
+	errors        int
+	bytes         int64
+	operationTime time.Duration
+}
+
+// throughputKey identifies the throughput of an operation of a file test.
+type throughputKey struct {
+	fileTestID config.ID
+	operation  config.Operation
+}
+
+// buildSummaryTable sums up every endpoint over all file tests: the
+// geometric mean of its throughputs, one per file test and operation which
+// measures them, so that no file size dominates, its failed results, the
+// bytes it transferred and the summed up time its operations took. The
+// geometric means only cover the throughputs every endpoint measured, so
+// that they compare the same transfers, and the row says how many of the
+// measured ones that is if some were left out. They are compared to that
+// of the reference endpoint of the options. It returns nil for results of a
+// single file test, whose table shows them already.
+func buildSummaryTable(fileTestSizes map[config.ID]int, results fileTestResults, options Options) *summaryTable {
+	fileTestIDs, endpointIDs, operations := uniqueSortedIDs(results)
+	if len(fileTestIDs) < 2 {
+		return nil
+	}
+
+	summaries := make(map[config.ID]*endpointSummary, len(endpointIDs))
+	for _, endpointID := range endpointIDs {
+		summaries[endpointID] = &endpointSummary{}
+	}
+	var keys []throughputKey
+	throughputs := make(map[throughputKey]map[config.ID]float64)
+	for _, fileTestID := range fileTestIDs {
+		for _, operation := range operations {
+			key := throughputKey{fileTestID, operation}
+			operationResults := results[fileTestID][operation]
+			for endpointID, endpointResults := range operationResults {
+				summary := summaries[endpointID]
+				for _, result := range endpointResults {
+					if result.Unsupported {
+						continue
+					}
+					if result.Error != "" {
+						summary.errors++
+					}
+					summary.bytes += result.Bytes
+					summary.operationTime += result.Duration
+				}
+
+				stats := NewStats(endpointResults)
+				if stats.Successes() == 0 || !measuresThroughput(operation, operationResults) {
+					continue
+				}
+				if throughputs[key] == nil {
+					throughputs[key] = make(map[config.ID]float64)
+					keys = append(keys, key)
+				}
+				throughputs[key][endpointID] = megabits(fileTestSizes[fileTestID]*stats.Objects) / stats.Mean.Seconds()
+			}
+		}
+	}
+
+	shared := 0
+	for _, key := range keys {
+		if len(throughputs[key]) < len(endpointIDs) {
+			continue
+		}
+		shared++
+		for endpointID, mbps := range throughputs[key] {
+			summaries[endpointID].logMbps += math.Log(mbps)
+			summaries[endpointID].throughputs++
+		}
+	}
+
+	table := &summaryTable{Header: []string{"Metric"}}
+	throughputRow := []string{"Throughput (geomean)"}
+	if shared < len(keys) {
+		throughputRow[0] = fmt.Sprintf("Throughput (geomean of %d/%d shared)", shared, len(keys))
+	}
+	errorsRow := []string{"Errors"}
+	bytesRow := []string{"Bytes moved"}
+	operationTimeRow := []string{"Operation time (sum)"}
+	for _, endpointID := range endpointIDs {
+		summary := summaries[endpointID]
+		table.Header = append(table.Header, string(endpointID))
+
+		if summary.throughputs == 0 {
+			throughputRow = append(throughputRow, "-")
+		} else {
+			cell := options.Units.format(summary.geomean())
+			if reference, ok := summaries[options.ReferenceEndpoint]; ok && endpointID != options.ReferenceEndpoint && reference.throughputs > 0 {
+				cell += formatRelative(summary.geomean(), reference.geomean(), options.ReferenceEndpoint)
+			}
+			throughputRow = append(throughputRow, cell)
+		}
+		errorsRow = append(errorsRow, strconv.Itoa(summary.errors))
+		bytesRow = append(bytesRow, memory.Size(summary.bytes).Base10String())
+		operationTimeRow = append(operationTimeRow, formatElapsed(summary.operationTime))
+	}
+	table.Rows = [][]string{throughputRow, errorsRow, bytesRow, operationTimeRow}
+	return table
+}
+
+// geomean returns the geometric mean of the throughputs of the endpoint in
+// Mbps.
+func (summary *endpointSummary) geomean() float64 {
+	return math.Exp(summary.logMbps / float64(summary.throughputs))
+}
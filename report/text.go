@@ -0,0 +1,1003 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// TextReporter gathers reports and generates a formatted text report.
+type TextReporter struct {
+	collector
+}
+
+// NewTextReporter creats a TextReporter.
+func NewTextReporter(fileTestSizes map[config.ID]int) *TextReporter {
+	return &TextReporter{
+		collector: newCollector(fileTestSizes),
+	}
+}
+
+// FormatResults returns a string report of all reported results.
+func (s *TextReporter) FormatResults(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return formatResults(s.metadata, s.fileTestSizes, s.results, s.options)
+}
+
+func formatResults(metadata *Metadata, fileTestSizes map[config.ID]int, results fileTestResults, options Options) (string, error) {
+	const filePrefix = "File: "
+
+	var reportString strings.Builder
+
+	if metadata != nil {
+		rows := metadata.rows()
+		width := 0
+		for _, row := range rows {
+			if len(row[0]) > width {
+				width = len(row[0])
+			}
+		}
+		for _, row := range rows {
+			writeWithBreak(&reportString, fmt.Sprintf("%-*s%s", width+2, row[0]+":", row[1]))
+		}
+		writeBreak(&reportString)
+	}
+
+	tables, err := buildTables(fileTestSizes, results, options)
+	if err != nil {
+		return "", err
+	}
+	errorTable := buildErrorTable(results)
+	summaryTable := buildSummaryTable(fileTestSizes, results, options)
+
+	// Metrics are the rows of the file test tables and the summary.
+	var allRows [][]string
+	for _, table := range tables {
+		allRows = append(allRows, table.Rows...)
+	}
+	if summaryTable != nil {
+		allRows = append(allRows, summaryTable.Rows...)
+	}
+	_, endpointIDs, _ := uniqueSortedIDs(results)
+	selection, err := newColumnSelection(options.Columns, endpointIDs, allRows)
+	if err != nil {
+		return "", err
+	}
+
+	// File tests without the selected metrics are still listed, so that
+	// they don't go missing unnoticed.
+	for _, table := range tables {
+		header, rows := selection.selectColumns(table.Header, selection.selectRows(table.Rows))
+		if err := writeTextSection(&reportString, filePrefix+string(table.FileTestID), header, rows, options.Width); err != nil {
+			return "", err
+		}
+	}
+
+	if errorTable != nil {
+		header, rows := selection.selectColumns(errorTable.Header, errorTable.Rows)
+		if err := writeTextSection(&reportString, "Errors", header, rows, options.Width); err != nil {
+			return "", err
+		}
+	}
+
+	if summaryTable != nil {
+		header, rows := selection.selectColumns(summaryTable.Header, summaryTable.Rows)
+		if err := writeTextSection(&reportString, "Summary", header, rows, options.Width); err != nil {
+			return "", err
+		}
+	}
+
+	return reportString.String(), nil
+}
+
+// writeTextSection writes the title underlined with stars followed by the
+// table, split into several tables of at most width characters if it is
+// wider.
+func writeTextSection(builder *strings.Builder, title string, header []string, rows [][]string, width int) error {
+	stars := strings.Repeat("*", len(title))
+	writeWithBreak(builder, stars)
+	writeWithBreak(builder, title)
+	writeWithBreak(builder, stars)
+	writeBreak(builder)
+
+	for _, part := range splitColumns(append([][]string{header}, rows...), width) {
+		tableStr, err := MakeTable(part, "-")
+		if err != nil {
+			return err
+		}
+		writeWithBreak(builder, tableStr)
+	}
+	return nil
+}
+
+// fileTestTable holds the formatted results of a single file test, with
+// one column per endpoint.
+type fileTestTable struct {
+	FileTestID config.ID
+	Header     []string
+	Rows       [][]string
+}
+
+// buildTables formats the results into one table per file test.
+func buildTables(fileTestSizes map[config.ID]int, results fileTestResults, options Options) ([]fileTestTable, error) {
+	var tables []fileTestTable
+
+	fileTestIDs, endpointIDs, operations := uniqueSortedIDs(results)
+	for _, fileTestID := range fileTestIDs {
+		// Build headerRow
+		headerRow := []string{"Operation"}
+		for _, endpointID := range endpointIDs {
+			headerRow = append(headerRow, string(endpointID))
+		}
+
+		fileTestSize := fileTestSizes[fileTestID]
+		if fileTestSize == 0 {
+			return nil, errs.New("Unknown fileTestSize for %s", string(fileTestID))
+		}
+
+		var rows [][]string
+		for _, operation := range operations {
+			if hasLatencies(results[fileTestID][operation]) {
+				rows = append(rows, formatLatencyRows(operation, endpointIDs, results[fileTestID][operation])...)
+				continue
+			}
+			if hasRamp(results[fileTestID][operation]) {
+				rows = append(rows, formatRampRows(operation, options.Units, fileTestSize, endpointIDs, results[fileTestID][operation])...)
+				continue
+			}
+
+			iterated := false
+			for _, endpointID := range endpointIDs {
+				if len(results[fileTestID][operation][endpointID]) > 1 {
+					iterated = true
+				}
+			}
+
+			summaryRow := len(rows)
+			if !iterated {
+				row := []string{operation.String()}
+				for _, endpointID := range endpointIDs {
+					var result *config.Result
+					if endpointResults := results[fileTestID][operation][endpointID]; len(endpointResults) > 0 {
+						result = endpointResults[0]
+					}
+					row = append(row, formatResultForRow(operation, options.Units, fileTestSize, maxObjects(results[fileTestID][operation]), result))
+				}
+				rows = append(rows, row)
+			} else {
+				rows = append(rows, formatStatsRows(operation, options.Units, fileTestSize, endpointIDs, results[fileTestID][operation])...)
+			}
+			if options.ReferenceEndpoint != "" && measuresThroughput(operation, results[fileTestID][operation]) {
+				appendRelativeNotes(rows[summaryRow], fileTestSize, endpointIDs, results[fileTestID][operation], options.ReferenceEndpoint)
+			}
+
+			rows = append(rows, formatObjectRows(endpointIDs, results[fileTestID][operation])...)
+			rows = append(rows, formatBucketRows(options.Units, fileTestSize, endpointIDs, results[fileTestID][operation])...)
+			rows = append(rows, formatConflictRows(endpointIDs, results[fileTestID][operation])...)
+			rows = append(rows, formatListingRows(endpointIDs, results[fileTestID][operation])...)
+			if isolated, ok := isolatedOperations[operation]; ok {
+				rows = append(rows, formatThroughputChangeRow("  vs isolated", endpointIDs, results[fileTestID][operation], results[fileTestID][isolated]))
+			}
+			if first, ok := repeatedOperations[operation]; ok {
+				rows = append(rows, formatCacheRows(fileTestSize, endpointIDs, results[fileTestID][operation], results[fileTestID][first])...)
+			}
+			rows = append(rows, formatTimingRows(endpointIDs, results[fileTestID][operation])...)
+			rows = append(rows, formatPartRows(options.Units, endpointIDs, results[fileTestID][operation])...)
+		}
+
+		tables = append(tables, fileTestTable{
+			FileTestID: fileTestID,
+			Header:     headerRow,
+			Rows:       rows,
+		})
+	}
+
+	return tables, nil
+}
+
+// errorTable holds the number of failed results of each endpoint per
+// error category, with one column per endpoint.
+type errorTable struct {
+	Header []string
+	Rows   [][]string
+}
+
+// buildErrorTable counts the classified failures of every endpoint over
+// all file tests and operations, with one row per category which occurred.
+// It returns nil if no failures were classified.
+func buildErrorTable(results fileTestResults) *errorTable {
+	_, endpointIDs, _ := uniqueSortedIDs(results)
+
+	counts := make(map[config.ErrorCategory]map[config.ID]int)
+	for _, operations := range results {
+		for _, endpoints := range operations {
+			for endpointID, endpointResults := range endpoints {
+				for _, result := range endpointResults {
+					if result.Error == "" || result.ErrorCategory == "" {
+						continue
+					}
+					if counts[result.ErrorCategory] == nil {
+						counts[result.ErrorCategory] = make(map[config.ID]int)
+					}
+					counts[result.ErrorCategory][endpointID]++
+				}
+			}
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	table := &errorTable{Header: []string{"Category"}}
+	for _, endpointID := range endpointIDs {
+		table.Header = append(table.Header, string(endpointID))
+	}
+	for _, category := range config.ErrorCategories {
+		if counts[category] == nil {
+			continue
+		}
+		row := []string{string(category)}
+		for _, endpointID := range endpointIDs {
+			row = append(row, strconv.Itoa(counts[category][endpointID]))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+func formatResultForRow(operation config.Operation, units Units, fileTestSize, rowObjects int, result *config.Result) string {
+	if result == nil {
+		return "-"
+	}
+
+	if result.Unsupported {
+		return "N/A"
+	}
+
+	if result.Error != "" {
+		return "ERR" + formatErrorCategories(map[config.ErrorCategory]int{result.ErrorCategory: 1}) + formatErrorNotes(result)
+	}
+
+	return formatDuration(operation, units, fileTestSize, objectCount(result), rowObjects, result.Duration) + formatRetries(result.Retries())
+}
+
+// formatDuration formats the duration of an operation on objects of
+// fileTestSize as a throughput in units, as the rate of deletes if any
+// endpoint of the row deleted several objects, rowObjects being the most
+// objects one did, or as is for other operations which don't transfer the
+// whole file. Choosing by row keeps the cells of a row comparable.
+func formatDuration(operation config.Operation, units Units, fileTestSize, objects, rowObjects int, duration time.Duration) string {
+	switch {
+	case operation.TransfersFile():
+		return units.format(megabits(fileTestSize*objects) / duration.Seconds())
+	case operation == config.Delete && rowObjects > 1:
+		return formatRate(float64(objects)/duration.Seconds(), "ops/s")
+	default:
+		return formatElapsed(duration)
+	}
+}
+
+// formatElapsed formats a duration rounded to milliseconds from a second on
+// and to microseconds from a millisecond on, so that durations of the same
+// magnitude show the same precision.
+func formatElapsed(duration time.Duration) string {
+	switch {
+	case duration >= time.Second || duration <= -time.Second:
+		duration = duration.Round(time.Millisecond)
+	case duration >= time.Millisecond || duration <= -time.Millisecond:
+		duration = duration.Round(time.Microsecond)
+	}
+	return duration.String()
+}
+
+// maxObjects returns the most objects transferred by a successful result
+// of any endpoint.
+func maxObjects(results endpointResults) int {
+	objects := 0
+	for _, endpointResults := range results {
+		if stats := NewStats(endpointResults); stats.Objects > objects {
+			objects = stats.Objects
+		}
+	}
+	return objects
+}
+
+func megabits(size int) float64 {
+	return float64(size) * 8 / 1000 / 1000
+}
+
+func formatRetries(retries int) string {
+	if retries == 0 {
+		return ""
+	}
+	return " (" + retriesNote(retries) + ")"
+}
+
+func retriesNote(retries int) string {
+	if retries == 1 {
+		return "1 retry"
+	}
+	return fmt.Sprintf("%d retries", retries)
+}
+
+// formatErrorNotes describes how many objects of a failed result went
+// through and its retries, such as " (7/8 OK, 1 retry)".
+func formatErrorNotes(result *config.Result) string {
+	var notes []string
+	if result.Succeeded > 0 {
+		notes = append(notes, fmt.Sprintf("%d/%d OK", result.Succeeded, len(result.ObjectDurations)))
+	}
+	if retries := result.Retries(); retries > 0 {
+		notes = append(notes, retriesNote(retries))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
+
+// statRows are the detail rows listing duration statistics.
+var statRows = []struct {
+	name  string
+	value func(Stats) time.Duration
+}{
+	{"  min", func(s Stats) time.Duration { return s.Min }},
+	{"  max", func(s Stats) time.Duration { return s.Max }},
+	{"  mean", func(s Stats) time.Duration { return s.Mean }},
+	{"  median", func(s Stats) time.Duration { return s.Median }},
+	{"  p95", func(s Stats) time.Duration { return s.P95 }},
+	{"  p99", func(s Stats) time.Duration { return s.P99 }},
+}
+
+// formatStatsRows returns a summary row followed by one row per duration
+// statistic for an operation which was run for several iterations.
+func formatStatsRows(operation config.Operation, units Units, fileTestSize int, endpointIDs []config.ID, results endpointResults) [][]string {
+	stats := make([]Stats, 0, len(endpointIDs))
+	summaryRow := []string{operation.String()}
+	rowObjects := maxObjects(results)
+	for _, endpointID := range endpointIDs {
+		endpointStats := NewStats(results[endpointID])
+		stats = append(stats, endpointStats)
+		summaryRow = append(summaryRow, formatStatsForRow(operation, units, fileTestSize, rowObjects, endpointStats))
+	}
+
+	rows := [][]string{summaryRow}
+	for _, statRow := range statRows {
+		row := []string{statRow.name}
+		for _, endpointStats := range stats {
+			if endpointStats.Successes() == 0 {
+				row = append(row, "-")
+				continue
+			}
+			row = append(row, formatElapsed(statRow.value(endpointStats)))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// formatObjectRows returns rows with the spread of the durations of the
+// individual objects of an operation, if any of its results transferred
+// several objects.
+func formatObjectRows(endpointIDs []config.ID, results endpointResults) [][]string {
+	objectRows := []struct {
+		name  string
+		value func(ObjectStats) time.Duration
+	}{
+		{"  object min", func(s ObjectStats) time.Duration { return s.Min }},
+		{"  object max", func(s ObjectStats) time.Duration { return s.Max }},
+		{"  object stddev", func(s ObjectStats) time.Duration { return s.StdDev }},
+	}
+
+	measured := false
+	stats := make([]ObjectStats, 0, len(endpointIDs))
+	for _, endpointID := range endpointIDs {
+		for _, result := range results[endpointID] {
+			if result.Error == "" && len(result.ObjectDurations) > 1 {
+				measured = true
+			}
+		}
+		stats = append(stats, NewObjectStats(results[endpointID]))
+	}
+	if !measured {
+		return nil
+	}
+
+	var rows [][]string
+	for _, objectRow := range objectRows {
+		row := []string{objectRow.name}
+		for _, endpointStats := range stats {
+			if endpointStats.Count == 0 {
+				row = append(row, "-")
+				continue
+			}
+			row = append(row, formatElapsed(objectRow.value(endpointStats)))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// formatBucketRows returns rows with the error rate and the spread of the
+// throughput over the time buckets of an operation, if any of its results
+// came from a soak test.
+func formatBucketRows(units Units, fileTestSize int, endpointIDs []config.ID, results endpointResults) [][]string {
+	measured := false
+	stats := make([]BucketStats, 0, len(endpointIDs))
+	for _, endpointID := range endpointIDs {
+		for _, result := range results[endpointID] {
+			if len(result.Buckets) > 0 {
+				measured = true
+			}
+		}
+		stats = append(stats, NewBucketStats(fileTestSize, results[endpointID]))
+	}
+	if !measured {
+		return nil
+	}
+
+	rows := [][]string{{"  error rate"}, {"  bucket min"}, {"  bucket max"}, {"  bucket stddev"}}
+	for _, endpointStats := range stats {
+		if endpointStats.Count == 0 {
+			for i := range rows {
+				rows[i] = append(rows[i], "-")
+			}
+			continue
+		}
+		errorRate := 100 * float64(endpointStats.Errors) / float64(endpointStats.Operations)
+		rows[0] = append(rows[0], strconv.FormatFloat(errorRate, 'f', 2, 64)+"%")
+		rows[1] = append(rows[1], units.format(endpointStats.Min))
+		rows[2] = append(rows[2], units.format(endpointStats.Max))
+		rows[3] = append(rows[3], units.format(endpointStats.StdDev))
+	}
+	return rows
+}
+
+// formatConflictRows returns rows with how many objects of a conflict test
+// ended up with the contents of the writer which finished last and how
+// many were corrupted, if any of the results verified conflicts.
+func formatConflictRows(endpointIDs []config.ID, results endpointResults) [][]string {
+	measured := false
+	for _, endpointID := range endpointIDs {
+		for _, result := range results[endpointID] {
+			if len(result.Conflicts) > 0 {
+				measured = true
+			}
+		}
+	}
+	if !measured {
+		return nil
+	}
+
+	rows := [][]string{{"  last writer won"}, {"  corrupted"}}
+	for _, endpointID := range endpointIDs {
+		var total, lastWriterWon, corrupted int
+		for _, result := range results[endpointID] {
+			for _, conflict := range result.Conflicts {
+				total++
+				switch {
+				case conflict.Winner < 0:
+					corrupted++
+				case conflict.Winner == conflict.LastWriter:
+					lastWriterWon++
+				}
+			}
+		}
+		if total == 0 {
+			rows[0] = append(rows[0], "-")
+			rows[1] = append(rows[1], "-")
+			continue
+		}
+		rows[0] = append(rows[0], fmt.Sprintf("%d/%d", lastWriterWon, total))
+		rows[1] = append(rows[1], fmt.Sprintf("%d/%d", corrupted, total))
+	}
+	return rows
+}
+
+// formatListingRows returns a row per page size of the lists of a listing
+// test with their mean duration and number of pages, if any of the results
+// listed objects.
+func formatListingRows(endpointIDs []config.ID, results endpointResults) [][]string {
+	pageSizes := make(map[int64]bool)
+	for _, endpointID := range endpointIDs {
+		for _, result := range results[endpointID] {
+			for _, listing := range result.Listings {
+				pageSizes[listing.PageSize] = true
+			}
+		}
+	}
+	sorted := make([]int64, 0, len(pageSizes))
+	for pageSize := range pageSizes {
+		sorted = append(sorted, pageSize)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var rows [][]string
+	for _, pageSize := range sorted {
+		row := []string{"  default pages"}
+		if pageSize > 0 {
+			row[0] = fmt.Sprintf("  %d per page", pageSize)
+		}
+		for _, endpointID := range endpointIDs {
+			var count int
+			var duration time.Duration
+			var pages int64
+			for _, result := range results[endpointID] {
+				for _, listing := range result.Listings {
+					if listing.PageSize == pageSize {
+						count++
+						duration += listing.Duration
+						pages += listing.Pages
+					}
+				}
+			}
+			switch {
+			case count == 0:
+				row = append(row, "-")
+			case pages == 0:
+				row = append(row, formatElapsed(duration/time.Duration(count)))
+			default:
+				row = append(row, fmt.Sprintf("%s (%d pages)", duration/time.Duration(count), pages/int64(count)))
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// isolatedOperations maps the operations of mixed tests to the operations
+// measuring the same direction on its own.
+var isolatedOperations = map[config.Operation]config.Operation{
+	config.MixedUpload:   config.Upload,
+	config.MixedDownload: config.Download,
+}
+
+// formatThroughputChangeRow returns a row with the change in throughput of
+// results compared to the baseline results, such as those of an operation
+// of a mixed test compared to its isolated run. The throughputs are those of
+// single operations, since the results may transfer different numbers of
+// objects.
+func formatThroughputChangeRow(label string, endpointIDs []config.ID, results, baseline endpointResults) []string {
+	row := []string{label}
+	for _, endpointID := range endpointIDs {
+		duration, ok := meanObjectDuration(results[endpointID])
+		baselineDuration, baselineOK := meanObjectDuration(baseline[endpointID])
+		if !ok || !baselineOK {
+			row = append(row, "-")
+			continue
+		}
+		row = append(row, fmt.Sprintf("%+.1f%%", 100*(float64(baselineDuration)/float64(duration)-1)))
+	}
+	return row
+}
+
+// meanObjectDuration returns the mean duration of the objects of the
+// successful results, counting results without object durations as a
+// single object, and whether there were any.
+func meanObjectDuration(results []*config.Result) (time.Duration, bool) {
+	var sum time.Duration
+	var count int
+	for _, result := range results {
+		if result.Unsupported || result.Error != "" {
+			continue
+		}
+		if len(result.ObjectDurations) == 0 {
+			sum += result.Duration
+			count++
+			continue
+		}
+		for _, duration := range result.ObjectDurations {
+			sum += duration
+			count++
+		}
+	}
+	if count == 0 || sum <= 0 {
+		return 0, false
+	}
+	return sum / time.Duration(count), true
+}
+
+// appendRelativeNotes appends the mean throughput of every endpoint
+// relative to that of the reference endpoint to its cell of the row. Cells
+// of endpoints without successful results are left as they are.
+func appendRelativeNotes(row []string, fileTestSize int, endpointIDs []config.ID, results endpointResults, referenceID config.ID) {
+	referenceStats := NewStats(results[referenceID])
+	if referenceStats.Successes() == 0 {
+		return
+	}
+	referenceMbps := megabits(fileTestSize*referenceStats.Objects) / referenceStats.Mean.Seconds()
+
+	for i, endpointID := range endpointIDs {
+		stats := NewStats(results[endpointID])
+		if endpointID == referenceID || stats.Successes() == 0 {
+			continue
+		}
+		mbps := megabits(fileTestSize*stats.Objects) / stats.Mean.Seconds()
+		row[i+1] += formatRelative(mbps, referenceMbps, referenceID)
+	}
+}
+
+// formatRelative describes a throughput relative to that of the reference
+// endpoint, such as " (0.84× of s3)".
+func formatRelative(mbps, referenceMbps float64, referenceID config.ID) string {
+	return fmt.Sprintf(" (%.2f× of %s)", mbps/referenceMbps, referenceID)
+}
+
+// repeatedOperations maps the repeated downloads of cache tests to the
+// first downloads.
+var repeatedOperations = map[config.Operation]config.Operation{
+	config.RepeatDownload: config.Download,
+}
+
+// formatCacheRows returns rows with the change in throughput and time to
+// first byte of repeated downloads compared to the first ones.
+func formatCacheRows(fileTestSize int, endpointIDs []config.ID, repeated, first endpointResults) [][]string {
+	ttfbRow := []string{"  ttfb vs first"}
+	for _, endpointID := range endpointIDs {
+		ttfb, firstTTFB := meanFirstByte(repeated[endpointID]), meanFirstByte(first[endpointID])
+		if ttfb <= 0 || firstTTFB <= 0 {
+			ttfbRow = append(ttfbRow, "-")
+			continue
+		}
+		ttfbRow = append(ttfbRow, fmt.Sprintf("%+.1f%%", 100*(float64(ttfb)/float64(firstTTFB)-1)))
+	}
+	return [][]string{
+		formatThroughputChangeRow("  vs first", endpointIDs, repeated, first),
+		ttfbRow,
+	}
+}
+
+// meanFirstByte returns the mean time to first byte of the successful
+// results, or zero if none recorded it.
+func meanFirstByte(results []*config.Result) time.Duration {
+	var sum time.Duration
+	var count int
+	for _, result := range results {
+		if result.Success && result.FirstByte > 0 {
+			sum += result.FirstByte
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return sum / time.Duration(count)
+}
+
+// formatTimingRows returns rows with the mean sub-timings of an operation,
+// if any of its results recorded them.
+func formatTimingRows(endpointIDs []config.ID, results endpointResults) [][]string {
+	timingRows := []struct {
+		name  string
+		value func(*config.Result) time.Duration
+		// measured reports whether the result recorded this timing.
+		measured func(*config.Result) bool
+	}{
+		{"  ttfb",
+			func(r *config.Result) time.Duration { return r.FirstByte },
+			func(r *config.Result) bool { return r.FirstByte > 0 }},
+		{"  transfer",
+			func(r *config.Result) time.Duration { return r.Duration - r.FirstByte },
+			func(r *config.Result) bool { return r.FirstByte > 0 }},
+		{"  streaming",
+			func(r *config.Result) time.Duration { return r.Duration - r.Finalize },
+			func(r *config.Result) bool { return r.Finalize > 0 }},
+		{"  finalize",
+			func(r *config.Result) time.Duration { return r.Finalize },
+			func(r *config.Result) bool { return r.Finalize > 0 }},
+	}
+
+	var rows [][]string
+	for _, timingRow := range timingRows {
+		row := []string{timingRow.name}
+		measured := false
+		for _, endpointID := range endpointIDs {
+			var total time.Duration
+			var count int
+			for _, result := range results[endpointID] {
+				if result.Error != "" || !timingRow.measured(result) {
+					continue
+				}
+				total += timingRow.value(result)
+				count++
+			}
+			if count == 0 {
+				row = append(row, "-")
+				continue
+			}
+			measured = true
+			row = append(row, formatElapsed(total/time.Duration(count)))
+		}
+		if measured {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// formatPartRows returns a row with the mean per-part throughput of an
+// operation, if any of its results uploaded parts.
+func formatPartRows(units Units, endpointIDs []config.ID, results endpointResults) [][]string {
+	row := []string{"  per part"}
+	measured := false
+	for _, endpointID := range endpointIDs {
+		var total float64
+		var count int
+		for _, result := range results[endpointID] {
+			if result.Error != "" {
+				continue
+			}
+			for _, part := range result.Parts {
+				total += megabits(int(part.Size)) / part.Duration.Seconds()
+				count++
+			}
+		}
+		if count == 0 {
+			row = append(row, "-")
+			continue
+		}
+		measured = true
+		row = append(row, units.format(total/float64(count)))
+	}
+	if !measured {
+		return nil
+	}
+	return [][]string{row}
+}
+
+func formatStatsForRow(operation config.Operation, units Units, fileTestSize, rowObjects int, stats Stats) string {
+	switch {
+	case stats.Count == 0 && stats.Unsupported > 0:
+		return "N/A"
+	case stats.Count == 0:
+		return "-"
+	case stats.Successes() == 0:
+		return "ERR" + formatErrorCategories(stats.ErrorCategories)
+	}
+
+	return formatDuration(operation, units, fileTestSize, stats.Objects, rowObjects, stats.Mean) + formatStatsNotes(stats)
+}
+
+// formatErrorCategories describes the categories of failed results, such
+// as " timeout" or " 2 timeout/1 5xx". Unclassified failures aren't
+// described.
+func formatErrorCategories(categories map[config.ErrorCategory]int) string {
+	var described []string
+	var last config.ErrorCategory
+	for _, category := range config.ErrorCategories {
+		if count := categories[category]; count > 0 {
+			described = append(described, fmt.Sprintf("%d %s", count, category))
+			last = category
+		}
+	}
+	switch len(described) {
+	case 0:
+		return ""
+	case 1:
+		return " " + string(last)
+	default:
+		return " " + strings.Join(described, "/")
+	}
+}
+
+// formatStatsNotes describes the failures and retries of repeated results.
+func formatStatsNotes(stats Stats) string {
+	if stats.Count == 1 {
+		return formatRetries(stats.Retries)
+	}
+
+	var notes []string
+	if stats.Errors > 0 {
+		notes = append(notes, fmt.Sprintf("%d/%d ERR", stats.Errors, stats.Count)+formatErrorCategories(stats.ErrorCategories))
+	}
+	if stats.Retries > 0 {
+		notes = append(notes, fmt.Sprintf("%d/%d first attempt", stats.FirstAttemptSuccesses, stats.Count))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
+
+func hasLatencies(results endpointResults) bool {
+	for _, endpointResults := range results {
+		for _, result := range endpointResults {
+			if len(result.Latencies) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatLatencyRows returns a row with the operation rate of a latency test
+// followed by one row per statistic of the latencies of all operations.
+func formatLatencyRows(operation config.Operation, endpointIDs []config.ID, results endpointResults) [][]string {
+	summaryRow := []string{operation.String()}
+	latencyStats := make([]Stats, 0, len(endpointIDs))
+	for _, endpointID := range endpointIDs {
+		stats := NewStats(results[endpointID])
+		latencyStats = append(latencyStats, newLatencyStats(results[endpointID]))
+
+		var operations int
+		var total time.Duration
+		for _, result := range results[endpointID] {
+			if result.Error == "" {
+				operations += len(result.Latencies)
+				total += result.Duration
+			}
+		}
+
+		switch {
+		case stats.Count == 0 && stats.Unsupported > 0:
+			summaryRow = append(summaryRow, "N/A")
+		case stats.Count == 0:
+			summaryRow = append(summaryRow, "-")
+		case stats.Successes() == 0:
+			summaryRow = append(summaryRow, "ERR"+formatStatsNotes(stats))
+		default:
+			opsPerSecond := float64(operations) / total.Seconds()
+			summaryRow = append(summaryRow, formatRate(opsPerSecond, "ops/s")+formatStatsNotes(stats))
+		}
+	}
+
+	rows := [][]string{summaryRow}
+	for _, statRow := range statRows {
+		row := []string{statRow.name}
+		for _, stats := range latencyStats {
+			if stats.Count == 0 {
+				row = append(row, "-")
+				continue
+			}
+			row = append(row, formatElapsed(statRow.value(stats)))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// measuresThroughput returns whether the results of the operation measure
+// the throughput of whole file transfers.
+func measuresThroughput(operation config.Operation, results endpointResults) bool {
+	if !operation.TransfersFile() {
+		return false
+	}
+	return !hasLatencies(results) && !hasRamp(results)
+}
+
+func hasRamp(results endpointResults) bool {
+	for _, endpointResults := range results {
+		for _, result := range endpointResults {
+			if result.Parallelism > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatRampRows returns a row with the best parallelism level of a ramp
+// test followed by one row per level.
+func formatRampRows(operation config.Operation, units Units, fileTestSize int, endpointIDs []config.ID, results endpointResults) [][]string {
+	levelSet := make(map[int64]bool)
+	for _, endpointResults := range results {
+		for _, result := range endpointResults {
+			levelSet[result.Parallelism] = true
+		}
+	}
+	levels := make([]int64, 0, len(levelSet))
+	for level := range levelSet {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+
+	summaryRow := []string{operation.String()}
+	rowObjects := maxObjects(results)
+	levelRows := make([][]string, 0, len(levels))
+	for _, level := range levels {
+		levelRows = append(levelRows, []string{fmt.Sprintf("  parallel %d", level)})
+	}
+
+	for _, endpointID := range endpointIDs {
+		best := "-"
+		var bestRate float64
+		for i, level := range levels {
+			var levelResults []*config.Result
+			for _, result := range results[endpointID] {
+				if result.Parallelism == level {
+					levelResults = append(levelResults, result)
+				}
+			}
+
+			stats := NewStats(levelResults)
+			cell := formatStatsForRow(operation, units, fileTestSize, rowObjects, stats)
+			levelRows[i] = append(levelRows[i], cell)
+
+			if stats.Successes() == 0 {
+				continue
+			}
+			// Objects per second rank the levels the same way as throughput.
+			if rate := float64(stats.Objects) / stats.Mean.Seconds(); rate > bestRate {
+				bestRate = rate
+				best = fmt.Sprintf("%s @ %d", formatDuration(operation, units, fileTestSize, stats.Objects, rowObjects, stats.Mean), level)
+			}
+		}
+		summaryRow = append(summaryRow, best)
+	}
+
+	return append([][]string{summaryRow}, levelRows...)
+}
+
+func writeWithBreak(builder *strings.Builder, s string) {
+	builder.WriteString(s)
+	writeBreak(builder)
+}
+
+func writeBreak(builder *strings.Builder) {
+	builder.WriteRune('\n')
+}
+
+// tablePadding is the number of spaces between the columns of tables.
+const tablePadding = 5
+
+// MakeTable creates a formatted test table based on the rows provided.
+func MakeTable(rows [][]string, headerSeperator string) (string, error) {
+	var numColumns int
+	var table strings.Builder
+
+	maxColumnLenghts := make(map[int]int)
+	for _, row := range rows {
+		if numColumns == 0 {
+			numColumns = len(row)
+		}
+		if len(row) != numColumns {
+			return "", errs.New("Mismatched column numbers")
+		}
+
+		for i, item := range row {
+			// Count runes, so that cells such as "0.84× of s3" line up.
+			if width := utf8.RuneCountInString(item); width > maxColumnLenghts[i] {
+				maxColumnLenghts[i] = width
+			}
+		}
+	}
+
+	for i, row := range rows {
+		for i, item := range row {
+			var postItem string
+
+			if i < len(row)-1 {
+				// Add padding. We default to 5 spaces after the longest item.
+				postItem = strings.Repeat(" ", maxColumnLenghts[i]-utf8.RuneCountInString(item)+tablePadding)
+			} else {
+				// If it's the last item in the list add a line break.
+				postItem = "\n"
+			}
+			table.WriteString(item + postItem)
+		}
+
+		// If we just wrote the first row, now write the header separator.
+		if i == 0 && headerSeperator != "" {
+			totalLength := 0
+			totalLength += (numColumns - 1) * tablePadding // add padding for all but the last item
+			for _, maxLength := range maxColumnLenghts {
+				totalLength += maxLength
+			}
+			writeWithBreak(&table, strings.Repeat(headerSeperator, totalLength))
+		}
+	}
+
+	return table.String(), nil
+}
@@ -0,0 +1,1411 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+type reportTest struct {
+	operation  config.Operation
+	fileTestID config.ID
+	endpointID config.ID
+	result     *config.Result
+}
+
+func TestTextReporter(t *testing.T) {
+	// - Result with error
+	// - missing endpointID
+	// - missing fileTestID
+	ctx := testcontext.New(t)
+	tests := []struct {
+		fileTestSizes map[config.ID]int
+		options       report.Options
+		reports       []*reportTest
+		expected      string
+	}{
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+				"ft2": 20000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1           end2
+---------------------------------------
+Upload        16.00 Mbps     10.00 Mbps
+Download      -              10.00 Mbps
+
+*********
+File: ft2
+*********
+
+Operation     end1     end2
+---------------------------------
+Upload        -        -
+Download      -        20.00 Mbps
+
+*******
+Summary
+*******
+
+Metric                                 end1           end2
+----------------------------------------------------------------
+Throughput (geomean of 1/3 shared)     16.00 Mbps     10.00 Mbps
+Errors                                 0              0
+Bytes moved                            10.00 MB       40.00 MB
+Operation time (sum)                   5s             24s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+						Error:    "",
+						Bytes:    10000000,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration: 8 * time.Second,
+						Success:  true,
+						Error:    "",
+						Bytes:    10000000,
+					},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration: 8 * time.Second,
+						Success:  true,
+						Error:    "",
+						Bytes:    10000000,
+					},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft2",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration: 8 * time.Second,
+						Success:  true,
+						Error:    "",
+						Bytes:    20000000,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1
+------------------
+Upload        ERR
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+						Error:    "Here is an error",
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1                     end2
+-------------------------------------------------
+Upload        10.00 Mbps (1/3 ERR)     20.00 Mbps
+  min         6s                       4s
+  max         10s                      4s
+  mean        8s                       4s
+  median      6s                       4s
+  p95         10s                      4s
+  p99         10s                      4s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 10 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 6 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: time.Second,
+						Error:    "Here is an error",
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration: 4 * time.Second,
+						Success:  true,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation       end1           end2
+-----------------------------------------
+Upload          16.00 Mbps     10.00 Mbps
+  streaming     4s             -
+  finalize      1s             -
+Download        10.00 Mbps     20.00 Mbps
+  ttfb          -              500ms
+  transfer      -              3.5s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+						Finalize: time.Second,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration: 8 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 8 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration:  4 * time.Second,
+						Success:   true,
+						FirstByte: 500 * time.Millisecond,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1                     end2
+------------------------------------------------------
+Upload        16.00 Mbps (1 retry)     ERR (2 retries)
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+						Attempts: []config.Attempt{{Error: "timeout"}, {}},
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Error:    "timeout",
+						Attempts: []config.Attempt{{Error: "timeout"}, {Error: "timeout"}, {Error: "timeout"}},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation           end1
+------------------------------
+Upload              80.00 Mbps
+  object min        1s
+  object max        3s
+  object stddev     707.107ms
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        4 * time.Second,
+						Success:         true,
+						ObjectDurations: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 2 * time.Second},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1           http
+---------------------------------------
+Upload        16.00 Mbps     N/A
+Download      16.00 Mbps     10.00 Mbps
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "http",
+					result: &config.Result{
+						Error:       "unsupported operation: Upload",
+						Unsupported: true,
+					},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "http",
+					result: &config.Result{
+						Duration: 8 * time.Second,
+						Success:  true,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 1000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1
+------------------------
+Upload        2.00 ops/s
+  min         100ms
+  max         900ms
+  mean        500ms
+  median      500ms
+  p95         900ms
+  p99         900ms
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:  time.Second,
+						Success:   true,
+						Latencies: []time.Duration{100 * time.Millisecond, 500 * time.Millisecond},
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:  time.Second,
+						Success:   true,
+						Latencies: []time.Duration{900 * time.Millisecond, 500 * time.Millisecond},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 1000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation        end1
+-------------------------------
+Upload           16.00 Mbps @ 2
+  parallel 1     8.00 Mbps
+  parallel 2     16.00 Mbps
+  parallel 4     8.00 Mbps
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        time.Second,
+						Success:         true,
+						Parallelism:     1,
+						ObjectDurations: make([]time.Duration, 1),
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        time.Second,
+						Success:         true,
+						Parallelism:     2,
+						ObjectDurations: make([]time.Duration, 2),
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        4 * time.Second,
+						Success:         true,
+						Parallelism:     4,
+						ObjectDurations: make([]time.Duration, 4),
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 1000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation           end1
+------------------------------
+Download            12.00 Mbps
+  object min        1s
+  object max        1s
+  object stddev     0s
+  error rate        25.00%
+  bucket min        8.00 Mbps
+  bucket max        16.00 Mbps
+  bucket stddev     4.00 Mbps
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        2 * time.Second,
+						Success:         true,
+						ObjectDurations: []time.Duration{time.Second, time.Second, time.Second},
+						Buckets: []config.Bucket{
+							{Duration: time.Second, Objects: 2},
+							{Duration: time.Second, Objects: 1, Errors: 1},
+						},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 1000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation             end1
+-------------------------------
+ConflictVerify        8.00 Mbps
+  last writer won     1/3
+  corrupted           1/3
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.ConflictVerify,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: time.Second,
+						Success:  false,
+						Conflicts: []config.Conflict{
+							{Object: 0, Winner: 2, LastWriter: 2},
+							{Object: 1, Winner: 0, LastWriter: 1},
+							{Object: 2, Winner: -1, LastWriter: 0},
+						},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 1000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation           end1               end2
+--------------------------------------------
+ListRecursive       3s                 500ms
+  default pages     -                  500ms
+  100 per page      2s (100 pages)     -
+  1000 per page     1s (10 pages)      -
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.ListRecursive,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 3 * time.Second,
+						Success:  true,
+						Listings: []config.Listing{
+							{PageSize: 100, Pages: 100, Objects: 10000, Duration: 2 * time.Second},
+							{PageSize: 1000, Pages: 10, Objects: 10000, Duration: time.Second},
+						},
+					},
+				},
+				{
+					operation:  config.ListRecursive,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration: 500 * time.Millisecond,
+						Success:  true,
+						Listings: []config.Listing{
+							{Objects: 10000, Duration: 500 * time.Millisecond},
+						},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 1000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation           end1
+------------------------------
+Upload              32.00 Mbps
+  object min        1s
+  object max        1s
+  object stddev     0s
+MixedUpload         8.00 Mbps
+  object min        2s
+  object max        2s
+  object stddev     0s
+  vs isolated       -50.0%
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        time.Second,
+						Success:         true,
+						ObjectDurations: []time.Duration{time.Second, time.Second, time.Second, time.Second},
+					},
+				},
+				{
+					// Fewer objects in the same time aren't a slowdown of
+					// each operation by themselves.
+					operation:  config.MixedUpload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        2 * time.Second,
+						Success:         true,
+						ObjectDurations: []time.Duration{2 * time.Second, 2 * time.Second},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 1000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation           end1
+-----------------------------
+Download            4.00 Mbps
+  ttfb              400ms
+  transfer          1.6s
+RepeatDownload      8.00 Mbps
+  vs first          +100.0%
+  ttfb vs first     -75.0%
+  ttfb              100ms
+  transfer          900ms
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:  2 * time.Second,
+						FirstByte: 400 * time.Millisecond,
+						Success:   true,
+					},
+				},
+				{
+					operation:  config.RepeatDownload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:  time.Second,
+						FirstByte: 100 * time.Millisecond,
+						Success:   true,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1                             end2
+------------------------------------------------------------------
+Upload        16.00 Mbps (1/2 ERR timeout)     ERR 1 timeout/1 5xx
+  min         5s                               -
+  max         5s                               -
+  mean        5s                               -
+  median      5s                               -
+  p95         5s                               -
+  p99         5s                               -
+Download      ERR checksum                     -
+
+******
+Errors
+******
+
+Category     end1     end2
+--------------------------
+timeout      1        1
+5xx          0        1
+checksum     1        0
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Error:         "context deadline exceeded",
+						ErrorCategory: config.TimeoutError,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Error:         "503 Service Unavailable",
+						ErrorCategory: config.ServerError,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Error:         "context deadline exceeded",
+						ErrorCategory: config.TimeoutError,
+					},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Error:         "checksum mismatch",
+						ErrorCategory: config.ChecksumError,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1
+---------------------------------------
+Upload        ERR 5xx (7/8 OK, 1 retry)
+
+******
+Errors
+******
+
+Category     end1
+-----------------
+5xx          1
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Error:           "503 Service Unavailable",
+						ErrorCategory:   config.ServerError,
+						ObjectDurations: make([]time.Duration, 8),
+						Succeeded:       7,
+						FailedObjects:   []int{3},
+						Attempts:        []config.Attempt{{Error: "503 Service Unavailable"}, {Error: "503 Service Unavailable"}},
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			options: report.Options{Units: report.MiBps},
+			expected: `*********
+File: ft1
+*********
+
+Operation           end1           end2
+---------------------------------------------
+Upload              1.91 MiB/s     -
+Delete              1.00 ops/s     3.33 ops/s
+  object min        100ms          -
+  object max        300ms          -
+  object stddev     100ms          -
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 5 * time.Second,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Delete,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        2 * time.Second,
+						Success:         true,
+						ObjectDurations: []time.Duration{100 * time.Millisecond, 300 * time.Millisecond},
+						Succeeded:       2,
+					},
+				},
+				{
+					operation:  config.Delete,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Duration: 300 * time.Millisecond,
+						Success:  true,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+				"ft2": 20000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation           end1           end2
+---------------------------------------
+Upload              40.00 Mbps     N/A
+  object min        2s             -
+  object max        2s             -
+  object stddev     0s             -
+Delete              -              -
+
+*********
+File: ft2
+*********
+
+Operation     end1                     end2
+-------------------------------------------
+Upload        ERR timeout (1/2 OK)     -
+Delete        500ms                    -
+
+******
+Errors
+******
+
+Category     end1     end2
+--------------------------
+timeout      1        0
+
+*******
+Summary
+*******
+
+Metric                                 end1         end2
+--------------------------------------------------------
+Throughput (geomean of 0/1 shared)     -            -
+Errors                                 1            0
+Bytes moved                            40.00 MB     0 B
+Operation time (sum)                   7.5s         0s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        4 * time.Second,
+						Success:         true,
+						ObjectDurations: []time.Duration{2 * time.Second, 2 * time.Second},
+						Succeeded:       2,
+						Bytes:           20000000,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft2",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:        3 * time.Second,
+						Error:           "context deadline exceeded",
+						ErrorCategory:   config.TimeoutError,
+						ObjectDurations: []time.Duration{2 * time.Second, 3 * time.Second},
+						Succeeded:       1,
+						FailedObjects:   []int{1},
+						Bytes:           20000000,
+					},
+				},
+				{
+					operation:  config.Delete,
+					fileTestID: "ft2",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: 500 * time.Millisecond,
+						Success:  true,
+					},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result: &config.Result{
+						Unsupported: true,
+					},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+				"ft2": 20000000,
+			},
+			options: report.Options{ReferenceEndpoint: "end2"},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1                           end2           end3
+----------------------------------------------------------------
+Upload        16.00 Mbps (0.80× of end2)     20.00 Mbps     ERR
+Download      -                              -              -
+Delete        1s                             2s             -
+
+*********
+File: ft2
+*********
+
+Operation     end1                           end2           end3
+--------------------------------------------------------------------------------------
+Upload        -                              -              -
+Download      32.00 Mbps (1.60× of end2)     20.00 Mbps     10.00 Mbps (0.50× of end2)
+  min         4s                             8s             16s
+  max         6s                             8s             16s
+  mean        5s                             8s             16s
+  median      4s                             8s             16s
+  p95         6s                             8s             16s
+  p99         6s                             8s             16s
+Delete        -                              -              -
+
+*******
+Summary
+*******
+
+Metric                                 end1                           end2           end3
+---------------------------------------------------------------------------------------------------------------
+Throughput (geomean of 1/2 shared)     32.00 Mbps (1.60× of end2)     20.00 Mbps     10.00 Mbps (0.50× of end2)
+Errors                                 0                              0              1
+Bytes moved                            0 B                            0 B            0 B
+Operation time (sum)                   16s                            14s            16s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 5 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result:     &config.Result{Duration: 4 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end3",
+					result:     &config.Result{Error: "Here is an error"},
+				},
+				{
+					operation:  config.Delete,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: time.Second, Success: true},
+				},
+				{
+					operation:  config.Delete,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result:     &config.Result{Duration: 2 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft2",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 4 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft2",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 6 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft2",
+					endpointID: "end2",
+					result:     &config.Result{Duration: 8 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft2",
+					endpointID: "end3",
+					result:     &config.Result{Duration: 16 * time.Second, Success: true},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			options: report.Options{Columns: []string{"end1", "end3", "P95"}},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1           end3
+--------------------------------------
+Download      16.00 Mbps     5.00 Mbps
+  p95         6s             16s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 5 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result:     &config.Result{Duration: 4 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end3",
+					result:     &config.Result{Duration: 10 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 4 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 6 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result:     &config.Result{Duration: 8 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end3",
+					result:     &config.Result{Duration: 16 * time.Second, Success: true},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+			},
+			options: report.Options{Width: 40},
+			expected: `*********
+File: ft1
+*********
+
+Operation     end1           end2
+---------------------------------------
+Upload        16.00 Mbps     20.00 Mbps
+Download      16.00 Mbps     10.00 Mbps
+  min         4s             8s
+  max         6s             8s
+  mean        5s             8s
+  median      4s             8s
+  p95         6s             8s
+  p99         6s             8s
+
+Operation     end3
+-----------------------
+Upload        8.00 Mbps
+Download      5.00 Mbps
+  min         16s
+  max         16s
+  mean        16s
+  median      16s
+  p95         16s
+  p99         16s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 5 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result:     &config.Result{Duration: 4 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end3",
+					result:     &config.Result{Duration: 10 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 4 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result:     &config.Result{Duration: 6 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end2",
+					result:     &config.Result{Duration: 8 * time.Second, Success: true},
+				},
+				{
+					operation:  config.Download,
+					fileTestID: "ft1",
+					endpointID: "end3",
+					result:     &config.Result{Duration: 16 * time.Second, Success: true},
+				},
+			},
+		},
+		{
+			fileTestSizes: map[config.ID]int{
+				"ft1": 10000000,
+				"ft2": 20000000,
+			},
+			expected: `*********
+File: ft1
+*********
+
+Operation         end1
+----------------------------
+Upload            1.00 ops/s
+  min             1s
+  max             1s
+  mean            1s
+  median          1s
+  p95             1s
+  p99             1s
+RangeDownload     -
+
+*********
+File: ft2
+*********
+
+Operation         end1
+----------------------
+Upload            -
+RangeDownload     1s
+
+*******
+Summary
+*******
+
+Metric                   end1
+---------------------------------
+Throughput (geomean)     -
+Errors                   0
+Bytes moved              25.00 MB
+Operation time (sum)     3s
+
+`,
+			reports: []*reportTest{
+				{
+					operation:  config.Upload,
+					fileTestID: "ft1",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration:  2 * time.Second,
+						Success:   true,
+						Latencies: []time.Duration{time.Second, time.Second},
+						Bytes:     20000000,
+					},
+				},
+				{
+					operation:  config.RangeDownload,
+					fileTestID: "ft2",
+					endpointID: "end1",
+					result: &config.Result{
+						Duration: time.Second,
+						Success:  true,
+						Bytes:    5000000,
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		reporter := report.NewTextReporter(test.fileTestSizes)
+		reporter.SetOptions(test.options)
+		var eg errgroup.Group
+		for _, rt := range test.reports {
+			// Call Report concurrently just to ensure it works.
+			func(rt *reportTest) {
+				eg.Go(func() error {
+					return reporter.Report(ctx, rt.operation, rt.fileTestID, rt.endpointID, rt.result)
+				})
+			}(rt)
+		}
+		require.NoError(t, eg.Wait())
+		str, err := reporter.FormatResults(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, str)
+	}
+}
+
+func TestTextReporterMetadata(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	reporter := report.NewTextReporter(map[config.ID]int{"ft1": 10000000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Duration: 5 * time.Second, Success: true}))
+	reporter.SetMetadata(report.Metadata{
+		Hostname:      "host1",
+		OS:            "linux/amd64",
+		GoVersion:     "go1.14",
+		Version:       "v1.0.0",
+		UplinkVersion: "v1.3.0",
+		AWSSDKVersion: "v1.34.24",
+		StartTime:     time.Date(2020, 9, 1, 12, 0, 0, 0, time.UTC),
+		ConfigHash:    "abc",
+		RunID:         "run1",
+		Mode:          "simultaneous, shuffled",
+		ShuffleSeed:   42,
+		Network: map[config.ID]config.NetworkTimings{
+			"end1": {Address: "192.0.2.1:443", IPs: []config.IPLocation{{IP: "192.0.2.1", Location: "Ashburn, VA, US"}, {IP: "192.0.2.3"}}, DNS: 12345 * time.Microsecond, Connect: 30 * time.Millisecond, TLSHandshake: 45 * time.Millisecond},
+			"end2": {Address: "192.0.2.2:80", DNS: time.Millisecond, Connect: 2 * time.Millisecond},
+			"end3": {Address: "example.invalid:443", Error: "no such host"},
+		},
+		Settings: map[config.ID]string{
+			"end1": "download 8 x 16.0 MiB parts, upload 5 x 5.0 MiB parts",
+		},
+		Servers: map[config.ID]string{
+			"end2": "online, 4/4 servers online, 16/16 drives ok, version 2020-10-03T02:19:42Z",
+		},
+	})
+
+	str, err := reporter.FormatResults(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, `Host:          host1
+OS:            linux/amd64
+Go:            go1.14
+perftester:    v1.0.0
+uplink:        v1.3.0
+aws-sdk-go:    v1.34.24
+Started:       2020-09-01T12:00:00Z
+Finished:      -
+Config hash:   abc
+Run ID:        run1
+Mode:          simultaneous, shuffled
+Shuffle seed:  42
+Settings end1: download 8 x 16.0 MiB parts, upload 5 x 5.0 MiB parts
+Server end2:   online, 4/4 servers online, 16/16 drives ok, version 2020-10-03T02:19:42Z
+Network end1:  192.0.2.1:443 dns 12.3ms, connect 30ms, tls 45ms
+IPs end1:      192.0.2.1 (Ashburn, VA, US), 192.0.2.3
+Network end2:  192.0.2.2:80 dns 1ms, connect 2ms
+Network end3:  example.invalid:443 error: no such host
+
+*********
+File: ft1
+*********
+
+Operation     end1
+------------------------
+Upload        16.00 Mbps
+
+`, str)
+}
+
+func TestMakeTable(t *testing.T) {
+	tests := []struct {
+		rows            [][]string
+		headerSeperator string
+		expected        string
+	}{
+		{
+			rows: [][]string{
+				{"this", "is", "a", "header"},
+				{"here", "we", "find", "row2"},
+				{"different", "items", "a", "b"},
+			},
+			headerSeperator: "-",
+			expected: `this          is        a        header
+---------------------------------------
+here          we        find     row2
+different     items     a        b
+`,
+		},
+		{
+			rows: [][]string{
+				{"this", "is", "a", "header"},
+				{"here", "we", "find", "row2"},
+				{"different", "items", "a", "b"},
+			},
+			headerSeperator: "i",
+			expected: `this          is        a        header
+iiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiiii
+here          we        find     row2
+different     items     a        b
+`,
+		},
+		{
+			rows: [][]string{
+				{"this", "is", "a", "header"},
+				{"here", "we", "find", "row2"},
+				{"different", "items", "a", "b"},
+			},
+			headerSeperator: "",
+			expected: `this          is        a        header
+here          we        find     row2
+different     items     a        b
+`,
+		},
+	}
+
+	for _, test := range tests {
+		table, err := report.MakeTable(test.rows, test.headerSeperator)
+		require.NoError(t, err)
+
+		require.Equal(t, test.expected, table)
+	}
+}
+
+func TestNewStats(t *testing.T) {
+	var results []*config.Result
+	for i := 1; i <= 100; i++ {
+		results = append(results, &config.Result{Duration: time.Duration(i) * time.Second, Success: true})
+	}
+	results = append(results, &config.Result{Error: "failed"})
+
+	stats := report.NewStats(results)
+	assert.Equal(t, 101, stats.Count)
+	assert.Equal(t, 1, stats.Errors)
+	assert.Equal(t, 100, stats.Successes())
+	assert.Equal(t, time.Second, stats.Min)
+	assert.Equal(t, 100*time.Second, stats.Max)
+	assert.Equal(t, 50500*time.Millisecond, stats.Mean)
+	assert.Equal(t, 50*time.Second, stats.Median)
+	assert.Equal(t, 95*time.Second, stats.P95)
+	assert.Equal(t, 99*time.Second, stats.P99)
+}
+
+func TestTextReporterColumns(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	reporter := report.NewTextReporter(map[config.ID]int{"ft1": 10000000, "ft2": 10000000})
+	require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Duration: 5 * time.Second, Success: true}))
+	require.NoError(t, reporter.Report(ctx, config.Download, "ft2", "end1", &config.Result{Duration: 5 * time.Second, Success: true}))
+
+	// File tests without the selected metric are kept.
+	reporter.SetOptions(report.Options{Columns: []string{"end1", "download"}})
+	str, err := reporter.FormatResults(ctx)
+	require.NoError(t, err)
+	require.Contains(t, str, "File: ft1")
+	require.Contains(t, str, "File: ft2")
+	require.NotContains(t, str, "Upload")
+
+	for _, columns := range [][]string{{"end2"}, {"end1", "p99.9"}} {
+		reporter.SetOptions(report.Options{Columns: columns})
+		_, err := reporter.FormatResults(ctx)
+		require.Error(t, err, "%q", columns)
+	}
+}
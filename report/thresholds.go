@@ -0,0 +1,82 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zeebo/errs"
+
+	"storj.io/perftester/config"
+)
+
+// ErrThreshold is the error class of runs which violated their thresholds.
+var ErrThreshold = errs.Class("threshold")
+
+// ThresholdReporter gathers reports and checks them against the failure
+// thresholds of the run.
+type ThresholdReporter struct {
+	collector
+	thresholds config.Thresholds
+}
+
+// NewThresholdReporter creates a ThresholdReporter.
+func NewThresholdReporter(fileTestSizes map[config.ID]int, thresholds config.Thresholds) *ThresholdReporter {
+	return &ThresholdReporter{
+		collector:  newCollector(fileTestSizes),
+		thresholds: thresholds,
+	}
+}
+
+// Check returns an ErrThreshold error listing every violated threshold.
+func (s *ThresholdReporter) Check() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var violations []string
+	operationCounts := make(map[config.ID]int)
+	errorCounts := make(map[config.ID]int)
+
+	fileTestIDs, endpointIDs, operations := uniqueSortedIDs(s.results)
+	for _, fileTestID := range fileTestIDs {
+		for _, operation := range operations {
+			results := s.results[fileTestID][operation]
+			for _, endpointID := range endpointIDs {
+				stats := NewStats(results[endpointID])
+				operationCounts[endpointID] += stats.Count
+				errorCounts[endpointID] += stats.Errors
+
+				if s.thresholds.FailOnError && stats.Errors > 0 {
+					violations = append(violations, fmt.Sprintf("%s %s on %s failed %d of %d times", fileTestID, operation, endpointID, stats.Errors, stats.Count))
+				}
+
+				minMbps, ok := s.thresholds.MinThroughput[endpointID]
+				if !ok || stats.Successes() == 0 || !measuresThroughput(operation, results) {
+					continue
+				}
+				if mbps := megabits(s.fileTestSizes[fileTestID]*stats.Objects) / stats.Mean.Seconds(); mbps < minMbps {
+					violations = append(violations, fmt.Sprintf("%s %s on %s ran at %s, below %s", fileTestID, operation, endpointID, formatMbps(mbps), formatMbps(minMbps)))
+				}
+			}
+		}
+	}
+
+	if s.thresholds.MaxErrorRate > 0 {
+		for _, endpointID := range endpointIDs {
+			if operationCounts[endpointID] == 0 {
+				continue
+			}
+			errorRate := 100 * float64(errorCounts[endpointID]) / float64(operationCounts[endpointID])
+			if errorRate > s.thresholds.MaxErrorRate {
+				violations = append(violations, fmt.Sprintf("%s error rate %.2f%% above %.2f%%", endpointID, errorRate, s.thresholds.MaxErrorRate))
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return ErrThreshold.New("%s", strings.Join(violations, "; "))
+}
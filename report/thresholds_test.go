@@ -0,0 +1,49 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/testcontext"
+	"storj.io/perftester/config"
+	"storj.io/perftester/report"
+)
+
+func TestThresholdReporter(t *testing.T) {
+	ctx := testcontext.New(t)
+
+	report10Mbps := func(reporter *report.ThresholdReporter) {
+		// One 10 Mbps upload on each endpoint and a failed download on end2.
+		require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end1", &config.Result{Duration: time.Second, Success: true}))
+		require.NoError(t, reporter.Report(ctx, config.Upload, "ft1", "end2", &config.Result{Duration: time.Second, Success: true}))
+		require.NoError(t, reporter.Report(ctx, config.Download, "ft1", "end2", &config.Result{Error: "failed"}))
+	}
+	sizes := map[config.ID]int{"ft1": 1250000}
+
+	reporter := report.NewThresholdReporter(sizes, config.Thresholds{})
+	report10Mbps(reporter)
+	require.NoError(t, reporter.Check())
+
+	reporter = report.NewThresholdReporter(sizes, config.Thresholds{
+		MaxErrorRate:  50,
+		MinThroughput: map[config.ID]float64{"end1": 5, "end2": 20},
+	})
+	report10Mbps(reporter)
+	err := reporter.Check()
+	require.True(t, report.ErrThreshold.Has(err))
+	require.Contains(t, err.Error(), "ft1 Upload on end2 ran at 10.00 Mbps, below 20.00 Mbps")
+	require.NotContains(t, err.Error(), "end1")
+	require.NotContains(t, err.Error(), "error rate")
+
+	reporter = report.NewThresholdReporter(sizes, config.Thresholds{FailOnError: true, MaxErrorRate: 10})
+	report10Mbps(reporter)
+	err = reporter.Check()
+	require.True(t, report.ErrThreshold.Has(err))
+	require.Contains(t, err.Error(), "ft1 Download on end2 failed 1 of 1 times")
+	require.Contains(t, err.Error(), "end2 error rate 50.00% above 10.00%")
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2020 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package report
+
+import (
+	"strconv"
+
+	"github.com/zeebo/errs"
+)
+
+// Units are the units reports give throughputs in.
+type Units string
+
+const (
+	// Mbps are megabits per second. They are the default.
+	Mbps Units = "Mbps"
+	// MBps are megabytes per second.
+	MBps Units = "MBps"
+	// MiBps are mebibytes per second.
+	MiBps Units = "MiBps"
+	// Gbps are gigabits per second.
+	Gbps Units = "Gbps"
+)
+
+// ParseUnits returns the units with the name, such as "MiBps". The empty
+// name selects the default Mbps.
+func ParseUnits(name string) (Units, error) {
+	switch units := Units(name); units {
+	case "":
+		return Mbps, nil
+	case Mbps, MBps, MiBps, Gbps:
+		return units, nil
+	default:
+		return "", errs.New("unknown units %q: expected Mbps, MBps, MiBps or Gbps", name)
+	}
+}
+
+// format formats a throughput given in Mbps in the units.
+func (units Units) format(mbps float64) string {
+	switch units {
+	case MBps:
+		return formatRate(mbps/8, "MB/s")
+	case MiBps:
+		return formatRate(mbps*1000*1000/8/(1<<20), "MiB/s")
+	case Gbps:
+		return formatRate(mbps/1000, "Gbps")
+	default:
+		return formatMbps(mbps)
+	}
+}
+
+// formatMbps formats a throughput in Mbps, the units thresholds and SLA
+// rules are configured in.
+func formatMbps(mbps float64) string {
+	return formatRate(mbps, "Mbps")
+}
+
+// formatRate formats a rate, such as a throughput or the operations per
+// second, rounded to two decimals like all rates of reports.
+func formatRate(rate float64, unit string) string {
+	return strconv.FormatFloat(rate, 'f', 2, 64) + " " + unit
+}